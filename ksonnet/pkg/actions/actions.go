@@ -25,8 +25,10 @@ import (
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	"github.com/ksonnet/ksonnet/pkg/upgrade"
+	"github.com/ksonnet/ksonnet/pkg/util/dockerregistry"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 )
@@ -40,14 +42,26 @@ const (
 	OptionArguments = "arguments"
 	// OptionAsString is asString. Used for setting values as strings.
 	OptionAsString = "as-string"
+	// OptionCheck is check option. Used to report problems without making changes.
+	OptionCheck = "check"
+	// OptionChartName is chart name option.
+	OptionChartName = "chart-name"
+	// OptionChartVersion is chart version option.
+	OptionChartVersion = "chart-version"
+	// OptionCheckAPIs is check APIs option.
+	OptionCheckAPIs = "check-apis"
 	// OptionClientConfig is clientConfig option.
 	OptionClientConfig = "client-config"
 	// OptionComponentName is a componentName option.
 	OptionComponentName = "component-name"
 	// OptionComponentNames is componentNames option.
 	OptionComponentNames = "component-names"
+	// OptionConvertDeprecated is convert deprecated option.
+	OptionConvertDeprecated = "convert-deprecated"
 	// OptionCreate is create option.
 	OptionCreate = "create"
+	// OptionCreateNamespace is create namespace option.
+	OptionCreateNamespace = "create-namespace"
 	// OptionDryRun is dryRun option.
 	OptionDryRun = "dry-run"
 	// OptionEnvName is envName option.
@@ -56,10 +70,17 @@ const (
 	OptionEnvName1 = "env-name-1"
 	// OptionEnvName2 is envName1. Used for param diff.
 	OptionEnvName2 = "env-name-2"
+	// OptionExcludeComponents is a list of components whose objects are
+	// filtered out.
+	OptionExcludeComponents = "exclude-components"
+	// OptionExplain is a <kind>/<name> reference to an object to explain.
+	OptionExplain = "explain"
 	// OptionExtVarFiles is jsonnet ext var files.
 	OptionExtVarFiles = "ext-vars-files"
 	// OptionExtVars is jsonnet ext vars.
 	OptionExtVars = "ext-vars"
+	// OptionFilenames is filenames option.
+	OptionFilenames = "filenames"
 	// OptionForce is force option.
 	OptionForce = "force"
 	// OptionFormat is format option.
@@ -74,14 +95,24 @@ const (
 	OptionGracePeriod = "grace-period"
 	// OptionHTTPClient is the http.Client for outbound network requests.
 	OptionHTTPClient = "http-client"
+	// OptionImagePlatform is image platform option. It is used to resolve
+	// multi-platform images to the digest of the image for a platform.
+	OptionImagePlatform = "image-platform"
 	// OptionInstalled is for listing installed packages.
 	OptionInstalled = "only-installed"
 	// OptionJPaths is jsonnet paths.
 	OptionJPaths = "jpaths"
+	// OptionKinds is a list of kinds objects are filtered by.
+	OptionKinds = "kinds"
+	// OptionLayout is layout option. It is a template for the paths of
+	// exported objects.
+	OptionLayout = "layout"
 	// OptionPkgName is (an optionally qualified) name of a package.
 	OptionPkgName = "pkg-name"
 	// OptionName is name option.
 	OptionName = "name"
+	// OptionNames is a list of names objects are filtered by.
+	OptionNames = "names"
 	// OptionModule is component module option.
 	OptionModule = "module"
 	// OptionNamespace is a cluster namespace option
@@ -90,19 +121,33 @@ const (
 	OptionNewRoot = "root-path"
 	// OptionNewEnvName is newEnvName option. Used for renaming environments.
 	OptionNewEnvName = "new-env-name"
+	// OptionOffline is offline option. Used to run without a cluster.
+	OptionOffline = "offline"
 	// OptionOutput is output option.
 	OptionOutput = "output"
+	// OptionOutputDir is output directory option.
+	OptionOutputDir = "output-dir"
 	// OptionOverride is override option.
 	OptionOverride = "override"
 	// OptionPackageName is packageName option.
 	OptionPackageName = "package-name"
+	// OptionPackageNames is packageNames option.
+	OptionPackageNames = "package-names"
 	// OptionPath is path option.
 	OptionPath = "path"
 	// OptionQuery is query option.
 	OptionQuery = "query"
+	// OptionRecordParams is record params option. It is used to record component
+	// params on applied objects.
+	OptionRecordParams = "record-params"
+	// OptionResolveImages is resolve images option. It is used to pin the images
+	// of rendered objects to digests.
+	OptionResolveImages = "resolve-images"
 	// OptionResolveImage is resolve image option. It is used to resolve docker image references
 	// when setting parameters.
 	OptionResolveImage = "resolve-image"
+	// OptionSelector is a label selector objects are filtered by.
+	OptionSelector = "selector"
 	// OptionServer is server option.
 	OptionServer = "server"
 	// OptionServerURI is serverURI option.
@@ -113,12 +158,18 @@ const (
 	OptionSkipDefaultRegistries = "skip-default-registries"
 	// OptionSkipGc is skipGc option.
 	OptionSkipGc = "skip-gc"
+	// OptionSkipPolicies is skipPolicies option.
+	OptionSkipPolicies = "skip-policies"
 	// OptionSpecFlag is specFlag option. Used for setting k8s spec.
 	OptionSpecFlag = "spec-flag"
 	// OptionSrc1 is src1 option.
 	OptionSrc1 = "src-1"
 	// OptionSrc2 is src2 option.
 	OptionSrc2 = "src-2"
+	// OptionStats reports render stats.
+	OptionStats = "stats"
+	// OptionStatsFormat is the format of render stats.
+	OptionStatsFormat = "stats-format"
 	// OptionTlaVarFiles is jsonnet tla var files.
 	OptionTlaVarFiles = "tla-var-files"
 	// OptionTlaVars is jsonnet tla vars.
@@ -131,6 +182,9 @@ const (
 	OptionURI = "URI"
 	// OptionWithoutModules is without modules option.
 	OptionWithoutModules = "without-modules"
+	// OptionValues is values option. It selects the component params lifted
+	// into the values of an exported Helm chart.
+	OptionValues = "values"
 	// OptionValue is value option.
 	OptionValue = "value"
 	// OptionVersion is version option.
@@ -326,6 +380,51 @@ func (o *optionLoader) LoadStringSlice(name string) []string {
 	return a
 }
 
+func (o *optionLoader) LoadOptionalStringSlice(name string) []string {
+	i := o.loadOptional(name)
+	if i == nil {
+		return nil
+	}
+
+	a, ok := i.([]string)
+	if !ok {
+		return nil
+	}
+
+	return a
+}
+
+// LoadObjectFilter loads the optional options which filter objects.
+func (o *optionLoader) LoadObjectFilter() pipeline.ObjectFilter {
+	return pipeline.ObjectFilter{
+		ExcludeComponents: o.LoadOptionalStringSlice(OptionExcludeComponents),
+		Kinds:             o.LoadOptionalStringSlice(OptionKinds),
+		Names:             o.LoadOptionalStringSlice(OptionNames),
+		Selector:          o.LoadOptionalString(OptionSelector),
+	}
+}
+
+// LoadImagePlatform loads the platform images are resolved for. A platform
+// can only be set when images are resolved.
+func (o *optionLoader) LoadImagePlatform() string {
+	platform := o.LoadOptionalString(OptionImagePlatform)
+	if platform == "" || o.err != nil {
+		return platform
+	}
+
+	if !o.LoadOptionalBool(OptionResolveImages) {
+		o.err = errors.Errorf("%s requires %s", OptionImagePlatform, OptionResolveImages)
+		return ""
+	}
+
+	if _, err := dockerregistry.ParsePlatform(platform); err != nil {
+		o.err = err
+		return ""
+	}
+
+	return platform
+}
+
 func (o *optionLoader) LoadClientConfig() *client.Config {
 	i := o.load(OptionClientConfig)
 	if i == nil {
@@ -341,6 +440,20 @@ func (o *optionLoader) LoadClientConfig() *client.Config {
 	return a
 }
 
+func (o *optionLoader) LoadOptionalClientConfig() *client.Config {
+	i := o.loadOptional(OptionClientConfig)
+	if i == nil {
+		return nil
+	}
+
+	a, ok := i.(*client.Config)
+	if !ok {
+		return nil
+	}
+
+	return a
+}
+
 // LoadApp returns an app.App reference - either as passed via OptionApp,
 // or newly constructed.
 func (o *optionLoader) LoadApp() app.App {
@@ -185,6 +185,13 @@ func Test_optionLoader_optional_types(t *testing.T) {
 			expected: "",
 			keyName:  OptionApp,
 		},
+		{
+			name:     "StringSlice",
+			valid:    []string{"valid"},
+			invalid:  9,
+			expected: []string(nil),
+			keyName:  OptionApp,
+		},
 	}
 
 	for _, tc := range cases {
@@ -286,3 +293,46 @@ func mockRegistry(name string) *rmocks.Registry {
 
 	return m
 }
+
+func Test_optionLoader_LoadImagePlatform(t *testing.T) {
+	cases := []struct {
+		name     string
+		m        map[string]interface{}
+		expected string
+		isErr    bool
+	}{
+		{
+			name: "no platform",
+			m:    map[string]interface{}{OptionResolveImages: true},
+		},
+		{
+			name:     "platform",
+			m:        map[string]interface{}{OptionResolveImages: true, OptionImagePlatform: "linux/arm64"},
+			expected: "linux/arm64",
+		},
+		{
+			name:  "platform without resolving images",
+			m:     map[string]interface{}{OptionImagePlatform: "linux/arm64"},
+			isErr: true,
+		},
+		{
+			name:  "invalid platform",
+			m:     map[string]interface{}{OptionResolveImages: true, OptionImagePlatform: "arm64"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ol := newOptionLoader(tc.m)
+			got := ol.LoadImagePlatform()
+			if tc.isErr {
+				require.Error(t, ol.err)
+				return
+			}
+
+			require.NoError(t, ol.err)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 )
 
 type runApplyFn func(cluster.ApplyConfig, ...cluster.ApplyOpts) error
@@ -37,14 +38,22 @@ type applyOpt func(*Apply)
 
 // Apply collects options for applying objects to a cluster.
 type Apply struct {
-	app            app.App
-	clientConfig   *client.Config
-	componentNames []string
-	create         bool
-	dryRun         bool
-	envName        string
-	gcTag          string
-	skipGc         bool
+	app               app.App
+	checkAPIs         bool
+	clientConfig      *client.Config
+	componentNames    []string
+	convertDeprecated bool
+	create            bool
+	createNamespace   bool
+	dryRun            bool
+	envName           string
+	filter            pipeline.ObjectFilter
+	gcTag             string
+	imagePlatform     string
+	recordParams      bool
+	resolveImages     bool
+	skipGc            bool
+	skipPolicies      bool
 
 	runApplyFn runApplyFn
 }
@@ -54,13 +63,21 @@ func newApply(m map[string]interface{}, opts ...applyOpt) (*Apply, error) {
 	ol := newOptionLoader(m)
 
 	a := &Apply{
-		app:            ol.LoadApp(),
-		clientConfig:   ol.LoadClientConfig(),
-		componentNames: ol.LoadStringSlice(OptionComponentNames),
-		create:         ol.LoadBool(OptionCreate),
-		dryRun:         ol.LoadBool(OptionDryRun),
-		gcTag:          ol.LoadString(OptionGcTag),
-		skipGc:         ol.LoadBool(OptionSkipGc),
+		app:               ol.LoadApp(),
+		checkAPIs:         ol.LoadOptionalBool(OptionCheckAPIs),
+		clientConfig:      ol.LoadClientConfig(),
+		componentNames:    ol.LoadStringSlice(OptionComponentNames),
+		convertDeprecated: ol.LoadOptionalBool(OptionConvertDeprecated),
+		create:            ol.LoadBool(OptionCreate),
+		createNamespace:   ol.LoadOptionalBool(OptionCreateNamespace),
+		dryRun:            ol.LoadBool(OptionDryRun),
+		filter:            ol.LoadObjectFilter(),
+		gcTag:             ol.LoadString(OptionGcTag),
+		imagePlatform:     ol.LoadImagePlatform(),
+		recordParams:      ol.LoadOptionalBool(OptionRecordParams),
+		resolveImages:     ol.LoadOptionalBool(OptionResolveImages),
+		skipGc:            ol.LoadBool(OptionSkipGc),
+		skipPolicies:      ol.LoadOptionalBool(OptionSkipPolicies),
 
 		runApplyFn: cluster.RunApply,
 	}
@@ -82,14 +99,22 @@ func newApply(m map[string]interface{}, opts ...applyOpt) (*Apply, error) {
 
 func (a *Apply) run() error {
 	config := cluster.ApplyConfig{
-		App:            a.app,
-		ClientConfig:   a.clientConfig,
-		ComponentNames: a.componentNames,
-		Create:         a.create,
-		DryRun:         a.dryRun,
-		EnvName:        a.envName,
-		GcTag:          a.gcTag,
-		SkipGc:         a.skipGc,
+		App:               a.app,
+		CheckAPIs:         a.checkAPIs,
+		ClientConfig:      a.clientConfig,
+		ComponentNames:    a.componentNames,
+		ConvertDeprecated: a.convertDeprecated,
+		Create:            a.create,
+		CreateNamespace:   a.createNamespace,
+		DryRun:            a.dryRun,
+		EnvName:           a.envName,
+		Filter:            a.filter,
+		GcTag:             a.gcTag,
+		ImagePlatform:     a.imagePlatform,
+		RecordParams:      a.recordParams,
+		ResolveImages:     a.resolveImages,
+		SkipGc:            a.skipGc,
+		SkipPolicies:      a.skipPolicies,
 	}
 
 	return a.runApplyFn(config)
@@ -21,6 +21,7 @@ import (
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -52,25 +53,47 @@ func TestApply(t *testing.T) {
 				appMock.On("CurrentEnvironment").Return(tc.currentName)
 
 				in := map[string]interface{}{
-					OptionApp:            appMock,
-					OptionClientConfig:   &client.Config{},
-					OptionComponentNames: []string{},
-					OptionCreate:         true,
-					OptionDryRun:         true,
-					OptionEnvName:        tc.envName,
-					OptionGcTag:          "gc-tag",
-					OptionSkipGc:         true,
+					OptionApp:               appMock,
+					OptionCheckAPIs:         true,
+					OptionClientConfig:      &client.Config{},
+					OptionComponentNames:    []string{},
+					OptionConvertDeprecated: true,
+					OptionCreate:            true,
+					OptionCreateNamespace:   true,
+					OptionDryRun:            true,
+					OptionEnvName:           tc.envName,
+					OptionExcludeComponents: []string{"redis"},
+					OptionGcTag:             "gc-tag",
+					OptionKinds:             []string{"ConfigMap"},
+					OptionNames:             []string{"guestbook-*"},
+					OptionRecordParams:      true,
+					OptionResolveImages:     true,
+					OptionSelector:          "tier=frontend",
+					OptionSkipGc:            true,
+					OptionSkipPolicies:      true,
 				}
 
 				expected := cluster.ApplyConfig{
-					App:            appMock,
-					ClientConfig:   &client.Config{},
-					ComponentNames: []string{},
-					Create:         true,
-					DryRun:         true,
-					EnvName:        "default",
-					GcTag:          "gc-tag",
-					SkipGc:         true,
+					App:               appMock,
+					CheckAPIs:         true,
+					ClientConfig:      &client.Config{},
+					ComponentNames:    []string{},
+					ConvertDeprecated: true,
+					Create:            true,
+					CreateNamespace:   true,
+					DryRun:            true,
+					EnvName:           "default",
+					Filter: pipeline.ObjectFilter{
+						ExcludeComponents: []string{"redis"},
+						Kinds:             []string{"ConfigMap"},
+						Names:             []string{"guestbook-*"},
+						Selector:          "tier=frontend",
+					},
+					GcTag:         "gc-tag",
+					RecordParams:  true,
+					ResolveImages: true,
+					SkipGc:        true,
+					SkipPolicies:  true,
 				}
 
 				runApplyOpt := func(a *Apply) {
@@ -19,6 +19,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 )
 
 type runDeleteFn func(cluster.DeleteConfig, ...cluster.DeleteOpts) error
@@ -41,6 +42,7 @@ type Delete struct {
 	clientConfig   *client.Config
 	componentNames []string
 	envName        string
+	filter         pipeline.ObjectFilter
 	gracePeriod    int64
 
 	runDeleteFn runDeleteFn
@@ -54,6 +56,7 @@ func newDelete(m map[string]interface{}, opts ...deleteOpt) (*Delete, error) {
 		app:            ol.LoadApp(),
 		clientConfig:   ol.LoadClientConfig(),
 		componentNames: ol.LoadStringSlice(OptionComponentNames),
+		filter:         ol.LoadObjectFilter(),
 		gracePeriod:    ol.LoadInt64(OptionGracePeriod),
 
 		runDeleteFn: cluster.RunDelete,
@@ -80,6 +83,7 @@ func (d *Delete) run() error {
 		ClientConfig:   d.clientConfig,
 		ComponentNames: d.componentNames,
 		EnvName:        d.envName,
+		Filter:         d.filter,
 		GracePeriod:    d.gracePeriod,
 	}
 
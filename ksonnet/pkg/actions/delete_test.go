@@ -21,6 +21,7 @@ import (
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -57,6 +58,7 @@ func TestDelete(t *testing.T) {
 					OptionComponentNames: []string{},
 					OptionEnvName:        tc.envName,
 					OptionGracePeriod:    int64(3),
+					OptionSelector:       "tier=frontend",
 				}
 
 				expected := cluster.DeleteConfig{
@@ -64,6 +66,7 @@ func TestDelete(t *testing.T) {
 					ClientConfig:   &client.Config{},
 					ComponentNames: []string{},
 					EnvName:        "default",
+					Filter:         pipeline.ObjectFilter{Selector: "tier=frontend"},
 					GracePeriod:    3,
 				}
 
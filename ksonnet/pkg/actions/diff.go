@@ -27,6 +27,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/diff"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/pkg/errors"
 )
 
@@ -50,13 +51,16 @@ func RunDiff(m map[string]interface{}) error {
 
 // Diff sets targets for an environment.
 type Diff struct {
-	app          app.App
-	clientConfig *client.Config
-	src1         string
-	src2         string
-	components   []string
+	app           app.App
+	clientConfig  *client.Config
+	src1          string
+	src2          string
+	components    []string
+	filter        pipeline.ObjectFilter
+	imagePlatform string
+	resolveImages bool
 
-	diffFn func(app.App, *client.Config, []string, *diff.Location, *diff.Location) (io.Reader, error)
+	diffFn func(app.App, *client.Config, []string, *diff.Location, *diff.Location, ...diff.Opt) (io.Reader, error)
 
 	out io.Writer
 }
@@ -66,11 +70,14 @@ func NewDiff(m map[string]interface{}) (*Diff, error) {
 	ol := newOptionLoader(m)
 
 	d := &Diff{
-		app:          ol.LoadApp(),
-		clientConfig: ol.LoadClientConfig(),
-		src1:         ol.LoadString(OptionSrc1),
-		src2:         ol.LoadOptionalString(OptionSrc2),
-		components:   ol.LoadStringSlice(OptionComponentNames),
+		app:           ol.LoadApp(),
+		clientConfig:  ol.LoadClientConfig(),
+		src1:          ol.LoadString(OptionSrc1),
+		src2:          ol.LoadOptionalString(OptionSrc2),
+		components:    ol.LoadStringSlice(OptionComponentNames),
+		filter:        ol.LoadObjectFilter(),
+		imagePlatform: ol.LoadImagePlatform(),
+		resolveImages: ol.LoadOptionalBool(OptionResolveImages),
 
 		diffFn: diff.DefaultDiff,
 
@@ -93,7 +100,15 @@ func (d *Diff) Run() error {
 	}
 	location2 := diff.NewLocation(d.src2)
 
-	r, err := d.diffFn(d.app, d.clientConfig, d.components, location1, location2)
+	var opts []diff.Opt
+	if d.resolveImages {
+		opts = append(opts, diff.ResolveImages(d.imagePlatform))
+	}
+	if !d.filter.IsEmpty() {
+		opts = append(opts, diff.FilterObjects(d.filter))
+	}
+
+	r, err := d.diffFn(d.app, d.clientConfig, d.components, location1, location2, opts...)
 	if err != nil {
 		return err
 	}
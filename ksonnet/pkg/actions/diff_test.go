@@ -36,6 +36,8 @@ func TestDiff(t *testing.T) {
 		src2       string
 		eLocation1 string
 		eLocation2 string
+		selector   string
+		eOpts      int
 		diffText   string
 		isNewError bool
 		isRunError bool
@@ -53,6 +55,14 @@ func TestDiff(t *testing.T) {
 			eLocation1: "local:default",
 			eLocation2: "remote:default",
 		},
+		{
+			name:       "filtered",
+			src1:       "default",
+			selector:   "tier=frontend",
+			eLocation1: "local:default",
+			eLocation2: "remote:default",
+			eOpts:      1,
+		},
 		{
 			name:       "diff detected",
 			src1:       "local:default",
@@ -71,6 +81,7 @@ func TestDiff(t *testing.T) {
 					OptionApp:            appMock,
 					OptionClientConfig:   &client.Config{},
 					OptionComponentNames: []string{},
+					OptionSelector:       tc.selector,
 					OptionSrc1:           tc.src1,
 					OptionSrc2:           tc.src2,
 				}
@@ -86,9 +97,10 @@ func TestDiff(t *testing.T) {
 				var buf bytes.Buffer
 				d.out = &buf
 
-				d.diffFn = func(a app.App, c *client.Config, components []string, l1 *diff.Location, l2 *diff.Location) (io.Reader, error) {
+				d.diffFn = func(a app.App, c *client.Config, components []string, l1 *diff.Location, l2 *diff.Location, opts ...diff.Opt) (io.Reader, error) {
 					assert.Equal(t, tc.eLocation1, l1.String(), "location1")
 					assert.Equal(t, tc.eLocation2, l2.String(), "location2")
+					assert.Len(t, opts, tc.eOpts, "opts")
 
 					r := strings.NewReader(tc.diffText)
 					return r, nil
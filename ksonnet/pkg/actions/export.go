@@ -0,0 +1,107 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+)
+
+type runExportFn func(cluster.ExportConfig, ...cluster.ExportOpts) error
+
+// RunExport runs `export`.
+func RunExport(m map[string]interface{}) error {
+	a, err := newExport(m)
+	if err != nil {
+		return err
+	}
+
+	return a.run()
+}
+
+type exportOpt func(*Export)
+
+// Export exports the objects of an environment to a directory.
+type Export struct {
+	app            app.App
+	componentNames []string
+	envName        string
+	dir            string
+	format         string
+	layout         string
+	imagePlatform  string
+	resolveImages  bool
+	chartName      string
+	chartVersion   string
+	values         []string
+
+	runExportFn runExportFn
+}
+
+func newExport(m map[string]interface{}, opts ...exportOpt) (*Export, error) {
+	ol := newOptionLoader(m)
+
+	e := &Export{
+		app:            ol.LoadApp(),
+		componentNames: ol.LoadStringSlice(OptionComponentNames),
+		dir:            ol.LoadString(OptionOutputDir),
+		format:         ol.LoadString(OptionFormat),
+		layout:         ol.LoadOptionalString(OptionLayout),
+		imagePlatform:  ol.LoadImagePlatform(),
+		resolveImages:  ol.LoadOptionalBool(OptionResolveImages),
+		chartName:      ol.LoadOptionalString(OptionChartName),
+		chartVersion:   ol.LoadOptionalString(OptionChartVersion),
+		values:         ol.LoadOptionalStringSlice(OptionValues),
+
+		runExportFn: cluster.RunExport,
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if err := setCurrentEnv(e.app, e, ol); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *Export) run() error {
+	config := cluster.ExportConfig{
+		App:            e.app,
+		ComponentNames: e.componentNames,
+		EnvName:        e.envName,
+		Dir:            e.dir,
+		Format:         e.format,
+		Layout:         e.layout,
+		ImagePlatform:  e.imagePlatform,
+		ResolveImages:  e.resolveImages,
+		ChartName:      e.chartName,
+		ChartVersion:   e.chartVersion,
+		Values:         e.values,
+	}
+
+	return e.runExportFn(config)
+}
+
+func (e *Export) setCurrentEnv(name string) {
+	e.envName = name
+}
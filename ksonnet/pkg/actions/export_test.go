@@ -0,0 +1,104 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	cases := []struct {
+		name        string
+		isSetupErr  bool
+		currentName string
+		envName     string
+	}{
+		{
+			name:    "with a supplied env",
+			envName: "default",
+		},
+		{
+			name:        "with a current env",
+			currentName: "default",
+		},
+		{
+			name:       "without supplied or current env",
+			isSetupErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("CurrentEnvironment").Return(tc.currentName)
+
+				in := map[string]interface{}{
+					OptionApp:            appMock,
+					OptionComponentNames: []string{},
+					OptionEnvName:        tc.envName,
+					OptionOutputDir:      "out",
+					OptionFormat:         "yaml",
+					OptionLayout:         "{{.Name}}.{{.Ext}}",
+					OptionResolveImages:  true,
+					OptionChartName:      "guestbook",
+					OptionChartVersion:   "1.0.0",
+					OptionValues:         []string{"guestbook.replicas"},
+				}
+
+				expected := cluster.ExportConfig{
+					App:            appMock,
+					ComponentNames: []string{},
+					EnvName:        "default",
+					Dir:            "out",
+					Format:         "yaml",
+					Layout:         "{{.Name}}.{{.Ext}}",
+					ResolveImages:  true,
+					ChartName:      "guestbook",
+					ChartVersion:   "1.0.0",
+					Values:         []string{"guestbook.replicas"},
+				}
+
+				runExportOpt := func(a *Export) {
+					a.runExportFn = func(config cluster.ExportConfig, opts ...cluster.ExportOpts) error {
+						assert.Equal(t, expected, config)
+						return nil
+					}
+				}
+
+				a, err := newExport(in, runExportOpt)
+				if tc.isSetupErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				err = a.run()
+				require.NoError(t, err)
+			})
+		})
+	}
+}
+
+func TestExport_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := newExport(in)
+	require.Error(t, err)
+}
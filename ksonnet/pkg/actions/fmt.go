@@ -0,0 +1,177 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// RunFmt runs `fmt`.
+func RunFmt(m map[string]interface{}) error {
+	f, err := NewFmt(m)
+	if err != nil {
+		return err
+	}
+
+	return f.Run()
+}
+
+// Fmt formats the jsonnet in an app.
+type Fmt struct {
+	app   app.App
+	check bool
+
+	out      io.Writer
+	formatFn func(filename, src string) (string, error)
+}
+
+// NewFmt creates an instance of Fmt.
+func NewFmt(m map[string]interface{}) (*Fmt, error) {
+	ol := newOptionLoader(m)
+
+	f := &Fmt{
+		app:   ol.LoadApp(),
+		check: ol.LoadOptionalBool(OptionCheck),
+
+		out:      os.Stdout,
+		formatFn: jsonnet.Format,
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	return f, nil
+}
+
+// Run runs the Fmt action. The paths of files which are not formatted are
+// printed. If check is set, the files are not changed and an error is
+// returned if any of them need to be formatted. Files which can't be
+// formatted without changing more than their layout are left unchanged.
+func (f *Fmt) Run() error {
+	paths, err := fmtPaths(f.app)
+	if err != nil {
+		return err
+	}
+
+	var unformatted, failed int
+
+	for _, path := range paths {
+		b, err := afero.ReadFile(f.app.Fs(), path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(f.app.Root(), path)
+		if err != nil {
+			rel = path
+		}
+
+		formatted, err := f.formatFn(rel, string(b))
+		if _, ok := errors.Cause(err).(*jsonnet.UnsupportedFormatError); ok {
+			log.Warnf("Skipping %s: %v", rel, err)
+			continue
+		}
+
+		if err != nil {
+			log.Errorf("Unable to format %s: %v", rel, err)
+			failed++
+			continue
+		}
+
+		if formatted == string(b) {
+			continue
+		}
+
+		unformatted++
+		fmt.Fprintln(f.out, rel)
+
+		if f.check {
+			continue
+		}
+
+		if err := afero.WriteFile(f.app.Fs(), path, []byte(formatted), app.DefaultFilePermissions); err != nil {
+			return errors.Wrapf(err, "writing %s", rel)
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("unable to format %d file(s)", failed)
+	}
+
+	if f.check && unformatted > 0 {
+		return errors.Errorf("%d file(s) are not formatted", unformatted)
+	}
+
+	return nil
+}
+
+// fmtPaths returns the jsonnet files in an app which are formatted: component
+// jsonnet, component params, and everything in environments.
+func fmtPaths(a app.App) ([]string, error) {
+	var paths []string
+
+	roots := map[string]func(name string) bool{
+		"components": func(name string) bool {
+			return filepath.Ext(name) == ".jsonnet" || name == "params.libsonnet"
+		},
+		"environments": func(name string) bool {
+			ext := filepath.Ext(name)
+			return ext == ".jsonnet" || ext == ".libsonnet"
+		},
+	}
+
+	for root, include := range roots {
+		dir := filepath.Join(a.Root(), root)
+
+		exists, err := afero.DirExists(a.Fs(), dir)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		err = afero.Walk(a.Fs(), dir, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !fi.IsDir() && include(fi.Name()) {
+				paths = append(paths, path)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "walk %s", root)
+		}
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFmt(t *testing.T) {
+	cases := []struct {
+		name      string
+		check     bool
+		files     map[string]string
+		isErr     bool
+		unchanged []string
+		formatted []string
+	}{
+		{
+			name: "format files",
+			files: map[string]string{
+				"unformatted.jsonnet": "/components/unformatted.jsonnet",
+				"formatted.jsonnet":   "/components/nested/formatted.jsonnet",
+			},
+			formatted: []string{"/components/unformatted.jsonnet", "/components/nested/formatted.jsonnet"},
+		},
+		{
+			name:  "check",
+			check: true,
+			files: map[string]string{
+				"unformatted.jsonnet": "/components/unformatted.jsonnet",
+				"formatted.jsonnet":   "/components/formatted.jsonnet",
+			},
+			isErr:     true,
+			unchanged: []string{"/components/unformatted.jsonnet"},
+		},
+		{
+			name: "comments which would be removed",
+			files: map[string]string{
+				"comments.libsonnet": "/environments/default/params.libsonnet",
+			},
+			unchanged: []string{"/environments/default/params.libsonnet"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				fs := appMock.Fs()
+				originals := make(map[string]string)
+				for src, dest := range tc.files {
+					stageFile(t, fs, filepath.Join("fmt", src), dest)
+					originals[dest] = src
+				}
+
+				in := map[string]interface{}{
+					OptionApp:   appMock,
+					OptionCheck: tc.check,
+				}
+
+				a, err := NewFmt(in)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				for _, path := range tc.unchanged {
+					test.AssertContents(t, fs, filepath.Join("fmt", originals[path]), path)
+				}
+
+				for _, path := range tc.formatted {
+					test.AssertContents(t, fs, filepath.Join("fmt", "formatted.jsonnet"), path)
+				}
+
+				if tc.check {
+					assert.Equal(t, "components/unformatted.jsonnet\n", buf.String())
+				}
+			})
+		})
+	}
+}
+
+func TestFmt_params_scaffold(t *testing.T) {
+	for _, check := range []bool{false, true} {
+		t.Run(fmt.Sprintf("check=%t", check), func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				fs := appMock.Fs()
+				path := "/components/params.libsonnet"
+				scaffold := component.GenParamsContent()
+				require.NoError(t, afero.WriteFile(fs, path, scaffold, app.DefaultFilePermissions))
+
+				in := map[string]interface{}{
+					OptionApp:   appMock,
+					OptionCheck: check,
+				}
+
+				a, err := NewFmt(in)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				require.NoError(t, a.Run())
+				assert.Empty(t, buf.String())
+
+				got, err := afero.ReadFile(fs, path)
+				require.NoError(t, err)
+				assert.Equal(t, string(scaffold), string(got))
+			})
+		})
+	}
+}
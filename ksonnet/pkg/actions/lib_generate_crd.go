@@ -0,0 +1,287 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/crd"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// RunLibGenerateCRD runs `lib generate-crd`.
+func RunLibGenerateCRD(m map[string]interface{}) error {
+	lg, err := NewLibGenerateCRD(m)
+	if err != nil {
+		return err
+	}
+
+	return lg.Run()
+}
+
+type clusterCRDsFn func(a app.App, clientConfig *client.Config, envName string) ([]*crd.CRD, error)
+
+// LibGenerateCRD generates libraries for CustomResourceDefinitions.
+type LibGenerateCRD struct {
+	app          app.App
+	filenames    []string
+	packageNames []string
+	envName      string
+	clientConfig *client.Config
+
+	packageManager registry.PackageManager
+	clusterCRDsFn  clusterCRDsFn
+}
+
+// NewLibGenerateCRD creates an instance of LibGenerateCRD.
+func NewLibGenerateCRD(m map[string]interface{}) (*LibGenerateCRD, error) {
+	ol := newOptionLoader(m)
+
+	httpClientOpt := registry.HTTPClientOpt(ol.LoadHTTPClient())
+
+	a := ol.LoadApp()
+	lg := &LibGenerateCRD{
+		app:          a,
+		filenames:    ol.LoadStringSlice(OptionFilenames),
+		packageNames: ol.LoadStringSlice(OptionPackageNames),
+		envName:      ol.LoadOptionalString(OptionEnvName),
+		clientConfig: ol.LoadOptionalClientConfig(),
+
+		packageManager: registry.NewPackageManager(a, httpClientOpt),
+		clusterCRDsFn:  clusterCRDs,
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	return lg, nil
+}
+
+// Run generates the libraries. Libraries which haven't changed are not
+// written.
+func (lg *LibGenerateCRD) Run() error {
+	crds, err := lg.collect()
+	if err != nil {
+		return err
+	}
+
+	if len(crds) == 0 {
+		return errors.New("no CustomResourceDefinitions found")
+	}
+
+	libs, err := crd.GenerateLibs(crds)
+	if err != nil {
+		return err
+	}
+
+	fs := lg.app.Fs()
+	libDir := filepath.Join(lg.app.Root(), app.LibDirName)
+
+	for _, lib := range libs {
+		path := filepath.Join(libDir, lib.Path)
+		rel := filepath.Join(app.LibDirName, lib.Path)
+
+		existing, err := afero.ReadFile(fs, path)
+		if err == nil && bytes.Equal(existing, lib.Data) {
+			log.Debugf("%s is up to date", rel)
+			continue
+		}
+
+		if err = fs.MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+			return err
+		}
+
+		if err = afero.WriteFile(fs, path, lib.Data, app.DefaultFilePermissions); err != nil {
+			return errors.Wrapf(err, "write %s", rel)
+		}
+
+		log.Infof("Generated %s", rel)
+	}
+
+	return nil
+}
+
+// collect collects the CRDs from all of the sources. If a CRD is found more
+// than once, the last one found is used.
+func (lg *LibGenerateCRD) collect() ([]*crd.CRD, error) {
+	if len(lg.filenames) == 0 && len(lg.packageNames) == 0 && lg.envName == "" {
+		return nil, errors.New("no CRD sources: specify files, packages or an environment")
+	}
+
+	var order []string
+	byName := make(map[string]*crd.CRD)
+	add := func(crds []*crd.CRD) {
+		for _, c := range crds {
+			if _, ok := byName[c.Name]; !ok {
+				order = append(order, c.Name)
+			}
+			byName[c.Name] = c
+		}
+	}
+
+	for _, filename := range lg.filenames {
+		crds, err := readCRDs(lg.app.Fs(), filename, true)
+		if err != nil {
+			return nil, err
+		}
+		add(crds)
+	}
+
+	for _, name := range lg.packageNames {
+		crds, err := lg.packageCRDs(name)
+		if err != nil {
+			return nil, err
+		}
+		add(crds)
+	}
+
+	if lg.envName != "" {
+		crds, err := lg.clusterCRDsFn(lg.app, lg.clientConfig, lg.envName)
+		if err != nil {
+			return nil, err
+		}
+		add(crds)
+	}
+
+	var crds []*crd.CRD
+	for _, name := range order {
+		crds = append(crds, byName[name])
+	}
+	crd.Sort(crds)
+
+	return crds, nil
+}
+
+// packageCRDs reads the CRDs in the manifests of an installed package.
+func (lg *LibGenerateCRD) packageCRDs(name string) ([]*crd.CRD, error) {
+	d, err := pkg.Parse(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := lg.packageManager.Find(d)
+	if err != nil {
+		return nil, err
+	}
+
+	installed, err := p.IsInstalled()
+	if err != nil {
+		return nil, err
+	}
+	if !installed {
+		return nil, errors.Errorf("package %s is not installed", name)
+	}
+
+	return readCRDs(lg.app.Fs(), p.Path(), false)
+}
+
+// readCRDs reads the CRDs in a file, or in the YAML and JSON files in a
+// directory. If strict is false, files which can't be decoded are skipped.
+func readCRDs(fs afero.Fs, root string, strict bool) ([]*crd.CRD, error) {
+	var crds []*crd.CRD
+
+	err := afero.Walk(fs, root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if fi.IsDir() {
+			return nil
+		}
+
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			if path != root {
+				return nil
+			}
+		}
+
+		f, err := fs.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		found, err := crd.Decode(f)
+		if err != nil {
+			if !strict {
+				log.Debugf("skipping %s: %v", path, err)
+				return nil
+			}
+			return errors.Wrapf(err, "read CRDs from %s", path)
+		}
+
+		crds = append(crds, found...)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return crds, nil
+}
+
+// clusterCRDs reads the CRDs in the cluster of an environment.
+func clusterCRDs(a app.App, clientConfig *client.Config, envName string) ([]*crd.CRD, error) {
+	if clientConfig == nil {
+		return nil, errors.New("ksonnet client config is required to read CRDs from the cluster")
+	}
+
+	clients, err := cluster.GenClients(a, clientConfig, envName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating client for environment: %s", envName)
+	}
+
+	objects, err := cluster.CollectCRDs(clients)
+	if err != nil {
+		return nil, err
+	}
+
+	var crds []*crd.CRD
+	for _, obj := range objects {
+		if obj.GetKind() == "" {
+			obj.SetAPIVersion("apiextensions.k8s.io/v1beta1")
+			obj.SetKind("CustomResourceDefinition")
+		}
+
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		found, err := crd.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "read CRD %s", obj.GetName())
+		}
+
+		crds = append(crds, found...)
+	}
+
+	return crds, nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/crd"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	pkgmocks "github.com/ksonnet/ksonnet/pkg/pkg/mocks"
+	regmocks "github.com/ksonnet/ksonnet/pkg/registry/mocks"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crdTestData is the directory, relative to testdata, which holds the CRD
+// fixtures shared with the crd package.
+var crdTestData = filepath.Join("..", "..", "crd", "testdata")
+
+func TestLibGenerateCRD(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		fs := appMock.Fs()
+		stageFile(t, fs, filepath.Join(crdTestData, "crds.yaml"), "/crds/crds.yaml")
+		stageFile(t, fs, filepath.Join(crdTestData, "list.json"), "/vendor/incubator/istio/crds.json")
+		stageFile(t, fs, "lib/generate-crd/invalid.yaml", "/vendor/incubator/istio/parts.yaml")
+
+		in := map[string]interface{}{
+			OptionApp:          appMock,
+			OptionFilenames:    []string{"/crds"},
+			OptionPackageNames: []string{"incubator/istio"},
+		}
+
+		a, err := NewLibGenerateCRD(in)
+		require.NoError(t, err)
+
+		p := &pkgmocks.Package{}
+		p.On("IsInstalled").Return(true, nil)
+		p.On("Path").Return("/vendor/incubator/istio")
+
+		pm := &regmocks.PackageManager{}
+		pm.On("Find", pkg.Descriptor{Registry: "incubator", Name: "istio"}).Return(p, nil)
+		a.packageManager = pm
+
+		for i := 0; i < 2; i++ {
+			err = a.Run()
+			require.NoError(t, err)
+
+			b, err := afero.ReadFile(fs, "/lib/crds/certmanager.k8s.io/v1alpha1.libsonnet")
+			require.NoError(t, err)
+			assertOutput(t, filepath.Join(crdTestData, "certmanager-v1alpha1.libsonnet"), string(b))
+
+			b, err = afero.ReadFile(fs, "/lib/crds/networking.istio.io/v1alpha3.libsonnet")
+			require.NoError(t, err)
+			assertOutput(t, filepath.Join(crdTestData, "istio-v1alpha3.libsonnet"), string(b))
+		}
+	})
+}
+
+func TestLibGenerateCRD_cluster(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:          appMock,
+			OptionFilenames:    []string{},
+			OptionPackageNames: []string{},
+			OptionEnvName:      "default",
+			OptionClientConfig: &client.Config{},
+		}
+
+		a, err := NewLibGenerateCRD(in)
+		require.NoError(t, err)
+
+		a.clusterCRDsFn = func(a app.App, clientConfig *client.Config, envName string) ([]*crd.CRD, error) {
+			assert.Equal(t, "default", envName)
+			return []*crd.CRD{
+				{
+					Name:     "foos.example.com",
+					Group:    "example.com",
+					Kind:     "Foo",
+					Versions: []crd.Version{{Name: "v1"}},
+				},
+			}, nil
+		}
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		exists, err := afero.Exists(appMock.Fs(), "/lib/crds/example.com/v1.libsonnet")
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
+
+func TestLibGenerateCRD_errors(t *testing.T) {
+	cases := []struct {
+		name      string
+		filenames []string
+		packages  []string
+		envName   string
+		installed bool
+		clusterFn clusterCRDsFn
+	}{
+		{
+			name: "no sources",
+		},
+		{
+			name:      "invalid file",
+			filenames: []string{"/invalid.yaml"},
+		},
+		{
+			name:      "missing file",
+			filenames: []string{"/missing.yaml"},
+		},
+		{
+			name:     "package not installed",
+			packages: []string{"incubator/istio"},
+		},
+		{
+			name:    "cluster error",
+			envName: "default",
+			clusterFn: func(app.App, *client.Config, string) ([]*crd.CRD, error) {
+				return nil, errors.New("failed")
+			},
+		},
+		{
+			name:    "no CRDs",
+			envName: "default",
+			clusterFn: func(app.App, *client.Config, string) ([]*crd.CRD, error) {
+				return nil, nil
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				stageFile(t, appMock.Fs(), "lib/generate-crd/invalid.yaml", "/invalid.yaml")
+
+				in := map[string]interface{}{
+					OptionApp:          appMock,
+					OptionFilenames:    tc.filenames,
+					OptionPackageNames: tc.packages,
+					OptionEnvName:      tc.envName,
+				}
+
+				a, err := NewLibGenerateCRD(in)
+				require.NoError(t, err)
+
+				p := &pkgmocks.Package{}
+				p.On("IsInstalled").Return(tc.installed, nil)
+
+				pm := &regmocks.PackageManager{}
+				pm.On("Find", pkg.Descriptor{Registry: "incubator", Name: "istio"}).Return(p, nil)
+				a.packageManager = pm
+				a.clusterCRDsFn = tc.clusterFn
+
+				err = a.Run()
+				require.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestLibGenerateCRD_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := NewLibGenerateCRD(in)
+	require.Error(t, err)
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/lint"
+	"github.com/pkg/errors"
+)
+
+// RunLint runs `lint`.
+func RunLint(m map[string]interface{}) error {
+	l, err := NewLint(m)
+	if err != nil {
+		return err
+	}
+
+	return l.Run()
+}
+
+// Lint reports common mistakes in an app.
+type Lint struct {
+	app        app.App
+	outputType string
+
+	out    io.Writer
+	lintFn func(a app.App) ([]lint.Problem, error)
+}
+
+// NewLint creates an instance of Lint.
+func NewLint(m map[string]interface{}) (*Lint, error) {
+	ol := newOptionLoader(m)
+
+	l := &Lint{
+		app:        ol.LoadApp(),
+		outputType: ol.LoadOptionalString(OptionOutput),
+
+		out: os.Stdout,
+		lintFn: func(a app.App) ([]lint.Problem, error) {
+			return lint.New(a).Lint()
+		},
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	return l, nil
+}
+
+// Run runs the Lint action. An error is returned if any problems are found.
+func (l *Lint) Run() error {
+	if l.outputType != "" && l.outputType != "json" && l.outputType != "text" {
+		return errors.Errorf("invalid output type %q; valid options are text and json", l.outputType)
+	}
+
+	problems, err := l.lintFn(l.app)
+	if err != nil {
+		return err
+	}
+
+	if l.outputType == "json" {
+		if problems == nil {
+			problems = []lint.Problem{}
+		}
+
+		b, err := json.MarshalIndent(problems, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "encoding problems")
+		}
+
+		fmt.Fprintln(l.out, string(b))
+	} else {
+		for _, p := range problems {
+			fmt.Fprintln(l.out, p.String())
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("found %d problem(s)", len(problems))
+	}
+
+	return nil
+}
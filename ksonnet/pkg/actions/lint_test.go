@@ -0,0 +1,108 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/lint"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	problems := []lint.Problem{
+		{
+			Path:    "components/guestbook.jsonnet",
+			Line:    3,
+			Column:  5,
+			Rule:    lint.RuleUndefinedParam,
+			Message: `param "image" is not defined for component "guestbook"`,
+		},
+		{
+			Path:    "components/redis.jsonnet",
+			Rule:    lint.RuleUntargetedComponent,
+			Message: `component "redis" is not targeted by any environment`,
+		},
+	}
+
+	cases := []struct {
+		name       string
+		outputType string
+		problems   []lint.Problem
+		outputFile string
+		isErr      bool
+	}{
+		{
+			name:       "text",
+			problems:   problems,
+			outputFile: filepath.Join("lint", "output.txt"),
+			isErr:      true,
+		},
+		{
+			name:       "json",
+			outputType: "json",
+			problems:   problems,
+			outputFile: filepath.Join("lint", "output.json"),
+			isErr:      true,
+		},
+		{
+			name:       "no problems json",
+			outputType: "json",
+			outputFile: filepath.Join("lint", "empty.json"),
+		},
+		{
+			name:       "invalid output type",
+			outputType: "xml",
+			isErr:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				in := map[string]interface{}{
+					OptionApp:    appMock,
+					OptionOutput: tc.outputType,
+				}
+
+				a, err := NewLint(in)
+				require.NoError(t, err)
+
+				a.lintFn = func(app.App) ([]lint.Problem, error) {
+					return tc.problems, nil
+				}
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				if tc.outputFile != "" {
+					assertOutput(t, tc.outputFile, buf.String())
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"sort"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/lsp"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// RunLsp runs `lsp`.
+func RunLsp(m map[string]interface{}) error {
+	l, err := NewLsp(m)
+	if err != nil {
+		return err
+	}
+
+	return l.Run()
+}
+
+// Lsp runs a language server for an app over stdio.
+type Lsp struct {
+	app     app.App
+	envName string
+
+	in             io.Reader
+	out            io.Writer
+	packageManager registry.PackageManager
+}
+
+// NewLsp creates an instance of Lsp.
+func NewLsp(m map[string]interface{}) (*Lsp, error) {
+	ol := newOptionLoader(m)
+
+	a := ol.LoadApp()
+	httpClientOpt := registry.HTTPClientOpt(ol.LoadHTTPClient())
+
+	l := &Lsp{
+		app:     a,
+		envName: ol.LoadOptionalString(OptionEnvName),
+
+		in:             os.Stdin,
+		out:            os.Stdout,
+		packageManager: registry.NewPackageManager(a, httpClientOpt),
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	return l, nil
+}
+
+// Run runs the language server until the client exits.
+func (l *Lsp) Run() error {
+	envName, err := l.env()
+	if err != nil {
+		return err
+	}
+
+	prototypes, err := l.packageManager.Prototypes()
+	if err != nil {
+		return errors.Wrap(err, "load prototypes")
+	}
+
+	index, err := prototype.NewIndex(prototypes, prototype.DefaultBuilder)
+	if err != nil {
+		return err
+	}
+
+	prototypes, err = index.List()
+	if err != nil {
+		return err
+	}
+
+	return lsp.New(l.app, envName, prototypes).Serve(l.in, l.out)
+}
+
+// env returns the environment documents are evaluated in. If no environment
+// is set, the current environment is used, or else the first environment by
+// name. It is blank if the app has no environments.
+func (l *Lsp) env() (string, error) {
+	if l.envName != "" {
+		return l.envName, nil
+	}
+
+	if envName := l.app.CurrentEnvironment(); envName != "" {
+		return envName, nil
+	}
+
+	envs, err := l.app.Environments()
+	if err != nil {
+		return "", errors.Wrap(err, "list environments")
+	}
+
+	var names []string
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	log.Debugf("no current environment: evaluating documents in %s", names[0])
+	return names[0], nil
+}
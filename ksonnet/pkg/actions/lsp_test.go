@@ -0,0 +1,126 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	registrymocks "github.com/ksonnet/ksonnet/pkg/registry/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLsp(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("default")
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewLsp(in)
+		require.NoError(t, err)
+
+		manager := &registrymocks.PackageManager{}
+		manager.On("Prototypes").Return(prototype.Prototypes{}, nil)
+		a.packageManager = manager
+
+		var messages []string
+		for _, msg := range []string{
+			`{"jsonrpc":"2.0","id":1,"method":"shutdown"}`,
+			`{"jsonrpc":"2.0","method":"exit"}`,
+		} {
+			messages = append(messages, fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(msg), msg))
+		}
+		a.in = strings.NewReader(strings.Join(messages, ""))
+
+		var out bytes.Buffer
+		a.out = &out
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		expected := `{"jsonrpc":"2.0","id":1,"result":null}`
+		require.Equal(t, fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(expected), expected), out.String())
+	})
+}
+
+func TestLsp_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := NewLsp(in)
+	require.Error(t, err)
+}
+
+func TestLsp_env(t *testing.T) {
+	cases := []struct {
+		name     string
+		envName  string
+		current  string
+		envs     app.EnvironmentConfigs
+		expected string
+	}{
+		{
+			name:     "env set",
+			envName:  "prod",
+			current:  "dev",
+			expected: "prod",
+		},
+		{
+			name:     "current env",
+			current:  "dev",
+			expected: "dev",
+		},
+		{
+			name: "first env",
+			envs: app.EnvironmentConfigs{
+				"prod": &app.EnvironmentConfig{},
+				"dev":  &app.EnvironmentConfig{},
+			},
+			expected: "dev",
+		},
+		{
+			name: "no envs",
+			envs: app.EnvironmentConfigs{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("CurrentEnvironment").Return(tc.current)
+				appMock.On("Environments").Return(tc.envs, nil)
+
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: tc.envName,
+				}
+
+				a, err := NewLsp(in)
+				require.NoError(t, err)
+
+				envName, err := a.env()
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, envName)
+			})
+		})
+	}
+}
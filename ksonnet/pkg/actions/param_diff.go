@@ -18,13 +18,55 @@ package actions
 import (
 	"io"
 	"os"
+	"strings"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
 	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/util/git"
 	"github.com/ksonnet/ksonnet/pkg/util/table"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// paramLocationCluster is the revision name for params applied to a cluster.
+	paramLocationCluster = "cluster"
+)
+
+// paramLocation is a source of parameters for an environment. Locations are
+// formatted as:
+//   env            - the environment in the working tree
+//   env@<revision> - the environment at a git revision
+//   env@cluster    - the parameters last applied to the cluster
+type paramLocation struct {
+	envName  string
+	revision string
+}
+
+func newParamLocation(s string) (paramLocation, error) {
+	parts := strings.SplitN(s, "@", 2)
+
+	l := paramLocation{envName: parts[0]}
+	if len(parts) == 2 {
+		if parts[1] == "" {
+			return paramLocation{}, errors.Errorf("location %q has a blank revision", s)
+		}
+		l.revision = parts[1]
+	}
+
+	if l.envName == "" {
+		return paramLocation{}, errors.Errorf("location %q has a blank environment", s)
+	}
+
+	return l, nil
+}
+
+func (l paramLocation) isCluster() bool {
+	return l.revision == paramLocationCluster
+}
+
 // RunParamDiff runs `param diff`.
 func RunParamDiff(m map[string]interface{}) error {
 	pd, err := NewParamDiff(m)
@@ -35,15 +77,20 @@ func RunParamDiff(m map[string]interface{}) error {
 	return pd.Run()
 }
 
-// ParamDiff shows difference between params in two environments.
+// ParamDiff shows difference between params in two locations. A location
+// is an environment in the working tree, at a git revision, or as last
+// applied to the cluster.
 type ParamDiff struct {
 	app           app.App
+	clientConfig  *client.Config
 	envName1      string
 	envName2      string
 	componentName string
 	outputType    string
 
 	modulesFromEnvFn func(app.App, string) ([]component.Module, error)
+	revisionAppFn    func(app.App, string) (app.App, error)
+	clusterParamsFn  func(app.App, *client.Config, string) ([]component.ModuleParameter, error)
 	out              io.Writer
 }
 
@@ -53,12 +100,15 @@ func NewParamDiff(m map[string]interface{}) (*ParamDiff, error) {
 
 	pd := &ParamDiff{
 		app:           ol.LoadApp(),
+		clientConfig:  ol.LoadOptionalClientConfig(),
 		envName1:      ol.LoadString(OptionEnvName1),
 		envName2:      ol.LoadString(OptionEnvName2),
 		componentName: ol.LoadOptionalString(OptionComponentName),
 		outputType:    ol.LoadOptionalString(OptionOutput),
 
 		modulesFromEnvFn: component.ModulesFromEnv,
+		revisionAppFn:    revisionApp,
+		clusterParamsFn:  clusterParams,
 		out:              os.Stdout,
 	}
 
@@ -145,8 +195,27 @@ func (pd *ParamDiff) checkMissing(env1, env2 []component.ModuleParameter) [][]st
 	return rows
 }
 
-func (pd *ParamDiff) moduleParams(envName string) ([]component.ModuleParameter, error) {
-	modules, err := pd.modulesFromEnvFn(pd.app, envName)
+func (pd *ParamDiff) moduleParams(src string) ([]component.ModuleParameter, error) {
+	location, err := newParamLocation(src)
+	if err != nil {
+		return nil, err
+	}
+
+	envName := location.envName
+
+	if location.isCluster() {
+		return pd.clusterParamsFn(pd.app, pd.clientConfig, envName)
+	}
+
+	a := pd.app
+	if location.revision != "" {
+		a, err = pd.revisionAppFn(pd.app, location.revision)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	modules, err := pd.modulesFromEnvFn(a, envName)
 	if err != nil {
 		return nil, err
 	}
@@ -178,3 +247,47 @@ func (pd *ParamDiff) print(rows [][]string) error {
 
 	return t.Render()
 }
+
+// revisionApp loads the app as it was at a git revision.
+func revisionApp(a app.App, revision string) (app.App, error) {
+	fs, err := git.Fs(a.Root(), revision)
+	if err != nil {
+		return nil, err
+	}
+
+	revApp, err := app.Load(fs, a.HTTPClient(), a.Root())
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading app at revision %s", revision)
+	}
+
+	return revApp, nil
+}
+
+// clusterParams loads the params recorded on the objects last applied to
+// the cluster for an environment.
+func clusterParams(a app.App, clientConfig *client.Config, envName string) ([]component.ModuleParameter, error) {
+	if clientConfig == nil {
+		return nil, errors.New("ksonnet client config is required to read params from the cluster")
+	}
+
+	env, err := a.Environment(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := cluster.GenClients(a, clientConfig, envName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating client for environment: %s", envName)
+	}
+
+	params, err := cluster.CollectParams(env.Destination.Namespace, envName, clients)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(params) == 0 {
+		log.Warnf("No params are recorded in the cluster for %s; apply it with --record-params to record them", envName)
+	}
+
+	return params, nil
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/component"
 	"github.com/ksonnet/ksonnet/pkg/component/mocks"
 	"github.com/pkg/errors"
@@ -116,3 +117,136 @@ func TestParamDiff_requires_app(t *testing.T) {
 	_, err := NewParamDiff(in)
 	require.Error(t, err)
 }
+
+func TestParamDiff_locations(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		revApp := &amocks.App{}
+
+		workingTree := &mocks.Module{}
+		workingTree.On("Params", "prod").Return([]component.ModuleParameter{
+			{Component: "a", Key: "replicas", Value: "3"},
+		}, nil)
+
+		revision := &mocks.Module{}
+		revision.On("Params", "prod").Return([]component.ModuleParameter{
+			{Component: "a", Key: "replicas", Value: "2"},
+		}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:      appMock,
+			OptionEnvName1: "prod@HEAD~1",
+			OptionEnvName2: "prod",
+			OptionOutput:   "json",
+		}
+
+		a, err := NewParamDiff(in)
+		require.NoError(t, err)
+
+		a.revisionAppFn = func(a app.App, rev string) (app.App, error) {
+			require.Equal(t, "HEAD~1", rev)
+			return revApp, nil
+		}
+
+		a.modulesFromEnvFn = func(a app.App, envName string) ([]component.Module, error) {
+			require.Equal(t, "prod", envName)
+			if a == revApp {
+				return []component.Module{revision}, nil
+			}
+			return []component.Module{workingTree}, nil
+		}
+
+		var buf bytes.Buffer
+		a.out = &buf
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assertOutput(t, filepath.Join("param", "diff", "revision.json"), buf.String())
+	})
+}
+
+func TestParamDiff_cluster(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		workingTree := &mocks.Module{}
+		workingTree.On("Params", "prod").Return([]component.ModuleParameter{
+			{Component: "a", Key: "replicas", Value: "3"},
+		}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:      appMock,
+			OptionEnvName1: "prod@cluster",
+			OptionEnvName2: "prod",
+			OptionOutput:   "json",
+		}
+
+		a, err := NewParamDiff(in)
+		require.NoError(t, err)
+
+		a.clusterParamsFn = func(_ app.App, _ *client.Config, envName string) ([]component.ModuleParameter, error) {
+			require.Equal(t, "prod", envName)
+			return []component.ModuleParameter{
+				{Component: "a", Key: "replicas", Value: "2"},
+			}, nil
+		}
+
+		a.modulesFromEnvFn = func(_ app.App, envName string) ([]component.Module, error) {
+			return []component.Module{workingTree}, nil
+		}
+
+		var buf bytes.Buffer
+		a.out = &buf
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assertOutput(t, filepath.Join("param", "diff", "revision.json"), buf.String())
+	})
+}
+
+func Test_newParamLocation(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		expected paramLocation
+		isErr    bool
+	}{
+		{
+			name:     "environment",
+			src:      "prod",
+			expected: paramLocation{envName: "prod"},
+		},
+		{
+			name:     "revision",
+			src:      "prod@HEAD~1",
+			expected: paramLocation{envName: "prod", revision: "HEAD~1"},
+		},
+		{
+			name:     "cluster",
+			src:      "us-west/prod@cluster",
+			expected: paramLocation{envName: "us-west/prod", revision: "cluster"},
+		},
+		{
+			name:  "blank revision",
+			src:   "prod@",
+			isErr: true,
+		},
+		{
+			name:  "blank environment",
+			src:   "@HEAD",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := newParamLocation(tc.src)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, l)
+			require.Equal(t, tc.expected.revision == "cluster", l.isCluster())
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/pkg/errors"
+)
+
+// paramsDocument is the document written by `param export` and read by
+// `param import`.
+type paramsDocument struct {
+	Global     map[string]interface{}            `json:"global,omitempty"`
+	Components map[string]map[string]interface{} `json:"components"`
+}
+
+// RunParamExport runs `param export`.
+func RunParamExport(m map[string]interface{}) error {
+	pe, err := NewParamExport(m)
+	if err != nil {
+		return err
+	}
+
+	return pe.Run()
+}
+
+// ParamExport exports the resolved parameters for an environment.
+type ParamExport struct {
+	app           app.App
+	envName       string
+	componentName string
+	outputType    string
+
+	out             io.Writer
+	modulesFn       func() ([]component.Module, error)
+	envParametersFn func(moduleName string, inherited bool) (string, error)
+}
+
+// NewParamExport creates an instance of ParamExport.
+func NewParamExport(m map[string]interface{}) (*ParamExport, error) {
+	ol := newOptionLoader(m)
+
+	pe := &ParamExport{
+		app:           ol.LoadApp(),
+		envName:       ol.LoadString(OptionEnvName),
+		componentName: ol.LoadOptionalString(OptionComponentName),
+		outputType:    ol.LoadOptionalString(OptionOutput),
+
+		out: os.Stdout,
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	if pe.envName == "" {
+		return nil, errors.New("environment name is required")
+	}
+
+	p := pipeline.New(pe.app, pe.envName)
+	pe.modulesFn = p.Modules
+	pe.envParametersFn = p.EnvParameters
+
+	return pe, nil
+}
+
+// Run runs the ParamExport action.
+func (pe *ParamExport) Run() error {
+	modules, err := pe.modulesFn()
+	if err != nil {
+		return err
+	}
+
+	resolved, err := resolveParams(modules, pe.envParametersFn)
+	if err != nil {
+		return err
+	}
+
+	doc := paramsDocument{
+		Components: make(map[string]map[string]interface{}),
+	}
+
+	for name, componentParams := range resolved {
+		if pe.componentName != "" && pe.componentName != name {
+			continue
+		}
+
+		doc.Components[name] = componentParams
+	}
+
+	if pe.componentName != "" && len(doc.Components) == 0 {
+		return errors.Errorf("unable to find component %q", pe.componentName)
+	}
+
+	var b []byte
+	switch pe.outputType {
+	case "", "yaml":
+		b, err = yaml.Marshal(&doc)
+	case "json":
+		b, err = json.MarshalIndent(&doc, "", "  ")
+		b = append(b, '\n')
+	default:
+		return errors.Errorf("invalid output type %q; valid options are yaml and json", pe.outputType)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "encoding params")
+	}
+
+	_, err = pe.out.Write(b)
+	return err
+}
+
+// resolveParams returns the resolved params for each component in modules,
+// keyed by component name.
+func resolveParams(modules []component.Module, envParametersFn func(string, bool) (string, error)) (map[string]map[string]interface{}, error) {
+	resolved := make(map[string]map[string]interface{})
+
+	for _, m := range modules {
+		source, err := envParametersFn(m.Name(), true)
+		if err != nil {
+			return nil, err
+		}
+
+		var moduleDoc paramsDocument
+		if err := json.Unmarshal([]byte(source), &moduleDoc); err != nil {
+			return nil, errors.Wrapf(err, "decoding params for module %q", m.Name())
+		}
+
+		for name, componentParams := range moduleDoc.Components {
+			resolved[name] = componentParams
+		}
+	}
+
+	return resolved, nil
+}
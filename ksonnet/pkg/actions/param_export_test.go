@@ -0,0 +1,123 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamExport(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		root := &cmocks.Module{}
+		root.On("Name").Return("/")
+
+		nested := &cmocks.Module{}
+		nested.On("Name").Return("nested")
+
+		envParams := map[string]string{
+			"/":      `{"components": {"guestbook": {"replicas": 3, "image": "nginx", "labels": {"tier": "web"}}}}`,
+			"nested": `{"components": {"nested.redis": {"replicas": 1}}}`,
+		}
+
+		cases := []struct {
+			name          string
+			componentName string
+			outputType    string
+			outputFile    string
+			isErr         bool
+		}{
+			{
+				name:       "yaml",
+				outputFile: filepath.Join("param", "export", "all.yaml"),
+			},
+			{
+				name:       "json",
+				outputType: "json",
+				outputFile: filepath.Join("param", "export", "all.json"),
+			},
+			{
+				name:          "component",
+				componentName: "nested.redis",
+				outputFile:    filepath.Join("param", "export", "component.yaml"),
+			},
+			{
+				name:          "unknown component",
+				componentName: "missing",
+				isErr:         true,
+			},
+			{
+				name:       "invalid output type",
+				outputType: "table",
+				isErr:      true,
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				in := map[string]interface{}{
+					OptionApp:           appMock,
+					OptionEnvName:       "default",
+					OptionComponentName: tc.componentName,
+					OptionOutput:        tc.outputType,
+				}
+
+				a, err := NewParamExport(in)
+				require.NoError(t, err)
+
+				a.modulesFn = func() ([]component.Module, error) {
+					return []component.Module{root, nested}, nil
+				}
+
+				a.envParametersFn = func(moduleName string, inherited bool) (string, error) {
+					assert.True(t, inherited, "should export inherited parameters")
+					return envParams[moduleName], nil
+				}
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assertOutput(t, tc.outputFile, buf.String())
+			})
+		}
+	})
+}
+
+func TestParamExport_requires_env(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "",
+		}
+
+		_, err := NewParamExport(in)
+		require.Error(t, err)
+	})
+}
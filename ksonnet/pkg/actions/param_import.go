@@ -0,0 +1,172 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	mp "github.com/ksonnet/ksonnet/metadata/params"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/params"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/pkg/errors"
+	godiff "github.com/shazow/go-diff"
+	"github.com/spf13/afero"
+)
+
+// RunParamImport runs `param import`.
+func RunParamImport(m map[string]interface{}) error {
+	pi, err := NewParamImport(m)
+	if err != nil {
+		return err
+	}
+
+	return pi.Run()
+}
+
+// ParamImport imports parameters for an environment from a YAML or JSON file.
+type ParamImport struct {
+	app     app.App
+	envName string
+	path    string
+	dryRun  bool
+
+	out             io.Writer
+	modulesFn       func() ([]component.Module, error)
+	envParametersFn func(moduleName string, inherited bool) (string, error)
+	updateParamsFn  func(a app.App, envName string, componentParams map[string]mp.Params, globals mp.Params) ([]env.ParamsUpdate, error)
+	writeParamsFn   func(a app.App, updates []env.ParamsUpdate) error
+}
+
+// NewParamImport creates an instance of ParamImport.
+func NewParamImport(m map[string]interface{}) (*ParamImport, error) {
+	ol := newOptionLoader(m)
+
+	pi := &ParamImport{
+		app:     ol.LoadApp(),
+		envName: ol.LoadString(OptionEnvName),
+		path:    ol.LoadString(OptionPath),
+		dryRun:  ol.LoadOptionalBool(OptionDryRun),
+
+		out:            os.Stdout,
+		updateParamsFn: env.UpdateParams,
+		writeParamsFn:  env.WriteParamsUpdates,
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	if pi.envName == "" {
+		return nil, errors.New("environment name is required")
+	}
+
+	p := pipeline.New(pi.app, pi.envName)
+	pi.modulesFn = p.Modules
+	pi.envParametersFn = p.EnvParameters
+
+	return pi, nil
+}
+
+// Run runs the ParamImport action.
+func (pi *ParamImport) Run() error {
+	b, err := afero.ReadFile(pi.app.Fs(), pi.path)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", pi.path)
+	}
+
+	// YAML is a superset of JSON, so this handles both formats.
+	var doc paramsDocument
+	if err = yaml.Unmarshal(b, &doc); err != nil {
+		return errors.Wrapf(err, "decoding %s", pi.path)
+	}
+
+	modules, err := pi.modulesFn()
+	if err != nil {
+		return err
+	}
+
+	resolved, err := resolveParams(modules, pi.envParametersFn)
+	if err != nil {
+		return err
+	}
+
+	// only values which differ from the currently resolved params are set, so
+	// importing the output of `param export` leaves the environment unchanged.
+	componentParams := make(map[string]mp.Params)
+	for name, values := range doc.Components {
+		p := make(mp.Params)
+		for k, v := range values {
+			if current, ok := resolved[name][k]; ok && reflect.DeepEqual(current, v) {
+				continue
+			}
+
+			// strings in the file are already decoded, so they are set as is.
+			if s, ok := v.(string); ok {
+				v = params.Literal(s)
+			}
+			p[k] = v
+		}
+
+		if len(p) == 0 {
+			continue
+		}
+
+		componentParams[name] = p
+	}
+
+	updates, err := pi.updateParamsFn(pi.app, pi.envName, componentParams, mp.Params(doc.Global))
+	if err != nil {
+		return err
+	}
+
+	if pi.dryRun {
+		return pi.printDiff(updates)
+	}
+
+	return pi.writeParamsFn(pi.app, updates)
+}
+
+func (pi *ParamImport) printDiff(updates []env.ParamsUpdate) error {
+	for _, u := range updates {
+		if u.Original == u.Updated {
+			continue
+		}
+
+		path, err := filepath.Rel(pi.app.Root(), u.Path)
+		if err != nil {
+			path = u.Path
+		}
+
+		fmt.Fprintf(pi.out, "--- a/%s\n+++ b/%s\n", path, path)
+
+		r1 := strings.NewReader(u.Original)
+		r2 := strings.NewReader(u.Updated)
+		if err := godiff.DefaultDiffer().Diff(pi.out, r1, r2); err != nil {
+			return errors.Wrapf(err, "diffing %s", path)
+		}
+	}
+
+	return nil
+}
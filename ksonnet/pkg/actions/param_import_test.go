@@ -0,0 +1,219 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	mp "github.com/ksonnet/ksonnet/metadata/params"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/params"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamImport(t *testing.T) {
+	cases := []struct {
+		name       string
+		src        string
+		dryRun     bool
+		outputFile string
+	}{
+		{
+			name: "yaml",
+			src:  "params.yaml",
+		},
+		{
+			name: "json",
+			src:  "params.json",
+		},
+		{
+			name:       "dry run",
+			src:        "params.yaml",
+			dryRun:     true,
+			outputFile: filepath.Join("param", "import", "dry-run.txt"),
+		},
+	}
+
+	root := &cmocks.Module{}
+	root.On("Name").Return("/")
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				stageFile(t, appMock.Fs(), filepath.Join("param", "import", tc.src), "/"+tc.src)
+
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: "default",
+					OptionPath:    "/" + tc.src,
+					OptionDryRun:  tc.dryRun,
+				}
+
+				a, err := NewParamImport(in)
+				require.NoError(t, err)
+
+				a.modulesFn = func() ([]component.Module, error) {
+					return []component.Module{root}, nil
+				}
+
+				a.envParametersFn = func(moduleName string, inherited bool) (string, error) {
+					return `{"components": {"guestbook": {"replicas": 1, "image": "apache"}}}`, nil
+				}
+
+				updates := []env.ParamsUpdate{
+					{
+						Path:     "/environments/default/params.libsonnet",
+						Original: "{\n  a: 1,\n}\n",
+						Updated:  "{\n  a: 2,\n}\n",
+					},
+					{
+						Path:     "/environments/default/globals.libsonnet",
+						Original: "{\n}\n",
+						Updated:  "{\n}\n",
+					},
+				}
+
+				a.updateParamsFn = func(a app.App, envName string, componentParams map[string]mp.Params, globals mp.Params) ([]env.ParamsUpdate, error) {
+					assert.Equal(t, "default", envName)
+
+					expectedParams := map[string]mp.Params{
+						"guestbook": {
+							"image":    params.Literal("nginx"),
+							"replicas": float64(3),
+							"labels":   map[string]interface{}{"tier": "web"},
+						},
+					}
+					assert.Equal(t, expectedParams, componentParams)
+					assert.Equal(t, mp.Params{"team": "web"}, globals)
+
+					return updates, nil
+				}
+
+				var written []env.ParamsUpdate
+				a.writeParamsFn = func(a app.App, u []env.ParamsUpdate) error {
+					written = u
+					return nil
+				}
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.Run()
+				require.NoError(t, err)
+
+				if tc.dryRun {
+					assert.Nil(t, written, "dry run should not write updates")
+					assertOutput(t, tc.outputFile, buf.String())
+					return
+				}
+
+				assert.Equal(t, updates, written)
+			})
+		})
+	}
+}
+
+func TestParamImport_export_round_trip(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		root := &cmocks.Module{}
+		root.On("Name").Return("/")
+
+		nested := &cmocks.Module{}
+		nested.On("Name").Return("nested")
+
+		envParams := map[string]string{
+			"/":      `{"components": {"guestbook": {"replicas": 3, "image": "nginx", "labels": {"tier": "web"}}}}`,
+			"nested": `{"components": {"nested.redis": {"replicas": 1}}}`,
+		}
+
+		modulesFn := func() ([]component.Module, error) {
+			return []component.Module{root, nested}, nil
+		}
+
+		envParametersFn := func(moduleName string, inherited bool) (string, error) {
+			return envParams[moduleName], nil
+		}
+
+		for _, outputType := range []string{"yaml", "json"} {
+			t.Run(outputType, func(t *testing.T) {
+				pe, err := NewParamExport(map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: "default",
+					OptionOutput:  outputType,
+				})
+				require.NoError(t, err)
+
+				pe.modulesFn = modulesFn
+				pe.envParametersFn = envParametersFn
+
+				var buf bytes.Buffer
+				pe.out = &buf
+
+				require.NoError(t, pe.Run())
+
+				path := "/params." + outputType
+				require.NoError(t, afero.WriteFile(appMock.Fs(), path, buf.Bytes(), 0644))
+
+				pi, err := NewParamImport(map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: "default",
+					OptionPath:    path,
+				})
+				require.NoError(t, err)
+
+				pi.modulesFn = modulesFn
+				pi.envParametersFn = envParametersFn
+
+				pi.updateParamsFn = func(a app.App, envName string, componentParams map[string]mp.Params, globals mp.Params) ([]env.ParamsUpdate, error) {
+					assert.Empty(t, componentParams, "exported params should not be imported as overrides")
+					assert.Empty(t, globals)
+					return nil, nil
+				}
+
+				pi.writeParamsFn = func(a app.App, u []env.ParamsUpdate) error {
+					assert.Empty(t, u)
+					return nil
+				}
+
+				require.NoError(t, pi.Run())
+			})
+		}
+	})
+}
+
+func TestParamImport_invalid_file(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "default",
+			OptionPath:    "/missing.yaml",
+		}
+
+		a, err := NewParamImport(in)
+		require.NoError(t, err)
+
+		err = a.Run()
+		require.Error(t, err)
+	})
+}
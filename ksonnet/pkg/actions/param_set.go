@@ -48,11 +48,12 @@ type ParamSet struct {
 	asString     bool
 	resolveImage bool
 
-	getModuleFn    getModuleFn
-	resolvePathFn  func(a app.App, path string) (component.Module, component.Component, error)
-	setEnvFn       func(ksApp app.App, envName, name, pName, value string) error
-	setGlobalEnvFn func(ksApp app.App, envName, pName, value string) error
-	resolveImageFn func(image string) (string, error)
+	getModuleFn        getModuleFn
+	resolvePathFn      func(a app.App, path string) (component.Module, component.Component, error)
+	loadParamsSchemaFn func(a app.App, moduleName string) (*component.ParamsSchema, error)
+	setEnvFn           func(ksApp app.App, envName, name, pName, value string) error
+	setGlobalEnvFn     func(ksApp app.App, envName, pName, value string) error
+	resolveImageFn     func(image string) (string, error)
 }
 
 // NewParamSet creates an instance of ParamSet.
@@ -69,11 +70,12 @@ func NewParamSet(m map[string]interface{}) (*ParamSet, error) {
 		asString:     ol.LoadOptionalBool(OptionAsString),
 		resolveImage: ol.LoadOptionalBool(OptionResolveImage),
 
-		getModuleFn:    component.GetModule,
-		resolvePathFn:  component.ResolvePath,
-		setEnvFn:       setEnv,
-		setGlobalEnvFn: setGlobalEnv,
-		resolveImageFn: dockerregistry.ResolveImage,
+		getModuleFn:        component.GetModule,
+		resolvePathFn:      component.ResolvePath,
+		loadParamsSchemaFn: component.LoadParamsSchema,
+		setEnvFn:           setEnv,
+		setGlobalEnvFn:     setGlobalEnv,
+		resolveImageFn:     dockerregistry.ResolveImage,
 	}
 
 	if ol.err != nil {
@@ -113,6 +115,9 @@ func (ps *ParamSet) Run() error {
 		}
 
 		if ps.name != "" {
+			if err := ps.validateEnvValue(value); err != nil {
+				return err
+			}
 			return ps.setEnvFn(ps.app, ps.envName, ps.name, ps.rawPath, value)
 		}
 		return ps.setGlobalEnvFn(ps.app, ps.envName, ps.rawPath, value)
@@ -163,6 +168,10 @@ func (ps *ParamSet) setLocal(path []string, value interface{}) error {
 		return errors.Errorf("unable to find component %s", ps.rawPath)
 	}
 
+	if err := ps.validate(path, value); err != nil {
+		return err
+	}
+
 	if err := c.SetParam(path, value); err != nil {
 		return errors.Wrap(err, "set param")
 	}
@@ -170,6 +179,43 @@ func (ps *ParamSet) setLocal(path []string, value interface{}) error {
 	return nil
 }
 
+// validateEnvValue validates a raw environment param value against the
+// component's params schema.
+func (ps *ParamSet) validateEnvValue(rawValue string) error {
+	var value interface{} = rawValue
+	if !ps.asString {
+		decoded, err := jsonnet.DecodeValue(rawValue)
+		if err != nil {
+			return errors.Wrap(err, "value is invalid")
+		}
+		value = decoded
+	}
+
+	return ps.validate(strings.Split(ps.rawPath, "."), value)
+}
+
+// validate validates a param value against the params schema of the
+// component's module. Modules without a schema are not validated.
+func (ps *ParamSet) validate(path []string, value interface{}) error {
+	var moduleName, componentName string
+	if i := strings.LastIndex(ps.name, "."); i >= 0 {
+		moduleName, componentName = ps.name[:i], ps.name[i+1:]
+	} else {
+		componentName = ps.name
+	}
+
+	schema, err := ps.loadParamsSchemaFn(ps.app, moduleName)
+	if err != nil {
+		return errors.Wrap(err, "loading params schema")
+	}
+
+	if schema == nil {
+		return nil
+	}
+
+	return schema.ValidateParam(componentName, path, value)
+}
+
 // TODO: move this to pkg/env
 func setEnv(ksApp app.App, envName, name, pName, value string) error {
 	spc := env.SetParamsConfig{
@@ -16,6 +16,7 @@
 package actions
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
@@ -23,6 +24,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/component"
 	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -269,3 +271,84 @@ func TestParamSet_requires_app(t *testing.T) {
 	_, err := NewParamSet(in)
 	require.Error(t, err)
 }
+
+func TestParamSet_schema(t *testing.T) {
+	schema := &component.ParamsSchema{}
+	err := json.Unmarshal([]byte(`{
+		"components": {
+			"deployment": {
+				"properties": {
+					"replicas": {"type": "integer", "minimum": 1}
+				}
+			}
+		}
+	}`), schema)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		envName string
+		value   string
+		isErr   bool
+	}{
+		{
+			name:  "valid local value",
+			value: "3",
+		},
+		{
+			name:  "invalid local value",
+			value: "three",
+			isErr: true,
+		},
+		{
+			name:    "valid env value",
+			envName: "default",
+			value:   "3",
+		},
+		{
+			name:    "invalid env value",
+			envName: "default",
+			value:   "0",
+			isErr:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				c := &cmocks.Component{}
+				c.On("SetParam", []string{"replicas"}, mock.Anything).Return(nil)
+
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionName:    "nested.deployment",
+					OptionPath:    "replicas",
+					OptionValue:   tc.value,
+					OptionEnvName: tc.envName,
+				}
+
+				a, err := NewParamSet(in)
+				require.NoError(t, err)
+
+				a.loadParamsSchemaFn = func(_ app.App, moduleName string) (*component.ParamsSchema, error) {
+					assert.Equal(t, "nested", moduleName)
+					return schema, nil
+				}
+				a.resolvePathFn = func(app.App, string) (component.Module, component.Component, error) {
+					return nil, c, nil
+				}
+				a.setEnvFn = func(ksApp app.App, envName, name, pName, value string) error {
+					return nil
+				}
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					c.AssertNotCalled(t, "SetParam", mock.Anything, mock.Anything)
+					return
+				}
+				require.NoError(t, err)
+			})
+		})
+	}
+}
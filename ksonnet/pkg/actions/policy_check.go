@@ -0,0 +1,111 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/policy"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// RunPolicyCheck runs `policy check`.
+func RunPolicyCheck(m map[string]interface{}) error {
+	pc, err := NewPolicyCheck(m)
+	if err != nil {
+		return err
+	}
+
+	return pc.Run()
+}
+
+// PolicyCheck checks the objects rendered for an environment against the
+// app's policies.
+type PolicyCheck struct {
+	app        app.App
+	envName    string
+	outputType string
+
+	out       io.Writer
+	objectsFn func(a app.App, envName string) ([]*unstructured.Unstructured, error)
+	checkFn   func(a app.App, envName string, objects []*unstructured.Unstructured) ([]policy.Violation, error)
+}
+
+// NewPolicyCheck creates an instance of PolicyCheck.
+func NewPolicyCheck(m map[string]interface{}) (*PolicyCheck, error) {
+	ol := newOptionLoader(m)
+
+	pc := &PolicyCheck{
+		app:        ol.LoadApp(),
+		outputType: ol.LoadOptionalString(OptionOutput),
+
+		out: os.Stdout,
+		objectsFn: func(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+			return pipeline.New(a, envName).Objects(nil)
+		},
+		checkFn: func(a app.App, envName string, objects []*unstructured.Unstructured) ([]policy.Violation, error) {
+			return policy.New(a, envName).Check(objects)
+		},
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	if err := setCurrentEnv(pc.app, pc, ol); err != nil {
+		return nil, err
+	}
+
+	return pc, nil
+}
+
+// Run checks the environment and reports the violations. An error is
+// returned if any violations block `apply`.
+func (pc *PolicyCheck) Run() error {
+	f, err := table.DetectFormat(pc.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	objects, err := pc.objectsFn(pc.app, pc.envName)
+	if err != nil {
+		return err
+	}
+
+	violations, err := pc.checkFn(pc.app, pc.envName, objects)
+	if err != nil {
+		return err
+	}
+
+	if err = policy.Report(pc.out, f, violations); err != nil {
+		return err
+	}
+
+	if n := policy.Errors(violations); n > 0 {
+		return errors.Errorf("%d policy violation(s) found in environment %q", n, pc.envName)
+	}
+
+	return nil
+}
+
+func (pc *PolicyCheck) setCurrentEnv(name string) {
+	pc.envName = name
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/policy"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	warning := policy.Violation{
+		Policy:    "team-label",
+		Severity:  policy.SeverityWarning,
+		Component: "guestbook",
+		Kind:      "Deployment",
+		Name:      "guestbook",
+		Message:   "missing team label",
+	}
+
+	violation := policy.Violation{
+		Policy:    policy.PolicyNoLatestImage,
+		Severity:  policy.SeverityError,
+		Component: "guestbook",
+		Kind:      "Deployment",
+		Name:      "guestbook",
+		Message:   `container "guestbook" uses image "gcr.io/heptio-images/ks-guestbook-demo"`,
+	}
+
+	cases := []struct {
+		name       string
+		outputType string
+		violations []policy.Violation
+		outputFile string
+		isErr      bool
+	}{
+		{
+			name:       "warnings",
+			violations: []policy.Violation{warning},
+			outputFile: filepath.Join("policy", "check", "warnings.txt"),
+		},
+		{
+			name:       "errors",
+			outputType: "json",
+			violations: []policy.Violation{violation, warning},
+			outputFile: filepath.Join("policy", "check", "errors.json"),
+			isErr:      true,
+		},
+		{
+			name:       "invalid output type",
+			outputType: "yaml",
+			isErr:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: "prod",
+					OptionOutput:  tc.outputType,
+				}
+
+				a, err := NewPolicyCheck(in)
+				require.NoError(t, err)
+
+				objects := []*unstructured.Unstructured{{}}
+				a.objectsFn = func(_ app.App, envName string) ([]*unstructured.Unstructured, error) {
+					require.Equal(t, "prod", envName)
+					return objects, nil
+				}
+
+				a.checkFn = func(_ app.App, envName string, got []*unstructured.Unstructured) ([]policy.Violation, error) {
+					require.Equal(t, "prod", envName)
+					require.Equal(t, objects, got)
+					return tc.violations, nil
+				}
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				if tc.outputFile != "" {
+					assertOutput(t, tc.outputFile, buf.String())
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/policy"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+)
+
+// RunPolicyList runs `policy list`.
+func RunPolicyList(m map[string]interface{}) error {
+	pl, err := NewPolicyList(m)
+	if err != nil {
+		return err
+	}
+
+	return pl.Run()
+}
+
+// PolicyList lists the app's policies.
+type PolicyList struct {
+	app        app.App
+	outputType string
+
+	out    io.Writer
+	loadFn func(a app.App) ([]policy.Policy, error)
+}
+
+// NewPolicyList creates an instance of PolicyList.
+func NewPolicyList(m map[string]interface{}) (*PolicyList, error) {
+	ol := newOptionLoader(m)
+
+	pl := &PolicyList{
+		app:        ol.LoadApp(),
+		outputType: ol.LoadOptionalString(OptionOutput),
+
+		out:    os.Stdout,
+		loadFn: policy.Load,
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	return pl, nil
+}
+
+// Run lists the policies.
+func (pl *PolicyList) Run() error {
+	policies, err := pl.loadFn(pl.app)
+	if err != nil {
+		return err
+	}
+
+	t := table.New("policyList", pl.out)
+	t.SetHeader([]string{"name", "severity", "source", "description"})
+
+	f, err := table.DetectFormat(pl.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+	t.SetFormat(f)
+
+	for _, p := range policies {
+		t.Append([]string{p.Name, string(p.Severity), p.Source, p.Description})
+	}
+
+	return t.Render()
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/policy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyList(t *testing.T) {
+	policies := []policy.Policy{
+		{
+			Name:        policy.PolicyNoLatestImage,
+			Description: "Container images must be pinned to a tag other than latest",
+			Severity:    policy.SeverityError,
+			Source:      "built-in",
+		},
+		{
+			Name:        "team-label",
+			Description: "Objects must have a team label",
+			Severity:    policy.SeverityWarning,
+			Source:      "policies/team-label.jsonnet",
+		},
+	}
+
+	cases := []struct {
+		name       string
+		outputType string
+		outputFile string
+		isErr      bool
+	}{
+		{
+			name:       "table",
+			outputFile: filepath.Join("policy", "list", "output.txt"),
+		},
+		{
+			name:       "json",
+			outputType: "json",
+			outputFile: filepath.Join("policy", "list", "output.json"),
+		},
+		{
+			name:       "invalid output type",
+			outputType: "yaml",
+			isErr:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				in := map[string]interface{}{
+					OptionApp:    appMock,
+					OptionOutput: tc.outputType,
+				}
+
+				a, err := NewPolicyList(in)
+				require.NoError(t, err)
+
+				a.loadFn = func(app.App) ([]policy.Policy, error) {
+					return policies, nil
+				}
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assertOutput(t, tc.outputFile, buf.String())
+			})
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/parts"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+var (
+	rePrototypeName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+const (
+	prototypeNamePrefix = "io.ksonnet.pkg."
+
+	prototypeScaffold = `// @apiVersion 0.1
+// @name %s
+// @description A deployment that replicates container 'image' some number of times
+//   (default: 1), and exposes a port (default: 80).
+// @shortDescription Replicates a container n times, exposes a single port
+// @param name string Name of the deployment
+// @param image string example=nginx Container image to deploy
+// @optionalParam replicas number 1 Number of replicas
+// @optionalParam containerPort number 80 Port to expose
+{
+  "apiVersion": "apps/v1beta1",
+  "kind": "Deployment",
+  "metadata": {
+    "name": import 'param://name'
+  },
+  "spec": {
+    "replicas": import 'param://replicas',
+    "template": {
+      "metadata": {
+        "labels": {
+          "app": import 'param://name'
+        }
+      },
+      "spec": {
+        "containers": [
+          {
+            "image": import 'param://image',
+            "name": import 'param://name',
+            "ports": [
+              {
+                "containerPort": import 'param://containerPort'
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`
+)
+
+// RunPrototypeNew runs `prototype new`
+func RunPrototypeNew(m map[string]interface{}) error {
+	pn, err := NewPrototypeNew(m)
+	if err != nil {
+		return err
+	}
+
+	return pn.Run()
+}
+
+// PrototypeNew scaffolds a prototype in a package.
+type PrototypeNew struct {
+	fs   afero.Fs
+	name string
+	path string
+	out  io.Writer
+}
+
+// NewPrototypeNew creates an instance of PrototypeNew.
+func NewPrototypeNew(m map[string]interface{}) (*PrototypeNew, error) {
+	ol := newOptionLoader(m)
+
+	pn := &PrototypeNew{
+		fs:   ol.LoadFs(),
+		name: ol.LoadString(OptionName),
+		path: ol.LoadOptionalString(OptionPath),
+
+		out: os.Stdout,
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	if pn.path == "" {
+		pn.path = "."
+	}
+
+	return pn, nil
+}
+
+// Run scaffolds the prototype. A parts.yaml is created for the package if it
+// does not have one.
+func (pn *PrototypeNew) Run() error {
+	if !rePrototypeName.MatchString(pn.name) {
+		return errors.Errorf("prototype name %q is not valid; it must consist of lower case alphanumeric characters or '-'", pn.name)
+	}
+
+	fullName := prototypeNamePrefix + pn.name
+
+	protoPath := filepath.Join(pn.path, "prototypes", pn.name+".jsonnet")
+	exists, err := afero.Exists(pn.fs, protoPath)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return errors.Errorf("prototype %q already exists at %s", pn.name, protoPath)
+	}
+
+	if err = pn.fs.MkdirAll(filepath.Dir(protoPath), app.DefaultFolderPermissions); err != nil {
+		return errors.Wrap(err, "creating prototypes directory")
+	}
+
+	text := fmt.Sprintf(prototypeScaffold, fullName)
+	if err = afero.WriteFile(pn.fs, protoPath, []byte(text), app.DefaultFilePermissions); err != nil {
+		return errors.Wrapf(err, "writing prototype %s", protoPath)
+	}
+
+	fmt.Fprintf(pn.out, "Created prototype %s at %s\n", fullName, protoPath)
+
+	return pn.writeParts(fullName)
+}
+
+// writeParts creates a parts.yaml for the package if it does not exist.
+func (pn *PrototypeNew) writeParts(fullName string) error {
+	partsPath := filepath.Join(pn.path, "parts.yaml")
+	exists, err := afero.Exists(pn.fs, partsPath)
+	if err != nil || exists {
+		return err
+	}
+
+	abs, err := filepath.Abs(pn.path)
+	if err != nil {
+		return err
+	}
+
+	spec := &parts.Spec{
+		APIVersion:  parts.DefaultAPIVersion,
+		Kind:        parts.DefaultKind,
+		Name:        filepath.Base(abs),
+		Version:     "0.0.1",
+		Description: fmt.Sprintf("Prototypes for %s", filepath.Base(abs)),
+		Prototypes:  parts.PrototypeRefSpecs{fullName},
+	}
+
+	b, err := spec.Marshal()
+	if err != nil {
+		return errors.Wrap(err, "marshalling package configuration")
+	}
+
+	if err = afero.WriteFile(pn.fs, partsPath, b, app.DefaultFilePermissions); err != nil {
+		return errors.Wrapf(err, "writing package configuration %s", partsPath)
+	}
+
+	fmt.Fprintf(pn.out, "Created package configuration at %s\n", partsPath)
+	return nil
+}
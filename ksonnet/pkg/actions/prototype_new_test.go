@@ -0,0 +1,121 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/parts"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrototypeNew(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	in := map[string]interface{}{
+		OptionFs:   fs,
+		OptionName: "redis-cluster",
+		OptionPath: "/redis",
+	}
+
+	a, err := NewPrototypeNew(in)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	a.out = &buf
+
+	err = a.Run()
+	require.NoError(t, err)
+
+	b, err := afero.ReadFile(fs, "/redis/prototypes/redis-cluster.jsonnet")
+	require.NoError(t, err)
+
+	p, err := prototype.DefaultBuilder(string(b))
+	require.NoError(t, err)
+	assert.Equal(t, "io.ksonnet.pkg.redis-cluster", p.Name)
+
+	b, err = afero.ReadFile(fs, "/redis/parts.yaml")
+	require.NoError(t, err)
+
+	spec, err := parts.Unmarshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, "redis", spec.Name)
+	assert.Equal(t, parts.PrototypeRefSpecs{"io.ksonnet.pkg.redis-cluster"}, spec.Prototypes)
+
+	// a second prototype leaves the package configuration alone.
+	in[OptionName] = "redis-sentinel"
+	a, err = NewPrototypeNew(in)
+	require.NoError(t, err)
+	a.out = &buf
+
+	err = a.Run()
+	require.NoError(t, err)
+
+	exists, err := afero.Exists(fs, "/redis/prototypes/redis-sentinel.jsonnet")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	b2, err := afero.ReadFile(fs, "/redis/parts.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, string(b), string(b2))
+}
+
+func TestPrototypeNew_invalid(t *testing.T) {
+	cases := []struct {
+		name string
+		file string
+	}{
+		{
+			name: "Redis_Cluster",
+		},
+		{
+			name: "redis-cluster",
+			file: "/redis/prototypes/redis-cluster.jsonnet",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tc.file != "" {
+				err := afero.WriteFile(fs, tc.file, []byte("{}"), 0644)
+				require.NoError(t, err)
+			}
+
+			in := map[string]interface{}{
+				OptionFs:   fs,
+				OptionName: tc.name,
+				OptionPath: "/redis",
+			}
+
+			a, err := NewPrototypeNew(in)
+			require.NoError(t, err)
+
+			err = a.Run()
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPrototypeNew_requires_name(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := NewPrototypeNew(in)
+	require.Error(t, err)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/pkg"
 	"github.com/ksonnet/ksonnet/pkg/prototype"
 	"github.com/ksonnet/ksonnet/pkg/prototype/snippet"
+	"github.com/ksonnet/ksonnet/pkg/prototype/snippet/gotemplate"
 	"github.com/ksonnet/ksonnet/pkg/prototype/snippet/jsonnet"
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	strutil "github.com/ksonnet/ksonnet/pkg/util/strings"
@@ -116,8 +117,7 @@ func (pp *PrototypePreview) Run() error {
 		return errors.Wrap(err, "parse preview args")
 	}
 
-	// NOTE: only supporting jsonnet templates
-	templateType := prototype.Jsonnet
+	templateType := p.Template.DefaultTemplateType()
 
 	params, err := pp.extractParametersFn(pp.app.Fs(), p, flags)
 	if err != nil {
@@ -139,6 +139,26 @@ func expandPrototype(proto *prototype.Prototype, templateType prototype.Template
 	if err != nil {
 		return "", err
 	}
+
+	if proto.Template.Engine == prototype.GoTemplateEngine {
+		tm, err := gotemplate.Parse(proto.Name, strings.Join(template, "\n"))
+		if err != nil {
+			return "", err
+		}
+
+		text, err := tm.Evaluate(params)
+		if err != nil {
+			return "", err
+		}
+
+		if templateType != prototype.Jsonnet {
+			return text, nil
+		}
+
+		// Jsonnet templates may still import params and the environment.
+		template = strings.Split(text, "\n")
+	}
+
 	if templateType == prototype.Jsonnet {
 		componentsText := "components." + componentName
 		if !strutil.IsASCIIIdentifier(componentName) {
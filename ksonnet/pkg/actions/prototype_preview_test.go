@@ -17,6 +17,8 @@ package actions
 
 import (
 	"bytes"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
@@ -62,6 +64,44 @@ func TestPrototypePreview(t *testing.T) {
 	})
 }
 
+func TestPrototypePreview_gotemplate(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		b, err := ioutil.ReadFile(filepath.Join("testdata", "prototype", "preview", "templated-deployment.jsonnet"))
+		require.NoError(t, err)
+
+		p, err := prototype.DefaultBuilder(string(b))
+		require.NoError(t, err)
+
+		manager := &registrymocks.PackageManager{}
+		manager.On("Prototypes").Return(prototype.Prototypes{p}, nil)
+
+		args := []string{
+			"--name", "myDeployment",
+			"--image", "nginx",
+		}
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionQuery:         "templated-deployment",
+			OptionArguments:     args,
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPrototypePreview(in)
+		require.NoError(t, err)
+
+		a.packageManager = manager
+
+		var buf bytes.Buffer
+		a.out = &buf
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assertOutput(t, "prototype/preview/templated-deployment.txt", buf.String())
+	})
+}
+
 func TestPrototypePreview_bind_flags_failed(t *testing.T) {
 	withApp(t, func(appMock *amocks.App) {
 		prototypes := prototype.Prototypes{}
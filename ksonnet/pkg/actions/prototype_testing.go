@@ -0,0 +1,258 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	param "github.com/ksonnet/ksonnet/metadata/params"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/openapi"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/ksonnet/ksonnet/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	// prototypeTestModule is the module prototypes are generated into when
+	// they are tested.
+	prototypeTestModule = "prototypetest"
+)
+
+// RunPrototypeTest runs `prototype test`
+func RunPrototypeTest(m map[string]interface{}) error {
+	pt, err := NewPrototypeTest(m)
+	if err != nil {
+		return err
+	}
+
+	return pt.Run()
+}
+
+type testPrototypeFn func(a app.App, envName string, p *prototype.Prototype, templateType prototype.TemplateType) []error
+
+// PrototypeTest renders the prototypes in a package and validates the
+// generated objects.
+type PrototypeTest struct {
+	app     app.App
+	envName string
+	path    string
+	out     io.Writer
+
+	loadPrototypesFn func(fs afero.Fs, path string) (prototype.Prototypes, error)
+	testPrototypeFn  testPrototypeFn
+}
+
+// NewPrototypeTest creates an instance of PrototypeTest.
+func NewPrototypeTest(m map[string]interface{}) (*PrototypeTest, error) {
+	ol := newOptionLoader(m)
+
+	pt := &PrototypeTest{
+		app:  ol.LoadApp(),
+		path: ol.LoadOptionalString(OptionPath),
+
+		out:              os.Stdout,
+		loadPrototypesFn: loadPackagePrototypes,
+		testPrototypeFn:  testPrototype,
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	if pt.path == "" {
+		pt.path = "."
+	}
+
+	if err := setCurrentEnv(pt.app, pt, ol); err != nil {
+		return nil, err
+	}
+
+	return pt, nil
+}
+
+// Run tests every prototype in the package with each of its template types.
+// An error is returned if any of them fail.
+func (pt *PrototypeTest) Run() error {
+	prototypes, err := pt.loadPrototypesFn(pt.app.Fs(), pt.path)
+	if err != nil {
+		return err
+	}
+
+	if len(prototypes) == 0 {
+		return errors.Errorf("no prototypes found in %s", filepath.Join(pt.path, "prototypes"))
+	}
+
+	var failed int
+
+	for _, p := range prototypes {
+		for _, templateType := range p.Template.AvailableTemplates() {
+			errs := pt.testPrototypeFn(pt.app, pt.envName, p, templateType)
+			if len(errs) == 0 {
+				fmt.Fprintf(pt.out, "ok   %s (%s)\n", p.Name, templateType)
+				continue
+			}
+
+			failed++
+			fmt.Fprintf(pt.out, "FAIL %s (%s)\n", p.Name, templateType)
+			for _, err := range errs {
+				fmt.Fprintf(pt.out, "     %v\n", err)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d prototype(s) failed", failed)
+	}
+
+	return nil
+}
+
+func (pt *PrototypeTest) setCurrentEnv(name string) {
+	pt.envName = name
+}
+
+// loadPackagePrototypes loads the prototypes in the `prototypes` directory of
+// a package.
+func loadPackagePrototypes(fs afero.Fs, path string) (prototype.Prototypes, error) {
+	var prototypes prototype.Prototypes
+
+	protoPath := filepath.Join(path, "prototypes")
+	exists, err := afero.DirExists(fs, protoPath)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	err = afero.Walk(fs, protoPath, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if fi.IsDir() || filepath.Ext(path) != ".jsonnet" {
+			return nil
+		}
+
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+
+		p, err := prototype.DefaultBuilder(string(data))
+		if err != nil {
+			return errors.Wrapf(err, "parsing prototype %s", path)
+		}
+
+		prototypes = append(prototypes, p)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(prototypes, func(i, j int) bool {
+		return prototypes[i].Name < prototypes[j].Name
+	})
+
+	return prototypes, nil
+}
+
+// testPrototype generates a component from a prototype in a scratch copy of
+// the app and validates the objects it renders against the environment's
+// schema. Required params are set to their sample values and optional params
+// to their defaults. Nothing is written to the app.
+func testPrototype(a app.App, envName string, p *prototype.Prototype, templateType prototype.TemplateType) []error {
+	scratch, err := scratchApp(a, envName)
+	if err != nil {
+		return []error{err}
+	}
+
+	flags, err := prototype.BindFlags(p)
+	if err != nil {
+		return []error{errors.Wrap(err, "binding prototype flags")}
+	}
+
+	for _, ps := range p.RequiredParams() {
+		if err = flags.Set(ps.Name, ps.SampleValue()); err != nil {
+			return []error{err}
+		}
+	}
+
+	values, err := prototype.ExtractParameters(scratch.Fs(), p, flags)
+	if err != nil {
+		return []error{err}
+	}
+
+	name := p.Name[strings.LastIndex(p.Name, ".")+1:]
+
+	text, err := expandPrototype(p, templateType, values, name)
+	if err != nil {
+		return []error{errors.Wrap(err, "expanding prototype")}
+	}
+
+	ps := param.Params{}
+	for k, v := range values {
+		ps[k] = v
+	}
+
+	if _, err = component.Create(scratch, prototypeTestModule, name, text, ps, templateType); err != nil {
+		return []error{errors.Wrap(err, "creating component")}
+	}
+
+	objects, err := pipeline.New(scratch, envName).Objects(nil)
+	if err != nil {
+		return []error{errors.Wrap(err, "rendering component")}
+	}
+
+	if len(objects) == 0 {
+		return []error{errors.New("prototype did not generate any objects")}
+	}
+
+	var errs []error
+	for _, obj := range objects {
+		for _, err := range openapi.ValidateAgainstSchema(scratch, obj, envName) {
+			errs = append(errs, errors.Wrapf(err, "%s %s", obj.GetKind(), utils.FqName(obj)))
+		}
+	}
+
+	return errs
+}
+
+// scratchApp loads a copy of the app whose changes are kept in memory. The
+// environment only targets the module prototypes are generated into.
+func scratchApp(a app.App, envName string) (app.App, error) {
+	fs := afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(a.Fs()), afero.NewMemMapFs())
+
+	scratch, err := app.Load(fs, a.HTTPClient(), a.Root())
+	if err != nil {
+		return nil, errors.Wrap(err, "loading scratch app")
+	}
+
+	isOverride := scratch.IsEnvOverride(envName)
+	if err = scratch.UpdateTargets(envName, []string{prototypeTestModule}, isOverride); err != nil {
+		return nil, err
+	}
+
+	return scratch, nil
+}
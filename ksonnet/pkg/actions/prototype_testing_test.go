@@ -0,0 +1,111 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrototypeTest(t *testing.T) {
+	cases := []struct {
+		name       string
+		failing    string
+		outputFile string
+		isErr      bool
+	}{
+		{
+			name:       "all pass",
+			outputFile: "prototype/test/pass.txt",
+		},
+		{
+			name:       "failure",
+			failing:    "io.ksonnet.pkg.redis-sentinel",
+			outputFile: "prototype/test/fail.txt",
+			isErr:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				stageFile(t, appMock.Fs(), "prototype/test/redis-cluster.jsonnet", "/redis/prototypes/redis-cluster.jsonnet")
+				stageFile(t, appMock.Fs(), "prototype/test/redis-sentinel.jsonnet", "/redis/prototypes/redis-sentinel.jsonnet")
+
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: "default",
+					OptionPath:    "/redis",
+				}
+
+				a, err := NewPrototypeTest(in)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				a.testPrototypeFn = func(_ app.App, envName string, p *prototype.Prototype, templateType prototype.TemplateType) []error {
+					assert.Equal(t, "default", envName)
+					assert.Equal(t, prototype.Jsonnet, templateType)
+
+					if p.Name == tc.failing {
+						return []error{errors.New("Deployment default.redis-sentinel: $.spec.replicas: invalid type")}
+					}
+
+					return nil
+				}
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				assertOutput(t, tc.outputFile, buf.String())
+			})
+		})
+	}
+}
+
+func TestPrototypeTest_no_prototypes(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "default",
+			OptionPath:    "/redis",
+		}
+
+		a, err := NewPrototypeTest(in)
+		require.NoError(t, err)
+
+		err = a.Run()
+		require.Error(t, err)
+	})
+}
+
+func TestPrototypeTest_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := NewPrototypeTest(in)
+	require.Error(t, err)
+}
@@ -16,6 +16,8 @@
 package actions
 
 import (
+	"bufio"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -29,6 +31,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/pflag"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 // RunPrototypeUse runs `prototype use`
@@ -45,7 +48,9 @@ func RunPrototypeUse(m map[string]interface{}) error {
 type PrototypeUse struct {
 	app                 app.App
 	args                []string
+	in                  io.Reader
 	out                 io.Writer
+	interactive         bool
 	packageManager      registry.PackageManager
 	createComponentFn   func(app.App, string, string, string, param.Params, prototype.TemplateType) (string, error)
 	bindFlagsFn         func(p *prototype.Prototype) (*pflag.FlagSet, error)
@@ -63,7 +68,9 @@ func NewPrototypeUse(m map[string]interface{}) (*PrototypeUse, error) {
 		app:  app,
 		args: ol.LoadStringSlice(OptionArguments),
 
+		in:                  os.Stdin,
 		out:                 os.Stdout,
+		interactive:         terminal.IsTerminal(int(os.Stdin.Fd())),
 		packageManager:      registry.NewPackageManager(app, httpClientOpt),
 		createComponentFn:   component.Create,
 		bindFlagsFn:         prototype.BindFlags,
@@ -126,10 +133,10 @@ func (pl *PrototypeUse) Run() error {
 		return errors.Errorf("Command is missing argument 'componentName'")
 	} else if len(args) == 2 {
 		componentName = args[1]
-		templateType = prototype.Jsonnet
+		templateType = p.Template.DefaultTemplateType()
 	} else if len(args) == 3 {
 		componentName = args[1]
-		templateType, err = prototype.ParseTemplateType(args[1])
+		templateType, err = prototype.ParseTemplateType(args[2])
 		if err != nil {
 			return err
 		}
@@ -159,7 +166,9 @@ func (pl *PrototypeUse) Run() error {
 		}
 	}
 
-	rawParams, err := pl.extractParametersFn(pl.app.Fs(), p, flags)
+	in := bufio.NewReader(pl.in)
+
+	rawParams, prompted, err := pl.extractParameters(in, p, flags)
 	if err != nil {
 		return err
 	}
@@ -169,6 +178,18 @@ func (pl *PrototypeUse) Run() error {
 		return err
 	}
 
+	if prompted {
+		ok, err := pl.confirm(in, prototypeName, text)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			fmt.Fprintln(pl.out, "Component was not created")
+			return nil
+		}
+	}
+
 	ps := param.Params{}
 	for k, v := range rawParams {
 		ps[k] = v
@@ -181,3 +202,78 @@ func (pl *PrototypeUse) Run() error {
 
 	return nil
 }
+
+// extractParameters extracts the prototype's parameters from flags. When
+// running interactively, required parameters which were not supplied are
+// prompted for. It reports whether any parameters were prompted for.
+func (pl *PrototypeUse) extractParameters(in *bufio.Reader, p *prototype.Prototype, flags *pflag.FlagSet) (map[string]string, bool, error) {
+	rawParams, err := pl.extractParametersFn(pl.app.Fs(), p, flags)
+	missing, ok := errors.Cause(err).(*prototype.MissingParametersError)
+	if !ok || !pl.interactive {
+		return rawParams, false, err
+	}
+
+	for _, param := range missing.Params {
+		value, err := pl.prompt(in, param)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if err = flags.Set(param.Name, value); err != nil {
+			return nil, false, err
+		}
+	}
+
+	rawParams, err = pl.extractParametersFn(pl.app.Fs(), p, flags)
+	return rawParams, true, err
+}
+
+// prompt asks for a parameter value until a valid one is supplied.
+func (pl *PrototypeUse) prompt(in *bufio.Reader, param *prototype.ParamSchema) (string, error) {
+	label := fmt.Sprintf("%s (%s", param.Name, param.Type)
+	if len(param.Enum) > 0 {
+		label += ", one of: " + strings.Join(param.Enum, ", ")
+	}
+	label += ")"
+
+	for {
+		fmt.Fprintf(pl.out, "%s - %s: ", label, param.Description)
+
+		line, err := in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", errors.Wrapf(err, "reading value for %q", param.Name)
+		}
+
+		value := strings.TrimSpace(line)
+		if value == "" {
+			fmt.Fprintln(pl.out, "A value is required")
+			continue
+		}
+
+		if err = param.Validate(value); err != nil {
+			fmt.Fprintln(pl.out, err)
+			continue
+		}
+
+		return value, nil
+	}
+}
+
+// confirm previews the generated component and asks whether it should be
+// created.
+func (pl *PrototypeUse) confirm(in *bufio.Reader, name, text string) (bool, error) {
+	fmt.Fprintf(pl.out, "\n%s\n", strings.TrimSpace(text))
+	fmt.Fprintf(pl.out, "\nCreate component %q? [y/N]: ", name)
+
+	line, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, errors.Wrap(err, "reading confirmation")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
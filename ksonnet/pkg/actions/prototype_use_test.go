@@ -16,6 +16,8 @@
 package actions
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	param "github.com/ksonnet/ksonnet/metadata/params"
@@ -183,3 +185,106 @@ func TestPrototypeUse_requires_app(t *testing.T) {
 	_, err := NewPrototypeUse(in)
 	require.Error(t, err)
 }
+
+func TestPrototypeUse_prompt_for_missing_params(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		created bool
+		isErr   bool
+	}{
+		{
+			name:    "confirmed",
+			input:   "nginx\ny\n",
+			created: true,
+		},
+		{
+			name:  "declined",
+			input: "nginx\nn\n",
+		},
+		{
+			name:    "reprompts for an empty value",
+			input:   "\nnginx\nyes\n",
+			created: true,
+		},
+		{
+			name:  "input ends before a value is supplied",
+			input: "",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				manager := &registrymocks.PackageManager{}
+				manager.On("Prototypes").Return(prototype.Prototypes{}, nil)
+
+				args := []string{
+					"single-port-deployment",
+					"deployment",
+					"--containerPort", "80",
+				}
+
+				in := map[string]interface{}{
+					OptionApp:           appMock,
+					OptionArguments:     args,
+					OptionTLSSkipVerify: false,
+				}
+
+				a, err := NewPrototypeUse(in)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				a.packageManager = manager
+				a.interactive = true
+				a.in = strings.NewReader(tc.input)
+				a.out = &buf
+
+				created := false
+				a.createComponentFn = func(_ app.App, moduleName, name, text string, params param.Params, template prototype.TemplateType) (string, error) {
+					created = true
+					assertOutput(t, "prototype/use/text.txt", text)
+					assert.Equal(t, `"nginx"`, params["image"])
+					return "", nil
+				}
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.created, created)
+				assert.Contains(t, buf.String(), "image (string) - Container image to deploy: ")
+				assert.Contains(t, buf.String(), `Create component "deployment"? [y/N]: `)
+			})
+		})
+	}
+}
+
+func TestPrototypeUse_not_interactive(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		manager := &registrymocks.PackageManager{}
+		manager.On("Prototypes").Return(prototype.Prototypes{}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionArguments:     []string{"single-port-deployment", "deployment"},
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPrototypeUse(in)
+		require.NoError(t, err)
+
+		a.packageManager = manager
+		a.interactive = false
+
+		err = a.Run()
+		require.Error(t, err)
+
+		_, ok := err.(*prototype.MissingParametersError)
+		assert.True(t, ok)
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 )
 
 type runShowFn func(cluster.ShowConfig, ...cluster.ShowOpts) error
@@ -44,7 +45,13 @@ type Show struct {
 	clientConfig   *client.Config
 	componentNames []string
 	envName        string
+	explain        string
+	filter         pipeline.ObjectFilter
 	format         string
+	imagePlatform  string
+	resolveImages  bool
+	stats          bool
+	statsFormat    string
 
 	out       io.Writer
 	runShowFn runShowFn
@@ -57,7 +64,13 @@ func newShow(m map[string]interface{}, opts ...showOpt) (*Show, error) {
 	s := &Show{
 		app:            ol.LoadApp(),
 		componentNames: ol.LoadStringSlice(OptionComponentNames),
+		explain:        ol.LoadOptionalString(OptionExplain),
+		filter:         ol.LoadObjectFilter(),
 		format:         ol.LoadString(OptionFormat),
+		imagePlatform:  ol.LoadImagePlatform(),
+		resolveImages:  ol.LoadOptionalBool(OptionResolveImages),
+		stats:          ol.LoadOptionalBool(OptionStats),
+		statsFormat:    ol.LoadOptionalString(OptionStatsFormat),
 
 		out:       os.Stdout,
 		runShowFn: cluster.RunShow,
@@ -83,8 +96,20 @@ func (s *Show) run() error {
 		App:            s.app,
 		ComponentNames: s.componentNames,
 		EnvName:        s.envName,
+		Explain:        s.explain,
+		Filter:         s.filter,
 		Format:         s.format,
+		ImagePlatform:  s.imagePlatform,
 		Out:            s.out,
+		ResolveImages:  s.resolveImages,
+	}
+
+	if s.stats {
+		config.Stats = s.statsFormat
+		if config.Stats == "" {
+			config.Stats = "table"
+		}
+		config.StatsOut = os.Stderr
 	}
 
 	return s.runShowFn(config)
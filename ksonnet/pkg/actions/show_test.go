@@ -21,6 +21,7 @@ import (
 
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -55,15 +56,27 @@ func TestShow(t *testing.T) {
 					OptionApp:            appMock,
 					OptionComponentNames: []string{},
 					OptionEnvName:        tc.envName,
+					OptionExplain:        "Deployment/guestbook-ui",
 					OptionFormat:         "yaml",
+					OptionImagePlatform:  "linux/arm64",
+					OptionKinds:          []string{"Deployment"},
+					OptionResolveImages:  true,
+					OptionStats:          true,
+					OptionStatsFormat:    "json",
 				}
 
 				expected := cluster.ShowConfig{
 					App:            appMock,
 					ComponentNames: []string{},
 					EnvName:        "default",
+					Explain:        "Deployment/guestbook-ui",
+					Filter:         pipeline.ObjectFilter{Kinds: []string{"Deployment"}},
 					Format:         "yaml",
+					ImagePlatform:  "linux/arm64",
 					Out:            os.Stdout,
+					ResolveImages:  true,
+					Stats:          "json",
+					StatsOut:       os.Stderr,
 				}
 
 				runShowOpt := func(a *Show) {
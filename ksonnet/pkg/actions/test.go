@@ -0,0 +1,118 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/unittest"
+	"github.com/pkg/errors"
+)
+
+// RunTest runs `test`.
+func RunTest(m map[string]interface{}) error {
+	t, err := NewTest(m)
+	if err != nil {
+		return err
+	}
+
+	return t.Run()
+}
+
+// Test runs the component tests in an app.
+type Test struct {
+	app        app.App
+	envName    string
+	outputType string
+
+	out        io.Writer
+	discoverFn func(a app.App) ([]string, error)
+	runFn      func(a app.App, envName string, paths []string) ([]unittest.Suite, error)
+}
+
+// NewTest creates an instance of Test.
+func NewTest(m map[string]interface{}) (*Test, error) {
+	ol := newOptionLoader(m)
+
+	t := &Test{
+		app:        ol.LoadApp(),
+		outputType: ol.LoadOptionalString(OptionOutput),
+
+		out:        os.Stdout,
+		discoverFn: unittest.Discover,
+		runFn: func(a app.App, envName string, paths []string) ([]unittest.Suite, error) {
+			return unittest.New(a, envName).Run(paths)
+		},
+	}
+
+	if ol.err != nil {
+		return nil, ol.err
+	}
+
+	if t.outputType == "" {
+		t.outputType = unittest.FormatTAP
+	}
+
+	if err := setCurrentEnv(t.app, t, ol); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Run runs the tests and reports their results. An error is returned if any
+// tests fail.
+func (t *Test) Run() error {
+	if t.outputType != unittest.FormatTAP && t.outputType != unittest.FormatJUnit {
+		return errors.Errorf("invalid output type %q; valid options are %s and %s",
+			t.outputType, unittest.FormatTAP, unittest.FormatJUnit)
+	}
+
+	paths, err := t.discoverFn(t.app)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return errors.New("no tests found; tests are named *_test.jsonnet and live in the components or tests directory")
+	}
+
+	suites, err := t.runFn(t.app, t.envName, paths)
+	if err != nil {
+		return err
+	}
+
+	if err = unittest.Report(t.out, t.outputType, suites); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, s := range suites {
+		failed += s.Failures()
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d test(s) failed", failed)
+	}
+
+	return nil
+}
+
+func (t *Test) setCurrentEnv(name string) {
+	t.envName = name
+}
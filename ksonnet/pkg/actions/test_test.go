@@ -0,0 +1,120 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/unittest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTest(t *testing.T) {
+	passed := []unittest.Suite{
+		{
+			Path: "components/guestbook_test.jsonnet",
+			Cases: []unittest.Case{
+				{Name: "has 5 replicas"},
+				{Name: "has a PodDisruptionBudget"},
+			},
+		},
+	}
+
+	failed := []unittest.Suite{
+		{
+			Path: "components/guestbook_test.jsonnet",
+			Cases: []unittest.Case{
+				{Name: "has 5 replicas", Failure: "expected true, got false"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		outputType string
+		paths      []string
+		suites     []unittest.Suite
+		outputFile string
+		isErr      bool
+	}{
+		{
+			name:       "passed",
+			paths:      []string{"components/guestbook_test.jsonnet"},
+			suites:     passed,
+			outputFile: filepath.Join("test", "passed.tap"),
+		},
+		{
+			name:       "failed",
+			outputType: "junit",
+			paths:      []string{"components/guestbook_test.jsonnet"},
+			suites:     failed,
+			outputFile: filepath.Join("test", "failed.xml"),
+			isErr:      true,
+		},
+		{
+			name:  "no tests",
+			isErr: true,
+		},
+		{
+			name:       "invalid output type",
+			outputType: "json",
+			isErr:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: "prod",
+					OptionOutput:  tc.outputType,
+				}
+
+				a, err := NewTest(in)
+				require.NoError(t, err)
+
+				a.discoverFn = func(app.App) ([]string, error) {
+					return tc.paths, nil
+				}
+
+				a.runFn = func(_ app.App, envName string, paths []string) ([]unittest.Suite, error) {
+					require.Equal(t, "prod", envName)
+					require.Equal(t, tc.paths, paths)
+					return tc.suites, nil
+				}
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				if tc.outputFile != "" {
+					assertOutput(t, tc.outputFile, buf.String())
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,5 @@
+// This comment is not attached to a field.
+local base = import "../base.libsonnet";
+base + {
+    components: {}
+}
@@ -16,17 +16,27 @@
 package actions
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/crd"
+	"github.com/ksonnet/ksonnet/pkg/deprecation"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
 	"github.com/ksonnet/ksonnet/pkg/openapi"
 	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/ksonnet/ksonnet/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/discovery"
 )
@@ -46,23 +56,39 @@ type discoveryFn func(a app.App, clientConfig *client.Config, envName string) (d
 type validateObjectFn func(
 	a app.App,
 	obj *unstructured.Unstructured,
-	envName string) []error
+	envName string,
+	crds []*crd.CRD) []error
 
 type findObjectsFn func(a app.App, envName string,
 	componentNames []string) ([]*unstructured.Unstructured, error)
 
+type validateParamsFn func(a app.App, envName string) []error
+
+type collectCRDsFn func(a app.App, objects []*unstructured.Unstructured) ([]*crd.CRD, error)
+
+type componentFilesFn func(a app.App) (map[string]string, error)
+
+type checkAPIsFn func(a app.App, envName string, disc discovery.DiscoveryInterface,
+	objects []*unstructured.Unstructured) ([]deprecation.Finding, error)
+
 // Validate lists namespaces.
 type Validate struct {
 	app            app.App
 	envName        string
 	module         string
 	componentNames []string
+	filter         pipeline.ObjectFilter
 	clientConfig   *client.Config
+	offline        bool
 	out            io.Writer
 
 	discoveryFn      discoveryFn
 	validateObjectFn validateObjectFn
 	findObjectsFn    findObjectsFn
+	validateParamsFn validateParamsFn
+	collectCRDsFn    collectCRDsFn
+	componentFilesFn componentFilesFn
+	checkAPIsFn      checkAPIsFn
 }
 
 // NewValidate creates an instance of Validate.
@@ -74,12 +100,18 @@ func NewValidate(m map[string]interface{}) (*Validate, error) {
 		envName:        ol.LoadString(OptionEnvName),
 		module:         ol.LoadString(OptionModule),
 		componentNames: ol.LoadStringSlice(OptionComponentNames),
+		filter:         ol.LoadObjectFilter(),
 		clientConfig:   ol.LoadClientConfig(),
+		offline:        ol.LoadOptionalBool(OptionOffline),
 
 		out:              os.Stdout,
 		discoveryFn:      loadDiscovery,
-		validateObjectFn: openapi.ValidateAgainstSchema,
+		validateObjectFn: validateObject,
 		findObjectsFn:    findObjects,
+		validateParamsFn: validateParams,
+		collectCRDsFn:    collectCRDs,
+		componentFilesFn: componentFiles,
+		checkAPIsFn:      checkAPIs,
 	}
 
 	if ol.err != nil {
@@ -95,29 +127,92 @@ func NewValidate(m map[string]interface{}) (*Validate, error) {
 
 // Run lists namespaces.
 func (v *Validate) Run() error {
-	objects, err := v.findObjectsFn(v.app, v.envName, v.componentNames)
+	if err := v.checkParams(); err != nil {
+		return err
+	}
+
+	all, err := v.findObjectsFn(v.app, v.envName, v.componentNames)
+	if err != nil {
+		return err
+	}
+
+	objects, err := v.filter.Filter(all)
+	if err != nil {
+		return err
+	}
+
+	// CRDs can be defined by any component, not only the ones being
+	// validated.
+	if len(v.componentNames) > 0 {
+		all, err = v.findObjectsFn(v.app, v.envName, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	crds, err := v.collectCRDsFn(v.app, all)
 	if err != nil {
 		return err
 	}
 
-	disc, err := v.discoveryFn(v.app, v.clientConfig, v.envName)
+	files, err := v.componentFilesFn(v.app)
 	if err != nil {
 		return err
 	}
 
+	resourceName := func(obj *unstructured.Unstructured) string {
+		return strings.ToLower(obj.GetKind())
+	}
+
+	var disc discovery.DiscoveryInterface
+	if !v.offline {
+		disc, err = v.discoveryFn(v.app, v.clientConfig, v.envName)
+		if err != nil {
+			return err
+		}
+
+		resourceName = func(obj *unstructured.Unstructured) string {
+			return utils.ResourceNameFor(disc, obj)
+		}
+	}
+
 	var hasError bool
 
 	for _, obj := range objects {
-		desc := fmt.Sprintf("%s %s", utils.ResourceNameFor(disc, obj), utils.FqName(obj))
+		desc := fmt.Sprintf("%s %s", resourceName(obj), utils.FqName(obj))
+		if file, ok := files[obj.GetLabels()[metadata.LabelComponent]]; ok {
+			desc = fmt.Sprintf("%s (%s)", desc, file)
+		}
 		log.Info("Validating ", desc)
 
-		errs := v.validateObjectFn(v.app, obj, v.envName)
+		errs := v.validateObjectFn(v.app, obj, v.envName, crds)
 		for _, err := range errs {
 			log.Errorf("Error in %s: %v", desc, err)
 			hasError = true
 		}
 	}
 
+	findings, err := v.checkAPIsFn(v.app, v.envName, disc, objects)
+	if err != nil {
+		return errors.Wrap(err, "check APIs")
+	}
+
+	for _, f := range findings {
+		entry := log.WithFields(log.Fields{
+			"component": f.Component,
+			"kind":      f.Kind,
+			"name":      f.Name,
+		})
+
+		if f.Blocking() {
+			entry.Error(f.Message)
+			hasError = true
+			continue
+		}
+
+		entry.Warn(f.Message)
+	}
+
 	if hasError {
 		return errors.Errorf("validation failed")
 	}
@@ -125,16 +220,148 @@ func (v *Validate) Run() error {
 	return nil
 }
 
+// checkParams validates the resolved params of every environment against
+// the params schemas in the app.
+func (v *Validate) checkParams() error {
+	envs, err := v.app.Environments()
+	if err != nil {
+		return err
+	}
+
+	var envNames []string
+	for name := range envs {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+
+	var hasError bool
+
+	for _, envName := range envNames {
+		for _, err := range v.validateParamsFn(v.app, envName) {
+			log.Errorf("Error in params for environment %q: %v", envName, err)
+			hasError = true
+		}
+	}
+
+	if hasError {
+		return errors.Errorf("params validation failed")
+	}
+
+	return nil
+}
+
 func loadDiscovery(a app.App, clientConfig *client.Config, envName string) (discovery.DiscoveryInterface, error) {
 	_, d, _, err := clientConfig.RestClient(a, &envName)
 	return d, err
 }
 
+// checkAPIs checks the APIs of objects against the cluster. Offline, they are
+// checked against the Kubernetes version of the environment.
+func checkAPIs(a app.App, envName string, disc discovery.DiscoveryInterface, objects []*unstructured.Unstructured) ([]deprecation.Finding, error) {
+	if disc != nil {
+		checker, err := deprecation.ForCluster(disc)
+		if err != nil {
+			return nil, err
+		}
+
+		return checker.Check(objects)
+	}
+
+	env, err := a.Environment(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := deprecation.ParseVersion(env.KubernetesVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "environment %q", envName)
+	}
+
+	return deprecation.New(version, nil).Check(objects)
+}
+
 func findObjects(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
 	p := pipeline.New(a, envName)
 	return p.Objects(componentNames)
 }
 
+func validateParams(a app.App, envName string) []error {
+	p := pipeline.New(a, envName)
+	return p.ValidateParams()
+}
+
+func validateObject(a app.App, obj *unstructured.Unstructured, envName string, crds []*crd.CRD) []error {
+	return openapi.ValidateAgainstSchema(a, obj, envName, crds...)
+}
+
+// collectCRDs collects the CRDs defined by components and by the manifests
+// in vendored packages.
+func collectCRDs(a app.App, objects []*unstructured.Unstructured) ([]*crd.CRD, error) {
+	var crds []*crd.CRD
+
+	for _, obj := range objects {
+		if obj.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		found, err := crd.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "read CRD %s", obj.GetName())
+		}
+
+		crds = append(crds, found...)
+	}
+
+	exists, err := afero.DirExists(a.Fs(), a.VendorPath())
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		found, err := readCRDs(a.Fs(), a.VendorPath(), false)
+		if err != nil {
+			return nil, err
+		}
+
+		crds = append(crds, found...)
+	}
+
+	return crds, nil
+}
+
+// componentFiles maps component names to the paths of their files relative
+// to the app root.
+func componentFiles(a app.App) (map[string]string, error) {
+	modules, err := component.Modules(a)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, m := range modules {
+		components, err := m.Components()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range components {
+			path := filepath.Join(m.Dir(), c.Name(false)+"."+c.Type())
+			if rel, err := filepath.Rel(a.Root(), path); err == nil {
+				path = rel
+			}
+
+			files[c.Name(true)] = path
+		}
+	}
+
+	return files, nil
+}
+
 func (v *Validate) setCurrentEnv(name string) {
 	v.envName = name
 }
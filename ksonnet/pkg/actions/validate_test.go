@@ -16,6 +16,7 @@
 package actions
 
 import (
+	"path/filepath"
 	"testing"
 
 	swagger "github.com/emicklei/go-restful-swagger12"
@@ -23,6 +24,8 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/crd"
+	"github.com/ksonnet/ksonnet/pkg/deprecation"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -97,17 +100,309 @@ func TestValidate(t *testing.T) {
 					return objects, nil
 				}
 
-				a.validateObjectFn = func(a app.App, obj *unstructured.Unstructured, envName string) []error {
+				a.validateObjectFn = func(a app.App, obj *unstructured.Unstructured, envName string, crds []*crd.CRD) []error {
 					return make([]error, 0)
 				}
 
+				a.collectCRDsFn = func(a app.App, objects []*unstructured.Unstructured) ([]*crd.CRD, error) {
+					return nil, nil
+				}
+
+				a.componentFilesFn = func(a app.App) (map[string]string, error) {
+					return nil, nil
+				}
+
+				a.checkAPIsFn = func(a app.App, envName string, disc discovery.DiscoveryInterface, got []*unstructured.Unstructured) ([]deprecation.Finding, error) {
+					assert.NotNil(t, disc)
+					assert.Equal(t, objects, got)
+					return nil, nil
+				}
+
+				appMock.On("Environments").Return(app.EnvironmentConfigs{"default": env}, nil)
+				a.validateParamsFn = func(a app.App, envName string) []error {
+					assert.Equal(t, "default", envName)
+					return nil
+				}
+
+				err = a.Run()
+				require.NoError(t, err)
+			})
+		})
+	}
+}
+
+func TestValidate_invalid_params(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		envs := app.EnvironmentConfigs{
+			"default": &app.EnvironmentConfig{},
+			"prod":    &app.EnvironmentConfig{},
+		}
+		appMock.On("Environment", "default").Return(envs["default"], nil)
+		appMock.On("Environments").Return(envs, nil)
+
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionEnvName:        "default",
+			OptionModule:         "module",
+			OptionComponentNames: make([]string, 0),
+			OptionClientConfig:   &client.Config{},
+		}
+
+		a, err := NewValidate(in)
+		require.NoError(t, err)
+
+		var validated []string
+		a.validateParamsFn = func(a app.App, envName string) []error {
+			validated = append(validated, envName)
+			if envName == "prod" {
+				return []error{errors.New("invalid")}
+			}
+			return nil
+		}
+
+		a.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+			t.Fatal("objects should not be rendered when params are invalid")
+			return nil, nil
+		}
+
+		err = a.Run()
+		require.Error(t, err)
+
+		assert.Equal(t, []string{"default", "prod"}, validated)
+	})
+}
+
+func TestValidate_offline(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		env := &app.EnvironmentConfig{}
+		appMock.On("Environment", "default").Return(env, nil)
+		appMock.On("Environments").Return(app.EnvironmentConfigs{"default": env}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionEnvName:        "default",
+			OptionModule:         "",
+			OptionComponentNames: []string{"foo"},
+			OptionClientConfig:   &client.Config{},
+			OptionOffline:        true,
+		}
+
+		a, err := NewValidate(in)
+		require.NoError(t, err)
+
+		a.validateParamsFn = func(a app.App, envName string) []error {
+			return nil
+		}
+
+		a.discoveryFn = func(a app.App, clientConfig *client.Config, envName string) (discovery.DiscoveryInterface, error) {
+			t.Fatal("discovery should not be used offline")
+			return nil, nil
+		}
+
+		foo := &unstructured.Unstructured{}
+		foo.SetAPIVersion("example.com/v1")
+		foo.SetKind("Foo")
+		foo.SetName("foo")
+		foo.SetLabels(map[string]string{"ksonnet.io/component": "foo"})
+
+		fooCRD := &unstructured.Unstructured{}
+		fooCRD.SetKind("CustomResourceDefinition")
+
+		a.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+			if len(componentNames) == 0 {
+				return []*unstructured.Unstructured{foo, fooCRD}, nil
+			}
+
+			assert.Equal(t, []string{"foo"}, componentNames)
+			return []*unstructured.Unstructured{foo}, nil
+		}
+
+		crds := []*crd.CRD{{Name: "foos.example.com"}}
+		a.collectCRDsFn = func(a app.App, objects []*unstructured.Unstructured) ([]*crd.CRD, error) {
+			assert.Equal(t, []*unstructured.Unstructured{foo, fooCRD}, objects)
+			return crds, nil
+		}
+
+		a.componentFilesFn = func(a app.App) (map[string]string, error) {
+			return map[string]string{"foo": "components/foo.jsonnet"}, nil
+		}
+
+		var validated []*unstructured.Unstructured
+		a.validateObjectFn = func(a app.App, obj *unstructured.Unstructured, envName string, got []*crd.CRD) []error {
+			assert.Equal(t, crds, got)
+			validated = append(validated, obj)
+			return []error{errors.New("invalid")}
+		}
+
+		err = a.Run()
+		require.Error(t, err)
+
+		assert.Equal(t, []*unstructured.Unstructured{foo}, validated)
+	})
+}
+
+func TestValidate_apis(t *testing.T) {
+	cases := []struct {
+		name              string
+		kubernetesVersion string
+		isErr             bool
+	}{
+		{
+			name:              "deprecated",
+			kubernetesVersion: "v1.9.0",
+		},
+		{
+			name:              "removed",
+			kubernetesVersion: "v1.16.0",
+			isErr:             true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				env := &app.EnvironmentConfig{KubernetesVersion: tc.kubernetesVersion}
+				appMock.On("Environment", "default").Return(env, nil)
+				appMock.On("Environments").Return(app.EnvironmentConfigs{"default": env}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:            appMock,
+					OptionEnvName:        "default",
+					OptionModule:         "",
+					OptionComponentNames: []string{},
+					OptionClientConfig:   &client.Config{},
+					OptionOffline:        true,
+				}
+
+				a, err := NewValidate(in)
+				require.NoError(t, err)
+
+				deployment := &unstructured.Unstructured{}
+				deployment.SetAPIVersion("extensions/v1beta1")
+				deployment.SetKind("Deployment")
+				deployment.SetName("guestbook-ui")
+
+				a.validateParamsFn = func(a app.App, envName string) []error {
+					return nil
+				}
+				a.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+					return []*unstructured.Unstructured{deployment}, nil
+				}
+				a.collectCRDsFn = func(a app.App, objects []*unstructured.Unstructured) ([]*crd.CRD, error) {
+					return nil, nil
+				}
+				a.componentFilesFn = func(a app.App) (map[string]string, error) {
+					return nil, nil
+				}
+				a.validateObjectFn = func(a app.App, obj *unstructured.Unstructured, envName string, crds []*crd.CRD) []error {
+					return nil
+				}
+
 				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
 				require.NoError(t, err)
 			})
 		})
 	}
 }
 
+func TestValidate_filtered(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		env := &app.EnvironmentConfig{}
+		appMock.On("Environment", "default").Return(env, nil)
+		appMock.On("Environments").Return(app.EnvironmentConfigs{"default": env}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionEnvName:        "default",
+			OptionModule:         "",
+			OptionComponentNames: make([]string, 0),
+			OptionClientConfig:   &client.Config{},
+			OptionKinds:          []string{"foo"},
+			OptionOffline:        true,
+		}
+
+		a, err := NewValidate(in)
+		require.NoError(t, err)
+
+		a.validateParamsFn = func(a app.App, envName string) []error {
+			return nil
+		}
+
+		foo := &unstructured.Unstructured{}
+		foo.SetKind("Foo")
+		foo.SetName("foo")
+
+		fooCRD := &unstructured.Unstructured{}
+		fooCRD.SetKind("CustomResourceDefinition")
+
+		a.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+			return []*unstructured.Unstructured{foo, fooCRD}, nil
+		}
+
+		a.collectCRDsFn = func(a app.App, objects []*unstructured.Unstructured) ([]*crd.CRD, error) {
+			assert.Equal(t, []*unstructured.Unstructured{foo, fooCRD}, objects)
+			return nil, nil
+		}
+
+		a.componentFilesFn = func(a app.App) (map[string]string, error) {
+			return map[string]string{}, nil
+		}
+
+		var validated []*unstructured.Unstructured
+		a.validateObjectFn = func(a app.App, obj *unstructured.Unstructured, envName string, got []*crd.CRD) []error {
+			validated = append(validated, obj)
+			return nil
+		}
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Equal(t, []*unstructured.Unstructured{foo}, validated)
+	})
+}
+
+func Test_collectCRDs(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		stageFile(t, appMock.Fs(), filepath.Join(crdTestData, "list.json"), "/vendor/incubator/istio/crds.json")
+		stageFile(t, appMock.Fs(), "lib/generate-crd/invalid.yaml", "/vendor/incubator/istio/parts.yaml")
+
+		obj := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "apiextensions.k8s.io/v1beta1",
+				"kind":       "CustomResourceDefinition",
+				"metadata": map[string]interface{}{
+					"name": "foos.example.com",
+				},
+				"spec": map[string]interface{}{
+					"group":   "example.com",
+					"version": "v1",
+					"names": map[string]interface{}{
+						"kind": "Foo",
+					},
+				},
+			},
+		}
+
+		service := &unstructured.Unstructured{}
+		service.SetAPIVersion("v1")
+		service.SetKind("Service")
+
+		crds, err := collectCRDs(appMock, []*unstructured.Unstructured{obj, service})
+		require.NoError(t, err)
+
+		var names []string
+		for _, c := range crds {
+			names = append(names, c.Name)
+		}
+
+		assert.Equal(t, []string{"foos.example.com", "gateways.networking.istio.io"}, names)
+	})
+}
+
 func TestValidate_requires_app(t *testing.T) {
 	in := make(map[string]interface{})
 	_, err := NewValidate(in)
@@ -76,6 +76,8 @@ type App interface {
 	LibPath(envName string) (string, error)
 	// Libraries returns all environments.
 	Libraries() (LibraryConfigs, error)
+	// Policies returns the policies configured for the application.
+	Policies() (PolicyConfigs, error)
 	// Registries returns all registries.
 	Registries() (RegistryConfigs, error)
 	// RemoveEnvironment removes an environment from the main configuration or an override.
@@ -416,10 +416,25 @@ func deepCopyEnvironmentConfig(src EnvironmentConfig) *EnvironmentConfig {
 	if src.Libraries != nil {
 		e.Libraries = deepCopyLibraries(src.Libraries)
 	}
+	e.CommonLabels = deepCopyStringMap(src.CommonLabels)
+	e.CommonAnnotations = deepCopyStringMap(src.CommonAnnotations)
 
 	return &e
 }
 
+func deepCopyStringMap(src map[string]string) map[string]string {
+	if src == nil {
+		return nil
+	}
+
+	m := make(map[string]string, len(src))
+	for k, v := range src {
+		m[k] = v
+	}
+
+	return m
+}
+
 // mergedEnvrionment returns a fresh copy of the named environment, merged with
 // optional overrides if present. Note overrides cannot override environment-scoped library
 // references.
@@ -460,6 +475,21 @@ func (ba *baseApp) mergedEnvironment(name string) *EnvironmentConfig {
 			copy(t, override.Targets)
 			combined.Targets = t
 		}
+		if override.CommonLabels != nil {
+			combined.CommonLabels = deepCopyStringMap(override.CommonLabels)
+		}
+		if override.CommonAnnotations != nil {
+			combined.CommonAnnotations = deepCopyStringMap(override.CommonAnnotations)
+		}
+		if override.NamePrefix != "" {
+			combined.NamePrefix = override.NamePrefix
+		}
+		if override.NameSuffix != "" {
+			combined.NameSuffix = override.NameSuffix
+		}
+		if override.NamespaceOverride != "" {
+			combined.NamespaceOverride = override.NamespaceOverride
+		}
 		return combined
 	case hasOverride:
 		e := deepCopyEnvironmentConfig(*override)
@@ -560,6 +590,17 @@ func (ba *baseApp) Libraries() (LibraryConfigs, error) {
 	return ba.config.Libraries, nil
 }
 
+// Policies returns the policies configured for the application.
+func (ba *baseApp) Policies() (PolicyConfigs, error) {
+	if !ba.loaded {
+		if err := ba.load(); err != nil {
+			return nil, errors.Wrap(err, "load configuration")
+		}
+	}
+
+	return ba.config.Policies, nil
+}
+
 // Registries returns application registries.
 func (ba *baseApp) Registries() (RegistryConfigs, error) {
 	if !ba.loaded {
@@ -402,6 +402,22 @@ func Test_baseApp_UpdateLib_Remove(t *testing.T) {
 	}
 }
 
+func Test_baseApp_Policies(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	stageFile(t, fs, "app030_policies.yaml", "/app.yaml")
+
+	ba := NewBaseApp(fs, "/", nil)
+
+	policies, err := ba.Policies()
+	require.NoError(t, err)
+
+	expected := PolicyConfigs{
+		"no-latest-image":         &PolicyConfig{Severity: "error"},
+		"require-resource-limits": &PolicyConfig{Severity: "warning"},
+	}
+	require.Equal(t, expected, policies)
+}
+
 func Test_baseApp_load_override(t *testing.T) {
 	fs := afero.NewMemMapFs()
 
@@ -478,6 +494,45 @@ func Test_baseApp_environment_override_is_merged(t *testing.T) {
 	assert.Equal(t, expected, e)
 }
 
+func Test_baseApp_environment_override_metadata(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ba := NewBaseApp(fs, "/", nil, optNoopLoader())
+	ba.config.Environments = EnvironmentConfigs{
+		"default": &EnvironmentConfig{
+			Name:              "default",
+			Path:              "default",
+			CommonLabels:      map[string]string{"team": "web"},
+			CommonAnnotations: map[string]string{"owner": "web@example.com"},
+			NamePrefix:        "dev-",
+			NamespaceOverride: "dev",
+		},
+	}
+	ba.overrides.Environments["default"] = &EnvironmentConfig{
+		Name:         "default",
+		Path:         "default",
+		CommonLabels: map[string]string{"team": "ops"},
+		NameSuffix:   "-local",
+	}
+
+	expected := &EnvironmentConfig{
+		Name:              "default",
+		Path:              "default",
+		CommonLabels:      map[string]string{"team": "ops"},
+		CommonAnnotations: map[string]string{"owner": "web@example.com"},
+		NamePrefix:        "dev-",
+		NameSuffix:        "-local",
+		NamespaceOverride: "dev",
+	}
+
+	e, err := ba.Environment("default")
+	require.NoError(t, err)
+	assert.Equal(t, expected, e)
+
+	// the merged environment is a copy.
+	e.CommonLabels["team"] = "changed"
+	assert.Equal(t, "ops", ba.overrides.Environments["default"].CommonLabels["team"])
+}
+
 func Test_baseApp_environment_just_override(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	ba := NewBaseApp(fs, "/", nil, optNoopLoader())
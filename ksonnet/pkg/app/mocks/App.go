@@ -239,6 +239,29 @@ func (_m *App) Libraries() (app.LibraryConfigs030, error) {
 	return r0, r1
 }
 
+// Policies provides a mock function with given fields:
+func (_m *App) Policies() (app.PolicyConfigs030, error) {
+	ret := _m.Called()
+
+	var r0 app.PolicyConfigs030
+	if rf, ok := ret.Get(0).(func() app.PolicyConfigs030); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(app.PolicyConfigs030)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Registries provides a mock function with given fields:
 func (_m *App) Registries() (app.RegistryConfigs030, error) {
 	ret := _m.Called()
@@ -95,6 +95,12 @@ type LibraryConfig = LibraryConfig030
 // LibraryConfigs is a mapping of a library configurations by name.
 type LibraryConfigs = LibraryConfigs030
 
+// PolicyConfig enables a policy and sets its severity.
+type PolicyConfig = PolicyConfig030
+
+// PolicyConfigs is a map of policy name to its configuration.
+type PolicyConfigs = PolicyConfigs030
+
 // ContributorSpec is a specification for the project contributors.
 type ContributorSpec = ContributorSpec030
 
@@ -43,6 +43,7 @@ type Spec030 struct {
 	Environments EnvironmentConfigs030 `json:"environments,omitempty"`
 	Libraries    LibraryConfigs030     `json:"libraries,omitempty"`
 	License      string                `json:"license,omitempty"`
+	Policies     PolicyConfigs030      `json:"policies,omitempty"`
 }
 
 // RepositorySpec030 defines the spec for the upstream repository of this project.
@@ -132,6 +133,21 @@ type EnvironmentConfig030 struct {
 	Targets []string `json:"targets,omitempty"`
 	// Libraries specifies versioned libraries specifically used by this environment.
 	Libraries LibraryConfigs030 `json:"libraries,omitempty"`
+	// CommonLabels are added to every object rendered for this environment,
+	// and to the pod templates and selectors of workloads and services.
+	CommonLabels map[string]string `json:"commonLabels,omitempty" yaml:"commonLabels,omitempty"`
+	// CommonAnnotations are added to every object rendered for this
+	// environment, and to the pod templates of workloads.
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty" yaml:"commonAnnotations,omitempty"`
+	// NamePrefix is prepended to the names of objects rendered for this
+	// environment. References to renamed objects are updated.
+	NamePrefix string `json:"namePrefix,omitempty" yaml:"namePrefix,omitempty"`
+	// NameSuffix is appended to the names of objects rendered for this
+	// environment. References to renamed objects are updated.
+	NameSuffix string `json:"nameSuffix,omitempty" yaml:"nameSuffix,omitempty"`
+	// NamespaceOverride replaces the namespace of every namespaced object
+	// rendered for this environment, including namespaces set by components.
+	NamespaceOverride string `json:"namespaceOverride,omitempty" yaml:"namespaceOverride,omitempty"`
 }
 
 // MakePath return the absolute path to the environment directory.
@@ -234,6 +250,14 @@ func (l LibraryConfig030) String() string {
 	}
 }
 
+// PolicyConfig030 enables a policy and sets its severity.
+type PolicyConfig030 struct {
+	Severity string `json:"severity,omitempty"`
+}
+
+// PolicyConfigs030 is a map of policy name to its configuration.
+type PolicyConfigs030 map[string]*PolicyConfig030
+
 // ContributorSpec030 is a specification for the project contributors.
 type ContributorSpec030 struct {
 	Name  string `json:"name"`
@@ -37,12 +37,19 @@ const (
 	actionEnvSet
 	actionEnvTargets
 	actionEnvUpdate
+	actionExport
+	actionFmt
 	actionImport
 	actionInit
+	actionLibGenerateCRD
+	actionLint
+	actionLsp
 	actionModuleCreate
 	actionModuleList
 	actionParamDelete
 	actionParamDiff
+	actionParamExport
+	actionParamImport
 	actionParamList
 	actionParamSet
 	actionParamUnset
@@ -50,16 +57,21 @@ const (
 	actionPkgInstall
 	actionPkgList
 	actionPkgRemove
+	actionPolicyCheck
+	actionPolicyList
 	actionPrototypeDescribe
 	actionPrototypeList
+	actionPrototypeNew
 	actionPrototypePreview
 	actionPrototypeSearch
+	actionPrototypeTest
 	actionPrototypeUse
 	actionRegistryAdd
 	actionRegistryDescribe
 	actionRegistryList
 	actionRegistrySet
 	actionShow
+	actionTest
 	actionUpgrade
 	actionValidate
 )
@@ -81,12 +93,19 @@ var (
 		actionEnvSet:            actions.RunEnvSet,
 		actionEnvTargets:        actions.RunEnvTargets,
 		actionEnvUpdate:         actions.RunEnvUpdate,
+		actionExport:            actions.RunExport,
+		actionFmt:               actions.RunFmt,
 		actionImport:            actions.RunImport,
 		actionInit:              actions.RunInit,
+		actionLibGenerateCRD:    actions.RunLibGenerateCRD,
+		actionLint:              actions.RunLint,
+		actionLsp:               actions.RunLsp,
 		actionModuleCreate:      actions.RunModuleCreate,
 		actionModuleList:        actions.RunModuleList,
 		actionParamDiff:         actions.RunParamDiff,
 		actionParamDelete:       actions.RunParamDelete,
+		actionParamExport:       actions.RunParamExport,
+		actionParamImport:       actions.RunParamImport,
 		actionParamUnset:        actions.RunParamDelete,
 		actionParamList:         actions.RunParamList,
 		actionParamSet:          actions.RunParamSet,
@@ -94,16 +113,21 @@ var (
 		actionPkgInstall:        actions.RunPkgInstall,
 		actionPkgList:           actions.RunPkgList,
 		actionPkgRemove:         actions.RunPkgRemove,
+		actionPolicyCheck:       actions.RunPolicyCheck,
+		actionPolicyList:        actions.RunPolicyList,
 		actionPrototypeDescribe: actions.RunPrototypeDescribe,
 		actionPrototypeList:     actions.RunPrototypeList,
+		actionPrototypeNew:      actions.RunPrototypeNew,
 		actionPrototypePreview:  actions.RunPrototypePreview,
 		actionPrototypeSearch:   actions.RunPrototypeSearch,
+		actionPrototypeTest:     actions.RunPrototypeTest,
 		actionPrototypeUse:      actions.RunPrototypeUse,
 		actionRegistryAdd:       actions.RunRegistryAdd,
 		actionRegistryDescribe:  actions.RunRegistryDescribe,
 		actionRegistryList:      actions.RunRegistryList,
 		actionRegistrySet:       actions.RunRegistrySet,
 		actionShow:              actions.RunShow,
+		actionTest:              actions.RunTest,
 		actionUpgrade:           actions.RunUpgrade,
 		actionValidate:          actions.RunValidate,
 	}
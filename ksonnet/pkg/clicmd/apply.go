@@ -25,11 +25,18 @@ import (
 )
 
 const (
+	vApplyCheckAPIs = "apply-check-apis"
 	vApplyComponent = "apply-components"
+	vApplyConvert   = "apply-convert-deprecated"
 	vApplyCreate    = "apply-create"
+	vApplyCreateNs  = "apply-create-namespace"
 	vApplyGcTag     = "apply-gc-tag"
 	vApplyDryRun    = "apply-dry-run"
+	vApplyPlatform  = "apply-image-platform"
+	vApplyRecord    = "apply-record-params"
 	vApplySkipGc    = "apply-skip-gc"
+	vApplySkipPol   = "apply-skip-policies"
+	vApplyResolve   = "apply-resolve-images"
 
 	applyShortDesc = "Apply local Kubernetes manifests (components) to remote clusters"
 	applyLong      = `
@@ -44,12 +51,39 @@ expanded using the parameters of the specified environment.
 By default, all component manifests are applied. To apply a subset of components,
 use the ` + "`--component` " + `flag, as seen in the examples below.
 
+Objects can also be selected with ` + "`--selector`" + `, ` + "`--kind`" + `, ` + "`--name`" + ` and
+` + "`--exclude-component`" + `. An object is applied only if it matches every filter
+given. Garbage collection with ` + "`--gc-tag`" + ` is limited to the same selection, so
+objects outside of it are left alone.
+
+Objects are checked against the app's policies before they are applied.
+Violations with ` + "`error`" + ` severity block the apply unless ` + "`--skip-policies`" + ` is set.
+
+Namespaces and CustomResourceDefinitions are applied before other objects. Custom
+resources are applied once their CustomResourceDefinitions are established. With
+` + "`--create-namespace`" + `, the environment's destination namespace and the namespaces
+of objects are created first if they don't exist.
+
+With ` + "`--check-apis`" + `, objects are checked against the APIs the cluster serves and a
+table of deprecated Kubernetes APIs before they are applied. Objects using APIs the
+cluster doesn't serve block the apply, and deprecated APIs are reported with their
+replacements. ` + "`--convert-deprecated`" + ` converts objects to the replacement APIs
+when only the apiVersion changes, or when the missing fields can be filled in from
+the object, e.g. the selector of an ` + "`extensions/v1beta1`" + ` Deployment.
+
+With ` + "`--record-params`" + `, the resolved params of each component are recorded in the
+` + "`ksonnet.io/params`" + ` annotation of one of its objects, so ` + "`ks param diff <env>@cluster`" + `
+can compare them. Anyone who can read the object can read the annotation, so the
+values of params with names like ` + "`password`" + `, ` + "`token`" + ` or ` + "`secret`" + `, and of every param
+of a component which renders a Secret, are recorded as ` + "`<redacted>`" + `.
+
 Note that this command needs to be run *within* a ksonnet app directory.
 
 ### Related Commands
 
 * ` + "`ks diff` " + `— ` + diffShortDesc + `
 * ` + "`ks delete` " + `— ` + deleteShortDesc + `
+* ` + "`ks policy check` " + `— Check an environment's objects against the app's policies
 
 ### Syntax
 `
@@ -79,6 +113,23 @@ ks apply dev -c guestbook-ui
 # This essentially deploys 'components/guestbook-ui.jsonnet' and
 # 'components/nginx-depl.jsonnet'.
 ks apply dev -c guestbook-ui -c nginx-depl --create false
+
+# Deploy the 'dev' environment, converting objects which use deprecated APIs and
+# stopping if any object uses an API the cluster doesn't serve
+ks apply dev --convert-deprecated --check-apis
+
+# Deploy the 'prod' environment and record its params, so they can be compared
+# with 'ks param diff prod@cluster prod'
+ks apply prod --record-params
+
+# Deploy the 'dev' environment, creating its namespace if it doesn't exist
+ks apply dev --create-namespace
+
+# Apply only the objects in the 'dev' environment labeled 'tier=frontend'
+ks apply dev -l tier=frontend
+
+# Apply only the 'redis-config' ConfigMap in the 'dev' environment
+ks apply dev --kind configmap --name redis-config
 `
 )
 
@@ -95,15 +146,23 @@ func newApplyCmd(fs afero.Fs) *cobra.Command {
 			}
 
 			m := map[string]interface{}{
-				actions.OptionClientConfig:   applyClientConfig,
-				actions.OptionComponentNames: viper.GetStringSlice(vApplyComponent),
-				actions.OptionCreate:         viper.GetBool(vApplyCreate),
-				actions.OptionDryRun:         viper.GetBool(vApplyDryRun),
-				actions.OptionEnvName:        envName,
-				actions.OptionGcTag:          viper.GetString(vApplyGcTag),
-				actions.OptionSkipGc:         viper.GetBool(vApplySkipGc),
+				actions.OptionCheckAPIs:         viper.GetBool(vApplyCheckAPIs),
+				actions.OptionClientConfig:      applyClientConfig,
+				actions.OptionConvertDeprecated: viper.GetBool(vApplyConvert),
+				actions.OptionComponentNames:    viper.GetStringSlice(vApplyComponent),
+				actions.OptionCreate:            viper.GetBool(vApplyCreate),
+				actions.OptionCreateNamespace:   viper.GetBool(vApplyCreateNs),
+				actions.OptionDryRun:            viper.GetBool(vApplyDryRun),
+				actions.OptionEnvName:           envName,
+				actions.OptionGcTag:             viper.GetString(vApplyGcTag),
+				actions.OptionImagePlatform:     viper.GetString(vApplyPlatform),
+				actions.OptionRecordParams:      viper.GetBool(vApplyRecord),
+				actions.OptionResolveImages:     viper.GetBool(vApplyResolve),
+				actions.OptionSkipGc:            viper.GetBool(vApplySkipGc),
+				actions.OptionSkipPolicies:      viper.GetBool(vApplySkipPol),
 			}
 			addGlobalOptions(m)
+			addObjectFilterOptions(m, "apply")
 
 			if err := extractJsonnetFlags(fs, "apply"); err != nil {
 				return errors.Wrap(err, "handle jsonnet flags")
@@ -117,6 +176,7 @@ func newApplyCmd(fs afero.Fs) *cobra.Command {
 
 	applyClientConfig.BindClientGoFlags(applyCmd)
 	bindJsonnetFlags(applyCmd, "apply")
+	bindObjectFilterFlags(applyCmd, "apply")
 
 	applyCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component (multiple -c flags accepted, allows YAML, JSON, and Jsonnet)")
 	viper.BindPFlag(vApplyComponent, applyCmd.Flags().Lookup(flagComponent))
@@ -124,9 +184,30 @@ func newApplyCmd(fs afero.Fs) *cobra.Command {
 	applyCmd.Flags().Bool(flagCreate, true, "Option to create resources if they do not already exist on the cluster")
 	viper.BindPFlag(vApplyCreate, applyCmd.Flags().Lookup(flagCreate))
 
+	applyCmd.Flags().Bool(flagCreateNamespace, false, "Option to create the destination namespace and the namespaces of objects if they do not exist")
+	viper.BindPFlag(vApplyCreateNs, applyCmd.Flags().Lookup(flagCreateNamespace))
+
 	applyCmd.Flags().Bool(flagSkipGc, false, "Option to skip garbage collection, even with --"+flagGcTag+" specified")
 	viper.BindPFlag(vApplySkipGc, applyCmd.Flags().Lookup(flagSkipGc))
 
+	applyCmd.Flags().Bool(flagRecordParams, false, "Option to record the params of each component on its objects for 'param diff'")
+	viper.BindPFlag(vApplyRecord, applyCmd.Flags().Lookup(flagRecordParams))
+
+	applyCmd.Flags().Bool(flagResolveImages, false, "Pin container images to digests")
+	viper.BindPFlag(vApplyResolve, applyCmd.Flags().Lookup(flagResolveImages))
+
+	applyCmd.Flags().String(flagImagePlatform, "", "Pin multi-platform images to the digest of the image for an os/arch[/variant] platform")
+	viper.BindPFlag(vApplyPlatform, applyCmd.Flags().Lookup(flagImagePlatform))
+
+	applyCmd.Flags().Bool(flagCheckAPIs, false, "Option to check objects for APIs which are deprecated or not served by the cluster before applying them")
+	viper.BindPFlag(vApplyCheckAPIs, applyCmd.Flags().Lookup(flagCheckAPIs))
+
+	applyCmd.Flags().Bool(flagConvertDeprecated, false, "Option to convert objects which use deprecated APIs to their replacements, where the conversion is simple")
+	viper.BindPFlag(vApplyConvert, applyCmd.Flags().Lookup(flagConvertDeprecated))
+
+	applyCmd.Flags().Bool(flagSkipPolicies, false, "Option to apply objects which violate policies")
+	viper.BindPFlag(vApplySkipPol, applyCmd.Flags().Lookup(flagSkipPolicies))
+
 	applyCmd.Flags().String(flagGcTag, "", "A tag that's (1) added to all updated objects (2) used to garbage collect existing objects that are no longer in the manifest")
 	viper.BindPFlag(vApplyGcTag, applyCmd.Flags().Lookup(flagGcTag))
 
@@ -30,14 +30,51 @@ func Test_applyCmd(t *testing.T) {
 			args:   []string{"apply", "default"},
 			action: actionApply,
 			expected: map[string]interface{}{
-				actions.OptionApp:            mock.AnythingOfType("*app.App"),
-				actions.OptionEnvName:        "default",
-				actions.OptionGcTag:          "",
-				actions.OptionSkipGc:         false,
-				actions.OptionComponentNames: make([]string, 0),
-				actions.OptionCreate:         true,
-				actions.OptionDryRun:         false,
-				actions.OptionClientConfig:   mock.AnythingOfType("*client.Config"),
+				actions.OptionApp:               mock.AnythingOfType("*app.App"),
+				actions.OptionEnvName:           "default",
+				actions.OptionGcTag:             "",
+				actions.OptionSkipGc:            false,
+				actions.OptionSkipPolicies:      false,
+				actions.OptionRecordParams:      false,
+				actions.OptionImagePlatform:     "",
+				actions.OptionResolveImages:     false,
+				actions.OptionComponentNames:    make([]string, 0),
+				actions.OptionExcludeComponents: make([]string, 0),
+				actions.OptionKinds:             make([]string, 0),
+				actions.OptionNames:             make([]string, 0),
+				actions.OptionSelector:          "",
+				actions.OptionCreate:            true,
+				actions.OptionCreateNamespace:   false,
+				actions.OptionDryRun:            false,
+				actions.OptionCheckAPIs:         false,
+				actions.OptionClientConfig:      mock.AnythingOfType("*client.Config"),
+				actions.OptionConvertDeprecated: false,
+			},
+		},
+		{
+			name:   "with object filters",
+			args:   []string{"apply", "default", "-l", "tier=frontend", "--kind", "service", "--name", "web-*", "--exclude-component", "db"},
+			action: actionApply,
+			expected: map[string]interface{}{
+				actions.OptionApp:               mock.AnythingOfType("*app.App"),
+				actions.OptionEnvName:           "default",
+				actions.OptionGcTag:             "",
+				actions.OptionSkipGc:            false,
+				actions.OptionSkipPolicies:      false,
+				actions.OptionRecordParams:      false,
+				actions.OptionImagePlatform:     "",
+				actions.OptionResolveImages:     false,
+				actions.OptionComponentNames:    make([]string, 0),
+				actions.OptionExcludeComponents: []string{"db"},
+				actions.OptionKinds:             []string{"service"},
+				actions.OptionNames:             []string{"web-*"},
+				actions.OptionSelector:          "tier=frontend",
+				actions.OptionCreate:            true,
+				actions.OptionCreateNamespace:   false,
+				actions.OptionDryRun:            false,
+				actions.OptionCheckAPIs:         false,
+				actions.OptionClientConfig:      mock.AnythingOfType("*client.Config"),
+				actions.OptionConvertDeprecated: false,
 			},
 		},
 		{
@@ -54,7 +54,10 @@ ks delete dev
 # Delete resources described by the 'nginx' component. $KUBECONFIG is overridden by
 # the CLI-specified './kubeconfig', so these changes are deployed to the current
 # context's cluster (not the 'default' environment)
-ks delete --kubeconfig=./kubeconfig -c nginx`
+ks delete --kubeconfig=./kubeconfig -c nginx
+
+# Delete only the Jobs from the 'dev' environment
+ks delete dev --kind job`
 )
 
 func newDeleteCmd(fs afero.Fs) *cobra.Command {
@@ -78,6 +81,7 @@ func newDeleteCmd(fs afero.Fs) *cobra.Command {
 				actions.OptionGracePeriod:    viper.GetInt64(vDeleteGracePeriod),
 			}
 			addGlobalOptions(m)
+			addObjectFilterOptions(m, "delete")
 
 			if err := extractJsonnetFlags(fs, "delete"); err != nil {
 				return errors.Wrap(err, "handle jsonnet flags")
@@ -89,6 +93,7 @@ func newDeleteCmd(fs afero.Fs) *cobra.Command {
 
 	deleteClientConfig.BindClientGoFlags(deleteCmd)
 	bindJsonnetFlags(deleteCmd, "delete")
+	bindObjectFilterFlags(deleteCmd, "delete")
 
 	deleteCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component (multiple -c flags accepted, allows YAML, JSON, and Jsonnet)")
 	viper.BindPFlag(vDeleteComponent, deleteCmd.Flags().Lookup(flagComponent))
@@ -28,11 +28,15 @@ func Test_deleteCmd(t *testing.T) {
 			args:   []string{"delete", "default"},
 			action: actionDelete,
 			expected: map[string]interface{}{
-				actions.OptionApp:            nil,
-				actions.OptionEnvName:        "default",
-				actions.OptionComponentNames: make([]string, 0),
-				actions.OptionClientConfig:   nil,
-				actions.OptionGracePeriod:    int64(-1),
+				actions.OptionApp:               nil,
+				actions.OptionEnvName:           "default",
+				actions.OptionComponentNames:    make([]string, 0),
+				actions.OptionExcludeComponents: make([]string, 0),
+				actions.OptionKinds:             make([]string, 0),
+				actions.OptionNames:             make([]string, 0),
+				actions.OptionSelector:          "",
+				actions.OptionClientConfig:      nil,
+				actions.OptionGracePeriod:       int64(-1),
 			},
 		},
 		{
@@ -29,6 +29,8 @@ import (
 
 const (
 	vDiffComponentNames = "diff-component-names"
+	vDiffImagePlatform  = "diff-image-platform"
+	vDiffResolveImages  = "diff-resolve-images"
 
 	diffShortDesc = "Compare manifests, based on environment or location (local or remote)"
 )
@@ -82,6 +84,9 @@ ks diff local:us-west/dev remote:us-west/prod
 # Show diff between what's in the local manifest and what's actually running in the
 # 'dev' environment, but for the Redis component ONLY
 ks diff dev -c redis
+
+# Show diff for the objects in the 'dev' environment labeled 'tier=frontend'
+ks diff dev -l tier=frontend
 `
 )
 
@@ -105,8 +110,11 @@ func newDiffCmd(fs afero.Fs) *cobra.Command {
 				actions.OptionClientConfig:   diffClientConfig,
 				actions.OptionSrc1:           args[0],
 				actions.OptionComponentNames: viper.GetStringSlice(vDiffComponentNames),
+				actions.OptionImagePlatform:  viper.GetString(vDiffImagePlatform),
+				actions.OptionResolveImages:  viper.GetBool(vDiffResolveImages),
 			}
 			addGlobalOptions(m)
+			addObjectFilterOptions(m, "diff")
 
 			if len(args) == 2 {
 				m[actions.OptionSrc2] = args[1]
@@ -122,9 +130,16 @@ func newDiffCmd(fs afero.Fs) *cobra.Command {
 
 	diffClientConfig.BindClientGoFlags(diffCmd)
 	bindJsonnetFlags(diffCmd, "diff")
+	bindObjectFilterFlags(diffCmd, "diff")
 
 	diffCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component")
 	viper.BindPFlag(vDiffComponentNames, diffCmd.Flags().Lookup(flagComponent))
 
+	diffCmd.Flags().Bool(flagResolveImages, false, "Pin the container images of local manifests to digests")
+	viper.BindPFlag(vDiffResolveImages, diffCmd.Flags().Lookup(flagResolveImages))
+
+	diffCmd.Flags().String(flagImagePlatform, "", "Pin multi-platform images to the digest of the image for an os/arch[/variant] platform")
+	viper.BindPFlag(vDiffImagePlatform, diffCmd.Flags().Lookup(flagImagePlatform))
+
 	return diffCmd
 }
@@ -28,11 +28,17 @@ func Test_diffCmd(t *testing.T) {
 			args:   []string{"diff", "env1", "env2"},
 			action: actionDiff,
 			expected: map[string]interface{}{
-				actions.OptionApp:            nil,
-				actions.OptionClientConfig:   nil,
-				actions.OptionSrc1:           "env1",
-				actions.OptionSrc2:           "env2",
-				actions.OptionComponentNames: []string{},
+				actions.OptionApp:               nil,
+				actions.OptionClientConfig:      nil,
+				actions.OptionSrc1:              "env1",
+				actions.OptionSrc2:              "env2",
+				actions.OptionComponentNames:    []string{},
+				actions.OptionExcludeComponents: []string{},
+				actions.OptionKinds:             []string{},
+				actions.OptionNames:             []string{},
+				actions.OptionSelector:          "",
+				actions.OptionImagePlatform:     "",
+				actions.OptionResolveImages:     false,
 			},
 		},
 		{
@@ -0,0 +1,158 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	exportShortDesc     = "Export the manifests of an environment to a directory."
+	vExportChartName    = "export-chart-name"
+	vExportChartVersion = "export-chart-version"
+	vExportComponent    = "export-components"
+	vExportFormat       = "export-format"
+	vExportLayout       = "export-layout"
+	vExportOut          = "export-out"
+	vExportPlatform     = "export-image-platform"
+	vExportResolve      = "export-resolve-images"
+	vExportValues       = "export-values"
+)
+
+var (
+	exportLong = `
+Export the expanded manifests of an environment to a directory, with one file
+per object. The directory can be committed and deployed by GitOps tools such as
+Argo CD or Flux.
+
+The path of each object is given by the ` + "`--layout`" + ` template. The template can use
+` + "`.Namespace`" + `, ` + "`.Kind`" + `, ` + "`.Name`" + `, ` + "`.Group`" + `, ` + "`.Version`" + `, ` + "`.Component`" + ` and ` + "`.Ext`" + `,
+and the ` + "`lower`" + ` function. The default layout is
+` + "`" + cluster.DefaultExportLayout + "`" + `.
+Objects without a namespace are exported to the top of the directory.
+
+With ` + "`--format yaml`" + ` or ` + "`--format json`" + `, a ` + "`kustomization.yaml`" + ` listing the
+objects is written too. With ` + "`--format list`" + `, the objects are exported as a single
+v1 List in ` + "`list.json`" + ` instead.
+
+With ` + "`--format helm`" + `, the objects are exported as a Helm chart, with templates in
+` + "`templates/`" + ` laid out by ` + "`--layout`" + `. If ` + "`--out`" + ` ends in ` + "`.tgz`" + `, the chart is
+archived to that file instead. Component params selected with ` + "`--values`" + `, as
+` + "`<component>.<param>`" + `, are lifted into ` + "`values.yaml`" + `, and the fields they set
+are templated. ksonnet finds the fields by rendering the environment again with
+placeholder params, so only fields which take a param's value, or strings
+which embed it, can be templated. With its default values, the chart renders
+the same objects as the environment.
+
+Exports are deterministic, so exporting an unchanged environment leaves the
+directory unchanged. The files written are recorded in ` + "`.ks-export`" + `, and files
+from a previous export which are no longer exported are removed. Other files
+in the directory are left alone.
+
+### Related Commands
+
+* ` + "`ks show` " + `— ` + showShortDesc + `
+
+### Syntax
+`
+	exportExample = `
+# Export the 'prod' environment to the deploy/prod directory
+ks export prod --out deploy/prod
+
+# Export a single v1 List
+ks export prod --out deploy/prod --format list
+
+# Export a Helm chart whose replicas and image tag can be set
+ks export prod --out guestbook-0.1.0.tgz --format helm --values guestbook.replicas --values guestbook.tag
+
+# Group objects by component
+ks export prod --out deploy/prod --layout '{{.Component}}/{{lower .Kind}}-{{.Name}}.{{.Ext}}'
+`
+)
+
+func newExportCmd(fs afero.Fs) *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:     "export <env> --out <dir> [-c <component-name>]",
+		Short:   exportShortDesc,
+		Long:    exportLong,
+		Example: exportExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var envName string
+			if len(args) == 1 {
+				envName = args[0]
+			}
+
+			out := viper.GetString(vExportOut)
+			if out == "" {
+				return errors.New("--out is required")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionComponentNames: viper.GetStringSlice(vExportComponent),
+				actions.OptionEnvName:        envName,
+				actions.OptionFormat:         viper.GetString(vExportFormat),
+				actions.OptionLayout:         viper.GetString(vExportLayout),
+				actions.OptionOutputDir:      out,
+				actions.OptionImagePlatform:  viper.GetString(vExportPlatform),
+				actions.OptionResolveImages:  viper.GetBool(vExportResolve),
+				actions.OptionChartName:      viper.GetString(vExportChartName),
+				actions.OptionChartVersion:   viper.GetString(vExportChartVersion),
+				actions.OptionValues:         viper.GetStringSlice(vExportValues),
+			}
+
+			if err := extractJsonnetFlags(fs, "export"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionExport, m)
+		},
+	}
+	bindJsonnetFlags(exportCmd, "export")
+
+	exportCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component (multiple -c flags accepted, allows YAML, JSON, and Jsonnet)")
+	viper.BindPFlag(vExportComponent, exportCmd.Flags().Lookup(flagComponent))
+
+	exportCmd.Flags().String(flagFormat, "yaml", "Output format.  Supported values are: yaml, json, list, helm")
+	viper.BindPFlag(vExportFormat, exportCmd.Flags().Lookup(flagFormat))
+
+	exportCmd.Flags().String(flagLayout, cluster.DefaultExportLayout, "Template for the paths of exported objects")
+	viper.BindPFlag(vExportLayout, exportCmd.Flags().Lookup(flagLayout))
+
+	exportCmd.Flags().String(flagOut, "", "Directory to export to")
+	viper.BindPFlag(vExportOut, exportCmd.Flags().Lookup(flagOut))
+
+	exportCmd.Flags().Bool(flagResolveImages, false, "Pin container images to digests")
+	viper.BindPFlag(vExportResolve, exportCmd.Flags().Lookup(flagResolveImages))
+
+	exportCmd.Flags().String(flagImagePlatform, "", "Pin multi-platform images to the digest of the image for an os/arch[/variant] platform")
+	viper.BindPFlag(vExportPlatform, exportCmd.Flags().Lookup(flagImagePlatform))
+
+	exportCmd.Flags().String(flagChartName, "", "Name of the Helm chart. Defaults to the name of the app's directory")
+	viper.BindPFlag(vExportChartName, exportCmd.Flags().Lookup(flagChartName))
+
+	exportCmd.Flags().String(flagChartVersion, "0.1.0", "Version of the Helm chart")
+	viper.BindPFlag(vExportChartVersion, exportCmd.Flags().Lookup(flagChartVersion))
+
+	exportCmd.Flags().StringSlice(flagValues, nil, "Component params to lift into the Helm chart's values, as <component>.<param> (multiple --values flags accepted)")
+	viper.BindPFlag(vExportValues, exportCmd.Flags().Lookup(flagValues))
+
+	return exportCmd
+}
@@ -0,0 +1,89 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+)
+
+func Test_exportCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "with no options",
+			args:   []string{"export", "default", "--out", "deploy"},
+			action: actionExport,
+			expected: map[string]interface{}{
+				actions.OptionApp:            nil,
+				actions.OptionEnvName:        "default",
+				actions.OptionComponentNames: make([]string, 0),
+				actions.OptionFormat:         "yaml",
+				actions.OptionLayout:         cluster.DefaultExportLayout,
+				actions.OptionOutputDir:      "deploy",
+				actions.OptionImagePlatform:  "",
+				actions.OptionResolveImages:  false,
+				actions.OptionChartName:      "",
+				actions.OptionChartVersion:   "0.1.0",
+				actions.OptionValues:         make([]string, 0),
+			},
+		},
+		{
+			name:   "list with a layout",
+			args:   []string{"export", "default", "--out", "deploy", "--format", "list", "--layout", "{{.Name}}.{{.Ext}}"},
+			action: actionExport,
+			expected: map[string]interface{}{
+				actions.OptionApp:            nil,
+				actions.OptionEnvName:        "default",
+				actions.OptionComponentNames: make([]string, 0),
+				actions.OptionFormat:         "list",
+				actions.OptionLayout:         "{{.Name}}.{{.Ext}}",
+				actions.OptionOutputDir:      "deploy",
+				actions.OptionImagePlatform:  "",
+				actions.OptionResolveImages:  false,
+				actions.OptionChartName:      "",
+				actions.OptionChartVersion:   "0.1.0",
+				actions.OptionValues:         make([]string, 0),
+			},
+		},
+		{
+			name:   "helm chart",
+			args:   []string{"export", "default", "--out", "chart.tgz", "--format", "helm", "--chart-name", "guestbook", "--values", "guestbook.replicas", "--values", "guestbook.tag"},
+			action: actionExport,
+			expected: map[string]interface{}{
+				actions.OptionApp:            nil,
+				actions.OptionEnvName:        "default",
+				actions.OptionComponentNames: make([]string, 0),
+				actions.OptionFormat:         "helm",
+				actions.OptionLayout:         cluster.DefaultExportLayout,
+				actions.OptionOutputDir:      "chart.tgz",
+				actions.OptionImagePlatform:  "",
+				actions.OptionResolveImages:  false,
+				actions.OptionChartName:      "guestbook",
+				actions.OptionChartVersion:   "0.1.0",
+				actions.OptionValues:         []string{"guestbook.replicas", "guestbook.tag"},
+			},
+		},
+		{
+			name:  "without an output directory",
+			args:  []string{"export", "default"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
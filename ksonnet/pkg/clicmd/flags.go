@@ -25,11 +25,19 @@ const (
 	// environment or the -f flag.
 	flagAPISpec               = "api-spec"
 	flagAsString              = "as-string"
+	flagChartName             = "chart-name"
+	flagChartVersion          = "chart-version"
+	flagCheck                 = "check"
 	flagComponent             = "component"
+	flagCheckAPIs             = "check-apis"
+	flagConvertDeprecated     = "convert-deprecated"
 	flagCreate                = "create"
+	flagCreateNamespace       = "create-namespace"
 	flagDir                   = "dir"
 	flagDryRun                = "dry-run"
 	flagEnv                   = "env"
+	flagExcludeComponent      = "exclude-component"
+	flagExplain               = "explain"
 	flagExtVar                = "ext-str"
 	flagExtVarFile            = "ext-str-file"
 	flagFilename              = "filename"
@@ -37,21 +45,36 @@ const (
 	flagFormat                = "format"
 	flagGcTag                 = "gc-tag"
 	flagGracePeriod           = "grace-period"
+	flagImagePlatform         = "image-platform"
 	flagInstalled             = "installed"
 	flagJpath                 = "jpath"
+	flagKind                  = "kind"
+	flagLayout                = "layout"
 	flagModule                = "module"
+	flagObjectName            = "name"
 	flagNamespace             = "namespace"
+	flagNoCache               = "no-cache"
+	flagRecordParams          = "record-params"
 	flagResolveImage          = "resolve-image"
+	flagResolveImages         = "resolve-images"
 	flagServer                = "server"
+	flagSelector              = "selector"
 	flagSet                   = "set"
 	flagSkipDefaultRegistries = "skip-default-registries"
 	flagSkipGc                = "skip-gc"
+	flagSkipPolicies          = "skip-policies"
+	flagStats                 = "stats"
+	flagStatsFormat           = "stats-format"
 	flagTlaVar                = "tla-str"
 	flagTlaVarFile            = "tla-str-file"
 	flagTLSSkipVerify         = "tls-skip-verify"
+	flagOffline               = "offline"
+	flagOut                   = "out"
 	flagOutput                = "output"
 	flagOverride              = "override"
+	flagPackage               = "package"
 	flagUnset                 = "unset"
+	flagValues                = "values"
 	flagVerbose               = "verbose"
 	flagVersion               = "version"
 	flagWithoutModules        = "without-modules"
@@ -61,6 +84,7 @@ const (
 	shortFormat    = "o"
 	shortOutput    = "o"
 	shortOverride  = "o"
+	shortSelector  = "l"
 )
 
 // addCmdOutput adds an output flag to a command. `name` is the name
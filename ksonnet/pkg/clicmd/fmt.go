@@ -0,0 +1,77 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vFmtCheck    = "fmt-check"
+	fmtShortDesc = "Format the jsonnet in an app"
+)
+
+var (
+	fmtLong = `
+The ` + "`fmt`" + ` command rewrites the jsonnet components, params and environments
+of an app in the canonical jsonnet style. The paths of the files which were
+changed are printed.
+
+Files are left unchanged, with a warning, if formatting would remove a comment
+from them or change more than their layout (e.g. text blocks).
+
+When the ` + "`--check`" + ` flag is set, files are not changed. Instead, the
+command prints the paths of the files which are not formatted and fails if there
+are any, which makes it suitable for CI.
+
+### Related Commands
+
+* ` + "`ks lint` " + `— ` + lintShortDesc + `
+
+### Syntax
+`
+	fmtExample = `
+# Format all of the jsonnet in the app
+ks fmt
+
+# Fail if any of the jsonnet in the app is not formatted
+ks fmt --check
+`
+)
+
+func newFmtCmd() *cobra.Command {
+	fmtCmd := &cobra.Command{
+		Use:     "fmt [--check]",
+		Short:   fmtShortDesc,
+		Long:    fmtLong,
+		Example: fmtExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := map[string]interface{}{
+				actions.OptionCheck: viper.GetBool(vFmtCheck),
+			}
+			addGlobalOptions(m)
+
+			return runAction(actionFmt, m)
+		},
+	}
+
+	fmtCmd.Flags().Bool(flagCheck, false, "Report unformatted files instead of formatting them")
+	viper.BindPFlag(vFmtCheck, fmtCmd.Flags().Lookup(flagCheck))
+
+	return fmtCmd
+}
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -40,6 +41,9 @@ func bindJsonnetFlags(cmd *cobra.Command, name string) {
 
 	cmd.Flags().StringSlice(flagTlaVarFile, nil, "Read top level argument from a file")
 	viper.BindPFlag(name+"-tla-var-file", cmd.Flags().Lookup(flagTlaVarFile))
+
+	cmd.Flags().Bool(flagNoCache, false, "Render every component instead of using cached objects")
+	viper.BindPFlag(name+"-no-cache", cmd.Flags().Lookup(flagNoCache))
 }
 
 func extractJsonnetFlags(fs afero.Fs, name string) error {
@@ -90,6 +94,10 @@ func extractJsonnetFlags(fs afero.Fs, name string) error {
 		}
 	}
 
+	if viper.GetBool(name + "-no-cache") {
+		pipeline.DisableCache()
+	}
+
 	return nil
 }
 
@@ -0,0 +1,38 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	libShortDesc = map[string]string{
+		"generate-crd": "Generate libraries for CustomResourceDefinitions",
+	}
+)
+
+func newLibCmd() *cobra.Command {
+	libCmd := &cobra.Command{
+		Use:   "lib",
+		Short: "Manage the libraries in the lib directory",
+		Long:  `Manage the libraries in the lib directory`,
+	}
+
+	libCmd.AddCommand(newLibGenerateCRDCmd())
+
+	return libCmd
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vLibGenerateCRDFilenames = "lib-generate-crd-filenames"
+	vLibGenerateCRDPackages  = "lib-generate-crd-packages"
+	vLibGenerateCRDEnv       = "lib-generate-crd-env"
+)
+
+var (
+	libGenerateCRDLong = `
+The ` + "`generate-crd`" + ` command generates libraries for the custom resources
+defined by CustomResourceDefinitions (CRDs). CRDs are read from:
+
+* files or directories of YAML and JSON manifests (` + "`--filename`" + `)
+* the manifests of installed packages (` + "`--package`" + `)
+* the cluster of an environment (` + "`--env`" + `)
+
+A library is generated for each group and version at
+` + "`lib/crds/<group>/<version>.libsonnet`" + `. It has a constructor and mixins for
+each kind, which are generated from the CRD's ` + "`openAPIV3Schema`" + `.
+
+Libraries are generated from scratch each time, so run the command again when a
+CRD changes. Libraries which have not changed are left untouched.
+
+### Related Commands
+
+* ` + "`ks env update` " + `— ` + envShortDesc["update"] + `
+
+### Syntax
+`
+	libGenerateCRDExample = `
+# Generate libraries for the CRDs in a manifest
+ks lib generate-crd -f cert-manager-crds.yaml
+
+# Generate libraries for the CRDs in an installed package
+ks lib generate-crd --package incubator/istio
+
+# Generate libraries for the CRDs in the cluster of the 'dev' environment
+ks lib generate-crd --env dev
+
+# Use the library in a component
+local certmanager = import "crds/certmanager.k8s.io/v1alpha1.libsonnet";
+local certificate = certmanager.certificate;
+
+certificate.new("example") +
+certificate.mixin.spec.withCommonName("example.com")
+`
+)
+
+func newLibGenerateCRDCmd() *cobra.Command {
+	libGenerateCRDClientConfig := client.NewDefaultClientConfig()
+
+	libGenerateCRDCmd := &cobra.Command{
+		Use:     "generate-crd [-f <file>] [--package <package>] [--env <env-name>]",
+		Short:   libShortDesc["generate-crd"],
+		Long:    libGenerateCRDLong,
+		Example: libGenerateCRDExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := map[string]interface{}{
+				actions.OptionFilenames:    viper.GetStringSlice(vLibGenerateCRDFilenames),
+				actions.OptionPackageNames: viper.GetStringSlice(vLibGenerateCRDPackages),
+				actions.OptionEnvName:      viper.GetString(vLibGenerateCRDEnv),
+				actions.OptionClientConfig: libGenerateCRDClientConfig,
+			}
+			addGlobalOptions(m)
+
+			return runAction(actionLibGenerateCRD, m)
+		},
+	}
+
+	libGenerateCRDClientConfig.BindClientGoFlags(libGenerateCRDCmd)
+
+	libGenerateCRDCmd.Flags().StringSliceP(flagFilename, shortFilename, nil, "Manifest file or directory containing CRDs")
+	viper.BindPFlag(vLibGenerateCRDFilenames, libGenerateCRDCmd.Flags().Lookup(flagFilename))
+
+	libGenerateCRDCmd.Flags().StringSlice(flagPackage, nil, "Installed package containing CRDs")
+	viper.BindPFlag(vLibGenerateCRDPackages, libGenerateCRDCmd.Flags().Lookup(flagPackage))
+
+	libGenerateCRDCmd.Flags().String(flagEnv, "", "Environment whose cluster CRDs are read from")
+	viper.BindPFlag(vLibGenerateCRDEnv, libGenerateCRDCmd.Flags().Lookup(flagEnv))
+
+	return libGenerateCRDCmd
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vLintOutput   = "lint-output"
+	lintShortDesc = "Find common mistakes in an app"
+)
+
+var (
+	lintLong = `
+The ` + "`lint`" + ` command checks the components, params and environments of an
+app for common mistakes without rendering them or contacting a cluster:
+
+* ` + "`unused-param`" + ` — params which are not used by their component
+* ` + "`undefined-param`" + ` — params which are used by a component but are not defined
+* ` + "`shadowed-import`" + ` — locals which shadow an imported local
+* ` + "`untargeted-component`" + ` — components which are not targeted by any environment
+* ` + "`missing-namespace`" + ` — namespaced objects without a namespace
+* ` + "`missing-labels`" + ` — objects without labels
+
+The command fails if any problems are found.
+
+### Related Commands
+
+* ` + "`ks fmt` " + `— ` + fmtShortDesc + `
+* ` + "`ks validate` " + `— ` + valShortDesc + `
+
+### Syntax
+`
+	lintExample = `
+# Lint the app
+ks lint
+
+# Lint the app and print the problems as JSON
+ks lint -o json
+`
+)
+
+func newLintCmd() *cobra.Command {
+	lintCmd := &cobra.Command{
+		Use:     "lint [-o text|json]",
+		Short:   lintShortDesc,
+		Long:    lintLong,
+		Example: lintExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := map[string]interface{}{
+				actions.OptionOutput: viper.GetString(vLintOutput),
+			}
+			addGlobalOptions(m)
+
+			return runAction(actionLint, m)
+		},
+	}
+
+	lintCmd.Flags().StringP(flagOutput, shortOutput, "", "Output format. Valid options: text|json")
+	viper.BindPFlag(vLintOutput, lintCmd.Flags().Lookup(flagOutput))
+
+	return lintCmd
+}
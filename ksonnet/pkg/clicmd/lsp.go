@@ -0,0 +1,81 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vLspEnv      = "lsp-env"
+	lspShortDesc = "Run a Jsonnet language server for the app"
+)
+
+var (
+	lspLong = `
+The ` + "`lsp`" + ` command runs a language server for the Jsonnet in an app. It speaks
+the Language Server Protocol over stdio, so it is meant to be started by an
+editor rather than run directly. The server provides:
+
+* completion of the members of imported libraries, e.g. ` + "`k.apps.v1beta2.`" + `
+* completion of the keys of component params, e.g. ` + "`params.`" + `
+* completion of import paths from the app's lib and vendor directories
+* prototypes as snippets
+* go-to-definition for locals and imports
+* diagnostics from parsing and evaluating documents
+
+Documents are evaluated in the environment set with ` + "`--env`" + `, or the current
+environment if it isn't set.
+
+### Related Commands
+
+* ` + "`ks lint` " + `— ` + lintShortDesc + `
+* ` + "`ks prototype list` " + `— ` + protoShortDesc["list"] + `
+
+### Syntax
+`
+	lspExample = `
+# Run the language server in the current environment
+ks lsp
+
+# Run the language server in the 'dev' environment
+ks lsp --env dev
+`
+)
+
+func newLspCmd() *cobra.Command {
+	lspCmd := &cobra.Command{
+		Use:     "lsp [--env <env-name>]",
+		Short:   lspShortDesc,
+		Long:    lspLong,
+		Example: lspExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := map[string]interface{}{
+				actions.OptionEnvName: viper.GetString(vLspEnv),
+			}
+			addGlobalOptions(m)
+
+			return runAction(actionLsp, m)
+		},
+	}
+
+	lspCmd.Flags().String(flagEnv, "", "Environment to evaluate documents in")
+	viper.BindPFlag(vLspEnv, lspCmd.Flags().Lookup(flagEnv))
+
+	return lspCmd
+}
@@ -0,0 +1,47 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// bindObjectFilterFlags adds the flags which filter rendered objects to a
+// command. `name` prefixes their viper assignments.
+func bindObjectFilterFlags(cmd *cobra.Command, name string) {
+	cmd.Flags().StringP(flagSelector, shortSelector, "", "Label selector of objects (e.g. tier=frontend,env!=dev)")
+	viper.BindPFlag(name+"-selector", cmd.Flags().Lookup(flagSelector))
+
+	cmd.Flags().StringSlice(flagKind, nil, "Kind of objects (multiple --kind flags accepted)")
+	viper.BindPFlag(name+"-kind", cmd.Flags().Lookup(flagKind))
+
+	cmd.Flags().StringSlice(flagObjectName, nil, "Name of objects, which can be a pattern like 'web-*' (multiple --name flags accepted)")
+	viper.BindPFlag(name+"-name", cmd.Flags().Lookup(flagObjectName))
+
+	cmd.Flags().StringSlice(flagExcludeComponent, nil, "Name of a component whose objects are excluded (multiple --exclude-component flags accepted)")
+	viper.BindPFlag(name+"-exclude-component", cmd.Flags().Lookup(flagExcludeComponent))
+}
+
+// addObjectFilterOptions adds the values of the flags which filter rendered
+// objects to action options.
+func addObjectFilterOptions(m map[string]interface{}, name string) {
+	m[actions.OptionExcludeComponents] = viper.GetStringSlice(name + "-exclude-component")
+	m[actions.OptionKinds] = viper.GetStringSlice(name + "-kind")
+	m[actions.OptionNames] = viper.GetStringSlice(name + "-name")
+	m[actions.OptionSelector] = viper.GetString(name + "-selector")
+}
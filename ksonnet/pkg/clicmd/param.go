@@ -25,6 +25,8 @@ var (
 		"set":    "Change component or environment parameters (e.g. replica count, name)",
 		"list":   "List known component parameters",
 		"diff":   "Display differences between the component parameters of two environments",
+		"export": "Export the resolved parameters of an environment to YAML or JSON",
+		"import": "Set environment parameters from a YAML or JSON file",
 	}
 	paramLong = `
 Parameters are customizable fields that are used inside ksonnet *component*
@@ -66,6 +68,8 @@ func newParamCmd() *cobra.Command {
 
 	paramCmd.AddCommand(newParamDeleteCmd())
 	paramCmd.AddCommand(newParamDiffCmd())
+	paramCmd.AddCommand(newParamExportCmd())
+	paramCmd.AddCommand(newParamImportCmd())
 	paramCmd.AddCommand(newParamListCmd())
 	paramCmd.AddCommand(newParamSetCmd())
 
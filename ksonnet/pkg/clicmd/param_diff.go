@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -31,7 +32,14 @@ const (
 var (
 	paramDiffLong = `
 The ` + "`diff`" + ` command pretty prints differences between the component parameters
-of two environments.
+of two locations.
+
+A location is an environment name, optionally followed by ` + "`@`" + ` and a source:
+
+* ` + "`<env>`" + ` — the environment in the current working tree
+* ` + "`<env>@<revision>`" + ` — the environment at a git revision, e.g. ` + "`prod@HEAD~1`" + `
+* ` + "`<env>@cluster`" + ` — the parameters recorded by the last ` + "`ks apply --record-params`" + ` to the
+  cluster. Values of sensitive parameters are recorded as ` + "`<redacted>`" + `.
 
 By default, the diff is performed for all components. Diff-ing for a single component
 is supported via a component flag.
@@ -49,21 +57,30 @@ ks param diff dev prod
 
 # Diff only between the parameters for the 'guestbook' component for environments
 # 'dev' and 'prod'
-ks param diff dev prod --component=guestbook`
+ks param diff dev prod --component=guestbook
+
+# Diff the parameters for 'prod' between the previous commit and the working tree
+ks param diff prod@HEAD~1 prod
+
+# Diff the parameters last applied to the cluster for 'prod' with the working tree
+ks param diff prod@cluster prod`
 )
 
 func newParamDiffCmd() *cobra.Command {
+	paramDiffClientConfig := client.NewDefaultClientConfig()
+
 	paramDiffCmd := &cobra.Command{
-		Use:     "diff <env1> <env2> [--component <component-name>]",
+		Use:     "diff <location1> <location2> [--component <component-name>]",
 		Short:   paramShortDesc["diff"],
 		Long:    paramDiffLong,
 		Example: paramDiffExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 2 {
-				return fmt.Errorf("'param diff' takes exactly two arguments: the respective names of the environments being diffed")
+				return fmt.Errorf("'param diff' takes exactly two arguments: the respective locations of the environments being diffed")
 			}
 
 			m := map[string]interface{}{
+				actions.OptionClientConfig:  paramDiffClientConfig,
 				actions.OptionEnvName1:      args[0],
 				actions.OptionEnvName2:      args[1],
 				actions.OptionComponentName: viper.GetString(vParamDiffComponent),
@@ -75,6 +92,7 @@ func newParamDiffCmd() *cobra.Command {
 		},
 	}
 
+	paramDiffClientConfig.BindClientGoFlags(paramDiffCmd)
 	addCmdOutput(paramDiffCmd, vParamDiffOutput)
 	paramDiffCmd.Flags().String(flagComponent, "", "Specify the component to diff against")
 	viper.BindPFlag(vParamDiffComponent, paramDiffCmd.Flags().Lookup(flagComponent))
@@ -29,6 +29,7 @@ func Test_paramDiffCmd(t *testing.T) {
 			action: actionParamDiff,
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
+				actions.OptionClientConfig:  nil,
 				actions.OptionComponentName: "component-name",
 				actions.OptionEnvName1:      "env1",
 				actions.OptionEnvName2:      "env2",
@@ -41,6 +42,7 @@ func Test_paramDiffCmd(t *testing.T) {
 			action: actionParamDiff,
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
+				actions.OptionClientConfig:  nil,
 				actions.OptionEnvName1:      "env1",
 				actions.OptionEnvName2:      "env2",
 				actions.OptionComponentName: "",
@@ -53,12 +55,26 @@ func Test_paramDiffCmd(t *testing.T) {
 			action: actionParamDiff,
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
+				actions.OptionClientConfig:  nil,
 				actions.OptionEnvName1:      "env1",
 				actions.OptionEnvName2:      "env2",
 				actions.OptionComponentName: "",
 				actions.OptionOutput:        "json",
 			},
 		},
+		{
+			name:   "with locations",
+			args:   []string{"param", "diff", "prod@HEAD~1", "prod@cluster"},
+			action: actionParamDiff,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionClientConfig:  nil,
+				actions.OptionEnvName1:      "prod@HEAD~1",
+				actions.OptionEnvName2:      "prod@cluster",
+				actions.OptionComponentName: "",
+				actions.OptionOutput:        "",
+			},
+		},
 		{
 			name:  "invalid args",
 			args:  []string{"param", "diff"},
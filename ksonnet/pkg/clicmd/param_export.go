@@ -0,0 +1,82 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vParamExportEnv       = "param-export-env"
+	vParamExportComponent = "param-export-component"
+	vParamExportOutput    = "param-export-output"
+)
+
+var (
+	paramExportLong = `
+The ` + "`export`" + ` command writes the resolved component parameters for an
+environment as YAML or JSON. The output can be edited and applied to another
+environment with ` + "`ks param import`" + `.
+
+### Related Commands
+
+* ` + "`ks param import` " + `— ` + paramShortDesc["import"] + `
+* ` + "`ks param list` " + `— ` + paramShortDesc["list"] + `
+
+### Syntax
+`
+	paramExportExample = `
+# Export the parameters for all components in the 'dev' environment as YAML
+ks param export --env=dev > dev-params.yaml
+
+# Export the parameters for the 'guestbook' component in the 'dev' environment
+# as JSON
+ks param export --env=dev --component=guestbook -o json`
+)
+
+func newParamExportCmd() *cobra.Command {
+	paramExportCmd := &cobra.Command{
+		Use:     "export --env <env-name> [--component <component-name>]",
+		Short:   paramShortDesc["export"],
+		Long:    paramExportLong,
+		Example: paramExportExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'param export' does not take arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionEnvName:       viper.GetString(vParamExportEnv),
+				actions.OptionComponentName: viper.GetString(vParamExportComponent),
+				actions.OptionOutput:        viper.GetString(vParamExportOutput),
+			}
+
+			return runAction(actionParamExport, m)
+		},
+	}
+
+	paramExportCmd.Flags().String(flagEnv, "", "Specify environment to export parameters for")
+	viper.BindPFlag(vParamExportEnv, paramExportCmd.Flags().Lookup(flagEnv))
+	paramExportCmd.Flags().StringP(flagComponent, shortComponent, "", "Only export parameters for this component")
+	viper.BindPFlag(vParamExportComponent, paramExportCmd.Flags().Lookup(flagComponent))
+	paramExportCmd.Flags().StringP(flagOutput, shortOutput, "", "Output format. Valid options: yaml|json")
+	viper.BindPFlag(vParamExportOutput, paramExportCmd.Flags().Lookup(flagOutput))
+
+	return paramExportCmd
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vParamImportEnv    = "param-import-env"
+	vParamImportDryRun = "param-import-dry-run"
+)
+
+var (
+	paramImportLong = `
+The ` + "`import`" + ` command sets environment parameters from a YAML or JSON file.
+The file uses the same layout as the output of ` + "`ks param export`" + `:
+
+    global:
+      <param-key>: <value>
+    components:
+      <component-name>:
+        <param-key>: <value>
+
+Component parameters are written to ` + "`environments/<env-name>/params.libsonnet`" + `
+and global parameters are written to ` + "`environments/<env-name>/globals.libsonnet`" + `.
+Component parameters which match the environment's resolved value are skipped, so
+importing an unmodified export leaves the environment unchanged.
+Use ` + "`--dry-run`" + ` to display the changes to these files without writing them.
+
+### Related Commands
+
+* ` + "`ks param export` " + `— ` + paramShortDesc["export"] + `
+* ` + "`ks param set` " + `— ` + paramShortDesc["set"] + `
+
+### Syntax
+`
+	paramImportExample = `
+# Set the parameters in the 'prod' environment to the ones exported from 'dev'
+ks param export --env=dev > params.yaml
+ks param import --env=prod params.yaml
+
+# Display the changes importing the file would make
+ks param import --env=prod params.yaml --dry-run`
+)
+
+func newParamImportCmd() *cobra.Command {
+	paramImportCmd := &cobra.Command{
+		Use:     "import --env <env-name> <file>",
+		Short:   paramShortDesc["import"],
+		Long:    paramImportLong,
+		Example: paramImportExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("'param import' requires a file to import")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionEnvName: viper.GetString(vParamImportEnv),
+				actions.OptionPath:    args[0],
+				actions.OptionDryRun:  viper.GetBool(vParamImportDryRun),
+			}
+
+			return runAction(actionParamImport, m)
+		},
+	}
+
+	paramImportCmd.Flags().String(flagEnv, "", "Specify environment to import parameters into")
+	viper.BindPFlag(vParamImportEnv, paramImportCmd.Flags().Lookup(flagEnv))
+	paramImportCmd.Flags().Bool(flagDryRun, false, "Display the changes to the params files without writing them")
+	viper.BindPFlag(vParamImportDryRun, paramImportCmd.Flags().Lookup(flagDryRun))
+
+	return paramImportCmd
+}
@@ -34,6 +34,10 @@ reflected in the ` + "`params.libsonnet`" + ` files.
 
 For more details on how parameters are organized, see ` + "`ks param --help`" + `.
 
+If the component's module has a ` + "`params.schema.json`" + ` file next to its
+` + "`params.libsonnet`" + `, the new value is validated against the JSON Schema
+for the component before it is written.
+
 *(If you need to customize multiple parameters at once, we suggest that you modify
 your ksonnet application's ` + " `components/params.libsonnet` " + `file directly. Likewise,
 for greater customization of environment parameters, we suggest modifying the
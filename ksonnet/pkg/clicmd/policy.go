@@ -0,0 +1,87 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	policyShortDesc = map[string]string{
+		"check": "Check an environment's objects against the app's policies",
+		"list":  "List the app's policies",
+	}
+
+	policyLong = `
+The ` + "`policy`" + ` subcommands check the objects rendered for an environment against
+the app's policies. ` + "`ks apply`" + ` runs the same checks before it applies objects.
+
+Built-in policies are enabled in the ` + "`policies`" + ` section of ` + "`app.yaml`" + `, which
+also sets the severity of any policy to ` + "`error`" + `, ` + "`warning`" + ` or ` + "`off`" + `:
+
+` + "```" + `
+policies:
+  no-latest-image: {}
+  require-resource-limits:
+    severity: warning
+` + "```" + `
+
+The built-in policies are ` + "`no-latest-image`" + `, ` + "`require-resource-limits`" + ` and
+` + "`no-host-network`" + `.
+
+Custom policies live in the app's ` + "`policies/`" + ` directory, and are named after their
+file. A CEL policy (` + "`.cel`" + `) is an expression which is evaluated for each ` + "`object`" + `.
+It returns ` + "`false`" + ` or a list of messages for a violation, and may set its
+description and severity in comments:
+
+` + "```" + `
+// @description Deployments must run more than one replica
+// @severity warning
+object.kind != "Deployment" || (has(object.spec.replicas) && object.spec.replicas > 1)
+` + "```" + `
+
+A Jsonnet policy (` + "`.jsonnet`" + `) has an optional description and severity, and a
+` + "`violations`" + ` function which returns the violation messages for an object:
+
+` + "```" + `
+{
+  description: "Objects must have a team label",
+  severity: "warning",
+  violations(object):: if "team" in object.metadata.labels then [] else ["missing team label"],
+}
+` + "```" + `
+
+An object is exempt from policies with the ` + "`policy.ksonnet.io/exempt`" + ` annotation.
+It is a comma separated list of policy names, each optionally prefixed with an
+environment name (` + "`prod:no-latest-image`" + `). ` + "`*`" + ` matches every policy or
+environment.
+
+----
+`
+)
+
+func newPolicyCmd() *cobra.Command {
+	policyCmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Check rendered objects against policies",
+		Long:  policyLong,
+	}
+
+	policyCmd.AddCommand(newPolicyCheckCmd())
+	policyCmd.AddCommand(newPolicyListCmd())
+
+	return policyCmd
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vPolicyCheckEnv    = "policy-check-env"
+	vPolicyCheckOutput = "policy-check-output"
+)
+
+var (
+	policyCheckLong = `
+The ` + "`check`" + ` command renders an environment and checks its objects against the
+app's policies. Violations are reported as a table, or as JSON with ` + "`-o json`" + `.
+The command fails if any violations have ` + "`error`" + ` severity.
+
+### Related Commands
+
+* ` + "`ks policy list` " + `— ` + policyShortDesc["list"] + `
+* ` + "`ks apply` " + `— ` + applyShortDesc + `
+
+### Syntax
+`
+	policyCheckExample = `
+# Check the current environment
+ks policy check
+
+# Check the 'prod' environment and report JSON
+ks policy check --env prod -o json
+`
+)
+
+func newPolicyCheckCmd() *cobra.Command {
+	policyCheckCmd := &cobra.Command{
+		Use:     "check [--env <env-name>] [-o table|json]",
+		Short:   policyShortDesc["check"],
+		Long:    policyCheckLong,
+		Example: policyCheckExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := map[string]interface{}{
+				actions.OptionEnvName: viper.GetString(vPolicyCheckEnv),
+				actions.OptionOutput:  viper.GetString(vPolicyCheckOutput),
+			}
+			addGlobalOptions(m)
+
+			return runAction(actionPolicyCheck, m)
+		},
+	}
+
+	addCmdOutput(policyCheckCmd, vPolicyCheckOutput)
+	policyCheckCmd.Flags().String(flagEnv, "", "Environment to check")
+	viper.BindPFlag(vPolicyCheckEnv, policyCheckCmd.Flags().Lookup(flagEnv))
+
+	return policyCheckCmd
+}
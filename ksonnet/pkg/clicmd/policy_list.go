@@ -0,0 +1,60 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vPolicyListOutput = "policy-list-output"
+)
+
+var (
+	policyListLong = `
+The ` + "`list`" + ` command lists the app's built-in and custom policies, with their
+severities and where they are defined. Built-in policies are only listed if they
+are enabled in ` + "`app.yaml`" + `.
+
+### Related Commands
+
+* ` + "`ks policy check` " + `— ` + policyShortDesc["check"] + `
+
+### Syntax
+`
+)
+
+func newPolicyListCmd() *cobra.Command {
+	policyListCmd := &cobra.Command{
+		Use:   "list [-o table|json]",
+		Short: policyShortDesc["list"],
+		Long:  policyListLong,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := map[string]interface{}{
+				actions.OptionOutput: viper.GetString(vPolicyListOutput),
+			}
+			addGlobalOptions(m)
+
+			return runAction(actionPolicyList, m)
+		},
+	}
+
+	addCmdOutput(policyListCmd, vPolicyListOutput)
+
+	return policyListCmd
+}
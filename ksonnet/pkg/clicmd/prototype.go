@@ -23,9 +23,11 @@ import (
 var (
 	protoShortDesc = map[string]string{
 		"list":     "List all locally available ksonnet prototypes",
+		"new":      "Scaffold a prototype in a package",
 		"describe": "See more info about a prototype's output and usage",
 		"preview":  "Preview a prototype's output without creating a component (stdout)",
 		"search":   "Search for a prototype",
+		"test":     "Render and validate the prototypes in a package",
 		"use":      "Use the specified prototype to generate a component manifest",
 	}
 	protoLong = `
@@ -42,6 +44,13 @@ words, prototypes provide the basis for the **components** of your app. You can
 use prototypes to autogenerate boilerplate code and focus on customizing them
 for your use case.
 
+Prototypes are usually written in Jsonnet, and import their parameters with
+` + "`import 'param://<name>'`" + `. A prototype which declares ` + "`@templateEngine gotemplate`" + `
+is rendered with Go's text/template instead, so it can use conditionals, loops
+over array parameters and the sprig functions, except ` + "`env`" + ` and ` + "`expandenv`" + `. Its
+parameters are available as ` + "`.Params`" + `. It may declare ` + "`@templateType yaml`" + ` or ` + "`@templateType json`" + ` to
+generate YAML or JSON components.
+
 ----
 `
 )
@@ -55,8 +64,10 @@ func newPrototypeCmd(fs afero.Fs) *cobra.Command {
 
 	prototypeCmd.AddCommand(newPrototypeDescribeCmd())
 	prototypeCmd.AddCommand(newPrototypeListCmd())
+	prototypeCmd.AddCommand(newPrototypeNewCmd(fs))
 	prototypeCmd.AddCommand(newPrototypePreviewCmd())
 	prototypeCmd.AddCommand(newPrototypeSearchCmd())
+	prototypeCmd.AddCommand(newPrototypeTestCmd())
 	prototypeCmd.AddCommand(newPrototypeUseCmd(fs))
 
 	return prototypeCmd
@@ -0,0 +1,82 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"fmt"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prototypeNewLong = `
+The ` + "`new`" + ` command scaffolds a prototype in a package. The prototype is written
+to ` + "`prototypes/<prototype-name>.jsonnet`" + ` in the package directory, which defaults
+to the current directory. If the package doesn't have a ` + "`parts.yaml`" + `, one is
+created.
+
+The scaffolded prototype declares its metadata and parameters with the
+` + "`@apiVersion`" + `, ` + "`@name`" + `, ` + "`@description`" + `, ` + "`@shortDescription`" + `, ` + "`@param`" + ` and
+` + "`@optionalParam`" + ` directives. Parameters may declare an ` + "`example=<value>`" + ` option,
+which is used as the parameter's value by ` + "`ks prototype test`" + `.
+
+### Related Commands
+
+* ` + "`ks prototype test` " + `— ` + protoShortDesc["test"] + `
+* ` + "`ks prototype preview` " + `— ` + protoShortDesc["preview"] + `
+
+### Syntax
+`
+	prototypeNewExample = `
+# Scaffold 'io.ksonnet.pkg.redis-cluster' in the package in the current
+# directory. The prototype is written to 'prototypes/redis-cluster.jsonnet'.
+ks prototype new redis-cluster
+
+# Scaffold 'io.ksonnet.pkg.redis-cluster' in the package in 'incubator/redis'.
+ks prototype new redis-cluster incubator/redis
+`
+)
+
+func newPrototypeNewCmd(fs afero.Fs) *cobra.Command {
+	prototypeNewCmd := &cobra.Command{
+		Use:     "new <prototype-name> [package-dir]",
+		Short:   protoShortDesc["new"],
+		Long:    prototypeNewLong,
+		Example: prototypeNewExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return fmt.Errorf("Command 'prototype new' requires a prototype name\n\n%s", cmd.UsageString())
+			}
+
+			var path string
+			if len(args) == 2 {
+				path = args[1]
+			}
+
+			m := map[string]interface{}{
+				actions.OptionFs:   fs,
+				actions.OptionName: args[0],
+				actions.OptionPath: path,
+			}
+
+			return runAction(actionPrototypeNew, m)
+		},
+	}
+
+	return prototypeNewCmd
+}
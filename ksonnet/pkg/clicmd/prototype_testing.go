@@ -0,0 +1,92 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"fmt"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vPrototypeTestEnv = "prototype-test-env"
+)
+
+var (
+	prototypeTestLong = `
+The ` + "`test`" + ` command checks that every prototype in a package renders. It is
+meant to be run in CI by package authors.
+
+Each prototype is generated with ` + "`ks generate`" + ` into a scratch copy of the app, using
+the defaults of its optional parameters and sample values for its required
+parameters. The sample value of a parameter is its ` + "`example=<value>`" + ` option if it
+has one, then the first value of its ` + "`enum`" + `, then a placeholder for its type. The
+generated component is rendered in the environment set with ` + "`--env`" + `, or the
+current environment if it isn't set, and the objects are validated against the
+environment's OpenAPI schema. Nothing is written to the app.
+
+The command fails if any prototype fails to render or generates invalid objects.
+
+### Related Commands
+
+* ` + "`ks prototype new` " + `— ` + protoShortDesc["new"] + `
+* ` + "`ks validate` " + `— ` + valShortDesc + `
+
+### Syntax
+`
+	prototypeTestExample = `
+# Test the prototypes in the package in the current directory
+ks prototype test
+
+# Test the prototypes in the package in 'incubator/redis' in the 'dev'
+# environment
+ks prototype test incubator/redis --env dev
+`
+)
+
+func newPrototypeTestCmd() *cobra.Command {
+	prototypeTestCmd := &cobra.Command{
+		Use:     "test [package-dir] [--env <env-name>]",
+		Short:   protoShortDesc["test"],
+		Long:    prototypeTestLong,
+		Example: prototypeTestExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("Command 'prototype test' takes at most one package directory\n\n%s", cmd.UsageString())
+			}
+
+			var path string
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			m := map[string]interface{}{
+				actions.OptionEnvName: viper.GetString(vPrototypeTestEnv),
+				actions.OptionPath:    path,
+			}
+			addGlobalOptions(m)
+
+			return runAction(actionPrototypeTest, m)
+		},
+	}
+
+	prototypeTestCmd.Flags().String(flagEnv, "", "Environment to render prototypes in")
+	viper.BindPFlag(vPrototypeTestEnv, prototypeTestCmd.Flags().Lookup(flagEnv))
+
+	return prototypeTestCmd
+}
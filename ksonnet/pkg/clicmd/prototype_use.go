@@ -52,7 +52,12 @@ expand prototypes into Jsonnet files.
 
 3. Prototypes can be further customized by passing in **parameters** via additional
 command line flags, such as ` + " `--image` " + `in the example above. Note that
-different prototypes support their own unique flags.
+different prototypes support their own unique flags. Parameter values are
+checked against the types, and any enum or pattern constraints, declared by the
+prototype.
+
+4. When run in a terminal, any required parameters which were not supplied are
+prompted for. The generated component is previewed before it is written.
 
 ### Related Commands
 
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 
 	"github.com/ksonnet/ksonnet/pkg/log"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/ksonnet/ksonnet/pkg/plugin"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -73,6 +74,9 @@ func NewRoot(appFs afero.Fs, wd string, args []string) (*cobra.Command, error) {
 		appFs = afero.NewOsFs()
 	}
 
+	// rendered objects are only reused by the version which cached them.
+	pipeline.Version = Version
+
 	rootCmd := &cobra.Command{
 		Use:           "ks",
 		Short:         `Configure your application to deploy to a Kubernetes cluster`,
@@ -139,15 +143,22 @@ func NewRoot(appFs afero.Fs, wd string, args []string) (*cobra.Command, error) {
 	rootCmd.AddCommand(newDeleteCmd(appFs))
 	rootCmd.AddCommand(newDiffCmd(appFs))
 	rootCmd.AddCommand(newEnvCmd())
+	rootCmd.AddCommand(newExportCmd(appFs))
+	rootCmd.AddCommand(newFmtCmd())
 	rootCmd.AddCommand(newGenerateCmd(appFs))
 	rootCmd.AddCommand(newImportCmd())
 	rootCmd.AddCommand(newInitCmd(appFs, wd))
+	rootCmd.AddCommand(newLibCmd())
+	rootCmd.AddCommand(newLintCmd())
+	rootCmd.AddCommand(newLspCmd())
 	rootCmd.AddCommand(newModuleCmd())
 	rootCmd.AddCommand(newParamCmd())
 	rootCmd.AddCommand(newPkgCmd())
+	rootCmd.AddCommand(newPolicyCmd())
 	rootCmd.AddCommand(newPrototypeCmd(appFs))
 	rootCmd.AddCommand(newRegistryCmd())
 	rootCmd.AddCommand(newShowCmd(appFs))
+	rootCmd.AddCommand(newTestCmd())
 	rootCmd.AddCommand(newValidateCmd(appFs))
 	rootCmd.AddCommand(newUpgradeCmd())
 	rootCmd.AddCommand(newVersionCmd())
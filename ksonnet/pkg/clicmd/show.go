@@ -26,7 +26,12 @@ import (
 const (
 	showShortDesc  = "Show expanded manifests for a specific environment."
 	vShowComponent = "show-components"
+	vShowExplain   = "show-explain"
 	vShowFormat    = "show-format"
+	vShowPlatform  = "show-image-platform"
+	vShowResolve   = "show-resolve-images"
+	vShowStats     = "show-stats"
+	vShowStatsFmt  = "show-stats-format"
 )
 
 var (
@@ -44,6 +49,38 @@ This is the YAML version of what gets deployed to your cluster with
 When a component IS specified via the ` + "`-c`" + ` flag, this command only expands the
 manifest for that particular component.
 
+Objects can also be selected with ` + "`--selector`" + `, ` + "`--kind`" + `, ` + "`--name`" + ` and
+` + "`--exclude-component`" + `. An object is shown only if it matches every filter given.
+
+With ` + "`--resolve-images`" + `, the container images of Pods, Deployments, StatefulSets,
+DaemonSets, ReplicaSets, Jobs and CronJobs are pinned to digests looked up in
+their registries. Credentials for private registries are read from the Docker
+` + "`config.json`" + `, including its credential helpers. The original references are recorded in the
+` + "`ksonnet.io/original-images`" + ` annotation. A multi-platform image is pinned to the
+digest of its manifest list, or with ` + "`--image-platform linux/arm64`" + `, to the digest
+of the image for that platform. ` + "`ks apply`" + ` and ` + "`ks diff`" + ` accept the same flags.
+
+With ` + "`--explain <kind>/<name>`" + `, the object is not shown. Instead, each of its
+top-level fields is listed with the location in the component source which
+produced it and the params the field uses. The params of the component are listed
+with their values in the environment, and params overridden by the environment's
+` + "`params.libsonnet`" + ` are listed with the location of the override and the value
+they replace. With ` + "`-o json`" + ` the explanation is printed as JSON.
+
+Explanations are a best-effort guess made by reading the component source, not by
+tracing its evaluation. When an object is built with ` + "`k.libsonnet`" + ` rather than
+written as an object literal, fields set by a mixin point at that mixin and other
+fields point at the expression that builds the object. Guessed locations are marked
+` + "`(guess)`" + `. Changes made in an environment's ` + "`main.jsonnet`" + ` or by its globals
+are not reported.
+
+With ` + "`--stats`" + `, render stats are written to stderr once the objects are
+shown: the time spent on each module and component, whether a module was read from
+the object cache, the number of Jsonnet VMs created, and the files imported with
+their sizes and how many imports were served from a VM's import cache. Use
+` + "`--stats-format json`" + ` to track render performance in CI. Elapsed times in JSON
+are in nanoseconds.
+
 ### Related Commands
 
 * ` + "`ks validate` " + `— ` + valShortDesc + `
@@ -61,6 +98,17 @@ ks show prod -c redis -o json
 
 # Show multiple components from the 'dev' environment, in YAML
 ks show dev -c redis -c nginx-server
+
+# Show the Services and Deployments from the 'dev' environment whose names
+# start with 'web-', leaving out the 'monitoring' component
+ks show dev --kind service --kind deployment --name 'web-*' --exclude-component monitoring
+
+# Explain where the fields of the 'guestbook-ui' Deployment in the 'dev'
+# environment come from
+ks show dev --explain deployment/guestbook-ui
+
+# Show the 'dev' environment and write render stats as JSON to stats.json
+ks show dev --stats --stats-format json 2> stats.json
 `
 )
 
@@ -79,7 +127,12 @@ func newShowCmd(fs afero.Fs) *cobra.Command {
 			m := map[string]interface{}{
 				actions.OptionComponentNames: viper.GetStringSlice(vShowComponent),
 				actions.OptionEnvName:        envName,
+				actions.OptionExplain:        viper.GetString(vShowExplain),
 				actions.OptionFormat:         viper.GetString(vShowFormat),
+				actions.OptionImagePlatform:  viper.GetString(vShowPlatform),
+				actions.OptionResolveImages:  viper.GetBool(vShowResolve),
+				actions.OptionStats:          viper.GetBool(vShowStats),
+				actions.OptionStatsFormat:    viper.GetString(vShowStatsFmt),
 			}
 
 			if err := extractJsonnetFlags(fs, "show"); err != nil {
@@ -90,6 +143,7 @@ func newShowCmd(fs afero.Fs) *cobra.Command {
 		},
 	}
 	bindJsonnetFlags(showCmd, "show")
+	bindObjectFilterFlags(showCmd, "show")
 
 	showCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component (multiple -c flags accepted, allows YAML, JSON, and Jsonnet)")
 	viper.BindPFlag(vShowComponent, showCmd.Flags().Lookup(flagComponent))
@@ -97,5 +151,20 @@ func newShowCmd(fs afero.Fs) *cobra.Command {
 	showCmd.Flags().StringP(flagFormat, shortFormat, "yaml", "Output format.  Supported values are: json, yaml")
 	viper.BindPFlag(vShowFormat, showCmd.Flags().Lookup(flagFormat))
 
+	showCmd.Flags().String(flagExplain, "", "Guess where the fields and params of an object come from, given as <kind>/<name>")
+	viper.BindPFlag(vShowExplain, showCmd.Flags().Lookup(flagExplain))
+
+	showCmd.Flags().Bool(flagResolveImages, false, "Pin container images to digests")
+	viper.BindPFlag(vShowResolve, showCmd.Flags().Lookup(flagResolveImages))
+
+	showCmd.Flags().String(flagImagePlatform, "", "Pin multi-platform images to the digest of the image for an os/arch[/variant] platform")
+	viper.BindPFlag(vShowPlatform, showCmd.Flags().Lookup(flagImagePlatform))
+
+	showCmd.Flags().Bool(flagStats, false, "Report render stats on stderr")
+	viper.BindPFlag(vShowStats, showCmd.Flags().Lookup(flagStats))
+
+	showCmd.Flags().String(flagStatsFormat, "table", "Format of render stats.  Supported values are: table, json")
+	viper.BindPFlag(vShowStatsFmt, showCmd.Flags().Lookup(flagStatsFormat))
+
 	return showCmd
 }
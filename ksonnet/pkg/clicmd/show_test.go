@@ -28,10 +28,99 @@ func Test_showCmd(t *testing.T) {
 			args:   []string{"show", "default"},
 			action: actionShow,
 			expected: map[string]interface{}{
-				actions.OptionApp:            nil,
-				actions.OptionEnvName:        "default",
-				actions.OptionComponentNames: make([]string, 0),
-				actions.OptionFormat:         "yaml",
+				actions.OptionApp:               nil,
+				actions.OptionEnvName:           "default",
+				actions.OptionComponentNames:    make([]string, 0),
+				actions.OptionExcludeComponents: make([]string, 0),
+				actions.OptionKinds:             make([]string, 0),
+				actions.OptionNames:             make([]string, 0),
+				actions.OptionSelector:          "",
+				actions.OptionExplain:           "",
+				actions.OptionFormat:            "yaml",
+				actions.OptionImagePlatform:     "",
+				actions.OptionResolveImages:     false,
+				actions.OptionStats:             false,
+				actions.OptionStatsFormat:       "table",
+			},
+		},
+		{
+			name:   "resolve images",
+			args:   []string{"show", "default", "--resolve-images"},
+			action: actionShow,
+			expected: map[string]interface{}{
+				actions.OptionApp:               nil,
+				actions.OptionEnvName:           "default",
+				actions.OptionComponentNames:    make([]string, 0),
+				actions.OptionExcludeComponents: make([]string, 0),
+				actions.OptionKinds:             make([]string, 0),
+				actions.OptionNames:             make([]string, 0),
+				actions.OptionSelector:          "",
+				actions.OptionExplain:           "",
+				actions.OptionFormat:            "yaml",
+				actions.OptionImagePlatform:     "",
+				actions.OptionResolveImages:     true,
+				actions.OptionStats:             false,
+				actions.OptionStatsFormat:       "table",
+			},
+		},
+		{
+			name:   "resolve images for a platform",
+			args:   []string{"show", "default", "--resolve-images", "--image-platform", "linux/arm64"},
+			action: actionShow,
+			expected: map[string]interface{}{
+				actions.OptionApp:               nil,
+				actions.OptionEnvName:           "default",
+				actions.OptionComponentNames:    make([]string, 0),
+				actions.OptionExcludeComponents: make([]string, 0),
+				actions.OptionKinds:             make([]string, 0),
+				actions.OptionNames:             make([]string, 0),
+				actions.OptionSelector:          "",
+				actions.OptionExplain:           "",
+				actions.OptionFormat:            "yaml",
+				actions.OptionImagePlatform:     "linux/arm64",
+				actions.OptionResolveImages:     true,
+				actions.OptionStats:             false,
+				actions.OptionStatsFormat:       "table",
+			},
+		},
+		{
+			name:   "stats",
+			args:   []string{"show", "default", "--stats", "--stats-format", "json"},
+			action: actionShow,
+			expected: map[string]interface{}{
+				actions.OptionApp:               nil,
+				actions.OptionEnvName:           "default",
+				actions.OptionComponentNames:    make([]string, 0),
+				actions.OptionExcludeComponents: make([]string, 0),
+				actions.OptionKinds:             make([]string, 0),
+				actions.OptionNames:             make([]string, 0),
+				actions.OptionSelector:          "",
+				actions.OptionExplain:           "",
+				actions.OptionFormat:            "yaml",
+				actions.OptionImagePlatform:     "",
+				actions.OptionResolveImages:     false,
+				actions.OptionStats:             true,
+				actions.OptionStatsFormat:       "json",
+			},
+		},
+		{
+			name:   "explain",
+			args:   []string{"show", "default", "--explain", "deployment/guestbook-ui"},
+			action: actionShow,
+			expected: map[string]interface{}{
+				actions.OptionApp:               nil,
+				actions.OptionEnvName:           "default",
+				actions.OptionComponentNames:    make([]string, 0),
+				actions.OptionExcludeComponents: make([]string, 0),
+				actions.OptionKinds:             make([]string, 0),
+				actions.OptionNames:             make([]string, 0),
+				actions.OptionSelector:          "",
+				actions.OptionExplain:           "deployment/guestbook-ui",
+				actions.OptionFormat:            "yaml",
+				actions.OptionImagePlatform:     "",
+				actions.OptionResolveImages:     false,
+				actions.OptionStats:             false,
+				actions.OptionStatsFormat:       "table",
 			},
 		},
 		{
@@ -0,0 +1,97 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vTestEnv      = "test-env"
+	vTestOutput   = "test-output"
+	testShortDesc = "Run component tests against an environment"
+)
+
+var (
+	testLong = `
+The ` + "`test`" + ` command runs the component tests in an app. Tests are Jsonnet files
+named ` + "`*_test.jsonnet`" + ` in the ` + "`components/`" + ` or ` + "`tests/`" + ` directory.
+
+A test evaluates to an object whose fields are test cases. A case passes if it
+evaluates to ` + "`true`" + `; use ` + "`assert`" + ` to fail with a message. Tests are evaluated
+with the same import paths, ext vars and params as the components of the
+environment set with ` + "`--env`" + `, or the current environment if it isn't set. The
+objects rendered for the environment are available as
+` + "`std.extVar(\"__ksonnet/objects\")`" + `:
+
+` + "```" + `
+local objects = std.extVar("__ksonnet/objects");
+local deployments = [o for o in objects if o.kind == "Deployment"];
+
+{
+  "has 5 replicas": deployments[0].spec.replicas == 5,
+  "has a PodDisruptionBudget":
+    assert std.length([o for o in objects if o.kind == "PodDisruptionBudget"]) > 0
+      : "no PodDisruptionBudget was rendered";
+    true,
+}
+` + "```" + `
+
+Results are reported in TAP, or as JUnit XML with ` + "`-o junit`" + `. The command
+fails if any tests fail.
+
+### Related Commands
+
+* ` + "`ks show` " + `— Show expanded manifests for a specific environment.
+* ` + "`ks validate` " + `— ` + valShortDesc + `
+
+### Syntax
+`
+	testExample = `
+# Run the tests against the current environment
+ks test
+
+# Run the tests against the 'prod' environment and report JUnit XML
+ks test --env prod -o junit
+`
+)
+
+func newTestCmd() *cobra.Command {
+	testCmd := &cobra.Command{
+		Use:     "test [--env <env-name>] [-o tap|junit]",
+		Short:   testShortDesc,
+		Long:    testLong,
+		Example: testExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := map[string]interface{}{
+				actions.OptionEnvName: viper.GetString(vTestEnv),
+				actions.OptionOutput:  viper.GetString(vTestOutput),
+			}
+			addGlobalOptions(m)
+
+			return runAction(actionTest, m)
+		},
+	}
+
+	testCmd.Flags().String(flagEnv, "", "Environment to run the tests against")
+	viper.BindPFlag(vTestEnv, testCmd.Flags().Lookup(flagEnv))
+	testCmd.Flags().StringP(flagOutput, shortOutput, "", "Output format. Valid options: tap|junit")
+	viper.BindPFlag(vTestOutput, testCmd.Flags().Lookup(flagOutput))
+
+	return testCmd
+}
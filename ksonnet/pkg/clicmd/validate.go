@@ -28,6 +28,7 @@ import (
 
 const (
 	vValidateComponent = "validate-component"
+	vValidateOffline   = "validate-offline"
 	valShortDesc       = "Check generated component manifests against the server's API"
 )
 
@@ -45,6 +46,23 @@ get deployed to your cluster with ` + "`ks apply <env-name>`" + `.
 When a component IS specified via the ` + "`-c`" + ` flag, this command only checks
 the manifest for that particular component.
 
+Before any manifests are checked, the resolved parameters of every environment
+are validated against the ` + "`params.schema.json`" + ` files in the app, if any.
+
+Custom resources are validated against the ` + "`openAPIV3Schema`" + ` of their
+CustomResourceDefinitions. CRDs are collected from the app's components and from
+the manifests of vendored packages. Errors are reported with the JSON path of the
+invalid field and the component the object came from.
+
+Objects are also checked for Kubernetes APIs which are deprecated, or which the
+cluster doesn't serve, with the replacement API to use. Deprecations come from a
+table of Kubernetes API deprecations. APIs the cluster doesn't serve and APIs which
+are removed are errors; deprecated APIs are warnings.
+
+When the ` + "`--offline`" + ` flag is set, the cluster is never contacted. Manifests are
+validated against the swagger in the environment's lib directory instead, and APIs
+are checked for the environment's ` + "`k8sVersion`" + `.
+
 ### Related Commands
 
 * ` + "`ks show` " + `— ` + showShortDesc + `
@@ -62,6 +80,12 @@ ksonnet validate dev
 # by the 'prod' environment
 # NOTE: Make sure your current $KUBECONFIG matches the 'prod' cluster info
 ksonnet validate prod -c redis
+
+# Validate all resources without contacting a cluster
+ksonnet validate dev --offline
+
+# Validate the objects labeled 'tier=frontend' in the 'dev' environment
+ksonnet validate dev -l tier=frontend
 `
 )
 
@@ -84,7 +108,9 @@ func newValidateCmd(fs afero.Fs) *cobra.Command {
 				actions.OptionModule:         "",
 				actions.OptionComponentNames: viper.GetStringSlice(vValidateComponent),
 				actions.OptionClientConfig:   validateClientConfig,
+				actions.OptionOffline:        viper.GetBool(vValidateOffline),
 			}
+			addObjectFilterOptions(m, "validate")
 
 			if err := extractJsonnetFlags(fs, "validate"); err != nil {
 				return errors.Wrap(err, "handle jsonnet flags")
@@ -96,9 +122,13 @@ func newValidateCmd(fs afero.Fs) *cobra.Command {
 
 	addEnvCmdFlags(validateCmd)
 	bindJsonnetFlags(validateCmd, "validate")
+	bindObjectFilterFlags(validateCmd, "validate")
 	validateClientConfig.BindClientGoFlags(validateCmd)
 
 	viper.BindPFlag(vValidateComponent, validateCmd.Flag(flagComponent))
 
+	validateCmd.Flags().Bool(flagOffline, false, "Validate without contacting the cluster")
+	viper.BindPFlag(vValidateOffline, validateCmd.Flags().Lookup(flagOffline))
+
 	return validateCmd
 }
@@ -28,11 +28,33 @@ func Test_validateCmd(t *testing.T) {
 			args:   []string{"validate", "env-name"},
 			action: actionValidate,
 			expected: map[string]interface{}{
-				actions.OptionApp:            nil,
-				actions.OptionEnvName:        "env-name",
-				actions.OptionModule:         "",
-				actions.OptionComponentNames: make([]string, 0),
-				actions.OptionClientConfig:   nil,
+				actions.OptionApp:               nil,
+				actions.OptionEnvName:           "env-name",
+				actions.OptionModule:            "",
+				actions.OptionComponentNames:    make([]string, 0),
+				actions.OptionExcludeComponents: make([]string, 0),
+				actions.OptionKinds:             make([]string, 0),
+				actions.OptionNames:             make([]string, 0),
+				actions.OptionSelector:          "",
+				actions.OptionClientConfig:      nil,
+				actions.OptionOffline:           false,
+			},
+		},
+		{
+			name:   "offline",
+			args:   []string{"validate", "env-name", "--offline"},
+			action: actionValidate,
+			expected: map[string]interface{}{
+				actions.OptionApp:               nil,
+				actions.OptionEnvName:           "env-name",
+				actions.OptionModule:            "",
+				actions.OptionComponentNames:    make([]string, 0),
+				actions.OptionExcludeComponents: make([]string, 0),
+				actions.OptionKinds:             make([]string, 0),
+				actions.OptionNames:             make([]string, 0),
+				actions.OptionSelector:          "",
+				actions.OptionClientConfig:      nil,
+				actions.OptionOffline:           true,
 			},
 		},
 	}
@@ -16,13 +16,18 @@
 package cluster
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
 	"time"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/deprecation"
 	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/policy"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
 	"github.com/ksonnet/ksonnet/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -32,6 +37,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	cmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
 )
 
@@ -43,6 +49,17 @@ const (
 	// defaultConflictTimeout sets the wait time before retrying after a conflict is detected.
 	defaultConflictTimeout = 1 * time.Second
 
+	// discoveryRetryCount sets how many times an object is retried when its kind
+	// can't be discovered, e.g. because its CustomResourceDefinition was just created.
+	discoveryRetryCount = 5
+
+	// defaultPollInterval sets the wait time between checks of the cluster's state.
+	defaultPollInterval = 1 * time.Second
+
+	// defaultCRDTimeout sets how long to wait for a CustomResourceDefinition to
+	// be established.
+	defaultCRDTimeout = 1 * time.Minute
+
 	appKsonnet = "ksonnet"
 )
 
@@ -52,14 +69,35 @@ var (
 
 // ApplyConfig is configuration for Apply.
 type ApplyConfig struct {
-	App            app.App
+	App app.App
+	// CheckAPIs checks objects for APIs which are deprecated or not served
+	// by the cluster before they are applied.
+	CheckAPIs      bool
 	ClientConfig   *client.Config
 	ComponentNames []string
-	Create         bool
-	DryRun         bool
-	EnvName        string
-	GcTag          string
-	SkipGc         bool
+	// ConvertDeprecated converts objects which use deprecated APIs to their
+	// replacements when the conversion is simple.
+	ConvertDeprecated bool
+	Create            bool
+	// CreateNamespace creates the destination namespace and the namespaces
+	// objects are in if they don't exist.
+	CreateNamespace bool
+	DryRun          bool
+	EnvName         string
+	// Filter selects which of the rendered objects are applied. Garbage
+	// collection is limited to objects it selects.
+	Filter pipeline.ObjectFilter
+	GcTag  string
+	// ImagePlatform resolves multi-platform images to the digest of the
+	// image for an os/architecture[/variant] platform.
+	ImagePlatform string
+	// RecordParams records the params of each component in an annotation
+	// on one of its objects, so they can be compared with `param diff`.
+	// Params which may hold secrets are redacted.
+	RecordParams  bool
+	ResolveImages bool
+	SkipGc        bool
+	SkipPolicies  bool
 }
 
 // ApplyOpts are options for configuring Apply.
@@ -71,12 +109,22 @@ type Apply struct {
 
 	// these make it easier to test Apply.
 	findObjectsFn         findObjectsFn
+	checkPoliciesFn       checkPoliciesFn
+	checkAPIsFn           checkAPIsFn
+	envParamsFn           envParamsFn
 	resourceClientFactory resourceClientFactoryFn
 	clientOpts            *Clients
 	objectInfo            ObjectInfo
 	ksonnetObjectFactory  func() ksonnetObject
 	upserterFactory       func() Upserter
 	conflictTimeout       time.Duration
+	pollInterval          time.Duration
+	crdTimeout            time.Duration
+
+	params         componentParams
+	appliedAt      time.Time
+	recordedParams sets.String
+	crdGroups      sets.String
 }
 
 // RunApply runs apply against a cluster given a configuration.
@@ -87,14 +135,19 @@ func RunApply(config ApplyConfig, opts ...ApplyOpts) error {
 
 	a := &Apply{
 		ApplyConfig:           config,
-		findObjectsFn:         findObjects,
+		findObjectsFn:         objectFinder(config.ResolveImages, config.ImagePlatform),
+		checkPoliciesFn:       checkPolicies,
+		checkAPIsFn:           checkAPIs,
+		envParamsFn:           envParams,
 		resourceClientFactory: resourceClientFactory,
 		objectInfo:            &objectInfo{},
 		ksonnetObjectFactory: func() ksonnetObject {
 			factory := cmdutil.NewFactory(config.ClientConfig.Config)
 			return newDefaultKsonnetObject(factory, config.DryRun)
 		},
-		conflictTimeout: 1 * time.Second,
+		conflictTimeout: defaultConflictTimeout,
+		pollInterval:    defaultPollInterval,
+		crdTimeout:      defaultCRDTimeout,
 	}
 
 	for _, opt := range opts {
@@ -130,7 +183,45 @@ func (a *Apply) Apply() error {
 		return errors.Wrap(err, "find objects")
 	}
 
-	sort.Sort(utils.DependencyOrder(apiObjects))
+	if apiObjects, err = a.Filter.Filter(apiObjects); err != nil {
+		return errors.Wrap(err, "filter objects")
+	}
+
+	if a.ConvertDeprecated {
+		a.convertDeprecated(apiObjects)
+	}
+
+	if a.CheckAPIs {
+		if err = a.checkAPIs(apiObjects); err != nil {
+			return err
+		}
+	}
+
+	if !a.SkipPolicies {
+		if err = a.checkPolicies(apiObjects); err != nil {
+			return err
+		}
+	}
+
+	if a.RecordParams && !a.DryRun {
+		a.params, err = a.envParamsFn(a.App, a.EnvName)
+		if err != nil {
+			return errors.Wrap(err, "find params")
+		}
+
+		a.params = redactParams(a.params, apiObjects)
+		a.appliedAt = time.Now()
+		a.recordedParams = sets.NewString()
+	}
+
+	sort.Stable(applyOrder(apiObjects))
+	a.crdGroups = crdGroups(apiObjects)
+
+	if a.CreateNamespace {
+		if err = a.ensureNamespaces(apiObjects); err != nil {
+			return errors.Wrap(err, "ensure namespaces")
+		}
+	}
 
 	seenUids := sets.NewString()
 
@@ -141,6 +232,12 @@ func (a *Apply) Apply() error {
 			return errors.Wrap(err, "handle object")
 		}
 
+		if isCRD(obj) && !a.DryRun {
+			if err = a.waitForCRD(obj); err != nil {
+				return err
+			}
+		}
+
 		// Some objects appear under multiple kinds
 		// (eg: Deployment is both extensions/v1beta1
 		// and apps/v1beta1).  UID is the only stable
@@ -158,11 +255,185 @@ func (a *Apply) Apply() error {
 	return nil
 }
 
+// convertDeprecated converts objects which use deprecated APIs. Objects which
+// can't be converted are left as they are.
+func (a *Apply) convertDeprecated(objects []*unstructured.Unstructured) {
+	for _, obj := range objects {
+		apiVersion := obj.GetAPIVersion()
+		fields := log.Fields{
+			"kind": obj.GetKind(),
+			"name": utils.FqName(obj),
+		}
+
+		converted, err := deprecation.Convert(obj)
+		if err != nil {
+			log.WithFields(fields).Warnf("unable to convert deprecated API: %v", err)
+			continue
+		}
+
+		if converted {
+			log.WithFields(fields).Infof("converted %s to %s", apiVersion, obj.GetAPIVersion())
+		}
+	}
+}
+
+// checkAPIs checks the APIs of objects against the cluster. Deprecated APIs
+// are logged, and APIs the cluster doesn't serve block the apply.
+func (a *Apply) checkAPIs(objects []*unstructured.Unstructured) error {
+	findings, err := a.checkAPIsFn(*a.clientOpts, objects)
+	if err != nil {
+		return errors.Wrap(err, "check APIs")
+	}
+
+	var blocking []deprecation.Finding
+	for _, f := range findings {
+		if f.Blocking() {
+			blocking = append(blocking, f)
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"kind": f.Kind,
+			"name": f.Name,
+		}).Warn(f.Message)
+	}
+
+	if len(blocking) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err = deprecation.Report(&buf, table.FormatTable, blocking); err != nil {
+		return err
+	}
+
+	return errors.Errorf("%d object(s) use APIs the cluster doesn't serve; update them or use --convert-deprecated:\n%s",
+		len(blocking), buf.String())
+}
+
+// checkPolicies checks objects against the app's policies. Warnings are
+// logged, and errors block the apply.
+func (a *Apply) checkPolicies(objects []*unstructured.Unstructured) error {
+	violations, err := a.checkPoliciesFn(a.App, a.EnvName, objects)
+	if err != nil {
+		return errors.Wrap(err, "check policies")
+	}
+
+	var blocking []policy.Violation
+	for _, v := range violations {
+		if v.Severity == policy.SeverityError {
+			blocking = append(blocking, v)
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"policy": v.Policy,
+			"kind":   v.Kind,
+			"name":   v.Name,
+		}).Warn(v.Message)
+	}
+
+	if len(blocking) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err = policy.Report(&buf, table.FormatTable, blocking); err != nil {
+		return err
+	}
+
+	return errors.Errorf("%d policy violation(s) block apply; fix them or use --skip-policies:\n%s",
+		len(blocking), buf.String())
+}
+
+// ensureNamespaces creates the namespaces objects will be created in if they
+// don't exist.
+func (a *Apply) ensureNamespaces(objects []*unstructured.Unstructured) error {
+	for _, name := range namespacesFor(objects, a.clientOpts.namespace) {
+		if err := a.ensureNamespace(name); err != nil {
+			return errors.Wrapf(err, "namespace %s", name)
+		}
+	}
+
+	return nil
+}
+
+func (a *Apply) ensureNamespace(name string) error {
+	rc, err := a.resourceClientFactory(*a.clientOpts, newNamespace(name))
+	if err != nil {
+		return err
+	}
+
+	_, err = rc.Get(metav1.GetOptions{})
+	if err == nil {
+		return nil
+	} else if !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	log.Info("Creating namespace ", name, a.dryRunText())
+	if a.DryRun {
+		return nil
+	}
+
+	if _, err = rc.Create(); err != nil && !kerrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return nil
+}
+
+// waitForCRD waits until a CustomResourceDefinition is established, then
+// refreshes discovery so its custom resources can be applied.
+func (a *Apply) waitForCRD(obj *unstructured.Unstructured) error {
+	rc, err := a.resourceClientFactory(*a.clientOpts, obj)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Waiting for CustomResourceDefinition ", obj.GetName(), " to be established")
+	err = wait.PollImmediate(a.pollInterval, a.crdTimeout, func() (bool, error) {
+		crd, err := rc.Get(metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return crdEstablished(crd), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("CustomResourceDefinition %s was not established within %s", obj.GetName(), a.crdTimeout)
+	} else if err != nil {
+		return errors.Wrapf(err, "waiting for CustomResourceDefinition %s", obj.GetName())
+	}
+
+	refreshDiscovery(*a.clientOpts)
+	return nil
+}
+
 func (a *Apply) handleObject(obj *unstructured.Unstructured) (string, error) {
 	if err := a.preprocessObject(obj); err != nil {
 		return "", errors.Wrap(err, "preprocessing object before apply")
 	}
 
+	// The kind of an object may not be discoverable right after the
+	// CustomResourceDefinition for it is created, so objects in groups
+	// defined by the applied CustomResourceDefinitions are retried after
+	// discovery is refreshed.
+	retry := a.crdGroups.Has(obj.GroupVersionKind().Group)
+	for i := 1; ; i++ {
+		uid, err := a.upsertFromCluster(obj)
+		if err == nil || !retry || !isUndiscoverable(err) || i == discoveryRetryCount {
+			return uid, err
+		}
+
+		log.Debugf("%s %s can't be discovered yet; refreshing discovery", obj.GetKind(), obj.GetName())
+		refreshDiscovery(*a.clientOpts)
+		time.Sleep(a.pollInterval)
+	}
+}
+
+// upsertFromCluster merges an object with its state in the cluster and
+// upserts the result.
+func (a *Apply) upsertFromCluster(obj *unstructured.Unstructured) (string, error) {
 	mergedObject, err := a.patchFromCluster(obj)
 	if err != nil {
 		return "", errors.Wrap(err, "patching object from cluster")
@@ -173,11 +444,33 @@ func (a *Apply) handleObject(obj *unstructured.Unstructured) (string, error) {
 	return a.upsert(mergedObject)
 }
 
+// recordParams records the params of an object's component if they haven't
+// been recorded on another of its objects. Params are recorded after the
+// pristine copy so they do not show up when the object is rebuilt from the
+// cluster.
+func (a *Apply) recordParams(obj *unstructured.Unstructured) error {
+	if !a.RecordParams {
+		return nil
+	}
+
+	componentName := obj.GetLabels()[metadata.LabelComponent]
+	if componentName == "" || a.recordedParams.Has(componentName) {
+		return nil
+	}
+
+	a.recordedParams.Insert(componentName)
+	return setParamsAnnotation(obj, a.EnvName, a.appliedAt, a.params)
+}
+
 // preprocessObject preprocesses an object for it is applied to the cluster.
 func (a *Apply) preprocessObject(obj *unstructured.Unstructured) error {
 	aa := newDefaultAnnotationApplier()
 	if !a.DryRun {
-		return errors.Wrap(aa.SetOriginalConfiguration(obj), "tagging ksonnet managed object")
+		if err := aa.SetOriginalConfiguration(obj); err != nil {
+			return errors.Wrap(err, "tagging ksonnet managed object")
+		}
+
+		return errors.Wrap(a.recordParams(obj), "recording component params")
 	}
 
 	log.Info("tagging ksonnet managed object", a.dryRunText())
@@ -243,6 +536,11 @@ func (a *Apply) runGc(seenUids sets.String) error {
 		return err
 	}
 
+	collectible, err := a.gcCollectible(seenUids)
+	if err != nil {
+		return err
+	}
+
 	err = walkObjects(*co, metav1.ListOptions{}, func(o runtime.Object) error {
 		var metav1Object metav1.Object
 		metav1Object, err = meta.Accessor(o)
@@ -253,7 +551,7 @@ func (a *Apply) runGc(seenUids sets.String) error {
 		desc := fmt.Sprintf("%s %s (%s)",
 			utils.ResourceNameFor(co.discovery, o), utils.FqName(metav1Object), gvk.GroupVersion())
 		log.Debugf("Considering %v for gc", desc)
-		if eligibleForGc(metav1Object, a.GcTag) && !seenUids.Has(string(metav1Object.GetUID())) {
+		if collectible(gvk.Kind, metav1Object) {
 			log.Info("Garbage collecting ", desc, a.dryRunText())
 			if !a.DryRun {
 				err = gcDelete(*co, a.resourceClientFactory, &version, o)
@@ -271,6 +569,27 @@ func (a *Apply) runGc(seenUids sets.String) error {
 	return nil
 }
 
+// gcCollectible returns a function which reports whether an object in the
+// cluster is garbage. Only objects which could have been applied are
+// garbage, so applying part of an app doesn't delete the rest of it.
+func (a *Apply) gcCollectible(seenUids sets.String) (func(kind string, obj metav1.Object) bool, error) {
+	scope := a.Filter
+	if len(scope.Components) == 0 {
+		scope.Components = a.ComponentNames
+	}
+
+	inScope, err := scope.Matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(kind string, obj metav1.Object) bool {
+		return eligibleForGc(obj, a.GcTag) &&
+			inScope(kind, obj.GetName(), obj.GetLabels()) &&
+			!seenUids.Has(string(obj.GetUID()))
+	}, nil
+}
+
 func (a *Apply) dryRunText() string {
 	text := ""
 	if a.DryRun {
@@ -16,22 +16,34 @@
 package cluster
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/deprecation"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/policy"
 	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/ksonnet/ksonnet/utils"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type conflictError struct{}
@@ -56,10 +68,15 @@ func Test_Apply(t *testing.T) {
 			ClientConfig: &client.Config{},
 		}
 
+		a.On("Policies").Return(app.PolicyConfigs{}, nil)
+
 		setupApp := func(apply *Apply) {
 			obj := &unstructured.Unstructured{Object: genObject()}
 
 			apply.clientOpts = &Clients{}
+			apply.envParamsFn = func(a app.App, envName string) (componentParams, error) {
+				return componentParams{}, nil
+			}
 
 			apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
 				objects := []*unstructured.Unstructured{obj}
@@ -85,6 +102,78 @@ func Test_Apply(t *testing.T) {
 	})
 }
 
+func Test_Apply_record_params(t *testing.T) {
+	for _, recordParams := range []bool{false, true} {
+		t.Run(fmt.Sprintf("record params %t", recordParams), func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+				applyConfig := ApplyConfig{
+					App:          a,
+					ClientConfig: &client.Config{},
+					EnvName:      "default",
+					RecordParams: recordParams,
+				}
+
+				a.On("Policies").Return(app.PolicyConfigs{}, nil)
+
+				newObject := func(kind, name string) *unstructured.Unstructured {
+					obj := &unstructured.Unstructured{Object: genObject()}
+					obj.SetKind(kind)
+					obj.SetName(name)
+					SetMetaDataLabel(obj, metadata.LabelComponent, "guestbook")
+					return obj
+				}
+
+				service := newObject("Service", "guestbook")
+				deployment := newObject("Deployment", "guestbook")
+
+				setupApp := func(apply *Apply) {
+					apply.clientOpts = &Clients{}
+					apply.envParamsFn = func(a app.App, envName string) (componentParams, error) {
+						return componentParams{"guestbook": {"replicas": "3", "password": `"hunter2"`}}, nil
+					}
+
+					apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+						return []*unstructured.Unstructured{deployment, service}, nil
+					}
+
+					apply.ksonnetObjectFactory = func() ksonnetObject {
+						return mergeFromClusterFn(func(o *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+							return o, nil
+						})
+					}
+
+					apply.upserterFactory = func() Upserter {
+						return &fakeUpserter{
+							upsertID: "12345",
+						}
+					}
+				}
+
+				require.NoError(t, RunApply(applyConfig, setupApp))
+
+				_, ok := deployment.GetAnnotations()[metadata.AnnotationParams]
+				assert.False(t, ok, "params should be recorded on one object per component")
+
+				_, ok = service.GetAnnotations()[metadata.AnnotationParams]
+				require.Equal(t, recordParams, ok)
+
+				if !recordParams {
+					return
+				}
+
+				params, err := paramsFromObjects([]*unstructured.Unstructured{service, deployment}, "default")
+				require.NoError(t, err)
+
+				expected := []component.ModuleParameter{
+					{Component: "guestbook", Key: "password", Value: redactedValue},
+					{Component: "guestbook", Key: "replicas", Value: "3"},
+				}
+				require.Equal(t, expected, params)
+			})
+		})
+	}
+}
+
 func Test_Apply_dry_run(t *testing.T) {
 	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
 		applyConfig := ApplyConfig{
@@ -93,10 +182,15 @@ func Test_Apply_dry_run(t *testing.T) {
 			DryRun:       true,
 		}
 
+		a.On("Policies").Return(app.PolicyConfigs{}, nil)
+
 		setupApp := func(apply *Apply) {
 			obj := &unstructured.Unstructured{Object: genObject()}
 
 			apply.clientOpts = &Clients{}
+			apply.envParamsFn = func(a app.App, envName string) (componentParams, error) {
+				return componentParams{}, nil
+			}
 
 			apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
 				objects := []*unstructured.Unstructured{obj}
@@ -122,6 +216,43 @@ func Test_Apply_dry_run(t *testing.T) {
 	})
 }
 
+func Test_Apply_policy_violation(t *testing.T) {
+	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		applyConfig := ApplyConfig{
+			App:          a,
+			ClientConfig: &client.Config{},
+			EnvName:      "default",
+		}
+
+		obj := &unstructured.Unstructured{Object: genObject()}
+
+		setupApp := func(apply *Apply) {
+			apply.clientOpts = &Clients{}
+			apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+				return []*unstructured.Unstructured{obj}, nil
+			}
+
+			apply.checkPoliciesFn = func(a app.App, envName string, objects []*unstructured.Unstructured) ([]policy.Violation, error) {
+				require.Equal(t, "default", envName)
+				return []policy.Violation{
+					{Policy: "no-latest-image", Severity: policy.SeverityError, Message: "uses latest"},
+					{Policy: "require-resource-limits", Severity: policy.SeverityWarning, Message: "no limits"},
+				}, nil
+			}
+
+			apply.upserterFactory = func() Upserter {
+				return &fakeUpserter{
+					upsertErr: errors.New("upsert should not run"),
+				}
+			}
+		}
+
+		err := RunApply(applyConfig, setupApp)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "1 policy violation(s) block apply")
+	})
+}
+
 func Test_Apply_retry_on_conflict(t *testing.T) {
 	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
 		applyConfig := ApplyConfig{
@@ -129,10 +260,15 @@ func Test_Apply_retry_on_conflict(t *testing.T) {
 			ClientConfig: &client.Config{},
 		}
 
+		a.On("Policies").Return(app.PolicyConfigs{}, nil)
+
 		setupApp := func(apply *Apply) {
 			obj := &unstructured.Unstructured{Object: genObject()}
 
 			apply.clientOpts = &Clients{}
+			apply.envParamsFn = func(a app.App, envName string) (componentParams, error) {
+				return componentParams{}, nil
+			}
 			apply.resourceClientFactory = func(opts Clients, object runtime.Object) (ResourceClient, error) {
 				rc := &mocks.ResourceClient{}
 				rc.On("Get", mock.Anything).Return(obj, nil)
@@ -166,6 +302,341 @@ func Test_Apply_retry_on_conflict(t *testing.T) {
 	})
 }
 
+func Test_Apply_create_namespace(t *testing.T) {
+	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		applyConfig := ApplyConfig{
+			App:             a,
+			ClientConfig:    &client.Config{},
+			CreateNamespace: true,
+		}
+
+		a.On("Policies").Return(app.PolicyConfigs{}, nil)
+
+		obj := &unstructured.Unstructured{Object: genObject()}
+		obj.SetNamespace("web")
+
+		var created []string
+
+		setupApp := func(apply *Apply) {
+			apply.clientOpts = &Clients{namespace: "default"}
+			apply.envParamsFn = func(a app.App, envName string) (componentParams, error) {
+				return componentParams{}, nil
+			}
+			apply.resourceClientFactory = func(opts Clients, object runtime.Object) (ResourceClient, error) {
+				ns := object.(*unstructured.Unstructured)
+				require.Equal(t, "Namespace", ns.GetKind())
+
+				rc := &mocks.ResourceClient{}
+				if ns.GetName() == "default" {
+					rc.On("Get", mock.Anything).Return(ns, nil)
+					return rc, nil
+				}
+
+				rc.On("Get", mock.Anything).Return(nil, kerrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, ns.GetName()))
+				rc.On("Create").Return(ns, nil).Run(func(mock.Arguments) {
+					created = append(created, ns.GetName())
+				})
+				return rc, nil
+			}
+
+			apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+				return []*unstructured.Unstructured{obj}, nil
+			}
+
+			apply.ksonnetObjectFactory = func() ksonnetObject {
+				return &fakeKsonnetObject{
+					obj: obj,
+				}
+			}
+
+			apply.upserterFactory = func() Upserter {
+				return &fakeUpserter{
+					upsertID: "12345",
+				}
+			}
+		}
+
+		err := RunApply(applyConfig, setupApp)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"web"}, created)
+	})
+}
+
+func Test_Apply_wait_for_crd(t *testing.T) {
+	crdStatus := func(established string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+					map[string]interface{}{"type": "Established", "status": established},
+				},
+			},
+		}}
+	}
+
+	cases := []struct {
+		name     string
+		statuses []*unstructured.Unstructured
+		isErr    bool
+	}{
+		{
+			name:     "established",
+			statuses: []*unstructured.Unstructured{crdStatus("False"), crdStatus("True")},
+		},
+		{
+			name:     "not established",
+			statuses: []*unstructured.Unstructured{crdStatus("False")},
+			isErr:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+				applyConfig := ApplyConfig{
+					App:          a,
+					ClientConfig: &client.Config{},
+				}
+
+				a.On("Policies").Return(app.PolicyConfigs{}, nil)
+
+				crd := makeUnstructuredName("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "widgets.example.com")
+
+				setupApp := func(apply *Apply) {
+					apply.clientOpts = &Clients{}
+					apply.envParamsFn = func(a app.App, envName string) (componentParams, error) {
+						return componentParams{}, nil
+					}
+
+					rc := &mocks.ResourceClient{}
+					for i, status := range tc.statuses {
+						call := rc.On("Get", mock.Anything).Return(status, nil)
+						if i < len(tc.statuses)-1 {
+							call.Once()
+						}
+					}
+					apply.resourceClientFactory = func(opts Clients, object runtime.Object) (ResourceClient, error) {
+						return rc, nil
+					}
+
+					apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+						return []*unstructured.Unstructured{crd}, nil
+					}
+
+					apply.ksonnetObjectFactory = func() ksonnetObject {
+						return &fakeKsonnetObject{
+							obj: crd,
+						}
+					}
+
+					apply.upserterFactory = func() Upserter {
+						return &fakeUpserter{
+							upsertID: "12345",
+						}
+					}
+
+					apply.pollInterval = time.Millisecond
+					apply.crdTimeout = 20 * time.Millisecond
+				}
+
+				err := RunApply(applyConfig, setupApp)
+				if tc.isErr {
+					require.Error(t, err)
+					require.Contains(t, err.Error(), "was not established")
+					return
+				}
+
+				require.NoError(t, err)
+			})
+		})
+	}
+}
+
+func Test_Apply_retry_undiscoverable(t *testing.T) {
+	crd := makeUnstructuredName("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "widgets.example.com")
+	crd.Object["spec"] = map[string]interface{}{"group": "example.com"}
+	crd.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Established", "status": "True"},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		objects  []*unstructured.Unstructured
+		attempts int
+		isErr    bool
+	}{
+		{
+			name:     "group defined by a CRD being applied",
+			objects:  []*unstructured.Unstructured{crd},
+			attempts: 3,
+		},
+		{
+			name:     "group not defined by a CRD being applied",
+			attempts: 1,
+			isErr:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+				applyConfig := ApplyConfig{
+					App:          a,
+					ClientConfig: &client.Config{},
+				}
+
+				a.On("Policies").Return(app.PolicyConfigs{}, nil)
+
+				obj := makeUnstructuredNS("example.com/v1", "Widget", "default")
+
+				attempts := 0
+
+				setupApp := func(apply *Apply) {
+					apply.clientOpts = &Clients{}
+					apply.envParamsFn = func(a app.App, envName string) (componentParams, error) {
+						return componentParams{}, nil
+					}
+
+					apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+						return append([]*unstructured.Unstructured{obj}, tc.objects...), nil
+					}
+
+					rc := &mocks.ResourceClient{}
+					rc.On("Get", mock.Anything).Return(crd, nil)
+					apply.resourceClientFactory = func(opts Clients, object runtime.Object) (ResourceClient, error) {
+						return rc, nil
+					}
+
+					apply.ksonnetObjectFactory = func() ksonnetObject {
+						return mergeFromClusterFn(func(o *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+							if o.GetKind() != obj.GetKind() {
+								return o, nil
+							}
+
+							attempts++
+							if attempts < 3 {
+								return nil, errors.Wrap(&utils.UnknownKindError{GroupVersionKind: obj.GroupVersionKind()}, "merging")
+							}
+
+							return o, nil
+						})
+					}
+
+					apply.upserterFactory = func() Upserter {
+						return &fakeUpserter{
+							upsertID: "12345",
+						}
+					}
+
+					apply.pollInterval = 0
+				}
+
+				err := RunApply(applyConfig, setupApp)
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				require.Equal(t, tc.attempts, attempts)
+			})
+		})
+	}
+}
+
+// mergeFromClusterFn is a ksonnetObject which merges objects with a function.
+type mergeFromClusterFn func(obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+
+func (fn mergeFromClusterFn) MergeFromCluster(co Clients, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return fn(obj)
+}
+
+func Test_Apply_check_apis(t *testing.T) {
+	cases := []struct {
+		name              string
+		convertDeprecated bool
+		isErr             bool
+	}{
+		{
+			name:  "unserved API",
+			isErr: true,
+		},
+		{
+			name:              "converted",
+			convertDeprecated: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+				applyConfig := ApplyConfig{
+					App:               a,
+					CheckAPIs:         true,
+					ClientConfig:      &client.Config{},
+					ConvertDeprecated: tc.convertDeprecated,
+				}
+
+				a.On("Policies").Return(app.PolicyConfigs{}, nil)
+
+				obj := &unstructured.Unstructured{Object: genObject()}
+				obj.SetAPIVersion("extensions/v1beta1")
+
+				setupApp := func(apply *Apply) {
+					apply.clientOpts = &Clients{}
+					apply.envParamsFn = func(a app.App, envName string) (componentParams, error) {
+						return componentParams{}, nil
+					}
+
+					apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+						return []*unstructured.Unstructured{obj}, nil
+					}
+
+					apply.checkAPIsFn = func(co Clients, objects []*unstructured.Unstructured) ([]deprecation.Finding, error) {
+						var findings []deprecation.Finding
+						for _, o := range objects {
+							if o.GetAPIVersion() == "extensions/v1beta1" {
+								findings = append(findings, deprecation.Finding{
+									Status:  deprecation.StatusUnserved,
+									Kind:    o.GetKind(),
+									Name:    o.GetName(),
+									Message: "extensions/v1beta1 Deployment is not served by the cluster; use apps/v1",
+								})
+							}
+						}
+						return findings, nil
+					}
+
+					apply.ksonnetObjectFactory = func() ksonnetObject {
+						return &fakeKsonnetObject{
+							obj: obj,
+						}
+					}
+
+					apply.upserterFactory = func() Upserter {
+						return &fakeUpserter{
+							upsertID: "12345",
+						}
+					}
+				}
+
+				err := RunApply(applyConfig, setupApp)
+				if tc.isErr {
+					require.Error(t, err)
+					require.Contains(t, err.Error(), "1 object(s) use APIs the cluster doesn't serve")
+					return
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, "apps/v1", obj.GetAPIVersion())
+			})
+		})
+	}
+}
+
 func genObject() map[string]interface{} {
 	return map[string]interface{}{
 		"apiVersion": "apps/v1beta1",
@@ -201,3 +672,78 @@ func genObject() map[string]interface{} {
 		},
 	}
 }
+
+func Test_Apply_gcCollectible(t *testing.T) {
+	object := func(uid, component, kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		u.SetUID(types.UID(uid))
+		u.SetKind(kind)
+		u.SetName(name)
+		u.SetLabels(map[string]string{metadata.LabelComponent: component})
+		u.SetAnnotations(map[string]string{metadata.AnnotationGcTag: "tag"})
+		return u
+	}
+
+	objects := []*unstructured.Unstructured{
+		object("1", "guestbook", "Deployment", "guestbook-ui"),
+		object("2", "guestbook", "Service", "guestbook-ui"),
+		object("3", "redis", "Deployment", "redis"),
+		object("4", "redis", "ConfigMap", "redis-config"),
+	}
+
+	cases := []struct {
+		name           string
+		componentNames []string
+		filter         pipeline.ObjectFilter
+		expected       []string
+	}{
+		{
+			name:     "whole app",
+			expected: []string{"2", "3", "4"},
+		},
+		{
+			name:           "components",
+			componentNames: []string{"guestbook"},
+			expected:       []string{"2"},
+		},
+		{
+			name:     "excluded components",
+			filter:   pipeline.ObjectFilter{ExcludeComponents: []string{"redis"}},
+			expected: []string{"2"},
+		},
+		{
+			name:     "kinds",
+			filter:   pipeline.ObjectFilter{Kinds: []string{"ConfigMap"}},
+			expected: []string{"4"},
+		},
+		{
+			name:     "names",
+			filter:   pipeline.ObjectFilter{Names: []string{"guestbook-*"}},
+			expected: []string{"2"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &Apply{
+				ApplyConfig: ApplyConfig{
+					ComponentNames: tc.componentNames,
+					Filter:         tc.filter,
+					GcTag:          "tag",
+				},
+			}
+
+			collectible, err := a.gcCollectible(sets.NewString("1"))
+			require.NoError(t, err)
+
+			var got []string
+			for _, obj := range objects {
+				if collectible(obj.GetKind(), obj) {
+					got = append(got, string(obj.GetUID()))
+				}
+			}
+
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
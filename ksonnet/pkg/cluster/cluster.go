@@ -20,8 +20,11 @@ import (
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/deprecation"
 	"github.com/ksonnet/ksonnet/pkg/metadata"
 	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/policy"
+	"github.com/ksonnet/ksonnet/pkg/util/dockerregistry"
 	"github.com/ksonnet/ksonnet/utils"
 	log "github.com/sirupsen/logrus"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -48,11 +51,58 @@ type validateObjectFn func(d discovery.DiscoveryInterface,
 type findObjectsFn func(a app.App, envName string,
 	componentNames []string) ([]*unstructured.Unstructured, error)
 
+type checkPoliciesFn func(a app.App, envName string,
+	objects []*unstructured.Unstructured) ([]policy.Violation, error)
+
+type checkAPIsFn func(co Clients, objects []*unstructured.Unstructured) ([]deprecation.Finding, error)
+
 func findObjects(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
 	p := pipeline.New(a, envName)
 	return p.Objects(componentNames)
 }
 
+// resolvedObjectFinder returns a findObjectsFn which pins the images of
+// objects to digests. If platform is set, multi-platform images are pinned to
+// the digest of the image for the platform.
+func resolvedObjectFinder(platform string) findObjectsFn {
+	return func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+		var opts []dockerregistry.DigesterOpt
+		if platform != "" {
+			p, err := dockerregistry.ParsePlatform(platform)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, dockerregistry.WithPlatform(p))
+		}
+
+		p := pipeline.New(a, envName, pipeline.ResolveImages(dockerregistry.NewDefaultDigester(opts...)))
+		return p.Objects(componentNames)
+	}
+}
+
+// objectFinder returns the findObjectsFn for a configuration.
+func objectFinder(resolveImages bool, imagePlatform string) findObjectsFn {
+	if resolveImages {
+		return resolvedObjectFinder(imagePlatform)
+	}
+
+	return findObjects
+}
+
+func checkPolicies(a app.App, envName string, objects []*unstructured.Unstructured) ([]policy.Violation, error) {
+	return policy.New(a, envName).Check(objects)
+}
+
+// checkAPIs checks the APIs of objects against the cluster.
+func checkAPIs(co Clients, objects []*unstructured.Unstructured) ([]deprecation.Finding, error) {
+	checker, err := deprecation.ForCluster(co.discovery)
+	if err != nil {
+		return nil, err
+	}
+
+	return checker.Check(objects)
+}
+
 func stringListContains(list []string, value string) bool {
 	for _, item := range list {
 		if item == value {
@@ -0,0 +1,114 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var (
+	crdGVK = schema.GroupVersionKind{
+		Group:   "apiextensions.k8s.io",
+		Version: "v1beta1",
+		Kind:    "CustomResourceDefinition",
+	}
+)
+
+// isCRD reports whether an object is a CustomResourceDefinition.
+func isCRD(obj *unstructured.Unstructured) bool {
+	return obj.GroupVersionKind().GroupKind() == crdGVK.GroupKind()
+}
+
+// crdGroups returns the groups defined by the CustomResourceDefinitions in
+// objects.
+func crdGroups(objects []*unstructured.Unstructured) sets.String {
+	groups := sets.NewString()
+	for _, obj := range objects {
+		if !isCRD(obj) {
+			continue
+		}
+
+		if group, _, _ := unstructured.NestedString(obj.Object, "spec", "group"); group != "" {
+			groups.Insert(group)
+		}
+	}
+
+	return groups
+}
+
+// crdEstablished reports whether a CustomResourceDefinition has been
+// established, i.e. its custom resources are served.
+func crdEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CollectCRDs fetches the CustomResourceDefinitions in a cluster.
+func CollectCRDs(clients Clients) ([]*unstructured.Unstructured, error) {
+	if clients.clientPool == nil {
+		return nil, errors.New("nil client pool")
+	}
+
+	dynamic, err := clients.clientPool.ClientForGroupVersionKind(crdGVK)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating client for resource: %s", crdGVK.String())
+	}
+
+	resource := &metav1.APIResource{
+		Name:       "customresourcedefinitions",
+		Kind:       crdGVK.Kind,
+		Namespaced: false,
+	}
+
+	obj, err := dynamic.Resource(resource, "").List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing CustomResourceDefinitions")
+	}
+
+	ul, ok := obj.(*unstructured.UnstructuredList)
+	if !ok {
+		return nil, errors.Errorf("unexpected list type %T", obj)
+	}
+
+	var results []*unstructured.Unstructured
+	err = ul.EachListItem(func(o runtime.Object) error {
+		if u, ok := o.(*unstructured.Unstructured); ok {
+			results = append(results, u)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "iterating CustomResourceDefinitions")
+	}
+
+	return results, nil
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/ksonnet/ksonnet/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -34,7 +35,9 @@ type DeleteConfig struct {
 	ClientConfig   *client.Config
 	ComponentNames []string
 	EnvName        string
-	GracePeriod    int64
+	// Filter selects which of the rendered objects are deleted.
+	Filter      pipeline.ObjectFilter
+	GracePeriod int64
 }
 
 // DeleteOpts is an option for configuring Delete.
@@ -75,6 +78,10 @@ func (d *Delete) Delete() error {
 		return errors.Wrap(err, "find objects")
 	}
 
+	if apiObjects, err = d.Filter.Filter(apiObjects); err != nil {
+		return errors.Wrap(err, "filter objects")
+	}
+
 	co, err := d.genClientOptsFn(d.App, d.ClientConfig, d.EnvName)
 	if err != nil {
 		return err
@@ -84,7 +91,7 @@ func (d *Delete) Delete() error {
 	if err != nil {
 		return err
 	}
-	sort.Sort(sort.Reverse(utils.DependencyOrder(apiObjects)))
+	sort.Stable(sort.Reverse(applyOrder(apiObjects)))
 
 	deleteOpts := metav1.DeleteOptions{}
 	if version.Compare(1, 6) < 0 {
@@ -18,10 +18,44 @@ package cluster
 import (
 	"sort"
 
+	"github.com/ksonnet/ksonnet/utils"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/discovery"
 )
 
+// refreshDiscovery drops cached discovery information, so kinds which were
+// added to the cluster after it was cached can be found.
+func refreshDiscovery(co Clients) {
+	if cached, ok := co.discovery.(discovery.CachedDiscoveryInterface); ok {
+		cached.Invalidate()
+	}
+}
+
+// isUndiscoverable reports whether an error was caused by a kind the cluster
+// doesn't serve (yet).
+func isUndiscoverable(err error) bool {
+	cause := errors.Cause(err)
+
+	if agg, ok := cause.(utilerrors.Aggregate); ok {
+		for _, e := range agg.Errors() {
+			if isUndiscoverable(e) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if _, ok := cause.(*utils.UnknownKindError); ok {
+		return true
+	}
+
+	return meta.IsNoMatchError(cause)
+}
+
 // sortResources sources resources by moving extensions to the end of the slice. The order of all
 // the other resources is preserved.
 func sortResources(resources []*metav1.APIResourceList) {
@@ -0,0 +1,651 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/params"
+	jsonnetutil "github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// reTopLevelKey matches a key in a YAML or JSON document. The indentation
+	// is captured so top-level keys can be told apart from nested ones.
+	reTopLevelKey = regexp.MustCompile(`^(\s*)"?([^"\s:#-][^"\s:]*)"?\s*:`)
+)
+
+// Explanation describes where the top-level fields of a rendered object and
+// the params of its component came from.
+type Explanation struct {
+	Object    string        `json:"object"`
+	Component string        `json:"component"`
+	Source    string        `json:"source"`
+	Fields    []FieldSource `json:"fields"`
+	Params    []ParamSource `json:"params"`
+}
+
+// FieldSource is the source location which produced a top-level field of an
+// object, and the params it uses. Locations are found by reading the source,
+// not by tracing its evaluation. Guessed is set when the field could not be
+// matched to the expression which set it, e.g. the object is built with
+// k.libsonnet, and Location is only the closest expression found.
+type FieldSource struct {
+	Path     string   `json:"path"`
+	Location string   `json:"location"`
+	Params   []string `json:"params,omitempty"`
+	Guessed  bool     `json:"guessed,omitempty"`
+}
+
+// ParamSource is a param value which fed into a component. Default is the
+// value set in the component's module when an environment overrides it.
+type ParamSource struct {
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	Location   string `json:"location,omitempty"`
+	Overridden bool   `json:"overridden"`
+	Default    string `json:"default,omitempty"`
+}
+
+// componentSource is the source of a component and of its params.
+type componentSource struct {
+	name string
+	path string
+	data []byte
+
+	// params are the params of the component resolved for the environment.
+	params []component.ModuleParameter
+	// defaults are the params of the component set in its module.
+	defaults []component.ModuleParameter
+	// paramLocations are the locations of params in the module params.
+	paramLocations map[string]ast.LocationRange
+	// overrideLocations are the locations of params in the environment
+	// params.
+	overrideLocations map[string]ast.LocationRange
+}
+
+type componentSourceFn func(a app.App, envName, componentName string) (*componentSource, error)
+
+// loadComponentSource loads the source of a component and its params in an
+// environment. Paths are relative to the root of the app.
+func loadComponentSource(a app.App, envName, componentName string) (*componentSource, error) {
+	moduleName, localName := "/", componentName
+	if i := strings.LastIndex(componentName, "."); i >= 0 {
+		moduleName, localName = componentName[:i], componentName[i+1:]
+	}
+
+	m, err := component.GetModule(a, moduleName)
+	if err != nil {
+		return nil, err
+	}
+
+	componentPath := localName
+	if moduleName != "/" {
+		componentPath = filepath.Join(strings.Replace(moduleName, ".", string(filepath.Separator), -1), localName)
+	}
+
+	c, err := component.ExtractComponent(a, componentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := component.Path(a, componentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	relPath := func(path string) string {
+		if rel, err := filepath.Rel(a.Root(), path); err == nil {
+			return rel
+		}
+		return path
+	}
+
+	cs := &componentSource{
+		name: componentName,
+		path: relPath(source),
+	}
+
+	if cs.data, err = afero.ReadFile(a.Fs(), source); err != nil {
+		return nil, err
+	}
+
+	if cs.params, err = c.Params(envName); err != nil {
+		return nil, errors.Wrapf(err, "loading params for %q", componentName)
+	}
+
+	if cs.defaults, err = c.Params(""); err != nil {
+		return nil, errors.Wrapf(err, "loading params for %q", componentName)
+	}
+
+	moduleParams, err := afero.ReadFile(a.Fs(), m.ParamsPath())
+	if err != nil {
+		return nil, err
+	}
+
+	cs.paramLocations, err = params.Locate(relPath(m.ParamsPath()), string(moduleParams), localName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "locating params for %q", componentName)
+	}
+
+	envParams, err := a.EnvironmentParams(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	envParamsPath := filepath.Join(app.EnvironmentDirName, envName, "params.libsonnet")
+	cs.overrideLocations, err = params.Locate(envParamsPath, envParams, componentName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "locating environment params for %q", componentName)
+	}
+
+	return cs, nil
+}
+
+// findExplained finds the object described by a <kind>/<name> reference.
+// Kinds are matched case-insensitively.
+func findExplained(objects []*unstructured.Unstructured, ref string) (*unstructured.Unstructured, error) {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.Errorf("%q is not in the form <kind>/<name>", ref)
+	}
+
+	var matches []*unstructured.Unstructured
+	for _, obj := range objects {
+		if strings.EqualFold(obj.GetKind(), parts[0]) && obj.GetName() == parts[1] {
+			matches = append(matches, obj)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, errors.Errorf("no object matches %q", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, errors.Errorf("%d objects match %q", len(matches), ref)
+	}
+}
+
+// explain explains where the fields of a rendered object came from.
+func (s *Show) explain(objects []*unstructured.Unstructured) error {
+	obj, err := findExplained(objects, s.Explain)
+	if err != nil {
+		return err
+	}
+
+	componentName := obj.GetLabels()[metadata.LabelComponent]
+	if componentName == "" {
+		return errors.Errorf("%s was not rendered from a component", s.Explain)
+	}
+
+	cs, err := s.componentSourceFn(s.App, s.EnvName, componentName)
+	if err != nil {
+		return errors.Wrapf(err, "loading component %q", componentName)
+	}
+
+	e, err := explainObject(obj, cs)
+	if err != nil {
+		return err
+	}
+
+	if s.Format == "json" {
+		enc := json.NewEncoder(s.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	}
+
+	return e.print(s.Out)
+}
+
+// explainObject maps the top-level fields of an object to the locations in
+// its component which produced them.
+func explainObject(obj *unstructured.Unstructured, cs *componentSource) (*Explanation, error) {
+	var (
+		locations map[string]ast.LocationRange
+		refs      map[string][]string
+		fallback  ast.LocationRange
+		exact     = true
+		err       error
+	)
+
+	switch filepath.Ext(cs.path) {
+	case ".jsonnet":
+		locations, refs, fallback, exact, err = jsonnetFieldLocations(cs.path, cs.data, obj)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		locations, fallback = documentFieldLocations(cs.path, cs.data)
+		refs = patchedFields(cs.params)
+	}
+
+	e := &Explanation{
+		Object:    fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName()),
+		Component: cs.name,
+		Source:    cs.path,
+		Fields:    make([]FieldSource, 0),
+		Params:    make([]ParamSource, 0),
+	}
+
+	var keys []string
+	for k := range obj.Object {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		loc, ok := locations[k]
+		if !ok {
+			loc = fallback
+		}
+
+		e.Fields = append(e.Fields, FieldSource{
+			Path:     k,
+			Location: loc.String(),
+			Params:   refs[k],
+			Guessed:  !ok || !exact,
+		})
+	}
+
+	defaults := make(map[string]string)
+	for _, p := range cs.defaults {
+		defaults[p.Key] = p.Value
+	}
+
+	for _, p := range cs.params {
+		ps := ParamSource{
+			Key:   p.Key,
+			Value: p.Value,
+		}
+
+		if loc, ok := paramLocation(cs.overrideLocations, p.Key); ok {
+			ps.Location = loc.String()
+			ps.Overridden = true
+			ps.Default = defaults[p.Key]
+		} else if loc, ok := paramLocation(cs.paramLocations, p.Key); ok {
+			ps.Location = loc.String()
+		}
+
+		e.Params = append(e.Params, ps)
+	}
+
+	sort.Slice(e.Params, func(i, j int) bool {
+		return e.Params[i].Key < e.Params[j].Key
+	})
+
+	return e, nil
+}
+
+// paramLocation returns the location of a param. The params of YAML
+// components are paths into the object, so they are found by their first
+// segment when they are set as nested objects.
+func paramLocation(locations map[string]ast.LocationRange, key string) (ast.LocationRange, bool) {
+	if loc, ok := locations[key]; ok {
+		return loc, true
+	}
+
+	loc, ok := locations[strings.SplitN(key, ".", 2)[0]]
+	return loc, ok
+}
+
+// jsonnetFieldLocations finds the object literal in a Jsonnet component
+// which produced an object, and returns the locations of its fields and the
+// params each field references. When there is no matching object literal,
+// e.g. the object is built with k.libsonnet, the locations are guessed from
+// the mixins added to the expression the component evaluates to, and the
+// fallback location is its first operand. exact is false when the locations
+// are guessed.
+func jsonnetFieldLocations(path string, data []byte, obj *unstructured.Unstructured) (locations map[string]ast.LocationRange, refs map[string][]string, fallback ast.LocationRange, exact bool, err error) {
+	node, err := jsonnetutil.ParseNode(path, string(data))
+	if err != nil {
+		return nil, nil, ast.LocationRange{}, false, errors.Wrapf(err, "parse %s", path)
+	}
+
+	locations = make(map[string]ast.LocationRange)
+	refs = make(map[string][]string)
+
+	literal := findObjectLiteral(node, obj.GetKind(), obj.GetName())
+	if literal == nil {
+		body := node
+		for {
+			local, ok := body.(*ast.Local)
+			if !ok {
+				break
+			}
+			body = local.Body
+		}
+
+		operands := plusOperands(body)
+		for _, operand := range operands[1:] {
+			field := mixinField(operand)
+			if field == "" {
+				continue
+			}
+
+			locations[field] = *operand.Loc()
+			refs[field] = mergeKeys(refs[field], paramRefs(operand))
+		}
+
+		return locations, refs, *operands[0].Loc(), false, nil
+	}
+
+	for _, f := range literal.Fields {
+		if f.Kind == ast.ObjectLocal || f.Kind == ast.ObjectAssert || f.Expr2 == nil {
+			continue
+		}
+
+		id, err := jsonnetutil.FieldID(f)
+		if err != nil {
+			continue
+		}
+
+		locations[id] = *f.Expr2.Loc()
+		if keys := paramRefs(f.Expr2); len(keys) > 0 {
+			refs[id] = keys
+		}
+	}
+
+	return locations, refs, *literal.Loc(), true, nil
+}
+
+// plusOperands flattens a chain of `+` expressions into its operands, e.g.
+// `a + b + c` into a, b and c.
+func plusOperands(node ast.Node) []ast.Node {
+	if b, ok := node.(*ast.Binary); ok && b.Op == ast.BopPlus {
+		return append(plusOperands(b.Left), plusOperands(b.Right)...)
+	}
+
+	return []ast.Node{node}
+}
+
+// mixinField returns the top-level field set by a k.libsonnet mixin, e.g.
+// `spec` for `deployment.mixin.spec.withReplicas(2)`.
+func mixinField(node ast.Node) string {
+	apply, ok := node.(*ast.Apply)
+	if !ok {
+		return ""
+	}
+
+	var ids []string
+	for target := apply.Target; ; {
+		index, ok := target.(*ast.Index)
+		if !ok {
+			break
+		}
+
+		if index.Id != nil {
+			ids = append([]string{string(*index.Id)}, ids...)
+		} else if s, ok := index.Index.(*ast.LiteralString); ok {
+			ids = append([]string{s.Value}, ids...)
+		} else {
+			return ""
+		}
+		target = index.Target
+	}
+
+	for i := 0; i < len(ids)-1; i++ {
+		if ids[i] == "mixin" {
+			return ids[i+1]
+		}
+	}
+
+	return ""
+}
+
+// mergeKeys merges two sorted lists of keys.
+func mergeKeys(a, b []string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, k := range append(a, b...) {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// findObjectLiteral finds the object literal with a literal kind which
+// matches an object. Literals with a matching literal name are preferred.
+func findObjectLiteral(node ast.Node, kind, name string) *astext.Object {
+	var byKind, byName *astext.Object
+
+	jsonnetutil.Walk(node, func(n ast.Node) bool {
+		if byName != nil {
+			return false
+		}
+
+		obj, ok := n.(*astext.Object)
+		if !ok {
+			return true
+		}
+
+		if literalField(obj, "kind") != kind {
+			return true
+		}
+
+		if byKind == nil {
+			byKind = obj
+		}
+
+		if f, ok := objectFieldByID(obj, "metadata"); ok {
+			if md, ok := f.Expr2.(*astext.Object); ok && literalField(md, "name") == name {
+				byName = obj
+			}
+		}
+
+		return true
+	})
+
+	if byName != nil {
+		return byName
+	}
+
+	return byKind
+}
+
+// literalField returns the value of an object field if it is a literal
+// string.
+func literalField(obj *astext.Object, id string) string {
+	f, ok := objectFieldByID(obj, id)
+	if !ok {
+		return ""
+	}
+
+	s, ok := f.Expr2.(*ast.LiteralString)
+	if !ok {
+		return ""
+	}
+
+	return s.Value
+}
+
+func objectFieldByID(obj *astext.Object, id string) (*astext.ObjectField, bool) {
+	for i := range obj.Fields {
+		f := &obj.Fields[i]
+		if f.Kind == ast.ObjectLocal || f.Kind == ast.ObjectAssert {
+			continue
+		}
+
+		if fieldID, err := jsonnetutil.FieldID(*f); err == nil && fieldID == id {
+			return f, true
+		}
+	}
+
+	return nil, false
+}
+
+// paramRefs returns the params referenced by an expression through the
+// `params` local, e.g. `params.replicas`.
+func paramRefs(node ast.Node) []string {
+	seen := make(map[string]bool)
+	var keys []string
+
+	jsonnetutil.Walk(node, func(n ast.Node) bool {
+		index, ok := n.(*ast.Index)
+		if !ok {
+			return true
+		}
+
+		v, ok := index.Target.(*ast.Var)
+		if !ok || v.Id != "params" {
+			return true
+		}
+
+		var key string
+		if index.Id != nil {
+			key = string(*index.Id)
+		} else if s, ok := index.Index.(*ast.LiteralString); ok {
+			key = s.Value
+		}
+
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+
+		return true
+	})
+
+	sort.Strings(keys)
+	return keys
+}
+
+// documentFieldLocations returns the lines of the top-level keys in a YAML
+// or JSON component. The fallback location is the start of the file.
+func documentFieldLocations(path string, data []byte) (map[string]ast.LocationRange, ast.LocationRange) {
+	fallback := ast.LocationRange{
+		FileName: path,
+		Begin:    ast.Location{Line: 1, Column: 1},
+		End:      ast.Location{Line: 1, Column: 1},
+	}
+
+	type key struct {
+		indent int
+		loc    ast.LocationRange
+	}
+
+	minIndent := -1
+	keys := make(map[string][]key)
+
+	for i, line := range strings.Split(string(data), "\n") {
+		match := reTopLevelKey.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		indent := len(match[1])
+		if minIndent == -1 || indent < minIndent {
+			minIndent = indent
+		}
+
+		keys[match[2]] = append(keys[match[2]], key{
+			indent: indent,
+			loc: ast.LocationRange{
+				FileName: path,
+				Begin:    ast.Location{Line: i + 1, Column: indent + 1},
+				End:      ast.Location{Line: i + 1, Column: len(line) + 1},
+			},
+		})
+	}
+
+	locations := make(map[string]ast.LocationRange)
+	for name, found := range keys {
+		for _, k := range found {
+			if k.indent == minIndent {
+				locations[name] = k.loc
+				break
+			}
+		}
+	}
+
+	return locations, fallback
+}
+
+// patchedFields maps the top-level fields of a YAML component to the params
+// which patch them.
+func patchedFields(moduleParams []component.ModuleParameter) map[string][]string {
+	refs := make(map[string][]string)
+	for _, p := range moduleParams {
+		field := strings.SplitN(p.Key, ".", 2)[0]
+		refs[field] = append(refs[field], p.Key)
+	}
+
+	for _, keys := range refs {
+		sort.Strings(keys)
+	}
+
+	return refs
+}
+
+// print prints an explanation as tables.
+func (e *Explanation) print(w io.Writer) error {
+	fmt.Fprintf(w, "OBJECT:    %s\n", e.Object)
+	fmt.Fprintf(w, "COMPONENT: %s\n", e.Component)
+	fmt.Fprintf(w, "SOURCE:    %s\n\n", e.Source)
+
+	guessed := false
+	fields := table.New("explainFields", w)
+	fields.SetHeader([]string{"FIELD", "LOCATION", "PARAMS"})
+	for _, f := range e.Fields {
+		location := f.Location
+		if f.Guessed {
+			location += " (guess)"
+			guessed = true
+		}
+		fields.Append([]string{f.Path, location, strings.Join(f.Params, ",")})
+	}
+
+	if err := fields.Render(); err != nil {
+		return err
+	}
+
+	if guessed {
+		fmt.Fprintln(w, "\nLocations marked (guess) could not be traced to the expression which set the field.")
+	}
+
+	if len(e.Params) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+
+	ps := table.New("explainParams", w)
+	ps.SetHeader([]string{"PARAM", "VALUE", "LOCATION", "DEFAULT"})
+	for _, p := range e.Params {
+		ps.Append([]string{p.Key, p.Value, p.Location, p.Default})
+	}
+
+	return ps.Render()
+}
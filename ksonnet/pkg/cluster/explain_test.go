@@ -0,0 +1,347 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func explainTestSource(t *testing.T, name, file string) *componentSource {
+	envParams := ast.LocationRange{
+		FileName: "environments/default/params.libsonnet",
+		Begin:    ast.Location{Line: 7, Column: 17},
+		End:      ast.Location{Line: 7, Column: 18},
+	}
+	moduleParams := ast.LocationRange{
+		FileName: "components/params.libsonnet",
+		Begin:    ast.Location{Line: 5, Column: 17},
+		End:      ast.Location{Line: 5, Column: 18},
+	}
+
+	return &componentSource{
+		name: name,
+		path: filepath.Join("components", file),
+		data: []byte(test.ReadTestData(t, filepath.Join("explain", file))),
+		params: []component.ModuleParameter{
+			{Component: name, Key: "replicas", Value: "3"},
+			{Component: name, Key: "name", Value: `"guestbook-ui"`},
+		},
+		defaults: []component.ModuleParameter{
+			{Component: name, Key: "replicas", Value: "1"},
+			{Component: name, Key: "name", Value: `"guestbook-ui"`},
+		},
+		paramLocations: map[string]ast.LocationRange{
+			"replicas": moduleParams,
+		},
+		overrideLocations: map[string]ast.LocationRange{
+			"replicas": envParams,
+		},
+	}
+}
+
+func explainTestObject(kind, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1beta1",
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+				"labels": map[string]interface{}{
+					"ksonnet.io/component": "guestbook-ui",
+				},
+			},
+			"spec":   map[string]interface{}{},
+			"status": map[string]interface{}{},
+		},
+	}
+}
+
+func Test_explainObject(t *testing.T) {
+	replicas := ParamSource{
+		Key:        "replicas",
+		Value:      "3",
+		Location:   "environments/default/params.libsonnet:7:17-18",
+		Overridden: true,
+		Default:    "1",
+	}
+	name := ParamSource{
+		Key:   "name",
+		Value: `"guestbook-ui"`,
+	}
+
+	cases := []struct {
+		name     string
+		file     string
+		obj      *unstructured.Unstructured
+		expected []FieldSource
+	}{
+		{
+			name: "object literal",
+			file: "guestbook-ui.jsonnet",
+			obj:  explainTestObject("Deployment", "guestbook-ui"),
+			expected: []FieldSource{
+				{Path: "apiVersion", Location: "components/guestbook-ui.jsonnet:18:17-31"},
+				{Path: "kind", Location: "components/guestbook-ui.jsonnet:19:11-23"},
+				{Path: "metadata", Location: "components/guestbook-ui.jsonnet:(20:15)-(22:6)"},
+				{Path: "spec", Location: "components/guestbook-ui.jsonnet:(23:11)-(31:6)", Params: []string{"image", "name", "replicas"}},
+				{Path: "status", Location: "components/guestbook-ui.jsonnet:(17:3)-(32:4)", Guessed: true},
+			},
+		},
+		{
+			name: "object literal with a name from params",
+			file: "guestbook-ui.jsonnet",
+			obj:  explainTestObject("Service", "guestbook-ui"),
+			expected: []FieldSource{
+				{Path: "apiVersion", Location: "components/guestbook-ui.jsonnet:6:17-21"},
+				{Path: "kind", Location: "components/guestbook-ui.jsonnet:7:11-20"},
+				{Path: "metadata", Location: "components/guestbook-ui.jsonnet:(8:15)-(10:6)", Params: []string{"name"}},
+				{Path: "spec", Location: "components/guestbook-ui.jsonnet:(11:11)-(15:6)", Params: []string{"containerPort", "name", "servicePort", "type"}},
+				{Path: "status", Location: "components/guestbook-ui.jsonnet:(5:3)-(16:4)", Guessed: true},
+			},
+		},
+		{
+			name: "object built with a library",
+			file: "library.jsonnet",
+			obj:  explainTestObject("Deployment", "guestbook-ui"),
+			expected: []FieldSource{
+				{Path: "apiVersion", Location: "components/library.jsonnet:5:1-69", Guessed: true},
+				{Path: "kind", Location: "components/library.jsonnet:5:1-69", Guessed: true},
+				{Path: "metadata", Location: "components/library.jsonnet:5:1-69", Guessed: true},
+				{Path: "spec", Location: "components/library.jsonnet:5:1-69", Guessed: true},
+				{Path: "status", Location: "components/library.jsonnet:5:1-69", Guessed: true},
+			},
+		},
+		{
+			name: "object built with k.libsonnet mixins",
+			file: "klibsonnet.jsonnet",
+			obj:  explainTestObject("Deployment", "guestbook-ui"),
+			expected: []FieldSource{
+				{Path: "apiVersion", Location: "components/klibsonnet.jsonnet:6:1-95", Guessed: true},
+				{Path: "kind", Location: "components/klibsonnet.jsonnet:6:1-95", Guessed: true},
+				{Path: "metadata", Location: "components/klibsonnet.jsonnet:9:1-57", Params: []string{"name", "namespace"}, Guessed: true},
+				{Path: "spec", Location: "components/klibsonnet.jsonnet:7:1-52", Params: []string{"replicas"}, Guessed: true},
+				{Path: "status", Location: "components/klibsonnet.jsonnet:6:1-95", Guessed: true},
+			},
+		},
+		{
+			name: "yaml",
+			file: "deployment.yaml",
+			obj:  explainTestObject("Deployment", "nginx"),
+			expected: []FieldSource{
+				{Path: "apiVersion", Location: "components/deployment.yaml:1:1-25"},
+				{Path: "kind", Location: "components/deployment.yaml:2:1-17"},
+				{Path: "metadata", Location: "components/deployment.yaml:3:1-10"},
+				{Path: "spec", Location: "components/deployment.yaml:5:1-6", Params: []string{"spec.replicas"}},
+				{Path: "status", Location: "components/deployment.yaml:1:1", Guessed: true},
+			},
+		},
+		{
+			name: "json",
+			file: "deployment.json",
+			obj:  explainTestObject("Deployment", "nginx"),
+			expected: []FieldSource{
+				{Path: "apiVersion", Location: "components/deployment.json:2:3-32"},
+				{Path: "kind", Location: "components/deployment.json:3:3-24"},
+				{Path: "metadata", Location: "components/deployment.json:4:3-16"},
+				{Path: "spec", Location: "components/deployment.json:7:3-12", Params: []string{"spec.replicas"}},
+				{Path: "status", Location: "components/deployment.json:1:1", Guessed: true},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := explainTestSource(t, "guestbook-ui", tc.file)
+			if filepath.Ext(tc.file) != ".jsonnet" {
+				cs.params = []component.ModuleParameter{
+					{Component: "guestbook-ui", Key: "spec.replicas", Value: "3"},
+				}
+				cs.defaults = nil
+				cs.overrideLocations = nil
+			}
+
+			e, err := explainObject(tc.obj, cs)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.obj.GetKind()+"/"+tc.obj.GetName(), e.Object)
+			assert.Equal(t, "guestbook-ui", e.Component)
+			assert.Equal(t, cs.path, e.Source)
+			assert.Equal(t, tc.expected, e.Fields)
+
+			if filepath.Ext(tc.file) == ".jsonnet" {
+				assert.Equal(t, []ParamSource{name, replicas}, e.Params)
+			}
+		})
+	}
+}
+
+func Test_explainObject_invalid_jsonnet(t *testing.T) {
+	cs := &componentSource{
+		name: "invalid",
+		path: "components/invalid.jsonnet",
+		data: []byte("{"),
+	}
+
+	_, err := explainObject(explainTestObject("Deployment", "invalid"), cs)
+	require.Error(t, err)
+}
+
+func Test_findExplained(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		explainTestObject("Deployment", "guestbook-ui"),
+		explainTestObject("Service", "guestbook-ui"),
+		explainTestObject("Service", "redis"),
+		explainTestObject("Service", "redis"),
+	}
+
+	cases := []struct {
+		name     string
+		ref      string
+		expected *unstructured.Unstructured
+		isErr    bool
+	}{
+		{
+			name:     "found",
+			ref:      "Service/guestbook-ui",
+			expected: objects[1],
+		},
+		{
+			name:     "kind is case insensitive",
+			ref:      "deployment/guestbook-ui",
+			expected: objects[0],
+		},
+		{
+			name:  "not found",
+			ref:   "Deployment/redis",
+			isErr: true,
+		},
+		{
+			name:  "ambiguous",
+			ref:   "Service/redis",
+			isErr: true,
+		},
+		{
+			name:  "invalid reference",
+			ref:   "guestbook-ui",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj, err := findExplained(objects, tc.ref)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tc.expected == obj)
+		})
+	}
+}
+
+func TestShow_explain(t *testing.T) {
+	unlabeled := explainTestObject("Deployment", "unlabeled")
+	unlabeled.SetLabels(nil)
+
+	objects := []*unstructured.Unstructured{
+		explainTestObject("Deployment", "guestbook-ui"),
+		unlabeled,
+	}
+
+	cases := []struct {
+		name    string
+		format  string
+		explain string
+		isErr   bool
+	}{
+		{
+			name:    "table",
+			format:  "yaml",
+			explain: "Deployment/guestbook-ui",
+		},
+		{
+			name:    "json",
+			format:  "json",
+			explain: "Deployment/guestbook-ui",
+		},
+		{
+			name:    "object without a component",
+			explain: "Deployment/unlabeled",
+			isErr:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+				var buf bytes.Buffer
+
+				config := ShowConfig{
+					App:     appMock,
+					EnvName: "default",
+					Explain: tc.explain,
+					Format:  tc.format,
+					Out:     &buf,
+				}
+
+				opt := func(s *Show) {
+					s.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+						return objects, nil
+					}
+					s.componentSourceFn = func(a app.App, envName, componentName string) (*componentSource, error) {
+						assert.Equal(t, "default", envName)
+						assert.Equal(t, "guestbook-ui", componentName)
+						return explainTestSource(t, componentName, "guestbook-ui.jsonnet"), nil
+					}
+				}
+
+				err := RunShow(config, opt)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+
+				if tc.format == "json" {
+					var e Explanation
+					require.NoError(t, json.Unmarshal(buf.Bytes(), &e))
+					assert.Equal(t, "Deployment/guestbook-ui", e.Object)
+					assert.Len(t, e.Fields, 5)
+					assert.Len(t, e.Params, 2)
+					return
+				}
+
+				assert.Equal(t, test.ReadTestData(t, filepath.Join("explain", "explain.txt")), buf.String())
+			})
+		})
+	}
+}
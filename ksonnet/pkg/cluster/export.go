@@ -0,0 +1,380 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DefaultExportLayout is the default template for the paths of exported
+	// objects.
+	DefaultExportLayout = "{{.Namespace}}/{{lower .Kind}}-{{.Name}}.{{.Ext}}"
+
+	// exportManifest lists the files written by the last export, so files
+	// which are no longer exported can be removed.
+	exportManifest    = ".ks-export"
+	kustomizationFile = "kustomization.yaml"
+	listFile          = "list.json"
+)
+
+// ExportConfig is configuration for Export.
+type ExportConfig struct {
+	App            app.App
+	ComponentNames []string
+	EnvName        string
+	// Dir is the directory objects are exported to. The helm format also
+	// accepts a path ending in .tgz, which the chart is archived to.
+	Dir string
+	// Format is yaml or json for a file per object, list for a single v1
+	// List, or helm for a Helm chart.
+	Format string
+	// Layout is a template for the paths of exported objects. It defaults to
+	// DefaultExportLayout.
+	Layout string
+	// ImagePlatform resolves multi-platform images to the digest of the
+	// image for an os/architecture[/variant] platform.
+	ImagePlatform string
+	ResolveImages bool
+
+	// ChartName and ChartVersion describe the chart exported by the helm
+	// format. The name defaults to the name of the app's directory.
+	ChartName    string
+	ChartVersion string
+	// Values are the component params lifted into the chart's values, as
+	// <component>.<param>.
+	Values []string
+}
+
+// ExportOpts is an option for configuring Export.
+type ExportOpts func(*Export)
+
+// Export exports objects to a directory.
+type Export struct {
+	ExportConfig
+
+	// these make it easier to test Export.
+	findObjectsFn findObjectsFn
+	renderFn      renderFn
+	paramsFn      paramsFn
+}
+
+// RunExport exports objects for a given configuration.
+func RunExport(config ExportConfig, opts ...ExportOpts) error {
+	e := &Export{
+		ExportConfig:  config,
+		findObjectsFn: objectFinder(config.ResolveImages, config.ImagePlatform),
+		renderFn:      renderWithParams,
+		paramsFn:      resolvedParams,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e.Export()
+}
+
+// exportPath is the data the layout template is executed with.
+type exportPath struct {
+	Namespace string
+	Kind      string
+	Name      string
+	Group     string
+	Version   string
+	Component string
+	Ext       string
+}
+
+// Export renders the objects and writes them to the directory. Files written
+// by a previous export which are no longer exported are removed. Output
+// only depends on the objects, so exporting twice gives identical files.
+func (e *Export) Export() error {
+	apiObjects, err := e.findObjectsFn(e.App, e.EnvName, e.ComponentNames)
+	if err != nil {
+		return errors.Wrap(err, "find objects")
+	}
+
+	sorted := make([]*unstructured.Unstructured, len(apiObjects))
+	copy(sorted, apiObjects)
+	UnstructuredSlice(sorted).Sort()
+
+	var files map[string][]byte
+	switch e.Format {
+	case "yaml", "json":
+		files, err = e.objectFiles(sorted)
+	case "list":
+		files, err = listFiles(sorted)
+	case "helm":
+		files, err = e.chartFiles(apiObjects)
+		if err == nil && strings.HasSuffix(e.Dir, ".tgz") {
+			return e.writeChartArchive(files)
+		}
+	default:
+		err = fmt.Errorf("Unknown --format: %s", e.Format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return e.write(files)
+}
+
+// objectFiles encodes each object to a file at the path given by the layout,
+// and lists the files in a kustomization.
+func (e *Export) objectFiles(objects []*unstructured.Unstructured) (map[string][]byte, error) {
+	t, err := e.layoutTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	owners := make(map[string]string)
+	var resources []string
+
+	for _, obj := range objects {
+		path, err := layoutPath(t, obj, e.Format)
+		if err != nil {
+			return nil, err
+		}
+
+		name := describeObject(obj)
+		if owner, ok := owners[path]; ok {
+			return nil, errors.Errorf("%s and %s are both exported to %s", owner, name, path)
+		}
+		owners[path] = name
+
+		var data []byte
+		if e.Format == "yaml" {
+			data, err = yaml.Marshal(obj)
+		} else {
+			data, err = marshalJSON(obj.Object)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding %s", name)
+		}
+
+		files[path] = data
+		resources = append(resources, filepath.ToSlash(path))
+	}
+
+	kustomization := map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	}
+
+	data, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding kustomization")
+	}
+	files[kustomizationFile] = data
+
+	return files, nil
+}
+
+func (e *Export) layoutTemplate() (*template.Template, error) {
+	layout := e.Layout
+	if layout == "" {
+		layout = DefaultExportLayout
+	}
+
+	t, err := template.New("layout").
+		Funcs(template.FuncMap{"lower": strings.ToLower}).
+		Option("missingkey=error").
+		Parse(layout)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing layout")
+	}
+
+	return t, nil
+}
+
+// layoutPath returns the path of an object relative to the export directory.
+func layoutPath(t *template.Template, obj *unstructured.Unstructured, format string) (string, error) {
+	gvk := obj.GroupVersionKind()
+
+	name := obj.GetName()
+	if name == "" {
+		name = obj.GetGenerateName()
+	}
+
+	data := exportPath{
+		Namespace: obj.GetNamespace(),
+		Kind:      gvk.Kind,
+		Name:      name,
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Component: obj.GetLabels()[metadata.LabelComponent],
+		Ext:       format,
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", errors.Wrapf(err, "applying layout to %s", describeObject(obj))
+	}
+
+	// objects without a namespace leave a leading slash with the default
+	// layout.
+	path := filepath.Clean(filepath.FromSlash(strings.TrimLeft(buf.String(), "/")))
+	switch {
+	case path == "." || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)):
+		return "", errors.Errorf("layout gives %s the invalid path %q", describeObject(obj), buf.String())
+	case path == exportManifest || path == kustomizationFile:
+		return "", errors.Errorf("layout gives %s the reserved path %q", describeObject(obj), path)
+	}
+
+	return path, nil
+}
+
+// listFiles encodes the objects as a single v1 List.
+func listFiles(objects []*unstructured.Unstructured) (map[string][]byte, error) {
+	var buf bytes.Buffer
+	if err := writeList(&buf, objects); err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{listFile: buf.Bytes()}, nil
+}
+
+// write writes files to the export directory. Unchanged files are left
+// alone, and files from the previous export which aren't in files are
+// removed.
+func (e *Export) write(files map[string][]byte) error {
+	fs := e.App.Fs()
+
+	previous, err := e.readManifest()
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err = writeFileIfChanged(fs, filepath.Join(e.Dir, path), files[path]); err != nil {
+			return errors.Wrapf(err, "writing %s", path)
+		}
+	}
+
+	for _, path := range previous {
+		if _, ok := files[path]; ok {
+			continue
+		}
+
+		if err = removeStale(fs, e.Dir, path); err != nil {
+			return errors.Wrapf(err, "removing %s", path)
+		}
+	}
+
+	manifest := strings.Join(paths, "\n") + "\n"
+	return writeFileIfChanged(fs, filepath.Join(e.Dir, exportManifest), []byte(manifest))
+}
+
+// readManifest returns the files written by the previous export.
+func (e *Export) readManifest() ([]string, error) {
+	data, err := afero.ReadFile(e.App.Fs(), filepath.Join(e.Dir, exportManifest))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "reading previous export")
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		path := filepath.Clean(strings.TrimSpace(line))
+		// never remove anything outside of the export directory.
+		if line == "" || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) || filepath.IsAbs(path) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+func writeFileIfChanged(fs afero.Fs, path string, data []byte) error {
+	existing, err := afero.ReadFile(fs, path)
+	if err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+
+	if err = fs.MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, data, app.DefaultFilePermissions)
+}
+
+// removeStale removes a file from the export directory, and then any of its
+// parent directories which are left empty.
+func removeStale(fs afero.Fs, dir, path string) error {
+	if err := fs.Remove(filepath.Join(dir, path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for parent := filepath.Dir(path); parent != "."; parent = filepath.Dir(parent) {
+		fis, err := afero.ReadDir(fs, filepath.Join(dir, parent))
+		if err != nil || len(fis) > 0 {
+			return nil
+		}
+
+		if err = fs.Remove(filepath.Join(dir, parent)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func describeObject(obj *unstructured.Unstructured) string {
+	name := obj.GetName()
+	if ns := obj.GetNamespace(); ns != "" {
+		name = ns + "/" + name
+	}
+
+	return fmt.Sprintf("%s %s", obj.GetKind(), name)
+}
@@ -0,0 +1,516 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/util/archive"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	defaultChartVersion = "0.1.0"
+	chartTemplatesDir   = "templates"
+
+	// placeholderPrefix marks the values of params and fields while a chart
+	// is built.
+	placeholderPrefix = "__ks_helm_"
+	// placeholderNumber is the first number params are replaced with.
+	placeholderNumber = 918273645
+)
+
+type renderFn func(a app.App, envName string, componentNames []string,
+	overrides map[string]map[string]interface{}) ([]*unstructured.Unstructured, error)
+
+type paramsFn func(a app.App, envName, componentName string) (map[string]interface{}, error)
+
+func renderWithParams(a app.App, envName string, componentNames []string,
+	overrides map[string]map[string]interface{}) ([]*unstructured.Unstructured, error) {
+	p := pipeline.New(a, envName, pipeline.OverrideParams(overrides))
+	return p.Objects(componentNames)
+}
+
+func resolvedParams(a app.App, envName, componentName string) (map[string]interface{}, error) {
+	return pipeline.New(a, envName).ComponentParams(componentName)
+}
+
+// chartValue is a component param lifted into the chart's values.
+type chartValue struct {
+	component string
+	param     string
+	value     interface{}
+	// placeholder replaces the param when finding the fields it sets.
+	placeholder interface{}
+}
+
+// expression is the template expression for the value.
+func (v chartValue) expression() string {
+	return fmt.Sprintf("index .Values %s %s", strconv.Quote(v.component), strconv.Quote(v.param))
+}
+
+// placeholderString is how the placeholder appears in strings.
+func (v chartValue) placeholderString() (string, bool) {
+	switch t := v.placeholder.(type) {
+	case string:
+		return t, true
+	case int:
+		return strconv.Itoa(t), true
+	default:
+		return "", false
+	}
+}
+
+// chartFiles builds a Helm chart for objects. The fields set by selected
+// params are templated with the params' values, so the chart renders the
+// objects with its default values.
+//
+// The fields are found by rendering again with each param replaced by a
+// unique placeholder. A field is templated if it takes the placeholder's
+// value, or is a string which embeds the placeholder. Any other change means
+// the param is transformed, which can't be expressed in the chart.
+func (e *Export) chartFiles(objects []*unstructured.Unstructured) (map[string][]byte, error) {
+	values, err := e.chartValues()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &chartTemplater{values: values}
+
+	templated := objects
+	if len(values) > 0 {
+		if e.ResolveImages {
+			return nil, errors.New("images can't be resolved when params are lifted into chart values")
+		}
+
+		overrides := make(map[string]map[string]interface{})
+		for _, v := range values {
+			if overrides[v.component] == nil {
+				overrides[v.component] = make(map[string]interface{})
+			}
+			overrides[v.component][v.param] = v.placeholder
+		}
+
+		placeheld, err := e.renderFn(e.App, e.EnvName, e.ComponentNames, overrides)
+		if err != nil {
+			return nil, errors.Wrap(err, "rendering with placeholder values")
+		}
+
+		if templated, err = t.templateObjects(objects, placeheld); err != nil {
+			return nil, err
+		}
+	}
+
+	// paths are given by the objects rendered with the params' values.
+	templates := make(map[*unstructured.Unstructured]*unstructured.Unstructured)
+	for i, obj := range objects {
+		templates[obj] = templated[i]
+	}
+
+	sorted := make([]*unstructured.Unstructured, len(objects))
+	copy(sorted, objects)
+	UnstructuredSlice(sorted).Sort()
+
+	layout, err := e.layoutTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	owners := make(map[string]string)
+	for _, obj := range sorted {
+		path, err := layoutPath(layout, obj, "yaml")
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(chartTemplatesDir, path)
+
+		name := describeObject(obj)
+		if owner, ok := owners[path]; ok {
+			return nil, errors.Errorf("%s and %s are both exported to %s", owner, name, path)
+		}
+		owners[path] = name
+
+		data, err := yaml.Marshal(templates[obj])
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding %s", name)
+		}
+
+		files[path] = t.render(data)
+	}
+
+	chartValues := make(map[string]map[string]interface{})
+	for _, v := range values {
+		if chartValues[v.component] == nil {
+			chartValues[v.component] = make(map[string]interface{})
+		}
+		chartValues[v.component][v.param] = v.value
+	}
+
+	if files["values.yaml"], err = yaml.Marshal(chartValues); err != nil {
+		return nil, errors.Wrap(err, "encoding values")
+	}
+
+	if files["Chart.yaml"], err = e.chartMetadata(); err != nil {
+		return nil, err
+	}
+
+	// Helm doesn't package the export manifest.
+	files[".helmignore"] = []byte(exportManifest + "\n")
+
+	return files, nil
+}
+
+func (e *Export) chartName() string {
+	if e.ChartName != "" {
+		return e.ChartName
+	}
+
+	return filepath.Base(e.App.Root())
+}
+
+func (e *Export) chartMetadata() ([]byte, error) {
+	version := e.ChartVersion
+	if version == "" {
+		version = defaultChartVersion
+	}
+
+	chart := map[string]interface{}{
+		"apiVersion":  "v1",
+		"name":        e.chartName(),
+		"version":     version,
+		"description": fmt.Sprintf("Generated by ksonnet from the %s environment", e.EnvName),
+	}
+
+	data, err := yaml.Marshal(chart)
+	return data, errors.Wrap(err, "encoding chart metadata")
+}
+
+// chartValues looks up the params selected for the chart's values.
+func (e *Export) chartValues() ([]chartValue, error) {
+	params := make(map[string]map[string]interface{})
+
+	var values []chartValue
+	for i, ref := range e.Values {
+		dot := strings.LastIndex(ref, ".")
+		if dot <= 0 || dot == len(ref)-1 {
+			return nil, errors.Errorf("invalid value %q; values are <component>.<param>", ref)
+		}
+		v := chartValue{component: ref[:dot], param: ref[dot+1:]}
+
+		if _, ok := params[v.component]; !ok {
+			p, err := e.paramsFn(e.App, e.EnvName, v.component)
+			if err != nil {
+				return nil, err
+			}
+			params[v.component] = p
+		}
+
+		var ok bool
+		if v.value, ok = params[v.component][v.param]; !ok {
+			return nil, errors.Errorf("component %q doesn't have a %q param", v.component, v.param)
+		}
+
+		switch t := v.value.(type) {
+		case string:
+			v.placeholder = fmt.Sprintf("%svalue_%d__", placeholderPrefix, i)
+		case float64, int, int64:
+			v.placeholder = placeholderNumber + i
+		case bool:
+			v.placeholder = !t
+		default:
+			return nil, errors.Errorf("param %s can't be lifted into values; only strings, numbers and booleans can", ref)
+		}
+
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+// chartTemplater replaces fields set by params with template expressions.
+type chartTemplater struct {
+	values []chartValue
+	// expressions are the template expressions for the placeholders of
+	// templated fields.
+	expressions []string
+}
+
+// templateObjects compares objects with the objects rendered with
+// placeholders, and returns copies whose templated fields are placeholders,
+// in the same order as objects. Objects are paired by component, in the
+// order they are rendered.
+func (t *chartTemplater) templateObjects(objects, placeheld []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	if len(placeheld) != len(objects) {
+		return nil, errors.New("lifted params change which objects are rendered")
+	}
+
+	placeheldByComponent := make(map[string][]*unstructured.Unstructured)
+	for _, obj := range placeheld {
+		c := obj.GetLabels()[metadata.LabelComponent]
+		placeheldByComponent[c] = append(placeheldByComponent[c], obj)
+	}
+
+	out := make([]*unstructured.Unstructured, len(objects))
+	for i, obj := range objects {
+		c := obj.GetLabels()[metadata.LabelComponent]
+		others := placeheldByComponent[c]
+		if len(others) == 0 {
+			return nil, errors.Errorf("lifted params change which objects component %q renders", c)
+		}
+		placeheldByComponent[c] = others[1:]
+
+		templated, err := t.templateValue(obj.Object, others[0].Object, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "templating %s", describeObject(obj))
+		}
+
+		out[i] = &unstructured.Unstructured{Object: templated.(map[string]interface{})}
+	}
+
+	return out, nil
+}
+
+// templateValue templates a value by comparing it with the value rendered
+// with placeholders.
+func (t *chartTemplater) templateValue(value, placeheld interface{}, path string) (interface{}, error) {
+	if reflect.DeepEqual(value, placeheld) {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		p, ok := placeheld.(map[string]interface{})
+		if !ok || !sameKeys(v, p) {
+			break
+		}
+
+		out := make(map[string]interface{}, len(v))
+		for k := range v {
+			templated, err := t.templateValue(v[k], p[k], path+"."+k)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = templated
+		}
+		return out, nil
+	case []interface{}:
+		p, ok := placeheld.([]interface{})
+		if !ok || len(p) != len(v) {
+			break
+		}
+
+		out := make([]interface{}, len(v))
+		for i := range v {
+			templated, err := t.templateValue(v[i], p[i], fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = templated
+		}
+		return out, nil
+	}
+
+	if expr, ok := t.scalarExpression(value, placeheld); ok {
+		return t.placeholder(expr), nil
+	}
+
+	if expr, ok := t.stringExpression(value, placeheld); ok {
+		return t.placeholder(expr), nil
+	}
+
+	return nil, errors.Errorf("field %s is changed by lifted params in a way which can't be templated", strings.TrimPrefix(path, "."))
+}
+
+// scalarExpression returns the expression for a field which takes the value
+// of a param.
+func (t *chartTemplater) scalarExpression(value, placeheld interface{}) (string, bool) {
+	var matches []chartValue
+	for _, v := range t.values {
+		if scalarEqual(placeheld, v.placeholder) && scalarEqual(value, v.value) {
+			matches = append(matches, v)
+		}
+	}
+
+	// booleans only have two values, so several params could match.
+	if len(matches) != 1 {
+		return "", false
+	}
+
+	return matches[0].expression(), true
+}
+
+// stringExpression returns the expression for a string field which embeds
+// the values of params.
+func (t *chartTemplater) stringExpression(value, placeheld interface{}) (string, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return "", false
+	}
+
+	p, ok := placeheld.(string)
+	if !ok {
+		return "", false
+	}
+
+	var args []string
+	var rebuilt bytes.Buffer
+	embeds := false
+	for p != "" {
+		next, v := len(p), chartValue{}
+		for _, candidate := range t.values {
+			token, ok := candidate.placeholderString()
+			if !ok {
+				continue
+			}
+
+			if i := strings.Index(p, token); i >= 0 && i < next {
+				next, v = i, candidate
+			}
+		}
+
+		if next > 0 {
+			args = append(args, strconv.Quote(p[:next]))
+			rebuilt.WriteString(p[:next])
+		}
+
+		if next == len(p) {
+			break
+		}
+
+		token, _ := v.placeholderString()
+		args = append(args, "("+v.expression()+")")
+		embeds = true
+		fmt.Fprint(&rebuilt, formatScalar(v.value))
+		p = p[next+len(token):]
+	}
+
+	// the parts around the placeholders must match, and the field must
+	// embed at least one param.
+	if !embeds || rebuilt.String() != s {
+		return "", false
+	}
+
+	return "print " + strings.Join(args, " "), true
+}
+
+// placeholder records an expression and returns the placeholder which marks
+// its field.
+func (t *chartTemplater) placeholder(expr string) string {
+	t.expressions = append(t.expressions, expr)
+	return fmt.Sprintf("%stemplate_%d__", placeholderPrefix, len(t.expressions)-1)
+}
+
+// render turns an encoded object into a template. Existing template
+// delimiters are escaped, and placeholders are replaced with their
+// expressions.
+func (t *chartTemplater) render(data []byte) []byte {
+	s := strings.Replace(string(data), "{{", `{{ "{{" }}`, -1)
+
+	// replace later placeholders first, so template_1 doesn't match the
+	// start of template_10.
+	for i := len(t.expressions) - 1; i >= 0; i-- {
+		placeholder := fmt.Sprintf("%stemplate_%d__", placeholderPrefix, i)
+		s = strings.Replace(s, placeholder, "{{ "+t.expressions[i]+" | toJson }}", -1)
+	}
+
+	return []byte(s)
+}
+
+func sameKeys(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// scalarEqual compares scalars. Numbers are compared by value, since
+// objects and params decode them differently.
+func scalarEqual(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+
+	return a == b
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case float64:
+		return t, true
+	default:
+		return 0, false
+	}
+}
+
+// formatScalar formats a param as Jsonnet embeds it in a string.
+func formatScalar(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// writeChartArchive archives the chart to the .tgz file at Dir. The files
+// are nested in a directory named after the chart.
+func (e *Export) writeChartArchive(files map[string][]byte) error {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	name := e.chartName()
+	var archived []*archive.File
+	for _, path := range paths {
+		archived = append(archived, &archive.File{
+			Name:   name + "/" + filepath.ToSlash(path),
+			Reader: bytes.NewReader(files[path]),
+		})
+	}
+
+	var buf bytes.Buffer
+	tgz := &archive.Tgz{}
+	if err := tgz.Archive(&buf, archived); err != nil {
+		return errors.Wrap(err, "archiving chart")
+	}
+
+	return writeFileIfChanged(e.App.Fs(), e.Dir, buf.Bytes())
+}
@@ -0,0 +1,301 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/archive"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/engine"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// guestbookParams are the params of the guestbook component.
+func guestbookParams() map[string]interface{} {
+	return map[string]interface{}{
+		"replicas":    float64(3),
+		"tag":         "1.15",
+		"port":        float64(8080),
+		"hostNetwork": false,
+		"name":        "guestbook",
+	}
+}
+
+// renderGuestbook renders the guestbook component like Jsonnet would.
+// scaled multiplies replicas, which can't be templated.
+func renderGuestbook(params map[string]interface{}, scaled bool) []*unstructured.Unstructured {
+	labels := map[string]interface{}{"ksonnet.io/component": "guestbook"}
+
+	replicas := params["replicas"]
+	if scaled {
+		replicas = toNumber(replicas) * 2
+	}
+
+	objects := []*unstructured.Unstructured{
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":      params["name"],
+					"namespace": "default",
+					"labels":    labels,
+					"annotations": map[string]interface{}{
+						"port": fmt.Sprintf("port-%v", params["port"]),
+					},
+				},
+				"spec": map[string]interface{}{
+					"replicas": replicas,
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"hostNetwork": params["hostNetwork"],
+							"containers": []interface{}{
+								map[string]interface{}{
+									"name":  "guestbook",
+									"image": fmt.Sprintf("gcr.io/heptio-images/guestbook:%v", params["tag"]),
+									"ports": []interface{}{
+										map[string]interface{}{"containerPort": params["port"]},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":      "guestbook-config",
+					"namespace": "default",
+					"labels":    labels,
+				},
+				"data": map[string]interface{}{
+					"template": "{{ .Values.notATemplate }}",
+				},
+			},
+		},
+	}
+
+	// decode like the pipeline does.
+	for i, obj := range objects {
+		objects[i] = normalizeObject(obj.Object)
+	}
+
+	return objects
+}
+
+func toNumber(v interface{}) float64 {
+	f, _ := toFloat(v)
+	return f
+}
+
+// normalizeObject decodes an object from JSON, as the pipeline does.
+func normalizeObject(v interface{}) *unstructured.Unstructured {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err = obj.UnmarshalJSON(data); err != nil {
+		panic(err)
+	}
+
+	return obj
+}
+
+func runHelmExport(t *testing.T, appMock app.App, config ExportConfig, scaled bool) error {
+	config.App = appMock
+	config.EnvName = "default"
+	config.Format = "helm"
+
+	opt := func(e *Export) {
+		e.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+			return renderGuestbook(guestbookParams(), scaled), nil
+		}
+		e.paramsFn = func(a app.App, envName, componentName string) (map[string]interface{}, error) {
+			if componentName != "guestbook" {
+				return nil, fmt.Errorf("unknown component %q", componentName)
+			}
+			return guestbookParams(), nil
+		}
+		e.renderFn = func(a app.App, envName string, componentNames []string, overrides map[string]map[string]interface{}) ([]*unstructured.Unstructured, error) {
+			params := guestbookParams()
+			for k, v := range overrides["guestbook"] {
+				params[k] = v
+			}
+			return renderGuestbook(params, scaled), nil
+		}
+	}
+
+	return RunExport(config, opt)
+}
+
+// renderChart renders the chart in dir with Helm, and returns its objects.
+func renderChart(t *testing.T, fs afero.Fs, dir, values string) []*unstructured.Unstructured {
+	var files []*chartutil.BufferedFile
+	for path, data := range exportedFiles(t, fs, dir) {
+		files = append(files, &chartutil.BufferedFile{Name: path, Data: []byte(data)})
+	}
+
+	c, err := chartutil.LoadFiles(files)
+	require.NoError(t, err)
+
+	vals, err := chartutil.ToRenderValues(c, &chart.Config{Raw: values}, chartutil.ReleaseOptions{Name: "test"})
+	require.NoError(t, err)
+
+	rendered, err := engine.New().Render(c, vals)
+	require.NoError(t, err)
+
+	var objects []*unstructured.Unstructured
+	for name, s := range rendered {
+		if filepath.Ext(name) != ".yaml" {
+			continue
+		}
+
+		data, err := yaml.YAMLToJSON([]byte(s))
+		require.NoError(t, err, "rendered %s:\n%s", name, s)
+
+		obj := &unstructured.Unstructured{}
+		require.NoError(t, obj.UnmarshalJSON(data))
+		objects = append(objects, obj)
+	}
+
+	UnstructuredSlice(objects).Sort()
+	return objects
+}
+
+func TestExport_helm(t *testing.T) {
+	test.WithApp(t, "/guestbook", func(appMock *mocks.App, fs afero.Fs) {
+		config := ExportConfig{
+			Dir:    "/chart",
+			Values: []string{"guestbook.replicas", "guestbook.tag", "guestbook.port", "guestbook.hostNetwork", "guestbook.name"},
+		}
+
+		err := runHelmExport(t, appMock, config, false)
+		require.NoError(t, err)
+
+		files := exportedFiles(t, fs, "/chart")
+		assert.Equal(t, "apiVersion: v1\ndescription: Generated by ksonnet from the default environment\nname: guestbook\nversion: 0.1.0\n", files["Chart.yaml"])
+		assert.Equal(t, "guestbook:\n  hostNetwork: false\n  name: guestbook\n  port: 8080\n  replicas: 3\n  tag: \"1.15\"\n", files["values.yaml"])
+		assert.Contains(t, files["templates/default/deployment-guestbook.yaml"], `replicas: {{ index .Values "guestbook" "replicas" | toJson }}`)
+		assert.Contains(t, files["templates/default/deployment-guestbook.yaml"], `image: {{ print "gcr.io/heptio-images/guestbook:" (index .Values "guestbook" "tag") | toJson }}`)
+
+		// the chart renders the objects with its default values.
+		expected := renderGuestbook(guestbookParams(), false)
+		UnstructuredSlice(expected).Sort()
+		require.Equal(t, expected, renderChart(t, fs, "/chart", ""))
+
+		// and follows changes to its values.
+		params := guestbookParams()
+		params["replicas"] = float64(5)
+		params["tag"] = "1.16"
+		params["name"] = "guestbook-canary"
+		expected = renderGuestbook(params, false)
+		UnstructuredSlice(expected).Sort()
+
+		values := "guestbook:\n  replicas: 5\n  tag: \"1.16\"\n  name: guestbook-canary\n"
+		require.Equal(t, expected, renderChart(t, fs, "/chart", values))
+
+		// exporting again gives identical files.
+		err = runHelmExport(t, appMock, config, false)
+		require.NoError(t, err)
+		require.Equal(t, files, exportedFiles(t, fs, "/chart"))
+	})
+}
+
+func TestExport_helm_archive(t *testing.T) {
+	test.WithApp(t, "/guestbook", func(appMock *mocks.App, fs afero.Fs) {
+		config := ExportConfig{
+			Dir:          "/out/guestbook-1.0.0.tgz",
+			ChartName:    "guestbook",
+			ChartVersion: "1.0.0",
+			Values:       []string{"guestbook.replicas"},
+		}
+
+		err := runHelmExport(t, appMock, config, false)
+		require.NoError(t, err)
+
+		data, err := afero.ReadFile(fs, "/out/guestbook-1.0.0.tgz")
+		require.NoError(t, err)
+
+		var names []string
+		tgz := &archive.Tgz{}
+		err = tgz.Unarchive(bytes.NewReader(data), func(f *archive.File) error {
+			names = append(names, f.Name)
+			return nil
+		})
+		require.NoError(t, err)
+
+		expected := []string{
+			"guestbook/.helmignore",
+			"guestbook/Chart.yaml",
+			"guestbook/templates/default/configmap-guestbook-config.yaml",
+			"guestbook/templates/default/deployment-guestbook.yaml",
+			"guestbook/values.yaml",
+		}
+		require.Equal(t, expected, names)
+
+		c, err := chartutil.LoadArchive(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, "1.0.0", c.Metadata.Version)
+	})
+}
+
+func TestExport_helm_invalid(t *testing.T) {
+	cases := []struct {
+		name          string
+		values        []string
+		scaled        bool
+		resolveImages bool
+		errContains   string
+	}{
+		{name: "malformed value", values: []string{"replicas"}, errContains: "<component>.<param>"},
+		{name: "unknown component", values: []string{"redis.replicas"}, errContains: "unknown component"},
+		{name: "unknown param", values: []string{"guestbook.missing"}, errContains: "doesn't have"},
+		{name: "transformed param", values: []string{"guestbook.replicas"}, scaled: true, errContains: "spec.replicas"},
+		{name: "resolved images", values: []string{"guestbook.tag"}, resolveImages: true, errContains: "images"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/guestbook", func(appMock *mocks.App, fs afero.Fs) {
+				config := ExportConfig{Dir: "/chart", Values: tc.values, ResolveImages: tc.resolveImages}
+
+				err := runHelmExport(t, appMock, config, tc.scaled)
+				require.Error(t, err)
+				assert.True(t, strings.Contains(err.Error(), tc.errContains), err.Error())
+			})
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func exportObjects() []*unstructured.Unstructured {
+	return []*unstructured.Unstructured{
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":      "web",
+					"namespace": "default",
+					"labels": map[string]interface{}{
+						"ksonnet.io/component": "guestbook",
+					},
+				},
+			},
+		},
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata": map[string]interface{}{
+					"name":      "web",
+					"namespace": "default",
+					"labels": map[string]interface{}{
+						"ksonnet.io/component": "guestbook",
+					},
+				},
+			},
+		},
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata": map[string]interface{}{
+					"name": "default",
+				},
+			},
+		},
+	}
+}
+
+func runExport(t *testing.T, appMock app.App, config ExportConfig, objects []*unstructured.Unstructured) error {
+	config.App = appMock
+	config.EnvName = "default"
+
+	findOpt := func(e *Export) {
+		e.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+			assert.Equal(t, "default", envName)
+			return objects, nil
+		}
+	}
+
+	return RunExport(config, findOpt)
+}
+
+// exportedFiles returns the contents of the files in dir by relative path.
+func exportedFiles(t *testing.T, fs afero.Fs, dir string) map[string]string {
+	files := make(map[string]string)
+	err := afero.Walk(fs, dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	require.NoError(t, err)
+
+	return files
+}
+
+func TestExport(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   string
+		layout   string
+		expected map[string]string
+	}{
+		{
+			name:   "yaml",
+			format: "yaml",
+			expected: map[string]string{
+				"namespace-default.yaml":      "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: default\n",
+				"default/service-web.yaml":    "apiVersion: v1\nkind: Service\nmetadata:\n  labels:\n    ksonnet.io/component: guestbook\n  name: web\n  namespace: default\n",
+				"default/deployment-web.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  labels:\n    ksonnet.io/component: guestbook\n  name: web\n  namespace: default\n",
+				"kustomization.yaml": "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n" +
+					"- namespace-default.yaml\n- default/service-web.yaml\n- default/deployment-web.yaml\n",
+				".ks-export": "default/deployment-web.yaml\ndefault/service-web.yaml\nkustomization.yaml\nnamespace-default.yaml\n",
+			},
+		},
+		{
+			name:   "json with a layout",
+			format: "json",
+			layout: "{{or .Component \"cluster\"}}/{{.Kind}}.{{.Ext}}",
+			expected: map[string]string{
+				"cluster/Namespace.json":    "{\n  \"apiVersion\": \"v1\",\n  \"kind\": \"Namespace\",\n  \"metadata\": {\n    \"name\": \"default\"\n  }\n}\n",
+				"guestbook/Service.json":    "{\n  \"apiVersion\": \"v1\",\n  \"kind\": \"Service\",\n  \"metadata\": {\n    \"labels\": {\n      \"ksonnet.io/component\": \"guestbook\"\n    },\n    \"name\": \"web\",\n    \"namespace\": \"default\"\n  }\n}\n",
+				"guestbook/Deployment.json": "{\n  \"apiVersion\": \"apps/v1\",\n  \"kind\": \"Deployment\",\n  \"metadata\": {\n    \"labels\": {\n      \"ksonnet.io/component\": \"guestbook\"\n    },\n    \"name\": \"web\",\n    \"namespace\": \"default\"\n  }\n}\n",
+				"kustomization.yaml": "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n" +
+					"- cluster/Namespace.json\n- guestbook/Service.json\n- guestbook/Deployment.json\n",
+				".ks-export": "cluster/Namespace.json\nguestbook/Deployment.json\nguestbook/Service.json\nkustomization.yaml\n",
+			},
+		},
+		{
+			name:   "list",
+			format: "list",
+			expected: map[string]string{
+				"list.json": "{\n  \"apiVersion\": \"v1\",\n  \"items\": [\n" +
+					"    {\n      \"apiVersion\": \"v1\",\n      \"kind\": \"Namespace\",\n      \"metadata\": {\n        \"name\": \"default\"\n      }\n    },\n" +
+					"    {\n      \"apiVersion\": \"v1\",\n      \"kind\": \"Service\",\n      \"metadata\": {\n        \"labels\": {\n          \"ksonnet.io/component\": \"guestbook\"\n        },\n        \"name\": \"web\",\n        \"namespace\": \"default\"\n      }\n    },\n" +
+					"    {\n      \"apiVersion\": \"apps/v1\",\n      \"kind\": \"Deployment\",\n      \"metadata\": {\n        \"labels\": {\n          \"ksonnet.io/component\": \"guestbook\"\n        },\n        \"name\": \"web\",\n        \"namespace\": \"default\"\n      }\n    }\n" +
+					"  ],\n  \"kind\": \"List\"\n}\n",
+				".ks-export": "list.json\n",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(appMock *mocks.App, fs afero.Fs) {
+				config := ExportConfig{Dir: "/out", Format: tc.format, Layout: tc.layout}
+
+				err := runExport(t, appMock, config, exportObjects())
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, exportedFiles(t, fs, "/out"))
+
+				// exporting again gives identical files.
+				err = runExport(t, appMock, config, exportObjects())
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, exportedFiles(t, fs, "/out"))
+			})
+		})
+	}
+}
+
+func TestExport_removesStaleFiles(t *testing.T) {
+	test.WithApp(t, "/app", func(appMock *mocks.App, fs afero.Fs) {
+		require.NoError(t, afero.WriteFile(fs, "/out/README.md", []byte("docs"), 0644))
+
+		config := ExportConfig{Dir: "/out", Format: "yaml"}
+		err := runExport(t, appMock, config, exportObjects())
+		require.NoError(t, err)
+
+		err = runExport(t, appMock, config, exportObjects()[2:])
+		require.NoError(t, err)
+
+		files := exportedFiles(t, fs, "/out")
+		assert.Contains(t, files, "namespace-default.yaml")
+		assert.Contains(t, files, "README.md", "files which weren't exported are kept")
+		assert.NotContains(t, files, "default/service-web.yaml")
+
+		exists, err := afero.DirExists(fs, "/out/default")
+		require.NoError(t, err)
+		assert.False(t, exists, "empty directories are removed")
+
+		// switching formats removes the previous format's files.
+		config.Format = "list"
+		err = runExport(t, appMock, config, exportObjects())
+		require.NoError(t, err)
+
+		files = exportedFiles(t, fs, "/out")
+		assert.Contains(t, files, "list.json")
+		assert.NotContains(t, files, "kustomization.yaml")
+		assert.NotContains(t, files, "namespace-default.yaml")
+	})
+}
+
+func TestExport_invalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		format string
+		layout string
+	}{
+		{name: "unknown format", format: "xml"},
+		{name: "layout collision", format: "yaml", layout: "{{.Namespace}}.{{.Ext}}"},
+		{name: "layout outside of directory", format: "yaml", layout: "../{{.Name}}.{{.Ext}}"},
+		{name: "reserved path", format: "yaml", layout: "kustomization.yaml"},
+		{name: "invalid layout", format: "yaml", layout: "{{.Missing}}"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(appMock *mocks.App, fs afero.Fs) {
+				config := ExportConfig{Dir: "/out", Format: tc.format, Layout: tc.layout}
+				err := runExport(t, appMock, config, exportObjects())
+				require.Error(t, err)
+			})
+		})
+	}
+}
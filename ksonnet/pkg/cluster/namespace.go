@@ -0,0 +1,57 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/util/k8s"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// namespacesFor returns the namespaces objects are created in, sorted by
+// name. The default namespace is included even if no object uses it, and
+// namespaces defined by the objects themselves are left out.
+func namespacesFor(objects []*unstructured.Unstructured, defaultNamespace string) []string {
+	names := sets.NewString()
+	if defaultNamespace != "" {
+		names.Insert(defaultNamespace)
+	}
+
+	defined := sets.NewString()
+	for _, obj := range objects {
+		if obj.GetKind() == "Namespace" {
+			defined.Insert(obj.GetName())
+			continue
+		}
+
+		if k8s.IsClusterScoped(obj.GetKind()) || obj.GetNamespace() == "" {
+			continue
+		}
+
+		names.Insert(obj.GetNamespace())
+	}
+
+	return names.Difference(defined).List()
+}
+
+// newNamespace creates a Namespace object.
+func newNamespace(name string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	return ns
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_namespacesFor(t *testing.T) {
+	cases := []struct {
+		name             string
+		objects          []*unstructured.Unstructured
+		defaultNamespace string
+		expected         []string
+	}{
+		{
+			name:             "default namespace",
+			defaultNamespace: "default",
+			expected:         []string{"default"},
+		},
+		{
+			name: "object namespaces",
+			objects: []*unstructured.Unstructured{
+				makeUnstructuredNS("v1", "ConfigMap", "second"),
+				makeUnstructuredNS("v1", "Service", "first"),
+				makeUnstructuredNS("v1", "Service", "second"),
+				makeUnstructured("v1", "Service"),
+			},
+			expected: []string{"first", "second"},
+		},
+		{
+			name: "cluster scoped objects",
+			objects: []*unstructured.Unstructured{
+				makeUnstructuredNS("rbac.authorization.k8s.io/v1", "ClusterRole", "first"),
+			},
+			expected: []string{},
+		},
+		{
+			name: "namespaces defined by objects",
+			objects: []*unstructured.Unstructured{
+				makeUnstructuredName("v1", "Namespace", "first"),
+				makeUnstructuredNS("v1", "Service", "first"),
+				makeUnstructuredNS("v1", "Service", "second"),
+			},
+			defaultNamespace: "first",
+			expected:         []string{"second"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, namespacesFor(tc.objects, tc.defaultNamespace))
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// redactedValue replaces the values of sensitive params in the
+// ksonnet.io/params annotation.
+const redactedValue = "<redacted>"
+
+// sensitiveParamName matches the names of params which likely hold secrets.
+var sensitiveParamName = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|private|key$)`)
+
+// appliedParams is the contents of the ksonnet.io/params annotation.
+type appliedParams struct {
+	Environment string            `json:"environment"`
+	AppliedAt   time.Time         `json:"appliedAt"`
+	Params      map[string]string `json:"params"`
+}
+
+// componentParams maps a component name to its parameters.
+type componentParams map[string]map[string]string
+
+type envParamsFn func(a app.App, envName string) (componentParams, error)
+
+// envParams collects the parameters for all components in an environment.
+func envParams(a app.App, envName string) (componentParams, error) {
+	modules, err := component.ModulesFromEnv(a, envName)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading modules")
+	}
+
+	cp := make(componentParams)
+	for _, module := range modules {
+		moduleParams, err := module.Params(envName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading params for module %q", module.Name())
+		}
+
+		for _, mp := range moduleParams {
+			if _, ok := cp[mp.Component]; !ok {
+				cp[mp.Component] = make(map[string]string)
+			}
+			cp[mp.Component][mp.Key] = mp.Value
+		}
+	}
+
+	return cp, nil
+}
+
+// redactParams replaces the values of params which may hold secrets: every
+// param of a component which renders a Secret, and params whose names look
+// sensitive.
+func redactParams(cp componentParams, objects []*unstructured.Unstructured) componentParams {
+	secrets := make(map[string]bool)
+	for _, obj := range objects {
+		if obj.GetKind() == "Secret" {
+			secrets[obj.GetLabels()[metadata.LabelComponent]] = true
+		}
+	}
+
+	redacted := make(componentParams)
+	for componentName, params := range cp {
+		redacted[componentName] = make(map[string]string)
+		for k, v := range params {
+			if secrets[componentName] || sensitiveParamName.MatchString(k) {
+				v = redactedValue
+			}
+			redacted[componentName][k] = v
+		}
+	}
+
+	return redacted
+}
+
+// setParamsAnnotation records the parameters of the object's component in
+// an annotation. Objects without a component label are left untouched.
+func setParamsAnnotation(obj *unstructured.Unstructured, envName string, appliedAt time.Time, cp componentParams) error {
+	componentName := obj.GetLabels()[metadata.LabelComponent]
+	if componentName == "" {
+		return nil
+	}
+
+	ap := appliedParams{
+		Environment: envName,
+		AppliedAt:   appliedAt.UTC(),
+		Params:      cp[componentName],
+	}
+
+	if ap.Params == nil {
+		ap.Params = make(map[string]string)
+	}
+
+	data, err := json.Marshal(&ap)
+	if err != nil {
+		return errors.Wrap(err, "encoding params annotation")
+	}
+
+	SetMetaDataAnnotation(obj, metadata.AnnotationParams, string(data))
+	return nil
+}
+
+// CollectParams collects the component parameters recorded by the last apply
+// of an environment to a cluster namespace.
+func CollectParams(namespace, envName string, clients Clients) ([]component.ModuleParameter, error) {
+	objects, err := fetchManagedObjects(namespace, clients, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return paramsFromObjects(objects, envName)
+}
+
+// paramsFromObjects returns the params recorded in objects for an
+// environment. Params are recorded on one object per component, so if several
+// objects of a component have them, e.g. because the component's objects
+// changed between applies, the most recently applied ones are used.
+func paramsFromObjects(objects []*unstructured.Unstructured, envName string) ([]component.ModuleParameter, error) {
+	latest := make(map[string]appliedParams)
+
+	for _, obj := range objects {
+		componentName := obj.GetLabels()[metadata.LabelComponent]
+		if componentName == "" {
+			continue
+		}
+
+		data, ok := obj.GetAnnotations()[metadata.AnnotationParams]
+		if !ok {
+			continue
+		}
+
+		var ap appliedParams
+		if err := json.Unmarshal([]byte(data), &ap); err != nil {
+			return nil, errors.Wrapf(err, "decoding params annotation for %s %s",
+				obj.GetKind(), obj.GetName())
+		}
+
+		if ap.Environment != envName {
+			continue
+		}
+
+		if current, ok := latest[componentName]; ok && !ap.AppliedAt.After(current.AppliedAt) {
+			continue
+		}
+
+		latest[componentName] = ap
+	}
+
+	var moduleParams []component.ModuleParameter
+	for componentName, ap := range latest {
+		for k, v := range ap.Params {
+			moduleParams = append(moduleParams, component.ModuleParameter{
+				Component: componentName,
+				Key:       k,
+				Value:     v,
+			})
+		}
+	}
+
+	sort.Slice(moduleParams, func(i, j int) bool {
+		if moduleParams[i].Component == moduleParams[j].Component {
+			return moduleParams[i].Key < moduleParams[j].Key
+		}
+		return moduleParams[i].Component < moduleParams[j].Component
+	})
+
+	return moduleParams, nil
+}
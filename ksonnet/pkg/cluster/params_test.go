@@ -0,0 +1,106 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_paramsFromObjects(t *testing.T) {
+	newObject := func(name, componentName string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: genObject()}
+		obj.SetName(name)
+		if componentName != "" {
+			SetMetaDataLabel(obj, metadata.LabelComponent, componentName)
+		}
+		return obj
+	}
+
+	cp := componentParams{
+		"guestbook": {"replicas": "3", "image": `"nginx"`},
+		"redis":     {"replicas": "1"},
+	}
+
+	guestbookDeployment := newObject("guestbook", "guestbook")
+	guestbookService := newObject("guestbook-svc", "guestbook")
+	redis := newObject("redis", "redis")
+	unlabeled := newObject("unlabeled", "")
+	otherEnv := newObject("other", "other")
+
+	appliedAt := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	// the service was annotated by an earlier apply.
+	stale := componentParams{"guestbook": {"replicas": "1"}}
+	require.NoError(t, setParamsAnnotation(guestbookService, "prod", appliedAt.Add(-time.Hour), stale))
+
+	for _, obj := range []*unstructured.Unstructured{guestbookDeployment, redis, unlabeled} {
+		require.NoError(t, setParamsAnnotation(obj, "prod", appliedAt, cp))
+	}
+	require.NoError(t, setParamsAnnotation(otherEnv, "dev", appliedAt, componentParams{"other": {"a": "1"}}))
+
+	_, ok := unlabeled.GetAnnotations()[metadata.AnnotationParams]
+	require.False(t, ok, "unlabeled objects should not be annotated")
+
+	objects := []*unstructured.Unstructured{guestbookService, guestbookDeployment, redis, unlabeled, otherEnv}
+
+	got, err := paramsFromObjects(objects, "prod")
+	require.NoError(t, err)
+
+	expected := []component.ModuleParameter{
+		{Component: "guestbook", Key: "image", Value: `"nginx"`},
+		{Component: "guestbook", Key: "replicas", Value: "3"},
+		{Component: "redis", Key: "replicas", Value: "1"},
+	}
+	require.Equal(t, expected, got)
+}
+
+func Test_paramsFromObjects_invalid_annotation(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: genObject()}
+	SetMetaDataLabel(obj, metadata.LabelComponent, "guestbook")
+	SetMetaDataAnnotation(obj, metadata.AnnotationParams, "{")
+
+	_, err := paramsFromObjects([]*unstructured.Unstructured{obj}, "prod")
+	require.Error(t, err)
+}
+
+func Test_redactParams(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: genObject()}
+	secret.SetKind("Secret")
+	SetMetaDataLabel(secret, metadata.LabelComponent, "credentials")
+
+	deployment := &unstructured.Unstructured{Object: genObject()}
+	SetMetaDataLabel(deployment, metadata.LabelComponent, "guestbook")
+
+	cp := componentParams{
+		"credentials": {"username": `"admin"`},
+		"guestbook":   {"replicas": "3", "dbPassword": `"hunter2"`, "apiToken": `"abc"`, "sshKey": `"key"`},
+	}
+
+	got := redactParams(cp, []*unstructured.Unstructured{secret, deployment})
+
+	expected := componentParams{
+		"credentials": {"username": redactedValue},
+		"guestbook":   {"replicas": "3", "dbPassword": redactedValue, "apiToken": redactedValue, "sshKey": redactedValue},
+	}
+	require.Equal(t, expected, got)
+	require.Equal(t, `"hunter2"`, cp["guestbook"]["dbPassword"], "params should not be modified")
+}
@@ -19,9 +19,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"path/filepath"
+	"strings"
 
 	"github.com/ghodss/yaml"
 	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -31,8 +36,23 @@ type ShowConfig struct {
 	App            app.App
 	ComponentNames []string
 	EnvName        string
-	Format         string
-	Out            io.Writer
+	// Explain is a <kind>/<name> reference to an object. When it is set, the
+	// sources of the object's fields and params are shown instead of the
+	// objects.
+	Explain string
+	// Filter selects which of the rendered objects are shown.
+	Filter pipeline.ObjectFilter
+	Format string
+	// ImagePlatform resolves multi-platform images to the digest of the
+	// image for an os/architecture[/variant] platform.
+	ImagePlatform string
+	Out           io.Writer
+	ResolveImages bool
+	// Stats is the format of render stats: table or json. Stats are not
+	// collected when it is blank.
+	Stats string
+	// StatsOut is where render stats are written.
+	StatsOut io.Writer
 }
 
 // ShowOpts is an option for configuring Show.
@@ -43,14 +63,18 @@ type Show struct {
 	ShowConfig
 
 	// these make it easier to test Show.
-	findObjectsFn findObjectsFn
+	findObjectsFn     findObjectsFn
+	componentSourceFn componentSourceFn
+	statsFn           statsFn
 }
 
 // RunShow shows objects for a given configuration.
 func RunShow(config ShowConfig, opts ...ShowOpts) error {
 	s := &Show{
-		ShowConfig:    config,
-		findObjectsFn: findObjects,
+		ShowConfig:        config,
+		findObjectsFn:     objectFinder(config.ResolveImages, config.ImagePlatform),
+		componentSourceFn: loadComponentSource,
+		statsFn:           pipeline.CurrentStats,
 	}
 
 	for _, opt := range opts {
@@ -62,15 +86,54 @@ func RunShow(config ShowConfig, opts ...ShowOpts) error {
 
 // Show shows objects.
 func (s *Show) Show() error {
+	if s.Stats == "" {
+		return s.show()
+	}
+
+	f, err := table.DetectFormat(s.Stats)
+	if err != nil {
+		return err
+	}
+
+	pipeline.CollectStats()
+	defer pipeline.StopStats()
+
+	if err = s.show(); err != nil {
+		return err
+	}
+
+	stats := s.statsFn()
+
+	// imports in the app are shown relative to its root.
+	stats.Jsonnet.Imports = append([]jsonnet.ImportStats(nil), stats.Jsonnet.Imports...)
+	for i := range stats.Jsonnet.Imports {
+		rel, err := filepath.Rel(s.App.Root(), stats.Jsonnet.Imports[i].Path)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			stats.Jsonnet.Imports[i].Path = rel
+		}
+	}
+
+	return writeStats(s.StatsOut, f, stats)
+}
+
+func (s *Show) show() error {
 	apiObjects, err := s.findObjectsFn(s.App, s.EnvName, s.ComponentNames)
 	if err != nil {
 		return errors.Wrap(err, "find objects")
 	}
 
+	if apiObjects, err = s.Filter.Filter(apiObjects); err != nil {
+		return errors.Wrap(err, "filter objects")
+	}
+
 	sorted := make([]*unstructured.Unstructured, len(apiObjects))
 	copy(sorted, apiObjects)
 	UnstructuredSlice(sorted).Sort()
 
+	if s.Explain != "" {
+		return s.explain(sorted)
+	}
+
 	switch s.Format {
 	case "yaml":
 		return s.showYAML(sorted)
@@ -86,7 +149,12 @@ func (s *Show) showYAML(apiObjects []*unstructured.Unstructured) error {
 }
 
 func (s *Show) showJSON(apiObjects []*unstructured.Unstructured) error {
-	enc := json.NewEncoder(s.Out)
+	return writeList(s.Out, apiObjects)
+}
+
+// writeList writes objects as an indented JSON v1 List.
+func writeList(out io.Writer, apiObjects []*unstructured.Unstructured) error {
+	enc := json.NewEncoder(out)
 	enc.SetIndent("", "  ")
 
 	m := map[string]interface{}{
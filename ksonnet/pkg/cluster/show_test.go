@@ -17,10 +17,15 @@ package cluster
 
 import (
 	"bytes"
+	"encoding/json"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
 	"github.com/ksonnet/ksonnet/pkg/util/test"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
@@ -44,6 +49,7 @@ func TestShow(t *testing.T) {
 	cases := []struct {
 		name        string
 		format      string
+		filter      pipeline.ObjectFilter
 		expected    string
 		findObjects func() ([]*unstructured.Unstructured, error)
 		isErr       bool
@@ -60,6 +66,20 @@ func TestShow(t *testing.T) {
 			expected:    "{\n  \"apiVersion\": \"v1\",\n  \"items\": [\n    {\n      \"kind\": \"a\"\n    },\n    {\n      \"kind\": \"b\"\n    }\n  ],\n  \"kind\": \"List\"\n}\n",
 			findObjects: dummyObjects,
 		},
+		{
+			name:        "show filtered",
+			format:      "yaml",
+			filter:      pipeline.ObjectFilter{Kinds: []string{"b"}},
+			expected:    "---\nkind: b\n",
+			findObjects: dummyObjects,
+		},
+		{
+			name:        "invalid filter",
+			format:      "yaml",
+			filter:      pipeline.ObjectFilter{Selector: "a in b"},
+			findObjects: dummyObjects,
+			isErr:       true,
+		},
 		{
 			name:        "unknown format",
 			format:      "xml",
@@ -83,6 +103,7 @@ func TestShow(t *testing.T) {
 					EnvName: "default",
 					Out:     &buf,
 					Format:  tc.format,
+					Filter:  tc.filter,
 				}
 
 				fn := func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
@@ -106,3 +127,99 @@ func TestShow(t *testing.T) {
 		})
 	}
 }
+
+func TestShow_stats(t *testing.T) {
+	stats := pipeline.Stats{
+		Modules: []pipeline.ModuleStats{
+			{
+				Name:    "/",
+				Elapsed: 120 * time.Millisecond,
+				Components: []pipeline.ComponentStats{
+					{Name: "guestbook-ui", Elapsed: 80 * time.Millisecond},
+					{Name: "redis", Elapsed: 25 * time.Millisecond},
+				},
+			},
+			{
+				Name:       "nested",
+				Elapsed:    2 * time.Millisecond,
+				Cached:     true,
+				Components: []pipeline.ComponentStats{},
+			},
+		},
+		Jsonnet: jsonnet.Stats{
+			VMs:     4,
+			Elapsed: 150 * time.Millisecond,
+			Imports: []jsonnet.ImportStats{
+				{Path: "/app/lib/k.libsonnet", Count: 2, Hits: 1, Size: 1024},
+				{Path: "/app/lib/k8s.libsonnet", Count: 1, Size: 4096},
+			},
+		},
+	}
+
+	cases := []struct {
+		name  string
+		stats string
+		isErr bool
+	}{
+		{
+			name:  "table",
+			stats: "table",
+		},
+		{
+			name:  "json",
+			stats: "json",
+		},
+		{
+			name:  "unknown format",
+			stats: "xml",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+				var out, statsOut bytes.Buffer
+
+				config := ShowConfig{
+					App:      appMock,
+					EnvName:  "default",
+					Format:   "yaml",
+					Out:      &out,
+					Stats:    tc.stats,
+					StatsOut: &statsOut,
+				}
+
+				opt := func(s *Show) {
+					s.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+						return []*unstructured.Unstructured{
+							{Object: map[string]interface{}{"kind": "a"}},
+						}, nil
+					}
+					s.statsFn = func() pipeline.Stats {
+						return stats
+					}
+				}
+
+				err := RunShow(config, opt)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, "---\nkind: a\n", out.String())
+
+				if tc.stats == "json" {
+					var got pipeline.Stats
+					require.NoError(t, json.Unmarshal(statsOut.Bytes(), &got))
+					assert.Equal(t, stats.Modules, got.Modules)
+					assert.Equal(t, "app/lib/k.libsonnet", got.Jsonnet.Imports[0].Path)
+					return
+				}
+
+				assert.Equal(t, test.ReadTestData(t, filepath.Join("stats", "stats.txt")), statsOut.String())
+			})
+		})
+	}
+}
@@ -92,6 +92,49 @@ func lessString(a, b string) (less bool, equal bool) {
 	}
 }
 
+func lessInt(a, b int) (less bool, equal bool) {
+	switch {
+	case a < b:
+		return true, false
+	case b < a:
+		return false, false
+	default:
+		return false, true
+	}
+}
+
+// applyOrder is a sort.Interface which sorts objects in the order they are
+// applied to a cluster: namespaces, then CustomResourceDefinitions, then the
+// rest in UnstructuredSlice order. Objects are deleted in the reverse order.
+type applyOrder []*unstructured.Unstructured
+
+func (l applyOrder) Len() int      { return len(l) }
+func (l applyOrder) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l applyOrder) Less(i, j int) bool {
+	if a, b := l[i], l[j]; a != nil && b != nil {
+		if less, equal := lessInt(kindTier(a.GetKind()), kindTier(b.GetKind())); !equal {
+			return less
+		}
+	}
+
+	return UnstructuredSlice(l).Less(i, j)
+}
+
+// kindTier ranks kinds which other objects depend on regardless of their
+// namespace. Namespaces come first since namespaced objects are created in
+// them, then CustomResourceDefinitions since custom resources can't be
+// created until their definitions are.
+func kindTier(kind string) int {
+	switch kind {
+	case "Namespace":
+		return 0
+	case "CustomResourceDefinition":
+		return 1
+	default:
+		return 2
+	}
+}
+
 // Compares k8s resource kinds, applies semantic ordering (order we want to apply in)
 func lessKind(a, b string) (less bool, equal bool) {
 	if a == b {
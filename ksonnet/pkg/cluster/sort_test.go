@@ -16,6 +16,7 @@
 package cluster
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -313,3 +314,31 @@ func Test_UnstructuredSlice_Sort_Namespaces(t *testing.T) {
 
 	require.Equal(t, expected, objects)
 }
+
+func Test_applyOrder(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		makeUnstructuredNS("example.com/v1", "Widget", "first"),
+		makeUnstructuredNS("v1", "ConfigMap", "first"),
+		makeUnstructuredName("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "widgets.example.com"),
+		makeUnstructuredName("rbac.authorization.k8s.io/v1", "ClusterRole", "role"),
+		makeUnstructuredName("v1", "Namespace", "first"),
+	}
+
+	expected := []*unstructured.Unstructured{
+		makeUnstructuredName("v1", "Namespace", "first"),
+		makeUnstructuredName("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "widgets.example.com"),
+		makeUnstructuredName("rbac.authorization.k8s.io/v1", "ClusterRole", "role"),
+		makeUnstructuredNS("v1", "ConfigMap", "first"),
+		makeUnstructuredNS("example.com/v1", "Widget", "first"),
+	}
+
+	sort.Stable(applyOrder(objects))
+	require.Equal(t, expected, objects)
+
+	// objects are deleted in the reverse order.
+	sort.Stable(sort.Reverse(applyOrder(objects)))
+	for i, j := 0, len(expected)-1; i < j; i, j = i+1, j-1 {
+		expected[i], expected[j] = expected[j], expected[i]
+	}
+	require.Equal(t, expected, objects)
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+)
+
+type statsFn func() pipeline.Stats
+
+// writeStats writes render stats as tables or as JSON.
+func writeStats(w io.Writer, f table.Format, stats pipeline.Stats) error {
+	if f == table.FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+
+	modules := table.New("modules", w)
+	modules.SetHeader([]string{"module", "component", "elapsed", "cached"})
+	for _, ms := range stats.Modules {
+		modules.Append([]string{ms.Name, "", formatElapsed(ms.Elapsed), strconv.FormatBool(ms.Cached)})
+		for _, cs := range ms.Components {
+			modules.Append([]string{ms.Name, cs.Name, formatElapsed(cs.Elapsed), ""})
+		}
+	}
+
+	if err := modules.Render(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+
+	imports := table.New("imports", w)
+	imports.SetHeader([]string{"import", "count", "hits", "size"})
+	for _, is := range stats.Jsonnet.Imports {
+		imports.Append([]string{is.Path, strconv.Itoa(is.Count), strconv.Itoa(is.Hits), strconv.Itoa(is.Size)})
+	}
+
+	if err := imports.Render(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+
+	summary := table.New("jsonnet", w)
+	summary.SetHeader([]string{"vms", "imports", "import hits", "import size", "elapsed"})
+	summary.Append([]string{
+		strconv.Itoa(stats.Jsonnet.VMs),
+		strconv.Itoa(stats.Jsonnet.ImportCount()),
+		strconv.Itoa(stats.Jsonnet.ImportHits()),
+		strconv.Itoa(stats.Jsonnet.ImportSize()),
+		formatElapsed(stats.Jsonnet.Elapsed),
+	})
+
+	return summary.Render()
+}
+
+func formatElapsed(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
@@ -74,8 +74,16 @@ const (
 	componentsRoot = "components"
 	// paramsFile is the params file for a component namespace.
 	paramsFile = "params.libsonnet"
+	// testSuffix is the suffix of component tests. Tests live alongside
+	// components, but they are not components.
+	testSuffix = "_test.jsonnet"
 )
 
+// IsTest reports if a file is a component test.
+func IsTest(path string) bool {
+	return strings.HasSuffix(path, testSuffix)
+}
+
 // LocateComponent locates a component given a module and a name.
 func LocateComponent(ksApp app.App, module, name string) (Component, error) {
 	path := make([]string, 0)
@@ -416,6 +416,9 @@ func (m *FilesystemModule) Components() ([]Component, error) {
 
 	var components []Component
 	for _, fi := range fis {
+		if fi.Name() == paramsSchemaFile || IsTest(fi.Name()) {
+			continue
+		}
 
 		ext := filepath.Ext(fi.Name())
 		path := filepath.Join(moduleDir, fi.Name())
@@ -99,7 +99,9 @@ func TestModule_Components(t *testing.T) {
 	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
 		test.StageFile(t, fs, "certificate-crd.yaml", "/app/components/module1/certificate-crd.yaml")
 		test.StageFile(t, fs, "params-with-entry.libsonnet", "/app/components/module1/params.libsonnet")
+		test.StageFile(t, fs, "params-schema.json", "/app/components/module1/params.schema.json")
 		test.StageFile(t, fs, "params-no-entry.libsonnet", "/app/components/params.libsonnet")
+		require.NoError(t, afero.WriteFile(fs, "/app/components/module1/certificate-crd_test.jsonnet", []byte("{}"), 0644))
 
 		cases := []struct {
 			name   string
@@ -0,0 +1,148 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package component
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	// paramsSchemaFile is the optional JSON Schema for the params in a module.
+	paramsSchemaFile = "params.schema.json"
+)
+
+// ParamsSchema describes the parameters of the components in a module using
+// JSON Schema. It lives next to the module's params.libsonnet and mirrors its
+// layout:
+//
+//   {
+//     "components": {
+//       "guestbook": {
+//         "properties": {
+//           "replicas": {"type": "integer", "minimum": 1}
+//         },
+//         "required": ["replicas"]
+//       }
+//     }
+//   }
+type ParamsSchema struct {
+	// Components maps a component name to the schema for its parameters.
+	Components map[string]spec.Schema `json:"components"`
+}
+
+// LoadParamsSchema loads the params schema for a module. It returns nil if
+// the module does not have a schema.
+func LoadParamsSchema(a app.App, moduleName string) (*ParamsSchema, error) {
+	m := NewModule(a, moduleName)
+	path := filepath.Join(m.Dir(), paramsSchemaFile)
+
+	exists, err := afero.Exists(a.Fs(), path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	b, err := afero.ReadFile(a.Fs(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ps ParamsSchema
+	if err := json.Unmarshal(b, &ps); err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", path)
+	}
+
+	return &ps, nil
+}
+
+// ComponentNames returns the names of the components with a schema.
+func (ps *ParamsSchema) ComponentNames() []string {
+	var names []string
+	for name := range ps.Components {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Validate validates all the parameters for a component. Components without
+// a schema are not validated.
+func (ps *ParamsSchema) Validate(componentName string, params map[string]interface{}) error {
+	schema, ok := ps.Components[componentName]
+	if !ok {
+		return nil
+	}
+
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+
+	if err := validate.AgainstSchema(&schema, params, strfmt.Default); err != nil {
+		return errors.Wrapf(err, "params for component %q are invalid", componentName)
+	}
+
+	return nil
+}
+
+// ValidateParam validates a single parameter value for a component. Required
+// parameters are not checked since they can't be determined from a single value.
+func (ps *ParamsSchema) ValidateParam(componentName string, path []string, value interface{}) error {
+	schema, ok := ps.Components[componentName]
+	if !ok {
+		return nil
+	}
+
+	cur := &schema
+	for i, key := range path {
+		next, ok := cur.Properties[key]
+		if ok {
+			cur = &next
+			continue
+		}
+
+		ap := cur.AdditionalProperties
+		switch {
+		case ap != nil && ap.Schema != nil:
+			cur = ap.Schema
+		case ap != nil && !ap.Allows:
+			return errors.Errorf("param %q is not defined for component %q",
+				strings.Join(path[:i+1], "."), componentName)
+		default:
+			// the schema does not describe this param.
+			return nil
+		}
+	}
+
+	if err := validate.AgainstSchema(cur, value, strfmt.Default); err != nil {
+		return errors.Wrapf(err, "param %q for component %q is invalid",
+			strings.Join(path, "."), componentName)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package component
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func withParamsSchema(t *testing.T, fn func(*ParamsSchema)) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		test.StageFile(t, fs, "params-schema.json", "/app/components/module1/params.schema.json")
+
+		ps, err := LoadParamsSchema(a, "module1")
+		require.NoError(t, err)
+		require.NotNil(t, ps)
+
+		fn(ps)
+	})
+}
+
+func TestLoadParamsSchema(t *testing.T) {
+	withParamsSchema(t, func(ps *ParamsSchema) {
+		require.Equal(t, []string{"guestbook"}, ps.ComponentNames())
+	})
+}
+
+func TestLoadParamsSchema_missing(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		ps, err := LoadParamsSchema(a, "/")
+		require.NoError(t, err)
+		require.Nil(t, ps)
+	})
+}
+
+func TestLoadParamsSchema_invalid(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		err := afero.WriteFile(fs, "/app/components/params.schema.json", []byte("{"), 0644)
+		require.NoError(t, err)
+
+		_, err = LoadParamsSchema(a, "/")
+		require.Error(t, err)
+	})
+}
+
+func TestParamsSchema_Validate(t *testing.T) {
+	cases := []struct {
+		name          string
+		componentName string
+		params        map[string]interface{}
+		isErr         bool
+	}{
+		{
+			name:          "valid",
+			componentName: "guestbook",
+			params: map[string]interface{}{
+				"image":    "nginx",
+				"replicas": float64(3),
+				"mode":     "blue",
+			},
+		},
+		{
+			name:          "missing required param",
+			componentName: "guestbook",
+			params: map[string]interface{}{
+				"replicas": float64(3),
+			},
+			isErr: true,
+		},
+		{
+			name:          "wrong type",
+			componentName: "guestbook",
+			params: map[string]interface{}{
+				"image":    "nginx",
+				"replicas": "three",
+			},
+			isErr: true,
+		},
+		{
+			name:          "out of range",
+			componentName: "guestbook",
+			params: map[string]interface{}{
+				"image":    "nginx",
+				"replicas": float64(0),
+			},
+			isErr: true,
+		},
+		{
+			name:          "not in enum",
+			componentName: "guestbook",
+			params: map[string]interface{}{
+				"image": "nginx",
+				"mode":  "red",
+			},
+			isErr: true,
+		},
+		{
+			name:          "component without schema",
+			componentName: "redis",
+			params: map[string]interface{}{
+				"replicas": "three",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withParamsSchema(t, func(ps *ParamsSchema) {
+				err := ps.Validate(tc.componentName, tc.params)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+			})
+		})
+	}
+}
+
+func TestParamsSchema_ValidateParam(t *testing.T) {
+	cases := []struct {
+		name  string
+		path  []string
+		value interface{}
+		isErr bool
+	}{
+		{
+			name:  "valid integer",
+			path:  []string{"replicas"},
+			value: float64(3),
+		},
+		{
+			name:  "string for integer",
+			path:  []string{"replicas"},
+			value: "three",
+			isErr: true,
+		},
+		{
+			name:  "valid nested",
+			path:  []string{"resources", "cpu"},
+			value: "100m",
+		},
+		{
+			name:  "invalid pattern",
+			path:  []string{"resources", "cpu"},
+			value: "100",
+			isErr: true,
+		},
+		{
+			name:  "additional properties not allowed",
+			path:  []string{"resources", "memory"},
+			value: "1Gi",
+			isErr: true,
+		},
+		{
+			name:  "param not described",
+			path:  []string{"unknown"},
+			value: "anything",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withParamsSchema(t, func(ps *ParamsSchema) {
+				err := ps.ValidateParam("guestbook", tc.path, tc.value)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+			})
+		})
+	}
+}
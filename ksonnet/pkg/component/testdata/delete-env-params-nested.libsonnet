@@ -1,9 +1,8 @@
-local params = import '../../components/params.libsonnet';
-
+local params = import "../../components/params.libsonnet";
 params {
-  components+: {
-    "guestbook-ui"+: {
-      name: 'guestbook-dev',
+  components +: {
+    "guestbook-ui" +: {
+       name: "guestbook-dev",
     },
   },
-}
\ No newline at end of file
+}
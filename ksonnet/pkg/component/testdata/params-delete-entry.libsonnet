@@ -1,10 +1,14 @@
 {
-  global: {},
+  global: {
+    // User-defined global parameters; accessible to all component and environments, Ex:
+    // replicas: 4,
+  },
   components: {
     // Component-level parameters, defined initially from 'ks prototype use ...'
     // Each object below should correspond to a component in the components/ directory
     "certificate-crd": {
-      spec: {},
+      spec: {
+      },
     },
   },
 }
\ No newline at end of file
@@ -1,13 +1,14 @@
 {
-  global: {},
+  global: {
+  },
   components: {
     a: {
       other: 1,
       metadata: {
         labels: {
-          locala: 'local',
+          locala: "local",
         },
       },
     },
   },
-}
\ No newline at end of file
+}
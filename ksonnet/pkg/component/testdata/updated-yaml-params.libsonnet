@@ -1,7 +1,12 @@
 {
-  global: {},
+  global: {
+    // User-defined global parameters; accessible to all component and environments, Ex:
+    // replicas: 4,
+  },
   components: {
-    "certificate-crd": {
+    // Component-level parameters, defined initially from 'ks prototype use ...'
+    // Each object below should correspond to a component in the components/ directory
+    'certificate-crd': {
       spec: {
         version: 'v2',
       },
@@ -0,0 +1,211 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package crd reads Kubernetes CustomResourceDefinitions.
+package crd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// crdKind is the kind of a CustomResourceDefinition.
+	crdKind = "CustomResourceDefinition"
+	// crdGroup is the API group of a CustomResourceDefinition.
+	crdGroup = "apiextensions.k8s.io"
+)
+
+// CRD is a CustomResourceDefinition.
+type CRD struct {
+	// Name is the name of the CRD, e.g. `certificates.certmanager.k8s.io`.
+	Name string
+	// Group is the API group of the custom resource.
+	Group string
+	// Kind is the kind of the custom resource.
+	Kind string
+	// Namespaced is true if the custom resource is namespaced.
+	Namespaced bool
+	// Versions are the served versions of the custom resource.
+	Versions []Version
+}
+
+// Version is a version of a custom resource.
+type Version struct {
+	// Name is the name of the version, e.g. `v1alpha1`.
+	Name string
+	// Schema is the OpenAPI v3 schema of the version. It is nil if the CRD
+	// does not have a schema.
+	Schema *spec.Schema
+}
+
+// APIVersion returns the apiVersion of a version of the custom resource.
+func (c *CRD) APIVersion(version string) string {
+	return c.Group + "/" + version
+}
+
+type validation struct {
+	OpenAPIV3Schema *spec.Schema `json:"openAPIV3Schema"`
+}
+
+// object is a CustomResourceDefinition, or a List of objects, as found in a
+// manifest. It supports the `v1beta1` and `v1` versions of CRDs.
+type object struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		Scope      string      `json:"scope"`
+		Validation *validation `json:"validation"`
+		Version    string      `json:"version"`
+		Versions   []struct {
+			Name   string      `json:"name"`
+			Served *bool       `json:"served"`
+			Schema *validation `json:"schema"`
+		} `json:"versions"`
+	} `json:"spec"`
+	Items []json.RawMessage `json:"items"`
+}
+
+// Decode decodes the CRDs in a YAML or JSON manifest. The manifest can contain
+// multiple documents and Lists. Objects which are not CRDs are ignored.
+func Decode(r io.Reader) ([]*CRD, error) {
+	reader := yaml.NewYAMLReader(bufio.NewReader(r))
+
+	var crds []*CRD
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		data, err := yaml.ToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		found, err := decodeObject(data)
+		if err != nil {
+			return nil, err
+		}
+
+		crds = append(crds, found...)
+	}
+
+	return crds, nil
+}
+
+func decodeObject(data []byte) ([]*CRD, error) {
+	var o object
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, errors.Wrap(err, "decode object")
+	}
+
+	if strings.HasSuffix(o.Kind, "List") {
+		var crds []*CRD
+		for _, item := range o.Items {
+			found, err := decodeObject(item)
+			if err != nil {
+				return nil, err
+			}
+
+			crds = append(crds, found...)
+		}
+
+		return crds, nil
+	}
+
+	if o.Kind != crdKind || !strings.HasPrefix(o.APIVersion, crdGroup+"/") {
+		return nil, nil
+	}
+
+	c, err := o.crd()
+	if err != nil {
+		return nil, err
+	}
+
+	return []*CRD{c}, nil
+}
+
+func (o *object) crd() (*CRD, error) {
+	if o.Spec.Group == "" || o.Spec.Names.Kind == "" {
+		return nil, errors.Errorf("CustomResourceDefinition %q does not have a group and kind", o.Metadata.Name)
+	}
+
+	c := &CRD{
+		Name:       o.Metadata.Name,
+		Group:      o.Spec.Group,
+		Kind:       o.Spec.Names.Kind,
+		Namespaced: o.Spec.Scope != "Cluster",
+	}
+
+	var shared *spec.Schema
+	if o.Spec.Validation != nil {
+		shared = o.Spec.Validation.OpenAPIV3Schema
+	}
+
+	for _, v := range o.Spec.Versions {
+		if v.Served != nil && !*v.Served {
+			continue
+		}
+
+		schema := shared
+		if v.Schema != nil && v.Schema.OpenAPIV3Schema != nil {
+			schema = v.Schema.OpenAPIV3Schema
+		}
+
+		c.Versions = append(c.Versions, Version{Name: v.Name, Schema: schema})
+	}
+
+	if len(o.Spec.Versions) == 0 && o.Spec.Version != "" {
+		c.Versions = append(c.Versions, Version{Name: o.Spec.Version, Schema: shared})
+	}
+
+	if len(c.Versions) == 0 {
+		return nil, errors.Errorf("CustomResourceDefinition %q does not have any served versions", c.Name)
+	}
+
+	return c, nil
+}
+
+// Sort sorts CRDs by group and kind.
+func Sort(crds []*CRD) {
+	sort.SliceStable(crds, func(i, j int) bool {
+		if crds[i].Group != crds[j].Group {
+			return crds[i].Group < crds[j].Group
+		}
+		return crds[i].Kind < crds[j].Kind
+	})
+}
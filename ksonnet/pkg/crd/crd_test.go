@@ -0,0 +1,146 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package crd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		name     string
+		file     string
+		expected []*CRD
+	}{
+		{
+			name: "yaml documents",
+			file: "crds.yaml",
+			expected: []*CRD{
+				{
+					Name:       "certificates.certmanager.k8s.io",
+					Group:      "certmanager.k8s.io",
+					Kind:       "Certificate",
+					Namespaced: true,
+					Versions:   []Version{{Name: "v1alpha1"}},
+				},
+				{
+					Name:     "clusterissuers.certmanager.k8s.io",
+					Group:    "certmanager.k8s.io",
+					Kind:     "ClusterIssuer",
+					Versions: []Version{{Name: "v1alpha1"}},
+				},
+			},
+		},
+		{
+			name: "list with versions",
+			file: "list.json",
+			expected: []*CRD{
+				{
+					Name:       "gateways.networking.istio.io",
+					Group:      "networking.istio.io",
+					Kind:       "Gateway",
+					Namespaced: true,
+					Versions:   []Version{{Name: "v1alpha3"}},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := os.Open("testdata/" + tc.file)
+			require.NoError(t, err)
+			defer f.Close()
+
+			crds, err := Decode(f)
+			require.NoError(t, err)
+
+			// schemas are checked by the library tests.
+			for _, c := range crds {
+				for i := range c.Versions {
+					c.Versions[i].Schema = nil
+				}
+			}
+
+			assert.Equal(t, tc.expected, crds)
+		})
+	}
+}
+
+func TestDecode_invalid(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "invalid yaml",
+			src:  "kind: [",
+		},
+		{
+			name: "missing kind",
+			src: `apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com
+spec:
+  group: example.com
+  version: v1`,
+		},
+		{
+			name: "no served versions",
+			src: `apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com
+spec:
+  group: example.com
+  names:
+    kind: Foo
+  versions:
+  - name: v1
+    served: false`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tc.src))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	crds := []*CRD{
+		{Group: "b.example.com", Kind: "A"},
+		{Group: "a.example.com", Kind: "B"},
+		{Group: "a.example.com", Kind: "A"},
+	}
+
+	Sort(crds)
+
+	expected := []*CRD{
+		{Group: "a.example.com", Kind: "A"},
+		{Group: "a.example.com", Kind: "B"},
+		{Group: "b.example.com", Kind: "A"},
+	}
+	assert.Equal(t, expected, crds)
+}
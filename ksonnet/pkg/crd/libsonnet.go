@@ -0,0 +1,422 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package crd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-openapi/spec"
+	jsonnetutil "github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+)
+
+const (
+	// LibDir is the directory in an app's lib directory which contains the
+	// libraries generated from CRDs.
+	LibDir = "crds"
+)
+
+var (
+	// skippedFields are top level fields which are set by the constructor or
+	// by the server.
+	skippedFields = map[string]bool{
+		"apiVersion": true,
+		"kind":       true,
+		"metadata":   true,
+		"status":     true,
+	}
+
+	// reservedIDs can't be used as identifiers in generated code.
+	reservedIDs = map[string]bool{
+		"assert": true, "else": true, "error": true, "false": true,
+		"for": true, "function": true, "if": true, "import": true,
+		"importstr": true, "in": true, "local": true, "null": true,
+		"self": true, "std": true, "super": true, "tailstrict": true,
+		"then": true, "true": true,
+	}
+)
+
+// Library is a library generated for the custom resources in a group and
+// version.
+type Library struct {
+	// Path is the path of the library relative to the lib directory, e.g.
+	// `crds/certmanager.k8s.io/v1alpha1.libsonnet`.
+	Path string
+	// Data is the contents of the library.
+	Data []byte
+}
+
+// GenerateLibs generates a library for each group and version of the CRDs.
+// A library has a constructor and mixins for each kind in the group and
+// version:
+//
+//   local certmanager = import "crds/certmanager.k8s.io/v1alpha1.libsonnet";
+//
+//   certmanager.certificate.new("example") +
+//   certmanager.certificate.mixin.spec.withCommonName("example.com")
+//
+// The output only depends on the CRDs, so libraries can be regenerated when
+// the CRDs change.
+func GenerateLibs(crds []*CRD) ([]Library, error) {
+	type groupVersion struct {
+		group, version string
+	}
+
+	kinds := make(map[groupVersion][]kindVersion)
+	for _, c := range crds {
+		for _, v := range c.Versions {
+			gv := groupVersion{group: c.Group, version: v.Name}
+			kinds[gv] = append(kinds[gv], kindVersion{crd: c, version: v})
+		}
+	}
+
+	var gvs []groupVersion
+	for gv := range kinds {
+		gvs = append(gvs, gv)
+	}
+	sort.Slice(gvs, func(i, j int) bool {
+		if gvs[i].group != gvs[j].group {
+			return gvs[i].group < gvs[j].group
+		}
+		return gvs[i].version < gvs[j].version
+	})
+
+	var libs []Library
+	for _, gv := range gvs {
+		path := filepath.Join(LibDir, gv.group, gv.version+".libsonnet")
+
+		data, err := generateLib(path, gv.group, gv.version, kinds[gv])
+		if err != nil {
+			return nil, errors.Wrapf(err, "generate library for %s/%s", gv.group, gv.version)
+		}
+
+		libs = append(libs, Library{Path: path, Data: data})
+	}
+
+	return libs, nil
+}
+
+// kindVersion is a version of a custom resource.
+type kindVersion struct {
+	crd     *CRD
+	version Version
+}
+
+func generateLib(path, group, version string, kinds []kindVersion) ([]byte, error) {
+	sort.SliceStable(kinds, func(i, j int) bool {
+		return kinds[i].crd.Kind < kinds[j].crd.Kind
+	})
+
+	w := &libWriter{}
+	w.line("{")
+	w.comment("Generated by `ks lib generate-crd`. Do not edit.")
+	w.line(`"__ksonnet": {group: %s, version: %s},`, quote(group), quote(version))
+	w.line("local apiVersion = {apiVersion: %s},", quote(group+"/"+version))
+
+	seen := make(map[string]bool)
+	for _, kv := range kinds {
+		name := kindFieldName(kv.crd.Kind)
+		if seen[name] {
+			return nil, errors.Errorf("kind %q is defined more than once", kv.crd.Kind)
+		}
+		seen[name] = true
+
+		w.writeKind(name, kv)
+	}
+
+	w.line("}")
+
+	out, err := jsonnetutil.Format(path, w.buf.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(out), nil
+}
+
+// libWriter writes the source of a library. The source is formatted once it
+// is complete, so it isn't indented.
+type libWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *libWriter) line(format string, a ...interface{}) {
+	fmt.Fprintf(&w.buf, format, a...)
+	w.buf.WriteByte('\n')
+}
+
+func (w *libWriter) comment(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		w.line("// %s", strings.TrimSpace(line))
+	}
+}
+
+func (w *libWriter) writeKind(name string, kv kindVersion) {
+	props := map[string]spec.Schema{
+		"spec": {SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"object"}}},
+	}
+
+	if s := kv.version.Schema; s != nil {
+		w.comment(s.Description)
+		if len(s.Properties) > 0 {
+			props = s.Properties
+		}
+	}
+
+	w.line("%s:: {", fieldKey(name))
+	w.line("local kind = {kind: %s},", quote(kv.crd.Kind))
+	w.line(`new(name=""):: apiVersion + kind + self.mixin.metadata.withName(name),`)
+	w.writeFields(props, "")
+	w.line("mixin:: {")
+	w.writeMetadata(kv.crd.Namespaced)
+	w.writeMixins(props, "", "")
+	w.line("},")
+	w.line("},")
+}
+
+// writeMetadata writes the mixin for the metadata of a custom resource. CRD
+// schemas don't describe metadata, so the common fields are written.
+func (w *libWriter) writeMetadata(namespaced bool) {
+	w.comment("Standard object metadata.")
+	w.line("metadata:: {")
+	w.line("local __metadataMixin(metadata) = {metadata+: metadata},")
+	w.line("mixinInstance(metadata):: __metadataMixin(metadata),")
+
+	fields := []struct {
+		name, description string
+		isMap             bool
+	}{
+		{"annotations", "Annotations is an unstructured key value map stored with a resource that may be set by external tools to store and retrieve arbitrary metadata.", true},
+		{"labels", "Map of string keys and values that can be used to organize and categorize (scope and select) objects.", true},
+		{"name", "Name must be unique within a namespace.", false},
+		{"namespace", "Namespace defines the space within each name must be unique.", false},
+	}
+
+	for _, f := range fields {
+		if f.name == "namespace" && !namespaced {
+			continue
+		}
+
+		fn := "with" + upperFirst(f.name)
+		w.comment(f.description)
+		w.line("%s(%s):: self + __metadataMixin({%s: %s}),", fn, f.name, f.name, f.name)
+		if f.isMap {
+			w.comment(f.description)
+			w.line("%sMixin(%s):: self + __metadataMixin({%s+: %s}),", fn, f.name, f.name, f.name)
+		}
+	}
+
+	w.line("},")
+}
+
+// writeFields writes the setters for the fields which are not objects with
+// properties. mixinFn is the local which merges a field into its parent, or
+// empty if the fields are top level fields.
+func (w *libWriter) writeFields(props map[string]spec.Schema, mixinFn string) {
+	wrap := func(key, id string) string {
+		field := fmt.Sprintf("{%s: %s}", key, id)
+		if mixinFn == "" {
+			return field
+		}
+		return fmt.Sprintf("%s(%s)", mixinFn, field)
+	}
+
+	for _, name := range propertyNames(props, mixinFn == "") {
+		s := props[name]
+		if hasProperties(s) {
+			continue
+		}
+
+		key, id := fieldKey(name), identifier(name)
+		fn := "with" + upperFirst(id)
+
+		switch {
+		case isArray(s):
+			w.comment(s.Description)
+			w.line(`%s(%s):: self + if std.type(%s) == "array" then %s else %s,`,
+				fn, id, id, wrap(key, id), wrap(key, "["+id+"]"))
+			w.comment(s.Description)
+			w.line(`%sMixin(%s):: self + if std.type(%s) == "array" then %s else %s,`,
+				fn, id, id, wrap(key+"+", id), wrap(key+"+", "["+id+"]"))
+		case isMap(s):
+			w.comment(s.Description)
+			w.line("%s(%s):: self + %s,", fn, id, wrap(key, id))
+			w.comment(s.Description)
+			w.line("%sMixin(%s):: self + %s,", fn, id, wrap(key+"+", id))
+		default:
+			w.comment(s.Description)
+			w.line("%s(%s):: self + %s,", fn, id, wrap(key, id))
+		}
+	}
+}
+
+// writeMixins writes a mixin object for each field which is an object with
+// properties. Each mixin has a local which merges into its parent: top level
+// fields merge into the object, and nested fields merge through the local of
+// their parent.
+func (w *libWriter) writeMixins(props map[string]spec.Schema, parentFn, prefix string) {
+	for _, name := range propertyNames(props, parentFn == "") {
+		s := props[name]
+		if !hasProperties(s) {
+			continue
+		}
+
+		key, id := fieldKey(name), identifier(name)
+		path := prefix + upperFirst(id)
+		if prefix == "" {
+			path = id
+		}
+		mixinFn := "__" + path + "Mixin"
+
+		merge := fmt.Sprintf("{%s+: %s}", key, id)
+		if parentFn != "" {
+			merge = fmt.Sprintf("%s(%s)", parentFn, merge)
+		}
+
+		w.comment(s.Description)
+		w.line("%s:: {", key)
+		w.line("local %s(%s) = %s,", mixinFn, id, merge)
+		w.line("mixinInstance(%s):: %s(%s),", id, mixinFn, id)
+		w.writeFields(s.Properties, mixinFn)
+		w.writeMixins(s.Properties, mixinFn, path)
+		w.line("},")
+	}
+}
+
+// propertyNames returns the sorted names of properties. Top level fields
+// which are set by the constructor or the server are skipped.
+func propertyNames(props map[string]spec.Schema, top bool) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for name := range props {
+		if top && skippedFields[name] {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// fields whose identifiers collide can't both have setters.
+	var unique []string
+	for _, name := range names {
+		id := identifier(name)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, name)
+	}
+
+	return unique
+}
+
+func hasProperties(s spec.Schema) bool {
+	return len(s.Properties) > 0
+}
+
+func isArray(s spec.Schema) bool {
+	return s.Type.Contains("array") || s.Items != nil
+}
+
+func isMap(s spec.Schema) bool {
+	return s.Type.Contains("object") || s.AdditionalProperties != nil
+}
+
+// kindFieldName converts a kind to the name of its field, e.g. `APIService`
+// becomes `apiService`.
+func kindFieldName(kind string) string {
+	runes := []rune(kind)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		// keep the last upper case letter of an acronym which starts a word.
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+
+	return identifier(string(runes))
+}
+
+// identifier converts a field name to a valid identifier, e.g. `x-foo`
+// becomes `xFoo`.
+func identifier(name string) string {
+	var b strings.Builder
+	upper := false
+
+	for _, r := range name {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			upper = b.Len() > 0
+			continue
+		}
+
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteRune('_')
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	id := b.String()
+	if id == "" {
+		return "value"
+	}
+	if reservedIDs[id] {
+		return id + "_"
+	}
+
+	return id
+}
+
+// fieldKey returns a field name which can be used as an object key.
+func fieldKey(name string) string {
+	if name == identifier(name) {
+		return name
+	}
+
+	return quote(name)
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
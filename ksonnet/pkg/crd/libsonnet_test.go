@@ -0,0 +1,135 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package crd
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	jsonnetutil "github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeFile(t *testing.T, name string) []*CRD {
+	f, err := os.Open("testdata/" + name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	crds, err := Decode(f)
+	require.NoError(t, err)
+
+	return crds
+}
+
+func TestGenerateLibs(t *testing.T) {
+	crds := append(decodeFile(t, "crds.yaml"), decodeFile(t, "list.json")...)
+
+	libs, err := GenerateLibs(crds)
+	require.NoError(t, err)
+
+	require.Len(t, libs, 2)
+	assert.Equal(t, "crds/certmanager.k8s.io/v1alpha1.libsonnet", libs[0].Path)
+	test.AssertOutput(t, "certmanager-v1alpha1.libsonnet", string(libs[0].Data))
+	assert.Equal(t, "crds/networking.istio.io/v1alpha3.libsonnet", libs[1].Path)
+	test.AssertOutput(t, "istio-v1alpha3.libsonnet", string(libs[1].Data))
+
+	// generating again gives the same output.
+	again, err := GenerateLibs(crds)
+	require.NoError(t, err)
+	assert.Equal(t, libs, again)
+}
+
+func TestGenerateLibs_evaluate(t *testing.T) {
+	libs, err := GenerateLibs(decodeFile(t, "crds.yaml"))
+	require.NoError(t, err)
+	require.Len(t, libs, 1)
+
+	snippet := "local certmanager = " + string(libs[0].Data) + `;
+local certificate = certmanager.certificate;
+
+certificate.new("example") +
+certificate.mixin.metadata.withLabels({app: "example"}) +
+certificate.mixin.spec.withCommonName("example.com") +
+certificate.mixin.spec.withDnsNames("example.com") +
+certificate.mixin.spec.withDnsNamesMixin(["www.example.com"]) +
+certificate.mixin.spec.withXRenewBefore("24h") +
+certificate.mixin.spec.issuerRef.withName("letsencrypt")
+`
+
+	out, err := jsonnetutil.NewVM().EvaluateSnippet("snippet", snippet)
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+
+	expected := map[string]interface{}{
+		"apiVersion": "certmanager.k8s.io/v1alpha1",
+		"kind":       "Certificate",
+		"metadata": map[string]interface{}{
+			"name":   "example",
+			"labels": map[string]interface{}{"app": "example"},
+		},
+		"spec": map[string]interface{}{
+			"commonName":     "example.com",
+			"dnsNames":       []interface{}{"example.com", "www.example.com"},
+			"x-renew-before": "24h",
+			"issuerRef":      map[string]interface{}{"name": "letsencrypt"},
+		},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func Test_identifier(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "replicas", expected: "replicas"},
+		{name: "x-renew-before", expected: "xRenewBefore"},
+		{name: "$ref", expected: "ref"},
+		{name: "3scale", expected: "_3scale"},
+		{name: "local", expected: "local_"},
+		{name: "std", expected: "std_"},
+		{name: "-", expected: "value"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, identifier(tc.name))
+		})
+	}
+}
+
+func Test_kindFieldName(t *testing.T) {
+	cases := []struct {
+		kind     string
+		expected string
+	}{
+		{kind: "Certificate", expected: "certificate"},
+		{kind: "APIService", expected: "apiService"},
+		{kind: "HTTPAPISpecBinding", expected: "httpapiSpecBinding"},
+		{kind: "TLS", expected: "tls"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.kind, func(t *testing.T) {
+			assert.Equal(t, tc.expected, kindFieldName(tc.kind))
+		})
+	}
+}
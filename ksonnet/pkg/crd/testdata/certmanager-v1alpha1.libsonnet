@@ -0,0 +1,74 @@
+{
+  // Generated by `ks lib generate-crd`. Do not edit.
+  __ksonnet: { group: 'certmanager.k8s.io', version: 'v1alpha1' },
+  local apiVersion = { apiVersion: 'certmanager.k8s.io/v1alpha1' },
+  // A Certificate is a TLS certificate issued by an Issuer.
+  certificate:: {
+    local kind = { kind: 'Certificate' },
+    new(name=''):: apiVersion + kind + self.mixin.metadata.withName(name),
+    mixin:: {
+      // Standard object metadata.
+      metadata:: {
+        local __metadataMixin(metadata) = { metadata+: metadata },
+        mixinInstance(metadata):: __metadataMixin(metadata),
+        // Annotations is an unstructured key value map stored with a resource that may be set by external tools to store and retrieve arbitrary metadata.
+        withAnnotations(annotations):: self + __metadataMixin({ annotations: annotations }),
+        // Annotations is an unstructured key value map stored with a resource that may be set by external tools to store and retrieve arbitrary metadata.
+        withAnnotationsMixin(annotations):: self + __metadataMixin({ annotations+: annotations }),
+        // Map of string keys and values that can be used to organize and categorize (scope and select) objects.
+        withLabels(labels):: self + __metadataMixin({ labels: labels }),
+        // Map of string keys and values that can be used to organize and categorize (scope and select) objects.
+        withLabelsMixin(labels):: self + __metadataMixin({ labels+: labels }),
+        // Name must be unique within a namespace.
+        withName(name):: self + __metadataMixin({ name: name }),
+        // Namespace defines the space within each name must be unique.
+        withNamespace(namespace):: self + __metadataMixin({ namespace: namespace }),
+      },
+      // Spec is the desired state of the Certificate.
+      spec:: {
+        local __specMixin(spec) = { spec+: spec },
+        mixinInstance(spec):: __specMixin(spec),
+        // CommonName is the common name of the certificate.
+        withCommonName(commonName):: self + __specMixin({ commonName: commonName }),
+        // DNSNames is a list of subject alt names.
+        // The first name is used as the common name if it is not set.
+        withDnsNames(dnsNames):: self + if std.type(dnsNames) == 'array' then __specMixin({ dnsNames: dnsNames }) else __specMixin({ dnsNames: [dnsNames] }),
+        // DNSNames is a list of subject alt names.
+        // The first name is used as the common name if it is not set.
+        withDnsNamesMixin(dnsNames):: self + if std.type(dnsNames) == 'array' then __specMixin({ dnsNames+: dnsNames }) else __specMixin({ dnsNames+: [dnsNames] }),
+        withSecretName(secretName):: self + __specMixin({ secretName: secretName }),
+        withXRenewBefore(xRenewBefore):: self + __specMixin({ "x-renew-before": xRenewBefore }),
+        // IssuerRef is the issuer of the certificate.
+        issuerRef:: {
+          local __specIssuerRefMixin(issuerRef) = __specMixin({ issuerRef+: issuerRef }),
+          mixinInstance(issuerRef):: __specIssuerRefMixin(issuerRef),
+          withKind(kind):: self + __specIssuerRefMixin({ kind: kind }),
+          withName(name):: self + __specIssuerRefMixin({ name: name }),
+        },
+      },
+    },
+  },
+  clusterIssuer:: {
+    local kind = { kind: 'ClusterIssuer' },
+    new(name=''):: apiVersion + kind + self.mixin.metadata.withName(name),
+    withSpec(spec):: self + { spec: spec },
+    withSpecMixin(spec):: self + { spec+: spec },
+    mixin:: {
+      // Standard object metadata.
+      metadata:: {
+        local __metadataMixin(metadata) = { metadata+: metadata },
+        mixinInstance(metadata):: __metadataMixin(metadata),
+        // Annotations is an unstructured key value map stored with a resource that may be set by external tools to store and retrieve arbitrary metadata.
+        withAnnotations(annotations):: self + __metadataMixin({ annotations: annotations }),
+        // Annotations is an unstructured key value map stored with a resource that may be set by external tools to store and retrieve arbitrary metadata.
+        withAnnotationsMixin(annotations):: self + __metadataMixin({ annotations+: annotations }),
+        // Map of string keys and values that can be used to organize and categorize (scope and select) objects.
+        withLabels(labels):: self + __metadataMixin({ labels: labels }),
+        // Map of string keys and values that can be used to organize and categorize (scope and select) objects.
+        withLabelsMixin(labels):: self + __metadataMixin({ labels+: labels }),
+        // Name must be unique within a namespace.
+        withName(name):: self + __metadataMixin({ name: name }),
+      },
+    },
+  },
+}
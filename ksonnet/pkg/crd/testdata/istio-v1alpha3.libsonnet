@@ -0,0 +1,34 @@
+{
+  // Generated by `ks lib generate-crd`. Do not edit.
+  __ksonnet: { group: 'networking.istio.io', version: 'v1alpha3' },
+  local apiVersion = { apiVersion: 'networking.istio.io/v1alpha3' },
+  gateway:: {
+    local kind = { kind: 'Gateway' },
+    new(name=''):: apiVersion + kind + self.mixin.metadata.withName(name),
+    mixin:: {
+      // Standard object metadata.
+      metadata:: {
+        local __metadataMixin(metadata) = { metadata+: metadata },
+        mixinInstance(metadata):: __metadataMixin(metadata),
+        // Annotations is an unstructured key value map stored with a resource that may be set by external tools to store and retrieve arbitrary metadata.
+        withAnnotations(annotations):: self + __metadataMixin({ annotations: annotations }),
+        // Annotations is an unstructured key value map stored with a resource that may be set by external tools to store and retrieve arbitrary metadata.
+        withAnnotationsMixin(annotations):: self + __metadataMixin({ annotations+: annotations }),
+        // Map of string keys and values that can be used to organize and categorize (scope and select) objects.
+        withLabels(labels):: self + __metadataMixin({ labels: labels }),
+        // Map of string keys and values that can be used to organize and categorize (scope and select) objects.
+        withLabelsMixin(labels):: self + __metadataMixin({ labels+: labels }),
+        // Name must be unique within a namespace.
+        withName(name):: self + __metadataMixin({ name: name }),
+        // Namespace defines the space within each name must be unique.
+        withNamespace(namespace):: self + __metadataMixin({ namespace: namespace }),
+      },
+      spec:: {
+        local __specMixin(spec) = { spec+: spec },
+        mixinInstance(spec):: __specMixin(spec),
+        withSelector(selector):: self + __specMixin({ selector: selector }),
+        withSelectorMixin(selector):: self + __specMixin({ selector+: selector }),
+      },
+    },
+  },
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package deprecation
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// convertFn converts an object to the replacement apiVersion. It must not
+// change the object if it returns an error.
+type convertFn func(obj *unstructured.Unstructured, replacement string) error
+
+// Convert converts an object which uses a deprecated API to its replacement
+// if the conversion is simple. It reports whether the object was converted.
+// An error explains why an object with a deprecated API can't be converted.
+func Convert(obj *unstructured.Unstructured) (bool, error) {
+	api, ok := lookup(obj.GroupVersionKind())
+	if !ok {
+		return false, nil
+	}
+
+	if api.convert == nil {
+		if api.replacement == "" {
+			return false, errors.Errorf("%s %s has no replacement", api.groupVersion, api.kind)
+		}
+		return false, errors.Errorf("%s %s has to be converted to %s by hand", api.groupVersion, api.kind, api.replacement)
+	}
+
+	if err := api.convert(obj, api.replacement); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// convertAPIVersion converts objects whose schema is the same in the
+// replacement.
+func convertAPIVersion(obj *unstructured.Unstructured, replacement string) error {
+	obj.SetAPIVersion(replacement)
+	return nil
+}
+
+// convertWorkload converts workloads to apps/v1, which requires a selector.
+// Earlier versions defaulted the selector to the labels of the pod template.
+func convertWorkload(obj *unstructured.Unstructured, replacement string) error {
+	for _, field := range []string{"rollbackTo", "templateGeneration"} {
+		if _, ok := nestedField(obj.Object, "spec", field); ok {
+			return errors.Errorf("spec.%s is not supported by %s", field, replacement)
+		}
+	}
+
+	_, hasSelector := nestedField(obj.Object, "spec", "selector")
+
+	var labels map[string]string
+	if !hasSelector {
+		labels, _, _ = unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "labels")
+		if len(labels) == 0 {
+			return errors.Errorf("%s requires spec.selector, and the pod template has no labels to default it to", replacement)
+		}
+	}
+
+	obj.SetAPIVersion(replacement)
+
+	if !hasSelector {
+		return unstructured.SetNestedStringMap(obj.Object, labels, "spec", "selector", "matchLabels")
+	}
+
+	return nil
+}
+
+func nestedField(obj map[string]interface{}, fields ...string) (interface{}, bool) {
+	var v interface{} = obj
+	for _, field := range fields {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if v, ok = m[field]; !ok {
+			return nil, false
+		}
+	}
+
+	return v, v != nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package deprecation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConvert(t *testing.T) {
+	deployment := func(spec map[string]interface{}) *unstructured.Unstructured {
+		obj := object("extensions/v1beta1", "Deployment", "guestbook-ui")
+		obj.Object["spec"] = spec
+		return obj
+	}
+
+	template := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app": "guestbook-ui"},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		obj       *unstructured.Unstructured
+		expected  *unstructured.Unstructured
+		converted bool
+		isErr     bool
+	}{
+		{
+			name:     "current API",
+			obj:      object("apps/v1", "Deployment", "guestbook-ui"),
+			expected: object("apps/v1", "Deployment", "guestbook-ui"),
+		},
+		{
+			name:      "same schema",
+			obj:       object("rbac.authorization.k8s.io/v1beta1", "Role", "reader"),
+			expected:  object("rbac.authorization.k8s.io/v1", "Role", "reader"),
+			converted: true,
+		},
+		{
+			name: "workload selector defaults to template labels",
+			obj:  deployment(map[string]interface{}{"template": template}),
+			expected: func() *unstructured.Unstructured {
+				obj := deployment(map[string]interface{}{
+					"template": template,
+					"selector": map[string]interface{}{
+						"matchLabels": map[string]interface{}{"app": "guestbook-ui"},
+					},
+				})
+				obj.SetAPIVersion("apps/v1")
+				return obj
+			}(),
+			converted: true,
+		},
+		{
+			name: "workload with selector",
+			obj: deployment(map[string]interface{}{
+				"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"tier": "frontend"}},
+			}),
+			expected: func() *unstructured.Unstructured {
+				obj := deployment(map[string]interface{}{
+					"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"tier": "frontend"}},
+				})
+				obj.SetAPIVersion("apps/v1")
+				return obj
+			}(),
+			converted: true,
+		},
+		{
+			name:     "workload without selector or template labels",
+			obj:      deployment(map[string]interface{}{}),
+			expected: deployment(map[string]interface{}{}),
+			isErr:    true,
+		},
+		{
+			name:     "workload with removed field",
+			obj:      deployment(map[string]interface{}{"template": template, "rollbackTo": map[string]interface{}{}}),
+			expected: deployment(map[string]interface{}{"template": template, "rollbackTo": map[string]interface{}{}}),
+			isErr:    true,
+		},
+		{
+			name:     "conversion by hand",
+			obj:      object("networking.k8s.io/v1beta1", "Ingress", "web"),
+			expected: object("networking.k8s.io/v1beta1", "Ingress", "web"),
+			isErr:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := Convert(tc.obj)
+			if tc.isErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.converted, converted)
+			require.Equal(t, tc.expected, tc.obj)
+		})
+	}
+}
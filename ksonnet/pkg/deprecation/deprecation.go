@@ -0,0 +1,234 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package deprecation finds objects which use deprecated or removed
+// Kubernetes APIs. APIs are looked up in a table of deprecations which ships
+// with ksonnet and, when a cluster is available, in the APIs it serves.
+package deprecation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/utils"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+)
+
+// Status is how an object's API is affected.
+type Status string
+
+const (
+	// StatusDeprecated APIs are served, but will be removed.
+	StatusDeprecated Status = "deprecated"
+	// StatusRemoved APIs were removed in the Kubernetes version objects are
+	// checked for.
+	StatusRemoved Status = "removed"
+	// StatusUnserved APIs are not served by the cluster.
+	StatusUnserved Status = "unserved"
+)
+
+// Finding is an object which uses a deprecated or removed API.
+type Finding struct {
+	Status     Status `json:"status"`
+	Component  string `json:"component,omitempty"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	// Replacement is the apiVersion which replaces the object's API.
+	Replacement string `json:"replacement,omitempty"`
+	// Convertible is true if Convert can convert the object to its replacement.
+	Convertible bool   `json:"convertible"`
+	Message     string `json:"message"`
+}
+
+// Blocking reports whether the object can't be applied.
+func (f Finding) Blocking() bool {
+	return f.Status != StatusDeprecated
+}
+
+// ServedFn reports whether a cluster serves a kind.
+type ServedFn func(gvk schema.GroupVersionKind) (bool, error)
+
+// Checker checks the APIs objects use.
+type Checker struct {
+	version utils.ServerVersion
+	served  ServedFn
+}
+
+// New creates an instance of Checker. Objects are checked for a Kubernetes
+// version; a zero version reports every deprecated API. If served isn't nil,
+// it is used to find APIs the cluster doesn't serve.
+func New(v utils.ServerVersion, served ServedFn) *Checker {
+	return &Checker{
+		version: v,
+		served:  served,
+	}
+}
+
+// ForCluster creates a Checker for the cluster disco is connected to.
+func ForCluster(disco discovery.DiscoveryInterface) (*Checker, error) {
+	v, err := utils.FetchVersion(disco)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch server version")
+	}
+
+	return New(v, DiscoveryServed(disco)), nil
+}
+
+// ParseVersion parses a Kubernetes version like the one environments
+// record, e.g. `v1.8.7`. A blank version is the zero version.
+func ParseVersion(s string) (utils.ServerVersion, error) {
+	if s == "" {
+		return utils.ServerVersion{}, nil
+	}
+
+	return utils.ParseVersion(&version.Info{GitVersion: s})
+}
+
+// DiscoveryServed creates a ServedFn which looks kinds up with discovery.
+func DiscoveryServed(disco discovery.ServerResourcesInterface) ServedFn {
+	return func(gvk schema.GroupVersionKind) (bool, error) {
+		resources, err := disco.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		for _, r := range resources.APIResources {
+			if r.Kind == gvk.Kind {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// Check checks the APIs of objects. Findings are in the order of objects.
+func (c *Checker) Check(objects []*unstructured.Unstructured) ([]Finding, error) {
+	customGroups := definedGroups(objects)
+
+	var findings []Finding
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		api, deprecated := lookup(gvk)
+
+		served := true
+		if c.served != nil && isBuiltinGroup(gvk.Group) && !customGroups.Has(gvk.Group) {
+			var err error
+			if served, err = c.served(gvk); err != nil {
+				return nil, errors.Wrapf(err, "discover %s %s", gvk.GroupVersion(), gvk.Kind)
+			}
+		}
+
+		if served && !deprecated {
+			continue
+		}
+
+		f := Finding{
+			Component:  obj.GetLabels()[metadata.LabelComponent],
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       utils.FqName(obj),
+		}
+
+		desc := fmt.Sprintf("%s %s", f.APIVersion, f.Kind)
+
+		if served {
+			var ok bool
+			if f.Status, ok = c.status(api); !ok {
+				continue
+			}
+		} else {
+			f.Status = StatusUnserved
+		}
+
+		switch f.Status {
+		case StatusUnserved:
+			f.Message = desc + " is not served by the cluster"
+		case StatusRemoved:
+			f.Message = fmt.Sprintf("%s was removed in Kubernetes %s", desc, api.removedIn)
+		default:
+			f.Message = fmt.Sprintf("%s is deprecated since Kubernetes %s and is removed in %s",
+				desc, api.deprecatedIn, api.removedIn)
+		}
+
+		if deprecated {
+			f.Replacement = api.replacement
+			f.Convertible = api.convert != nil
+
+			if api.replacement == "" {
+				f.Message += "; it has no replacement"
+			} else {
+				f.Message += "; use " + api.replacement
+			}
+		}
+
+		findings = append(findings, f)
+	}
+
+	return findings, nil
+}
+
+// status returns the status of a deprecated API in the checked version. It
+// returns false if the API isn't deprecated yet.
+func (c *Checker) status(api deprecatedAPI) (Status, bool) {
+	if c.version == (utils.ServerVersion{}) {
+		return StatusDeprecated, true
+	}
+
+	// A cluster which serves an API hasn't removed it, whatever its version.
+	if c.served == nil && c.version.Compare(api.removedIn.Major, api.removedIn.Minor) >= 0 {
+		return StatusRemoved, true
+	}
+
+	if c.version.Compare(api.deprecatedIn.Major, api.deprecatedIn.Minor) >= 0 {
+		return StatusDeprecated, true
+	}
+
+	return "", false
+}
+
+// definedGroups returns the groups of the CustomResourceDefinitions in
+// objects. Their kinds aren't served until the definitions are applied.
+func definedGroups(objects []*unstructured.Unstructured) sets.String {
+	groups := sets.NewString()
+	for _, obj := range objects {
+		if obj.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+
+		if group, _, _ := unstructured.NestedString(obj.Object, "spec", "group"); group != "" {
+			groups.Insert(group)
+		}
+	}
+
+	return groups
+}
+
+// isBuiltinGroup reports whether a group could belong to a Kubernetes API
+// rather than a custom resource.
+func isBuiltinGroup(group string) bool {
+	return group == "" || !strings.Contains(group, ".") || strings.HasSuffix(group, ".k8s.io")
+}
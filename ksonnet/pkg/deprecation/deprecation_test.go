@@ -0,0 +1,270 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package deprecation
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func object(apiVersion, kind, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetLabels(map[string]string{metadata.LabelComponent: "guestbook"})
+	return obj
+}
+
+func servedKinds(kinds ...string) ServedFn {
+	return func(gvk schema.GroupVersionKind) (bool, error) {
+		for _, kind := range kinds {
+			if kind == gvk.GroupVersion().String()+" "+gvk.Kind {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	crd := object("apiextensions.k8s.io/v1", "CustomResourceDefinition", "widgets.example.k8s.io")
+	crd.Object["spec"] = map[string]interface{}{"group": "example.k8s.io"}
+
+	objects := []*unstructured.Unstructured{
+		object("extensions/v1beta1", "Deployment", "guestbook-ui"),
+		object("v1", "Service", "guestbook-ui"),
+		object("batch/v1beta1", "CronJob", "cleanup"),
+		object("policy/v1beta1", "PodSecurityPolicy", "restricted"),
+		object("example.k8s.io/v1", "Widget", "widget"),
+		object("example.com/v1", "Gadget", "gadget"),
+		crd,
+	}
+
+	cases := []struct {
+		name     string
+		version  utils.ServerVersion
+		served   ServedFn
+		expected []Finding
+		isErr    bool
+	}{
+		{
+			name: "unknown version",
+			expected: []Finding{
+				{
+					Status:      StatusDeprecated,
+					Component:   "guestbook",
+					APIVersion:  "extensions/v1beta1",
+					Kind:        "Deployment",
+					Name:        "guestbook-ui",
+					Replacement: "apps/v1",
+					Convertible: true,
+					Message:     "extensions/v1beta1 Deployment is deprecated since Kubernetes 1.9 and is removed in 1.16; use apps/v1",
+				},
+				{
+					Status:      StatusDeprecated,
+					Component:   "guestbook",
+					APIVersion:  "batch/v1beta1",
+					Kind:        "CronJob",
+					Name:        "cleanup",
+					Replacement: "batch/v1",
+					Convertible: true,
+					Message:     "batch/v1beta1 CronJob is deprecated since Kubernetes 1.21 and is removed in 1.25; use batch/v1",
+				},
+				{
+					Status:     StatusDeprecated,
+					Component:  "guestbook",
+					APIVersion: "policy/v1beta1",
+					Kind:       "PodSecurityPolicy",
+					Name:       "restricted",
+					Message:    "policy/v1beta1 PodSecurityPolicy is deprecated since Kubernetes 1.21 and is removed in 1.25; it has no replacement",
+				},
+			},
+		},
+		{
+			name:    "before deprecation",
+			version: utils.ServerVersion{Major: 1, Minor: 8},
+		},
+		{
+			name:    "removed",
+			version: utils.ServerVersion{Major: 1, Minor: 16},
+			expected: []Finding{
+				{
+					Status:      StatusRemoved,
+					Component:   "guestbook",
+					APIVersion:  "extensions/v1beta1",
+					Kind:        "Deployment",
+					Name:        "guestbook-ui",
+					Replacement: "apps/v1",
+					Convertible: true,
+					Message:     "extensions/v1beta1 Deployment was removed in Kubernetes 1.16; use apps/v1",
+				},
+			},
+		},
+		{
+			name:    "served by the cluster",
+			version: utils.ServerVersion{Major: 1, Minor: 20},
+			served: servedKinds("extensions/v1beta1 Deployment", "v1 Service", "batch/v1beta1 CronJob",
+				"policy/v1beta1 PodSecurityPolicy", "apiextensions.k8s.io/v1 CustomResourceDefinition"),
+			expected: []Finding{
+				{
+					Status:      StatusDeprecated,
+					Component:   "guestbook",
+					APIVersion:  "extensions/v1beta1",
+					Kind:        "Deployment",
+					Name:        "guestbook-ui",
+					Replacement: "apps/v1",
+					Convertible: true,
+					Message:     "extensions/v1beta1 Deployment is deprecated since Kubernetes 1.9 and is removed in 1.16; use apps/v1",
+				},
+			},
+		},
+		{
+			name:    "not served by the cluster",
+			version: utils.ServerVersion{Major: 1, Minor: 25},
+			served:  servedKinds("v1 Service", "batch/v1beta1 CronJob", "policy/v1beta1 PodSecurityPolicy", "apiextensions.k8s.io/v1 CustomResourceDefinition"),
+			expected: []Finding{
+				{
+					Status:      StatusUnserved,
+					Component:   "guestbook",
+					APIVersion:  "extensions/v1beta1",
+					Kind:        "Deployment",
+					Name:        "guestbook-ui",
+					Replacement: "apps/v1",
+					Convertible: true,
+					Message:     "extensions/v1beta1 Deployment is not served by the cluster; use apps/v1",
+				},
+				{
+					Status:      StatusDeprecated,
+					Component:   "guestbook",
+					APIVersion:  "batch/v1beta1",
+					Kind:        "CronJob",
+					Name:        "cleanup",
+					Replacement: "batch/v1",
+					Convertible: true,
+					Message:     "batch/v1beta1 CronJob is deprecated since Kubernetes 1.21 and is removed in 1.25; use batch/v1",
+				},
+				{
+					Status:     StatusDeprecated,
+					Component:  "guestbook",
+					APIVersion: "policy/v1beta1",
+					Kind:       "PodSecurityPolicy",
+					Name:       "restricted",
+					Message:    "policy/v1beta1 PodSecurityPolicy is deprecated since Kubernetes 1.21 and is removed in 1.25; it has no replacement",
+				},
+			},
+		},
+		{
+			name: "discovery error",
+			served: func(gvk schema.GroupVersionKind) (bool, error) {
+				return false, errors.New("failed")
+			},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := New(tc.version, tc.served)
+
+			findings, err := c.Check(objects)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expected, findings)
+		})
+	}
+}
+
+func TestFinding_Blocking(t *testing.T) {
+	require.False(t, Finding{Status: StatusDeprecated}.Blocking())
+	require.True(t, Finding{Status: StatusRemoved}.Blocking())
+	require.True(t, Finding{Status: StatusUnserved}.Blocking())
+}
+
+func TestParseVersion(t *testing.T) {
+	got, err := ParseVersion("v1.8.7")
+	require.NoError(t, err)
+	require.Equal(t, utils.ServerVersion{Major: 1, Minor: 8}, got)
+
+	got, err = ParseVersion("")
+	require.NoError(t, err)
+	require.Equal(t, utils.ServerVersion{}, got)
+
+	_, err = ParseVersion("latest")
+	require.Error(t, err)
+}
+
+type fakeResources struct {
+	resources map[string]*metav1.APIResourceList
+}
+
+func (r *fakeResources) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	if list, ok := r.resources[groupVersion]; ok {
+		return list, nil
+	}
+	return nil, kerrors.NewNotFound(schema.GroupResource{}, groupVersion)
+}
+
+func (r *fakeResources) ServerResources() ([]*metav1.APIResourceList, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeResources) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeResources) ServerPreferredNamespacedResources() ([]*metav1.APIResourceList, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestDiscoveryServed(t *testing.T) {
+	served := DiscoveryServed(&fakeResources{
+		resources: map[string]*metav1.APIResourceList{
+			"apps/v1": {
+				APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}},
+			},
+		},
+	})
+
+	cases := []struct {
+		gvk      schema.GroupVersionKind
+		expected bool
+	}{
+		{gvk: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, expected: true},
+		{gvk: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}},
+		{gvk: schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Deployment"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.gvk.String(), func(t *testing.T) {
+			got, err := served(tc.gvk)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package deprecation
+
+import (
+	"io"
+
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+)
+
+// Report writes findings to w as a table or JSON.
+func Report(w io.Writer, format table.Format, findings []Finding) error {
+	t := table.New("apiFindings", w)
+	t.SetFormat(format)
+	t.SetHeader([]string{"status", "component", "kind", "name", "message"})
+
+	for _, f := range findings {
+		t.Append([]string{string(f.Status), f.Component, f.Kind, f.Name, f.Message})
+	}
+
+	return t.Render()
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package deprecation
+
+import (
+	"github.com/ksonnet/ksonnet/utils"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// deprecatedAPI is a kind in an API version which is deprecated.
+type deprecatedAPI struct {
+	groupVersion string
+	kind         string
+	deprecatedIn utils.ServerVersion
+	removedIn    utils.ServerVersion
+	// replacement is the apiVersion to use instead. It is blank if the
+	// kind was removed without a replacement.
+	replacement string
+	// convert converts objects to the replacement. It is nil if the
+	// conversion isn't simple.
+	convert convertFn
+}
+
+func v(major, minor int) utils.ServerVersion {
+	return utils.ServerVersion{Major: major, Minor: minor}
+}
+
+// deprecatedAPIs is the table of deprecated APIs. It is based on the
+// Kubernetes deprecated API migration guide.
+var deprecatedAPIs = []deprecatedAPI{
+	{"extensions/v1beta1", "DaemonSet", v(1, 9), v(1, 16), "apps/v1", convertWorkload},
+	{"extensions/v1beta1", "Deployment", v(1, 9), v(1, 16), "apps/v1", convertWorkload},
+	{"extensions/v1beta1", "ReplicaSet", v(1, 9), v(1, 16), "apps/v1", convertWorkload},
+	{"extensions/v1beta1", "NetworkPolicy", v(1, 9), v(1, 16), "networking.k8s.io/v1", convertAPIVersion},
+	{"extensions/v1beta1", "PodSecurityPolicy", v(1, 10), v(1, 16), "policy/v1beta1", convertAPIVersion},
+	{"extensions/v1beta1", "Ingress", v(1, 14), v(1, 22), "networking.k8s.io/v1", nil},
+	{"apps/v1beta1", "Deployment", v(1, 9), v(1, 16), "apps/v1", convertWorkload},
+	{"apps/v1beta1", "StatefulSet", v(1, 9), v(1, 16), "apps/v1", convertWorkload},
+	{"apps/v1beta2", "DaemonSet", v(1, 9), v(1, 16), "apps/v1", convertWorkload},
+	{"apps/v1beta2", "Deployment", v(1, 9), v(1, 16), "apps/v1", convertWorkload},
+	{"apps/v1beta2", "ReplicaSet", v(1, 9), v(1, 16), "apps/v1", convertWorkload},
+	{"apps/v1beta2", "StatefulSet", v(1, 9), v(1, 16), "apps/v1", convertWorkload},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", v(1, 14), v(1, 22), "scheduling.k8s.io/v1", convertAPIVersion},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", v(1, 16), v(1, 22), "admissionregistration.k8s.io/v1", nil},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", v(1, 16), v(1, 22), "admissionregistration.k8s.io/v1", nil},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", v(1, 16), v(1, 22), "apiextensions.k8s.io/v1", nil},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", v(1, 17), v(1, 22), "rbac.authorization.k8s.io/v1", convertAPIVersion},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", v(1, 17), v(1, 22), "rbac.authorization.k8s.io/v1", convertAPIVersion},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", v(1, 17), v(1, 22), "rbac.authorization.k8s.io/v1", convertAPIVersion},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", v(1, 17), v(1, 22), "rbac.authorization.k8s.io/v1", convertAPIVersion},
+	{"apiregistration.k8s.io/v1beta1", "APIService", v(1, 19), v(1, 22), "apiregistration.k8s.io/v1", convertAPIVersion},
+	{"networking.k8s.io/v1beta1", "Ingress", v(1, 19), v(1, 22), "networking.k8s.io/v1", nil},
+	{"batch/v1beta1", "CronJob", v(1, 21), v(1, 25), "batch/v1", convertAPIVersion},
+	{"policy/v1beta1", "PodDisruptionBudget", v(1, 21), v(1, 25), "policy/v1", nil},
+	{"policy/v1beta1", "PodSecurityPolicy", v(1, 21), v(1, 25), "", nil},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", v(1, 22), v(1, 25), "autoscaling/v2", nil},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", v(1, 23), v(1, 26), "autoscaling/v2", nil},
+}
+
+// lookup finds a kind in the table of deprecated APIs.
+func lookup(gvk schema.GroupVersionKind) (deprecatedAPI, bool) {
+	gv := gvk.GroupVersion().String()
+	for _, api := range deprecatedAPIs {
+		if api.groupVersion == gv && api.kind == gvk.Kind {
+			return api, true
+		}
+	}
+
+	return deprecatedAPI{}, false
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
 	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/util/dockerregistry"
 	"github.com/pkg/errors"
 	godiff "github.com/shazow/go-diff"
 	"github.com/sirupsen/logrus"
@@ -39,14 +40,41 @@ type Differ struct {
 	remoteGen yamlGenerator
 }
 
+// Opt is an option for configuring Differ.
+type Opt func(d *Differ)
+
+// ResolveImages is an option which pins the images of local objects to
+// digests before they are compared. If platform is set, multi-platform images
+// are pinned to the digest of the image for the platform.
+func ResolveImages(platform string) Opt {
+	return func(d *Differ) {
+		if yl, ok := d.localGen.(*yamlLocal); ok {
+			yl.collectObjectsFn = resolvedCollectObjects(platform)
+		}
+	}
+}
+
+// FilterObjects is an option which only compares the local and remote
+// objects selected by a filter.
+func FilterObjects(f pipeline.ObjectFilter) Opt {
+	return func(d *Differ) {
+		if yl, ok := d.localGen.(*yamlLocal); ok {
+			yl.filter = f
+		}
+		if yr, ok := d.remoteGen.(*yamlRemote); ok {
+			yr.filter = f
+		}
+	}
+}
+
 // DefaultDiff runs diff with default options.
-func DefaultDiff(a app.App, config *client.Config, components []string, l1 *Location, l2 *Location) (io.Reader, error) {
-	differ := New(a, config, components)
+func DefaultDiff(a app.App, config *client.Config, components []string, l1 *Location, l2 *Location, opts ...Opt) (io.Reader, error) {
+	differ := New(a, config, components, opts...)
 	return differ.Diff(l2, l1)
 }
 
 // New creates an instance of Differ.
-func New(a app.App, config *client.Config, components []string) *Differ {
+func New(a app.App, config *client.Config, components []string, opts ...Opt) *Differ {
 	yl := newYamlLocal(a)
 	yr := newYamlRemote(a, config)
 
@@ -58,6 +86,10 @@ func New(a app.App, config *client.Config, components []string) *Differ {
 		remoteGen:  yr,
 	}
 
+	for _, opt := range opts {
+		opt(d)
+	}
+
 	return d
 }
 
@@ -107,6 +139,7 @@ type yamlGenerator interface {
 
 type yamlLocal struct {
 	app              app.App
+	filter           pipeline.ObjectFilter
 	collectObjectsFn func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error)
 	showFn           func(io.Writer, []*unstructured.Unstructured) error
 }
@@ -124,6 +157,22 @@ func localCollectObjects(a app.App, envName string, componentNames []string) ([]
 	return p.Objects(componentNames)
 }
 
+func resolvedCollectObjects(platform string) func(app.App, string, []string) ([]*unstructured.Unstructured, error) {
+	return func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+		var opts []dockerregistry.DigesterOpt
+		if platform != "" {
+			p, err := dockerregistry.ParsePlatform(platform)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, dockerregistry.WithPlatform(p))
+		}
+
+		p := pipeline.New(a, envName, pipeline.ResolveImages(dockerregistry.NewDefaultDigester(opts...)))
+		return p.Objects(componentNames)
+	}
+}
+
 func (yl *yamlLocal) Generate(location *Location, components []string) (io.ReadSeeker, error) {
 	var buf bytes.Buffer
 
@@ -133,6 +182,10 @@ func (yl *yamlLocal) Generate(location *Location, components []string) (io.ReadS
 
 	}
 
+	if objects, err = yl.filter.Filter(objects); err != nil {
+		return nil, err
+	}
+
 	cluster.UnstructuredSlice(objects).Sort()
 
 	if err := yl.showFn(&buf, objects); err != nil {
@@ -145,6 +198,7 @@ func (yl *yamlLocal) Generate(location *Location, components []string) (io.ReadS
 type yamlRemote struct {
 	app              app.App
 	config           *client.Config
+	filter           pipeline.ObjectFilter
 	genClientsFn     func(a app.App, clientConfig *client.Config, envName string) (cluster.Clients, error)
 	collectObjectsFn func(string, cluster.Clients, []string) ([]*unstructured.Unstructured, error)
 	showFn           func(io.Writer, []*unstructured.Unstructured) error
@@ -179,6 +233,10 @@ func (yr *yamlRemote) Generate(location *Location, components []string) (io.Read
 		return nil, err
 	}
 
+	if objects, err = yr.filter.Filter(objects); err != nil {
+		return nil, err
+	}
+
 	cluster.UnstructuredSlice(objects).Sort()
 
 	if err := yr.showFn(&buf, objects); err != nil {
@@ -27,6 +27,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/ksonnet/ksonnet/pkg/util/test"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
@@ -79,6 +80,7 @@ func Test_yamlLocal(t *testing.T) {
 	cases := []struct {
 		name             string
 		collectObjectsFn func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error)
+		filter           pipeline.ObjectFilter
 		showFn           func(io.Writer, []*unstructured.Unstructured) error
 		expected         string
 		isErr            bool
@@ -112,6 +114,24 @@ func Test_yamlLocal(t *testing.T) {
 			showFn:   showYAML,
 			expected: sortedYAML,
 		},
+		{
+			name: "filtered",
+			collectObjectsFn: func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+				return genObjects(), nil
+			},
+			filter:   pipeline.ObjectFilter{Kinds: []string{"deployment"}},
+			showFn:   showNames,
+			expected: "deploymentA\ndeploymentZ\n",
+		},
+		{
+			name: "invalid filter",
+			collectObjectsFn: func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+				return genObjects(), nil
+			},
+			filter: pipeline.ObjectFilter{Names: []string{"["}},
+			showFn: showNames,
+			isErr:  true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -122,6 +142,7 @@ func Test_yamlLocal(t *testing.T) {
 				yl := newYamlLocal(appMock)
 
 				yl.collectObjectsFn = tc.collectObjectsFn
+				yl.filter = tc.filter
 				yl.showFn = tc.showFn
 
 				rs, err := yl.Generate(location, []string{})
@@ -155,6 +176,7 @@ func Test_yamlRemote(t *testing.T) {
 		name      string
 		appSetup  func(a *mocks.App)
 		collectFn func(namespace string, clients cluster.Clients, components []string) ([]*unstructured.Unstructured, error)
+		filter    pipeline.ObjectFilter
 		showFn    func(w io.Writer, objects []*unstructured.Unstructured) error
 		expected  string
 		isErr     bool
@@ -206,6 +228,16 @@ func Test_yamlRemote(t *testing.T) {
 			showFn:   showYAML,
 			expected: sortedYAML,
 		},
+		{
+			name:     "filtered",
+			appSetup: validAppSetup,
+			collectFn: func(namespace string, clients cluster.Clients, components []string) ([]*unstructured.Unstructured, error) {
+				return genObjects(), nil
+			},
+			filter:   pipeline.ObjectFilter{Names: []string{"service*"}},
+			showFn:   showNames,
+			expected: "serviceA\n",
+		},
 	}
 
 	for _, tc := range cases {
@@ -217,6 +249,7 @@ func Test_yamlRemote(t *testing.T) {
 				yr := newYamlRemote(appMock, config)
 
 				yr.collectObjectsFn = tc.collectFn
+				yr.filter = tc.filter
 				yr.showFn = tc.showFn
 				yr.genClientsFn = func(a app.App, clientConfig *client.Config, envName string) (cluster.Clients, error) {
 					return cluster.Clients{}, nil
@@ -240,6 +273,14 @@ func Test_yamlRemote(t *testing.T) {
 	}
 }
 
+func showNames(out io.Writer, objects []*unstructured.Unstructured) error {
+	for _, obj := range objects {
+		fmt.Fprintln(out, obj.GetName())
+	}
+
+	return nil
+}
+
 func showYAML(out io.Writer, objects []*unstructured.Unstructured) error {
 	for _, obj := range objects {
 		fmt.Fprintln(out, "---")
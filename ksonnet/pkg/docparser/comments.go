@@ -0,0 +1,80 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package docparser
+
+import "strings"
+
+// Comment is a comment in a jsonnet snippet.
+type Comment struct {
+	// Text is the comment without its markers and surrounding whitespace.
+	Text string
+	// Trailing is true if the comment follows code on the same line.
+	Trailing bool
+}
+
+// Comments returns the text of the comments in a jsonnet snippet in the order
+// they appear. Comment markers and surrounding whitespace are removed, and
+// empty comments are skipped.
+func Comments(fn string, input string) ([]string, error) {
+	placed, err := PlacedComments(fn, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []string
+	for _, c := range placed {
+		comments = append(comments, c.Text)
+	}
+
+	return comments, nil
+}
+
+// PlacedComments returns the comments in a jsonnet snippet in the order they
+// appear, along with whether each comment trails code on its line. A comment
+// spanning several lines results in one Comment per non-empty line.
+func PlacedComments(fn string, input string) ([]Comment, error) {
+	tokens, err := Lex(fn, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []Comment
+	afterCode := false
+	for _, t := range tokens {
+		for _, f := range t.fodder {
+			if f.Kind == FodderWhitespace {
+				if strings.Contains(f.Data, "\n") {
+					afterCode = false
+				}
+				continue
+			}
+
+			for _, line := range strings.Split(f.Data, "\n") {
+				if text := strings.TrimSpace(line); text != "" {
+					comments = append(comments, Comment{Text: text, Trailing: afterCode})
+				}
+			}
+
+			if f.Kind != FodderCommentC {
+				afterCode = false
+			}
+		}
+
+		afterCode = true
+	}
+
+	return comments, nil
+}
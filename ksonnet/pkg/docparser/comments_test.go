@@ -0,0 +1,74 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package docparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	src := `// first
+local a = 1; # second
+{
+  /* third
+     fourth */
+  a: a,
+  //
+}
+`
+
+	got, err := Comments("test.jsonnet", src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"first", "second", "third", "fourth"}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %#v; got %#v", expected, got)
+	}
+
+	if _, err := Comments("test.jsonnet", "/* unterminated"); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestPlacedComments(t *testing.T) {
+	src := `// first
+{
+  a: 1, // second
+  /* third */ b: 2, # fourth
+  // fifth
+  c: 3,
+}
+`
+
+	got, err := PlacedComments("test.jsonnet", src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Comment{
+		{Text: "first"},
+		{Text: "second", Trailing: true},
+		{Text: "third"},
+		{Text: "fourth", Trailing: true},
+		{Text: "fifth"},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %#v; got %#v", expected, got)
+	}
+}
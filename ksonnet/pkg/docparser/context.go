@@ -114,6 +114,8 @@ func directChildren(node ast.Node) []ast.Node {
 		return []ast.Node{node.Index}
 	case *ast.InSuper:
 		return []ast.Node{node.Index}
+	case *ast.Parens:
+		return []ast.Node{node.Inner}
 	case *ast.Unary:
 		return []ast.Node{node.Expr}
 	case *ast.Var:
@@ -187,6 +189,8 @@ func thunkChildren(node ast.Node) []ast.Node {
 		return nil
 	case *ast.InSuper:
 		return nil
+	case *ast.Parens:
+		return nil
 	case *ast.Unary:
 		return nil
 	case *ast.Var:
@@ -275,6 +279,8 @@ func specialChildren(node ast.Node) []ast.Node {
 		return nil
 	case *ast.InSuper:
 		return nil
+	case *ast.Parens:
+		return nil
 	case *ast.Unary:
 		return nil
 	case *ast.Var:
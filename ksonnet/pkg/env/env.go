@@ -18,7 +18,11 @@ package env
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/ksonnet/ksonnet/pkg/helm"
 	utilio "github.com/ksonnet/ksonnet/pkg/util/io"
@@ -40,6 +44,10 @@ const (
 
 	// envRootName is the name for the environment root.
 	envRootName = "environments"
+
+	// revendorPrefix is the prefix of the temporary directories packages
+	// are re-vendored to.
+	revendorPrefix = "ksvendor"
 )
 
 var (
@@ -53,6 +61,11 @@ func AddJPaths(paths ...string) {
 	componentJPaths = append(componentJPaths, paths...)
 }
 
+// JPaths returns the paths added to JPath for a component evaluation.
+func JPaths() []string {
+	return append([]string(nil), componentJPaths...)
+}
+
 // AddExtVar adds an ext var to a component evaluation.
 func AddExtVar(key, value string) {
 	componentExtVars[key] = value
@@ -85,6 +98,33 @@ func AddTlaVarFile(fs afero.Fs, key, filePath string) error {
 	return nil
 }
 
+// WriteVMConfig writes the jpaths, ext vars and tla vars added for component
+// evaluation to w in a stable order. It identifies the configuration when
+// caching evaluated components.
+func WriteVMConfig(w io.Writer) {
+	for _, path := range componentJPaths {
+		fmt.Fprintf(w, "jpath %s\n", path)
+	}
+
+	for _, vars := range []struct {
+		kind string
+		m    map[string]string
+	}{
+		{kind: "ext", m: componentExtVars},
+		{kind: "tla", m: componentTlaVars},
+	} {
+		keys := make([]string, 0, len(vars.m))
+		for k := range vars.m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s %q=%q\n", vars.kind, k, vars.m[k])
+		}
+	}
+}
+
 // MainFile returns the contents of the environment's main source.
 func MainFile(a app.App, envName string) (string, error) {
 	path, err := Path(a, envName, envFileName)
@@ -108,23 +148,50 @@ func Evaluate(a app.App, envName, components, paramsStr string, opts ...jsonnet.
 		return "", err
 	}
 
-	evaluated, err := evaluateMain(a, envName, snippet, components, paramsStr, opts...)
+	return evaluateMain(a, envName, snippet, components, paramsStr, opts...)
+}
+
+func evaluateMain(a app.App, envName, snippet, components, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
+	vm, cleanup, err := newVM(a, envName, opts...)
 	if err != nil {
 		return "", err
 	}
+	defer cleanup()
+
+	vm.ExtCode(ComponentsExtCodeKey, components)
+	vm.ExtCode("__ksonnet/params", paramsStr)
 
-	return upgradeArray(evaluated)
+	return vm.EvaluateSnippet(envFileName, snippet)
 }
 
-func evaluateMain(a app.App, envName, snippet, components, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
-	libPath, err := a.LibPath(envName)
+// EvaluateTest evaluates a component test with the same import paths, ext vars
+// and params as the environment's components. The objects rendered for the
+// environment are available to the test as `std.extVar("__ksonnet/objects")`.
+func EvaluateTest(a app.App, envName, filename, snippet, objects, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
+	vm, cleanup, err := newVM(a, envName, opts...)
 	if err != nil {
 		return "", err
 	}
+	defer cleanup()
+
+	vm.ExtCode(ObjectsExtCodeKey, objects)
+	vm.ExtCode("__ksonnet/params", paramsStr)
+
+	return vm.EvaluateSnippet(filename, snippet)
+}
+
+// newVM creates a VM for evaluating code in an environment. The returned
+// function removes the re-vendored packages and must be called once the VM is
+// no longer needed.
+func newVM(a app.App, envName string, opts ...jsonnet.VMOpt) (*jsonnet.VM, func() error, error) {
+	libPath, err := a.LibPath(envName)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	appEnv, err := a.Environment(envName)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 
 	vm := jsonnet.NewVM(opts...)
@@ -146,9 +213,8 @@ func evaluateMain(a app.App, envName, snippet, components, paramsStr string, opt
 	pm := registry.NewPackageManager(a)
 	revendoredPath, cleanup, err := revendorPackages(a, pm, appEnv)
 	if err != nil {
-		return "", errors.Wrapf(err, "revendoring packages for environment: %v", envName)
+		return nil, nil, errors.Wrapf(err, "revendoring packages for environment: %v", envName)
 	}
-	defer cleanup()
 	vm.AddJPath(revendoredPath) // TODO does precedence matter?
 	// end re-vendor
 
@@ -163,7 +229,8 @@ func evaluateMain(a app.App, envName, snippet, components, paramsStr string, opt
 
 	envCode, err := params.JsonnetEnvObject(a, envName)
 	if err != nil {
-		return "", err
+		cleanup()
+		return nil, nil, err
 	}
 
 	for k, v := range componentExtVars {
@@ -175,33 +242,10 @@ func evaluateMain(a app.App, envName, snippet, components, paramsStr string, opt
 	}
 
 	vm.ExtCode("__ksonnet/environments", envCode)
-	vm.ExtCode(ComponentsExtCodeKey, components)
-	vm.ExtCode("__ksonnet/params", paramsStr)
 
-	return vm.EvaluateSnippet(envFileName, snippet)
-}
-
-// upgradeArray wraps component lists in Kubernetes lists.
-func upgradeArray(snippet string) (string, error) {
-	vm := jsonnet.NewVM()
-
-	vm.ExtCode("__src", snippet)
-	return vm.EvaluateSnippet("upgradeArray", jsonnetUpgradeArray)
+	return vm, cleanup, nil
 }
 
-var jsonnetUpgradeArray = `
-local __src = std.extVar("__src");
-local components = std.objectFields(__src);
-
-{
-[x]:
-  if std.type(__src[x]) == "array"
-  then {apiVersion: "v1", kind: "List", items: __src[x]}
-  else __src[x]
-for x in components
-}
-`
-
 func envRoot(a app.App, envName string) (string, error) {
 	envSpec, err := a.Environment(envName)
 	if err != nil {
@@ -274,6 +318,18 @@ func buildPackagePaths(pm registry.PackageManager, e *app.EnvironmentConfig) (ma
 	return result, nil
 }
 
+// IsRevendored reports whether a path is in a temporary directory packages are
+// re-vendored to. Re-vendored packages are copies of packages in the app's
+// vendor directory.
+func IsRevendored(path string) bool {
+	rel, err := filepath.Rel(os.TempDir(), path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	return strings.HasPrefix(rel, revendorPrefix)
+}
+
 // Builds a vendor import path with the correct versions of referenced packages for
 // the specified environment.
 // The caller is responsible for calling the returned cleanup function to release
@@ -304,7 +360,7 @@ func revendorPackages(a app.App, pm registry.PackageManager, e *app.EnvironmentC
 	}
 
 	// Build our temporary space
-	tmpDir, err := afero.TempDir(fs, "", "ksvendor")
+	tmpDir, err := afero.TempDir(fs, "", revendorPrefix)
 	if err != nil {
 		return "", noop, errors.Wrap(err, "creating temporary vendor path")
 	}
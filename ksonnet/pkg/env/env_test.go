@@ -243,6 +243,35 @@ func TestEvaluate(t *testing.T) {
 	})
 }
 
+func TestEvaluateTest(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		envSpec := &app.EnvironmentConfig{
+			Path: "default",
+			Destination: &app.EnvironmentDestinationSpec{
+				Server:    "http://example.com",
+				Namespace: "default",
+			},
+		}
+		a.On("Environment", "default").Return(envSpec, nil)
+		a.On("Libraries").Return(app.LibraryConfigs{}, nil)
+		a.On("Registries").Return(app.RegistryConfigs{}, nil)
+
+		snippet := `{
+  replicas: std.extVar("__ksonnet/objects")[0].spec.replicas == 5,
+  name: std.extVar("__ksonnet/params").components.guestbook.name,
+}`
+		objects := `[{"kind": "Deployment", "spec": {"replicas": 5}}]`
+		paramsStr := `{"components": {"guestbook": {"name": "guestbook"}}}`
+
+		got, err := EvaluateTest(a, "default", "/app/tests/guestbook_test.jsonnet", snippet, objects, paramsStr,
+			jsonnet.AferoImporterOpt(fs))
+		require.NoError(t, err)
+
+		expected := "{\n   \"name\": \"guestbook\",\n   \"replicas\": true\n}\n"
+		require.Equal(t, expected, got)
+	})
+}
+
 func TestEvaluate_versionedPackages(t *testing.T) {
 	require.Empty(t, componentJPaths)
 
@@ -300,14 +329,15 @@ func TestMainFile(t *testing.T) {
 	})
 }
 
-func Test_upgradeArray(t *testing.T) {
-	snippet, err := ioutil.ReadFile(filepath.FromSlash("testdata/upgradeArray/in.jsonnet"))
-	require.NoError(t, err)
-
-	got, err := upgradeArray(string(snippet))
+func TestIsRevendored(t *testing.T) {
+	fs := afero.NewOsFs()
+	dir, err := afero.TempDir(fs, "", revendorPrefix)
 	require.NoError(t, err)
+	defer fs.RemoveAll(dir)
 
-	test.AssertOutput(t, "upgradeArray/out.jsonnet", got)
+	assert.True(t, IsRevendored(filepath.Join(dir, "incubator", "redis", "redis.libsonnet")))
+	assert.False(t, IsRevendored(filepath.Join(os.TempDir(), "shared", "redis.libsonnet")))
+	assert.False(t, IsRevendored("/app/vendor/incubator/redis/redis.libsonnet"))
 }
 
 // Helper for creating mock pkg.Package
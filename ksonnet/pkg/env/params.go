@@ -16,10 +16,13 @@
 package env
 
 import (
+	"sort"
+
 	param "github.com/ksonnet/ksonnet/metadata/params"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/component"
 	"github.com/ksonnet/ksonnet/pkg/params"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
@@ -176,6 +179,104 @@ func DeleteParam(a app.App, envName, componentName, paramName string) error {
 	return nil
 }
 
+// ParamsUpdate is an update to an environment params or globals file.
+type ParamsUpdate struct {
+	// Path is the path to the file.
+	Path string
+	// Original is the current contents of the file.
+	Original string
+	// Updated is the contents of the file after the update.
+	Updated string
+}
+
+// UpdateParams computes the updates to an environment's params and globals
+// files which set the supplied component and global params. Nothing is
+// written to disk; use WriteParamsUpdates to persist the updates.
+func UpdateParams(a app.App, envName string, componentParams map[string]param.Params, globals param.Params) ([]ParamsUpdate, error) {
+	if err := ensureEnvExists(a, envName); err != nil {
+		return nil, err
+	}
+
+	var updates []ParamsUpdate
+
+	if len(componentParams) > 0 {
+		path, err := Path(a, envName, paramsFileName)
+		if err != nil {
+			return nil, err
+		}
+
+		text, err := afero.ReadFile(a.Fs(), path)
+		if err != nil {
+			return nil, err
+		}
+
+		var names []string
+		for name := range componentParams {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		eps := params.NewEnvParamSet()
+		updated := string(text)
+		for _, name := range names {
+			updated, err = eps.Set(name, updated, componentParams[name])
+			if err != nil {
+				return nil, errors.Wrapf(err, "set params for component %q", name)
+			}
+		}
+
+		updates = append(updates, ParamsUpdate{Path: path, Original: string(text), Updated: updated})
+	}
+
+	if len(globals) > 0 {
+		path, err := Path(a, envName, globalsFileName)
+		if err != nil {
+			return nil, err
+		}
+
+		exists, err := afero.Exists(a.Fs(), path)
+		if err != nil {
+			return nil, err
+		}
+
+		var text []byte
+		if exists {
+			text, err = afero.ReadFile(a.Fs(), path)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		source := string(text)
+		if !exists {
+			source = "{\n}"
+		}
+
+		egs := params.NewEnvGlobalsSet()
+		updated, err := egs.Set(source, globals)
+		if err != nil {
+			return nil, errors.Wrap(err, "set global params")
+		}
+
+		updates = append(updates, ParamsUpdate{Path: path, Original: string(text), Updated: updated})
+	}
+
+	return updates, nil
+}
+
+// WriteParamsUpdates writes params updates to disk.
+func WriteParamsUpdates(a app.App, updates []ParamsUpdate) error {
+	for _, u := range updates {
+		if err := afero.WriteFile(a.Fs(), u.Path, []byte(u.Updated), app.DefaultFilePermissions); err != nil {
+			return err
+		}
+
+		log.Debugf("Updated %s", u.Path)
+	}
+
+	return nil
+}
+
 // GetParamsConfig is config items for getting environment params.
 type GetParamsConfig struct {
 	App app.App
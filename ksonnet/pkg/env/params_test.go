@@ -64,6 +64,32 @@ func TestSetParams(t *testing.T) {
 	})
 }
 
+func TestUpdateParams(t *testing.T) {
+	withEnv(t, func(appMock *mocks.App, fs afero.Fs) {
+		componentParams := map[string]params.Params{
+			"component2": {"replicas": float64(3)},
+			"component1": {"foo": "baz", "image": "nginx"},
+		}
+		globals := params.Params{"env": "prod"}
+
+		updates, err := UpdateParams(appMock, "env1", componentParams, globals)
+		require.NoError(t, err)
+		require.Len(t, updates, 2)
+
+		require.Equal(t, "/environments/env1/params.libsonnet", updates[0].Path)
+		require.Equal(t, "/environments/env1/globals.libsonnet", updates[1].Path)
+
+		// nothing is written until the updates are written.
+		compareOutput(t, fs, "params.libsonnet", "/environments/env1/params.libsonnet")
+
+		err = WriteParamsUpdates(appMock, updates)
+		require.NoError(t, err)
+
+		compareOutput(t, fs, "import-params.libsonnet", "/environments/env1/params.libsonnet")
+		compareOutput(t, fs, "import-globals.libsonnet", "/environments/env1/globals.libsonnet")
+	})
+}
+
 func TestDeleteParams(t *testing.T) {
 	withEnv(t, func(appMock *mocks.App, fs afero.Fs) {
 		err := DeleteParam(appMock, "env1", "component1", "foo")
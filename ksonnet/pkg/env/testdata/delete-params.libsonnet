@@ -1,7 +1,7 @@
 local params = import '../../components/params.libsonnet';
-
 params + {
-  components+: {
-    component1+: {},
+  components +: {
+    component1 +: {
+    },
   },
-}
\ No newline at end of file
+}
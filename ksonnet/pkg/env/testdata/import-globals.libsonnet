@@ -0,0 +1,4 @@
+{
+  foo: "bar",
+  env: 'prod',
+}
\ No newline at end of file
@@ -0,0 +1,12 @@
+local params = import '../../components/params.libsonnet';
+params + {
+  components +: {
+    component1 +: {
+      foo: 'baz',
+      image: 'nginx',
+    },
+    component2+: {
+      replicas: 3,
+    },
+  },
+}
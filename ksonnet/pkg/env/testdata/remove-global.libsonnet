@@ -1 +1,2 @@
-{}
\ No newline at end of file
+{
+}
\ No newline at end of file
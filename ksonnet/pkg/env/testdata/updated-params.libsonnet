@@ -1,9 +1,8 @@
 local params = import '../../components/params.libsonnet';
-
 params + {
-  components+: {
-    component1+: {
+  components +: {
+    component1 +: {
       foo: 'bar',
     },
   },
-}
\ No newline at end of file
+}
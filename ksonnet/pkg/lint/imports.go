@@ -0,0 +1,126 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+)
+
+// importBinding is a local which is bound to an import.
+type importBinding struct {
+	file string
+	loc  ast.Location
+}
+
+type importScope map[ast.Identifier]importBinding
+
+func (s importScope) with(fn func(s importScope)) importScope {
+	scope := make(importScope)
+	for k, v := range s {
+		scope[k] = v
+	}
+
+	fn(scope)
+	return scope
+}
+
+// importLinter finds locals, function parameters and object locals which
+// shadow a local bound to an import.
+type importLinter struct {
+	path     string
+	problems []Problem
+}
+
+func lintImports(path string, node ast.Node) []Problem {
+	il := &importLinter{path: path}
+	il.visit(node, importScope{})
+
+	return il.problems
+}
+
+func (il *importLinter) visit(node ast.Node, scope importScope) {
+	if node == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *ast.Local:
+		// binds in a local can refer to each other, so they share a scope.
+		inner := scope.with(func(s importScope) {
+			for _, bind := range n.Binds {
+				il.bind(s, bind.Variable, bind.Body, bind.Body)
+			}
+		})
+
+		for _, bind := range n.Binds {
+			il.visit(bind.Body, inner)
+			if bind.Fun != nil {
+				il.visit(bind.Fun, inner)
+			}
+		}
+
+		il.visit(n.Body, inner)
+		return
+	case *ast.Function:
+		inner := scope.with(func(s importScope) {
+			for _, p := range n.Parameters.Required {
+				il.bind(s, p, nil, n)
+			}
+			for _, p := range n.Parameters.Optional {
+				il.bind(s, p.Name, nil, n)
+			}
+		})
+
+		for _, child := range children(n) {
+			il.visit(child, inner)
+		}
+		return
+	case *astext.Object:
+		inner := scope.with(func(s importScope) {
+			for _, f := range n.Fields {
+				if f.Kind == ast.ObjectLocal && f.Id != nil {
+					il.bind(s, *f.Id, f.Expr2, f.Expr2)
+				}
+			}
+		})
+
+		for _, child := range children(n) {
+			il.visit(child, inner)
+		}
+		return
+	}
+
+	for _, child := range children(node) {
+		il.visit(child, scope)
+	}
+}
+
+// bind adds a binding to a scope. If the name shadows an import, a problem is
+// reported at the location of at.
+func (il *importLinter) bind(s importScope, name ast.Identifier, value, at ast.Node) {
+	if prev, ok := s[name]; ok {
+		il.problems = append(il.problems, problemAt(il.path, at.Loc().Begin, RuleShadowedImport,
+			"%q shadows the import of %q on line %d", name, prev.file, prev.loc.Line))
+	}
+
+	if imp, ok := value.(*ast.Import); ok {
+		s[name] = importBinding{file: imp.File.Value, loc: imp.Loc().Begin}
+		return
+	}
+
+	delete(s, name)
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_lintImports(t *testing.T) {
+	cases := []struct {
+		name  string
+		src   string
+		lines []int
+	}{
+		{
+			name: "no shadowing",
+			src: `local k = import "k.libsonnet";
+local a = 1;
+{ a: k.a + a }`,
+		},
+		{
+			name: "local",
+			src: `local k = import "k.libsonnet";
+local k = {};
+k`,
+			lines: []int{2},
+		},
+		{
+			name: "function parameter",
+			src: `local k = import "k.libsonnet";
+local f(k) = k;
+f(1)`,
+			lines: []int{2},
+		},
+		{
+			name: "object local",
+			src: `local k = import "k.libsonnet";
+{
+  local k = 1,
+  a: k,
+}`,
+			lines: []int{3},
+		},
+		{
+			name: "rebound before import",
+			src: `local a = {
+  local k = 1,
+  a: k,
+};
+local k = import "k.libsonnet";
+a`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			node, err := parse("file.jsonnet", tc.src)
+			require.NoError(t, err)
+
+			var lines []int
+			for _, p := range lintImports("file.jsonnet", node) {
+				assert.Equal(t, RuleShadowedImport, p.Rule)
+				lines = append(lines, p.Line)
+			}
+
+			assert.Equal(t, tc.lines, lines)
+		})
+	}
+}
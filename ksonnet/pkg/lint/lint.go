@@ -0,0 +1,283 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package lint finds common mistakes in the components, params and
+// environments of a ksonnet app.
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	// RuleUnusedParam reports params which are not used by their component.
+	RuleUnusedParam = "unused-param"
+	// RuleUndefinedParam reports params which are used by a component but are
+	// not defined.
+	RuleUndefinedParam = "undefined-param"
+	// RuleShadowedImport reports locals which shadow an imported local.
+	RuleShadowedImport = "shadowed-import"
+	// RuleUntargetedComponent reports components which are not targeted by any
+	// environment.
+	RuleUntargetedComponent = "untargeted-component"
+	// RuleMissingNamespace reports namespaced objects without a namespace.
+	RuleMissingNamespace = "missing-namespace"
+	// RuleMissingLabels reports objects without labels.
+	RuleMissingLabels = "missing-labels"
+)
+
+// Problem is a problem found by the linter. Line and Column are 1-based, and
+// are zero if the problem applies to the whole file.
+type Problem struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	pos := p.Path
+	if p.Line > 0 {
+		pos = fmt.Sprintf("%s:%d:%d", p.Path, p.Line, p.Column)
+	}
+
+	return fmt.Sprintf("%s: %s (%s)", pos, p.Message, p.Rule)
+}
+
+// Linter lints an app.
+type Linter struct {
+	app app.App
+
+	modulesFn        func(a app.App) ([]component.Module, error)
+	modulesFromEnvFn func(a app.App, envName string) ([]component.Module, error)
+}
+
+// New creates an instance of Linter.
+func New(a app.App) *Linter {
+	return &Linter{
+		app:              a,
+		modulesFn:        component.Modules,
+		modulesFromEnvFn: component.ModulesFromEnv,
+	}
+}
+
+// Lint lints the app. Problems are sorted by path and position.
+func (l *Linter) Lint() ([]Problem, error) {
+	modules, err := l.modulesFn(l.app)
+	if err != nil {
+		return nil, err
+	}
+
+	envParams, err := l.envParams()
+	if err != nil {
+		return nil, err
+	}
+
+	targeted, err := l.targetedModules()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, m := range modules {
+		mp, err := l.lintModule(m, envParams, targeted[m.Name()])
+		if err != nil {
+			return nil, errors.Wrapf(err, "lint module %q", m.Name())
+		}
+
+		problems = append(problems, mp...)
+	}
+
+	envProblems, err := l.lintEnvironments()
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, envProblems...)
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i], problems[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return problems, nil
+}
+
+func (l *Linter) lintModule(m component.Module, envParams *definedParams, targeted bool) ([]Problem, error) {
+	components, err := m.Components()
+	if err != nil {
+		return nil, err
+	}
+
+	paramsPath := l.rel(m.ParamsPath())
+	moduleParams, err := l.readModuleParams(m.ParamsPath())
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	names := make(map[string]bool)
+
+	for _, c := range components {
+		name := c.Name(false)
+		names[name] = true
+
+		path := filepath.Join(m.Dir(), name+"."+c.Type())
+		b, err := afero.ReadFile(l.app.Fs(), path)
+		if err != nil {
+			return nil, err
+		}
+
+		rel := l.rel(path)
+
+		if !targeted {
+			problems = append(problems, Problem{
+				Path:    rel,
+				Rule:    RuleUntargetedComponent,
+				Message: fmt.Sprintf("component %q is not targeted by any environment", c.Name(true)),
+			})
+		}
+
+		if c.Type() != "jsonnet" {
+			problems = append(problems, lintYAMLObjects(rel, b)...)
+			continue
+		}
+
+		cp, err := lintJsonnet(rel, string(b), name, c.Name(true), paramsPath, moduleParams, envParams)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, cp...)
+	}
+
+	for _, name := range moduleParams.componentNames() {
+		if names[name] {
+			continue
+		}
+
+		problems = append(problems, problemAt(paramsPath, moduleParams.components[name].loc, RuleUnusedParam,
+			"params are defined for component %q which does not exist", name))
+	}
+
+	return problems, nil
+}
+
+// lintJsonnet lints a jsonnet component.
+func lintJsonnet(path, src, name, fullName, paramsPath string, mp *moduleParams, dp *definedParams) ([]Problem, error) {
+	node, err := parse(path, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	problems = append(problems, lintImports(path, node)...)
+	problems = append(problems, lintParams(path, node, name, fullName, paramsPath, mp.components[name], dp)...)
+	problems = append(problems, lintJsonnetObjects(path, node)...)
+
+	return problems, nil
+}
+
+// lintEnvironments lints the jsonnet in the environments directory.
+func (l *Linter) lintEnvironments() ([]Problem, error) {
+	envs, err := l.app.Environments()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, name := range sortedEnvNames(envs) {
+		dir := envs[name].MakePath(l.app.Root())
+
+		for _, file := range []string{"main.jsonnet", "params.libsonnet", "globals.libsonnet"} {
+			path := filepath.Join(dir, file)
+			node, err := l.parseOptional(path)
+			if err != nil {
+				return nil, err
+			}
+
+			if node != nil {
+				problems = append(problems, lintImports(l.rel(path), node)...)
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// targetedModules returns the names of the modules targeted by at least one
+// environment.
+func (l *Linter) targetedModules() (map[string]bool, error) {
+	envs, err := l.app.Environments()
+	if err != nil {
+		return nil, err
+	}
+
+	targeted := make(map[string]bool)
+	for _, name := range sortedEnvNames(envs) {
+		modules, err := l.modulesFromEnvFn(l.app, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "find modules for environment %q", name)
+		}
+
+		for _, m := range modules {
+			targeted[m.Name()] = true
+		}
+	}
+
+	return targeted, nil
+}
+
+func (l *Linter) rel(path string) string {
+	rel, err := filepath.Rel(l.app.Root(), path)
+	if err != nil {
+		return path
+	}
+
+	return rel
+}
+
+func sortedEnvNames(envs app.EnvironmentConfigs) []string {
+	var names []string
+	for name := range envs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func problemAt(path string, loc ast.Location, rule, format string, a ...interface{}) Problem {
+	return Problem{
+		Path:    path,
+		Line:    loc.Line,
+		Column:  loc.Column,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinter_Lint(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		test.StageDir(t, fs, "app", "/app")
+
+		envs := app.EnvironmentConfigs{
+			"default": &app.EnvironmentConfig{Name: "default", Path: "default"},
+		}
+		a.On("Environments").Return(envs, nil)
+
+		l := New(a)
+		l.modulesFromEnvFn = func(a app.App, envName string) ([]component.Module, error) {
+			require.Equal(t, "default", envName)
+			return []component.Module{component.NewModule(a, "/")}, nil
+		}
+
+		problems, err := l.Lint()
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		for _, p := range problems {
+			fmt.Fprintln(&buf, p.String())
+		}
+
+		test.AssertOutput(t, "lint.txt", buf.String())
+	})
+}
+
+func TestProblem_String(t *testing.T) {
+	p := Problem{Path: "components/a.jsonnet", Rule: RuleUntargetedComponent, Message: "message"}
+	require.Equal(t, "components/a.jsonnet: message (untargeted-component)", p.String())
+
+	p.Line = 2
+	p.Column = 3
+	require.Equal(t, "components/a.jsonnet:2:3: message (untargeted-component)", p.String())
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/ksonnet/ksonnet/pkg/util/k8s"
+)
+
+// lintJsonnetObjects checks the metadata of the object literals in a
+// component which look like Kubernetes objects. Objects with metadata which
+// isn't an object literal can't be checked, so they are skipped.
+func lintJsonnetObjects(path string, node ast.Node) []Problem {
+	var problems []Problem
+
+	walk(node, func(n ast.Node) bool {
+		obj, ok := n.(*astext.Object)
+		if !ok {
+			return true
+		}
+
+		kind, ok := objectKind(obj)
+		if !ok {
+			return true
+		}
+
+		var fields []string
+		loc := obj.Loc().Begin
+
+		if f, ok := objectField(obj, "metadata"); ok {
+			metadata, ok := f.Expr2.(*astext.Object)
+			if !ok {
+				return true
+			}
+
+			fields = objectFieldNames(metadata)
+			loc = metadata.Loc().Begin
+		}
+
+		problems = append(problems, checkMetadata(path, loc, kind, fields)...)
+		return true
+	})
+
+	return problems
+}
+
+// objectKind returns the kind of an object if it has a literal apiVersion and
+// kind.
+func objectKind(obj *astext.Object) (string, bool) {
+	if _, ok := objectField(obj, "apiVersion"); !ok {
+		return "", false
+	}
+
+	f, ok := objectField(obj, "kind")
+	if !ok {
+		return "", false
+	}
+
+	kind, ok := f.Expr2.(*ast.LiteralString)
+	if !ok || kind.Value == "List" {
+		return "", false
+	}
+
+	return kind.Value, true
+}
+
+// lintYAMLObjects checks the metadata of the objects in a YAML or JSON
+// component. Problems are reported at the start of the document which
+// contains the object.
+func lintYAMLObjects(path string, b []byte) []Problem {
+	var problems []Problem
+
+	line := 1
+	for _, doc := range strings.Split(string(b), "\n---") {
+		start := line
+		line += strings.Count(doc, "\n") + 1
+
+		// skip the rest of the separator line and any leading blank lines.
+		lines := strings.Split(doc, "\n")
+		if start > 1 {
+			lines = lines[1:]
+			start++
+		}
+		for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+			lines = lines[1:]
+			start++
+		}
+
+		var obj struct {
+			APIVersion string                 `json:"apiVersion"`
+			Kind       string                 `json:"kind"`
+			Metadata   map[string]interface{} `json:"metadata"`
+		}
+
+		if err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &obj); err != nil {
+			// invalid documents are reported when the component is rendered.
+			continue
+		}
+
+		if obj.APIVersion == "" || obj.Kind == "" || obj.Kind == "List" {
+			continue
+		}
+
+		var fields []string
+		for k := range obj.Metadata {
+			fields = append(fields, k)
+		}
+
+		loc := ast.Location{Line: start, Column: 1}
+		problems = append(problems, checkMetadata(path, loc, obj.Kind, fields)...)
+	}
+
+	return problems
+}
+
+func checkMetadata(path string, loc ast.Location, kind string, fields []string) []Problem {
+	has := make(map[string]bool)
+	for _, f := range fields {
+		has[f] = true
+	}
+
+	var problems []Problem
+
+	if !has["namespace"] && !k8s.IsClusterScoped(kind) {
+		problems = append(problems, problemAt(path, loc, RuleMissingNamespace,
+			"%s does not have a namespace", kind))
+	}
+
+	if !has["labels"] {
+		problems = append(problems, problemAt(path, loc, RuleMissingLabels,
+			"%s does not have labels", kind))
+	}
+
+	return problems
+}
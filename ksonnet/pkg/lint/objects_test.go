@@ -0,0 +1,71 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_lintJsonnetObjects(t *testing.T) {
+	src := `[
+  { apiVersion: "v1", kind: "Service", metadata: { name: "a", namespace: "b", labels: {} } },
+  { apiVersion: "v1", kind: "Namespace", metadata: { name: "a" } },
+  { apiVersion: "v1", kind: "ConfigMap" },
+  { apiVersion: "v1", kind: "Secret", metadata: {} + { namespace: "b" } },
+  { apiVersion: "v1", kind: "List", items: [] },
+]`
+
+	node, err := parse("component.jsonnet", src)
+	require.NoError(t, err)
+
+	expected := []Problem{
+		{Path: "component.jsonnet", Line: 3, Column: 52, Rule: RuleMissingLabels, Message: "Namespace does not have labels"},
+		{Path: "component.jsonnet", Line: 4, Column: 3, Rule: RuleMissingNamespace, Message: "ConfigMap does not have a namespace"},
+		{Path: "component.jsonnet", Line: 4, Column: 3, Rule: RuleMissingLabels, Message: "ConfigMap does not have labels"},
+	}
+
+	assert.Equal(t, expected, lintJsonnetObjects("component.jsonnet", node))
+}
+
+func Test_lintYAMLObjects(t *testing.T) {
+	src := `# a comment
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+  namespace: b
+  labels:
+    app: a
+---
+
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    app: a
+---
+not: an object
+`
+
+	expected := []Problem{
+		{Path: "component.yaml", Line: 11, Column: 1, Rule: RuleMissingNamespace, Message: "ConfigMap does not have a namespace"},
+	}
+
+	assert.Equal(t, expected, lintYAMLObjects("component.yaml", []byte(src)))
+}
@@ -0,0 +1,393 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/spf13/afero"
+)
+
+const (
+	// paramsExtVar is the ext var components use to read their params.
+	paramsExtVar = "__ksonnet/params"
+)
+
+// componentParams are the params defined for a component in a module's
+// params.libsonnet.
+type componentParams struct {
+	loc    ast.Location
+	params map[string]ast.Location
+}
+
+// moduleParams are the params defined in a module's params.libsonnet.
+type moduleParams struct {
+	components map[string]*componentParams
+}
+
+func (mp *moduleParams) componentNames() []string {
+	var names []string
+	for name := range mp.components {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// definedParams are params defined outside of a module: component params set
+// in environments, and environment globals which are set on every component.
+type definedParams struct {
+	components map[string]map[string]bool
+	globals    map[string]bool
+}
+
+func (dp *definedParams) has(componentName, key string) bool {
+	return dp.globals[key] || dp.components[componentName][key]
+}
+
+// readModuleParams reads the component params in a module's params file. A
+// missing file has no params.
+func (l *Linter) readModuleParams(path string) (*moduleParams, error) {
+	mp := &moduleParams{components: make(map[string]*componentParams)}
+
+	exists, err := afero.Exists(l.app.Fs(), path)
+	if err != nil || !exists {
+		return mp, err
+	}
+
+	b, err := afero.ReadFile(l.app.Fs(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := parse(l.rel(path), string(b))
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := node.(*astext.Object)
+	if !ok {
+		return mp, nil
+	}
+
+	f, ok := objectField(root, "components")
+	if !ok {
+		return mp, nil
+	}
+
+	components, ok := f.Expr2.(*astext.Object)
+	if !ok {
+		return mp, nil
+	}
+
+	for _, name := range objectFieldNames(components) {
+		cf, _ := objectField(components, name)
+		cp := &componentParams{
+			loc:    cf.Expr2.Loc().Begin,
+			params: make(map[string]ast.Location),
+		}
+
+		if obj, ok := cf.Expr2.(*astext.Object); ok {
+			for _, key := range objectFieldNames(obj) {
+				pf, _ := objectField(obj, key)
+				cp.params[key] = pf.Expr2.Loc().Begin
+			}
+		}
+
+		mp.components[name] = cp
+	}
+
+	return mp, nil
+}
+
+// envParams finds the params defined by environments. Component params are
+// found in any object nested in a `components` field, since environment
+// params extend the module params.
+func (l *Linter) envParams() (*definedParams, error) {
+	dp := &definedParams{
+		components: make(map[string]map[string]bool),
+		globals:    make(map[string]bool),
+	}
+
+	envs, err := l.app.Environments()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range sortedEnvNames(envs) {
+		dir := envs[name].MakePath(l.app.Root())
+
+		node, err := l.parseOptional(filepath.Join(dir, "params.libsonnet"))
+		if err != nil {
+			return nil, err
+		}
+
+		walk(node, func(n ast.Node) bool {
+			obj, ok := n.(*astext.Object)
+			if !ok {
+				return true
+			}
+
+			f, ok := objectField(obj, "components")
+			if !ok {
+				return true
+			}
+
+			if components, ok := f.Expr2.(*astext.Object); ok {
+				for _, componentName := range objectFieldNames(components) {
+					cf, _ := objectField(components, componentName)
+					if params, ok := cf.Expr2.(*astext.Object); ok {
+						if dp.components[componentName] == nil {
+							dp.components[componentName] = make(map[string]bool)
+						}
+						for _, key := range objectFieldNames(params) {
+							dp.components[componentName][key] = true
+						}
+					}
+				}
+			}
+
+			return true
+		})
+
+		node, err = l.parseOptional(filepath.Join(dir, "globals.libsonnet"))
+		if err != nil {
+			return nil, err
+		}
+
+		if obj, ok := node.(*astext.Object); ok {
+			for _, key := range objectFieldNames(obj) {
+				dp.globals[key] = true
+			}
+		}
+	}
+
+	return dp, nil
+}
+
+// parseOptional parses a jsonnet file. It returns nil if the file does not
+// exist.
+func (l *Linter) parseOptional(path string) (ast.Node, error) {
+	exists, err := afero.Exists(l.app.Fs(), path)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	b, err := afero.ReadFile(l.app.Fs(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(l.rel(path), string(b))
+}
+
+// paramRef is a reference to a component param.
+type paramRef struct {
+	key string
+	loc ast.Location
+}
+
+// paramRefFinder finds the params a component references. A component
+// references its params through `std.extVar("__ksonnet/params")`, usually
+// with a local:
+//
+//   local params = std.extVar("__ksonnet/params").components.guestbook;
+//
+// If the params are used other than by indexing them with a literal key, the
+// params the component uses can't be determined and escapes is set.
+type paramRefFinder struct {
+	componentName string
+	vars          map[ast.Identifier]bool
+	refs          []paramRef
+	escapes       bool
+}
+
+func findParamRefs(node ast.Node, componentName string) ([]paramRef, bool) {
+	f := &paramRefFinder{
+		componentName: componentName,
+		vars:          make(map[ast.Identifier]bool),
+	}
+	f.visit(node)
+
+	return f.refs, f.escapes
+}
+
+func (f *paramRefFinder) visit(node ast.Node) {
+	if node == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *ast.Local:
+		for _, bind := range n.Binds {
+			if f.isParamsChain(bind.Body) {
+				f.vars[bind.Variable] = true
+				continue
+			}
+
+			f.visit(bind.Body)
+			if bind.Fun != nil {
+				f.visit(bind.Fun)
+			}
+		}
+
+		f.visit(n.Body)
+		return
+	case *ast.Index:
+		if f.isParams(n.Target) {
+			key, ok := indexKey(n)
+			if !ok {
+				f.escapes = true
+				f.visit(n.Index)
+				return
+			}
+
+			f.refs = append(f.refs, paramRef{key: key, loc: n.Loc().Begin})
+			return
+		}
+	}
+
+	if f.isParams(node) {
+		f.escapes = true
+		return
+	}
+
+	for _, child := range children(node) {
+		f.visit(child)
+	}
+}
+
+// isParams returns true if node evaluates to the component's params.
+func (f *paramRefFinder) isParams(node ast.Node) bool {
+	if v, ok := node.(*ast.Var); ok {
+		return f.vars[v.Id]
+	}
+
+	return f.isParamsChain(node)
+}
+
+// isParamsChain returns true if node is
+// `std.extVar("__ksonnet/params").components.<component>`.
+func (f *paramRefFinder) isParamsChain(node ast.Node) bool {
+	component, ok := node.(*ast.Index)
+	if !ok {
+		return false
+	}
+	if key, ok := indexKey(component); !ok || key != f.componentName {
+		return false
+	}
+
+	components, ok := component.Target.(*ast.Index)
+	if !ok {
+		return false
+	}
+	if key, ok := indexKey(components); !ok || key != "components" {
+		return false
+	}
+
+	apply, ok := components.Target.(*ast.Apply)
+	if !ok || len(apply.Arguments.Positional) != 1 {
+		return false
+	}
+
+	fn, ok := apply.Target.(*ast.Index)
+	if !ok {
+		return false
+	}
+	if key, ok := indexKey(fn); !ok || key != "extVar" {
+		return false
+	}
+	if std, ok := fn.Target.(*ast.Var); !ok || std.Id != "std" {
+		return false
+	}
+
+	arg, ok := apply.Arguments.Positional[0].(*ast.LiteralString)
+	return ok && arg.Value == paramsExtVar
+}
+
+// lintParams checks the params a component references against the params
+// defined for it.
+func lintParams(path string, node ast.Node, componentName, fullName, paramsPath string, cp *componentParams, dp *definedParams) []Problem {
+	refs, escapes := findParamRefs(node, componentName)
+
+	var problems []Problem
+	used := make(map[string]bool)
+
+	for _, ref := range refs {
+		used[ref.key] = true
+
+		if cp != nil {
+			if _, ok := cp.params[ref.key]; ok {
+				continue
+			}
+		}
+
+		if dp.has(fullName, ref.key) {
+			continue
+		}
+
+		problems = append(problems, problemAt(path, ref.loc, RuleUndefinedParam,
+			"param %q is not defined for component %q", ref.key, fullName))
+	}
+
+	if escapes || cp == nil {
+		return problems
+	}
+
+	var keys []string
+	for key := range cp.params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if used[key] {
+			continue
+		}
+
+		problems = append(problems, problemAt(paramsPath, cp.params[key], RuleUnusedParam,
+			"param %q is not used by component %q", key, fullName))
+	}
+
+	return problems
+}
+
+// objectFieldNames returns the literal field names of an object in source
+// order.
+func objectFieldNames(obj *astext.Object) []string {
+	var names []string
+	for i := range obj.Fields {
+		f := obj.Fields[i]
+		if f.Kind == ast.ObjectLocal || f.Kind == ast.ObjectAssert {
+			continue
+		}
+
+		if f.Id != nil {
+			names = append(names, string(*f.Id))
+			continue
+		}
+
+		if s, ok := f.Expr1.(*ast.LiteralString); ok {
+			names = append(names, s.Value)
+		}
+	}
+
+	return names
+}
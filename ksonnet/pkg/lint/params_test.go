@@ -0,0 +1,81 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_findParamRefs(t *testing.T) {
+	cases := []struct {
+		name    string
+		src     string
+		keys    []string
+		escapes bool
+	}{
+		{
+			name: "local",
+			src: `local params = std.extVar("__ksonnet/params").components.app;
+{ a: params.a, b: params["b"] }`,
+			keys: []string{"a", "b"},
+		},
+		{
+			name: "inline",
+			src:  `{ a: std.extVar("__ksonnet/params").components["app"].a }`,
+			keys: []string{"a"},
+		},
+		{
+			name: "other component",
+			src: `local params = std.extVar("__ksonnet/params").components.other;
+{ a: params.a }`,
+		},
+		{
+			name: "passed to a function",
+			src: `local params = std.extVar("__ksonnet/params").components.app;
+local f(p) = p;
+{ a: params.a, b: f(params) }`,
+			keys:    []string{"a"},
+			escapes: true,
+		},
+		{
+			name: "computed key",
+			src: `local params = std.extVar("__ksonnet/params").components.app;
+local key = "a";
+{ a: params[key] }`,
+			escapes: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			node, err := parse("component.jsonnet", tc.src)
+			require.NoError(t, err)
+
+			refs, escapes := findParamRefs(node, "app")
+
+			var keys []string
+			for _, ref := range refs {
+				keys = append(keys, ref.key)
+			}
+
+			assert.Equal(t, tc.keys, keys)
+			assert.Equal(t, tc.escapes, escapes)
+		})
+	}
+}
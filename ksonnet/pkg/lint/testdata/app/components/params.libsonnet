@@ -0,0 +1,13 @@
+{
+  global: {},
+  components: {
+    guestbook: {
+      name: "guestbook",
+      replicas: 1,
+      unused: true,
+    },
+    removed: {
+      name: "removed",
+    },
+  },
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	jsonnetutil "github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+)
+
+// parse parses jsonnet source.
+func parse(path, src string) (ast.Node, error) {
+	node, err := jsonnetutil.ParseNode(path, src)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s", path)
+	}
+
+	return node, nil
+}
+
+// walk calls fn for node and all of its descendants. Children are not visited
+// if fn returns false.
+func walk(node ast.Node, fn func(ast.Node) bool) {
+	jsonnetutil.Walk(node, fn)
+}
+
+// children returns the child nodes of a node as written in the source.
+func children(node ast.Node) []ast.Node {
+	return jsonnetutil.Children(node)
+}
+
+// indexKey returns the key of an index expression which uses a literal key,
+// e.g. `a.b` or `a["b"]`.
+func indexKey(n *ast.Index) (string, bool) {
+	if n.Id != nil {
+		return string(*n.Id), true
+	}
+
+	if s, ok := n.Index.(*ast.LiteralString); ok {
+		return s.Value, true
+	}
+
+	return "", false
+}
+
+// objectField returns the field of an object with a literal name.
+func objectField(obj *astext.Object, name string) (*astext.ObjectField, bool) {
+	for i := range obj.Fields {
+		f := &obj.Fields[i]
+		if f.Kind == ast.ObjectLocal || f.Kind == ast.ObjectAssert {
+			continue
+		}
+
+		id, err := jsonnetutil.FieldID(*f)
+		if err == nil && id == name {
+			return f, true
+		}
+	}
+
+	return nil, false
+}
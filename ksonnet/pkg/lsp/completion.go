@@ -0,0 +1,324 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/ksonnet/ksonnet/pkg/ksonnet"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+var (
+	// reImportPath matches an import path being typed, e.g. `import "ks`.
+	reImportPath = regexp.MustCompile(`\bimport(?:str)?\s*["']([^"']*)$`)
+	// reMember matches a member being typed, e.g. `k.apps.v1beta2.dep`.
+	reMember = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)((?:\.[A-Za-z_][A-Za-z0-9_]*)*)\.([A-Za-z0-9_]*)$`)
+	// reParamImport matches a prototype param, e.g. `import 'param://name'`.
+	reParamImport = regexp.MustCompile(`import\s*(?:'param://([^']*)'|"param://([^"]*)")`)
+
+	// textEscaper escapes the text of a snippet, and placeholderEscaper escapes
+	// the text of a placeholder.
+	textEscaper        = strings.NewReplacer(`\`, `\\`, `$`, `\$`)
+	placeholderEscaper = strings.NewReplacer(`\`, `\\`, `$`, `\$`, `}`, `\}`)
+)
+
+// memberSnippet lists the members of an object and their types.
+const memberSnippet = `local value = %s;
+[{name: field, type: std.type(value[field])} for field in std.objectFieldsAll(value)]`
+
+// member is a member of an object.
+type member struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// completion completes import paths, the members of locals bound to imports
+// or params, and prototypes.
+func (s *Server) completion(params textDocumentPositionParams) (*completionList, error) {
+	d, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := d.offset(params.Position)
+	line := d.text[d.lineStart(params.Position.Line):offset]
+
+	var items []completionItem
+	if m := reImportPath.FindStringSubmatch(line); m != nil {
+		items = s.completeImport(d, m[1], params.Position)
+	} else if m := reMember.FindStringSubmatch(line); m != nil {
+		items = s.completeMember(d, d.text[:offset], m[1], m[2])
+	} else {
+		items = s.completePrototypes()
+	}
+
+	if items == nil {
+		items = []completionItem{}
+	}
+
+	return &completionList{Items: items}, nil
+}
+
+// completeImport completes an import path with the directories and jsonnet
+// files found relative to the document and in the app's jsonnet paths.
+func (s *Server) completeImport(d *document, partial string, pos position) []completionItem {
+	dir, base := path.Split(partial)
+
+	roots := []string{filepath.Dir(d.path)}
+	jPaths := s.jPaths()
+	for i := len(jPaths) - 1; i >= 0; i-- {
+		roots = append(roots, jPaths[i])
+	}
+
+	replace := textRange{
+		Start: position{Line: pos.Line, Character: pos.Character - len(utf16.Encode([]rune(base)))},
+		End:   pos,
+	}
+
+	seen := make(map[string]bool)
+	var items []completionItem
+	for _, root := range roots {
+		fis, err := afero.ReadDir(s.app.Fs(), filepath.Join(root, filepath.FromSlash(dir)))
+		if err != nil {
+			continue
+		}
+
+		for _, fi := range fis {
+			name := fi.Name()
+			if strings.HasPrefix(name, ".") || seen[name] {
+				continue
+			}
+
+			item := completionItem{Label: name}
+			switch {
+			case fi.IsDir():
+				item.Kind = kindFolder
+				item.TextEdit = &textEdit{Range: replace, NewText: name + "/"}
+			case isImportable(name):
+				item.Kind = kindFile
+				item.TextEdit = &textEdit{Range: replace, NewText: name}
+			default:
+				continue
+			}
+
+			seen[name] = true
+			items = append(items, item)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Label < items[j].Label
+	})
+
+	return items
+}
+
+func isImportable(name string) bool {
+	switch filepath.Ext(name) {
+	case ".jsonnet", ".libsonnet", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// completeMember completes the members of id.chain, where id is std or a
+// local bound to an import or to the app's params. The local is looked up in
+// the text before the cursor.
+func (s *Server) completeMember(d *document, before, id, chain string) []completionItem {
+	expr, ok := bindingExpr(before, id)
+	if !ok {
+		return nil
+	}
+
+	members, err := s.members(d, expr+chain)
+	if err != nil {
+		log.WithError(err).Debugf("list members of %s%s", id, chain)
+		return nil
+	}
+
+	var items []completionItem
+	for _, m := range members {
+		// members such as std.$objectFlatMerge are internal to jsonnet.
+		if strings.HasPrefix(m.Name, "$") {
+			continue
+		}
+
+		item := completionItem{Label: m.Name, Detail: m.Type, Kind: kindField}
+		switch m.Type {
+		case "function":
+			item.Kind = kindMethod
+		case "object":
+			item.Kind = kindModule
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// members evaluates expr in the context of a document and returns its
+// members. If the types of the members can't be evaluated, only their names
+// are returned.
+func (s *Server) members(d *document, expr string) ([]member, error) {
+	vm, err := s.vm(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []member
+	out, err := vm.EvaluateSnippet(d.path, fmt.Sprintf(memberSnippet, expr))
+	if err == nil {
+		err = json.Unmarshal([]byte(out), &members)
+		return members, err
+	}
+
+	out, err = vm.EvaluateSnippet(d.path, fmt.Sprintf("std.objectFieldsAll(%s)", expr))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if err := json.Unmarshal([]byte(out), &names); err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		members = append(members, member{Name: name})
+	}
+
+	return members, nil
+}
+
+// bindingExpr returns the expression a local is bound to if it is an import
+// or the app's params, e.g.
+//
+//   local k = import "k.libsonnet";
+//   local params = std.extVar("__ksonnet/params").components.guestbook;
+//
+// The last binding in text is used.
+func bindingExpr(text, id string) (string, bool) {
+	if id == "std" {
+		return "std", true
+	}
+
+	re := regexp.MustCompile(`\blocal\s+` + regexp.QuoteMeta(id) + `\s*=\s*(` +
+		`import\s*(?:"[^"]*"|'[^']*')|` +
+		`std\.extVar\(\s*["']` + regexp.QuoteMeta(ksonnet.ParamsExtCodeKey) + `["']\s*\)(?:\.[A-Za-z_][A-Za-z0-9_]*)*)`)
+
+	matches := re.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	expr := matches[len(matches)-1][1]
+	if strings.HasPrefix(expr, "import") {
+		expr = "(" + expr + ")"
+	}
+
+	return expr, true
+}
+
+// completePrototypes offers the prototypes as snippets.
+func (s *Server) completePrototypes() []completionItem {
+	var items []completionItem
+	for _, p := range s.prototypes {
+		if len(p.Template.JsonnetBody) == 0 {
+			continue
+		}
+
+		items = append(items, completionItem{
+			Label:            p.Name,
+			Kind:             kindSnippet,
+			Detail:           p.Template.ShortDescription,
+			Documentation:    p.Template.Description,
+			InsertText:       snippetBody(p),
+			InsertTextFormat: formatSnippet,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Label < items[j].Label
+	})
+
+	return items
+}
+
+// snippetBody converts the jsonnet body of a prototype to a snippet. Each param
+// becomes a placeholder holding its default value.
+func snippetBody(p *prototype.Prototype) string {
+	body := strings.Join(p.Template.JsonnetBody, "\n")
+	tabstops := make(map[string]int)
+
+	var buf bytes.Buffer
+	last := 0
+	for _, m := range reParamImport.FindAllStringSubmatchIndex(body, -1) {
+		buf.WriteString(textEscaper.Replace(body[last:m[0]]))
+		last = m[1]
+
+		var name string
+		if m[2] >= 0 {
+			name = body[m[2]:m[3]]
+		} else {
+			name = body[m[4]:m[5]]
+		}
+
+		n, ok := tabstops[name]
+		if !ok {
+			n = len(tabstops) + 1
+			tabstops[name] = n
+		}
+
+		fmt.Fprintf(&buf, "${%d:%s}", n, placeholderEscaper.Replace(placeholder(p, name)))
+	}
+	buf.WriteString(textEscaper.Replace(body[last:]))
+
+	return buf.String()
+}
+
+// placeholder returns the placeholder for a prototype param.
+func placeholder(p *prototype.Prototype, name string) string {
+	for _, ps := range p.Params {
+		if ps.Name != name {
+			continue
+		}
+
+		if ps.Default != nil {
+			if v, err := ps.Quote(*ps.Default); err == nil {
+				return v
+			}
+			return *ps.Default
+		}
+
+		if ps.Type == prototype.String {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+
+	return name
+}
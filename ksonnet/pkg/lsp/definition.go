@@ -0,0 +1,295 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	jsonnetutil "github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+)
+
+// definition finds the definition of the variable at the cursor, or the file
+// imported at the cursor.
+func (s *Server) definition(params textDocumentPositionParams) ([]location, error) {
+	d, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := []location{}
+
+	node, err := jsonnetutil.ParseNode(d.path, d.text)
+	if err != nil {
+		return locations, nil
+	}
+
+	f := &definitionFinder{doc: d, cursor: d.offset(params.Position), def: -1}
+	f.visit(node, definitionScope{})
+
+	switch {
+	case f.importPath != "":
+		if path, ok := s.resolveImport(d, f.importPath); ok {
+			locations = append(locations, location{URI: pathToURI(path)})
+		}
+	case f.def >= 0:
+		locations = append(locations, location{
+			URI: d.uri,
+			Range: textRange{
+				Start: d.position(f.def),
+				End:   d.position(f.def + len(f.id)),
+			},
+		})
+	}
+
+	return locations, nil
+}
+
+// resolveImport finds an imported file the way the jsonnet VM does: relative
+// to the importing document first, then in the jsonnet paths.
+func (s *Server) resolveImport(d *document, file string) (string, bool) {
+	candidates := []string{filepath.Join(filepath.Dir(d.path), file)}
+	if filepath.IsAbs(file) {
+		candidates = []string{file}
+	}
+
+	jPaths := s.jPaths()
+	for i := len(jPaths) - 1; i >= 0; i-- {
+		candidates = append(candidates, filepath.Join(jPaths[i], file))
+	}
+
+	for _, path := range candidates {
+		if fi, err := s.app.Fs().Stat(path); err == nil && !fi.IsDir() {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+// definitionScope maps the variables in scope to the offsets of the
+// identifiers which bind them. The offset is -1 if it is not known.
+type definitionScope map[ast.Identifier]int
+
+func (s definitionScope) with(fn func(s definitionScope)) definitionScope {
+	scope := make(definitionScope)
+	for k, v := range s {
+		scope[k] = v
+	}
+
+	fn(scope)
+	return scope
+}
+
+// definitionFinder finds the variable or import at the cursor. The AST does not
+// record the locations of identifiers which bind variables, so they are found
+// in the text of the node which binds them.
+type definitionFinder struct {
+	doc    *document
+	cursor int
+
+	found      bool
+	id         ast.Identifier
+	def        int
+	importPath string
+}
+
+func (f *definitionFinder) visit(node ast.Node, scope definitionScope) {
+	if node == nil || f.found {
+		return
+	}
+
+	switch n := node.(type) {
+	case *ast.Var:
+		if f.contains(n) {
+			f.found = true
+			if def, ok := scope[n.Id]; ok {
+				f.id, f.def = n.Id, def
+			}
+		}
+		return
+	case *ast.Import:
+		if f.contains(n) {
+			f.found = true
+			f.importPath = n.File.Value
+		}
+		return
+	case *ast.ImportStr:
+		if f.contains(n) {
+			f.found = true
+			f.importPath = n.File.Value
+		}
+		return
+	case *ast.Local:
+		// binds in a local can refer to each other, so they share a scope.
+		inner := scope.with(func(s definitionScope) {
+			for _, bind := range n.Binds {
+				s[bind.Variable] = f.find(n, `\b(%s)\s*[=(]`, bind.Variable)
+			}
+		})
+
+		for _, bind := range n.Binds {
+			if bind.Fun != nil {
+				f.visit(bind.Fun, inner)
+			} else {
+				f.visit(bind.Body, inner)
+			}
+		}
+
+		f.visit(n.Body, inner)
+		return
+	case *ast.Function:
+		inner := scope.with(func(s definitionScope) {
+			for _, p := range n.Parameters.Required {
+				s[p] = f.find(n, `\b(%s)\b`, p)
+			}
+			for _, p := range n.Parameters.Optional {
+				s[p.Name] = f.find(n, `\b(%s)\b`, p.Name)
+			}
+		})
+
+		for _, p := range n.Parameters.Optional {
+			f.visit(p.DefaultArg, inner)
+		}
+
+		f.visit(n.Body, inner)
+		return
+	case *astext.Object:
+		inner := scope.with(func(s definitionScope) {
+			for _, field := range n.Fields {
+				if field.Kind == ast.ObjectLocal && field.Id != nil {
+					s[*field.Id] = f.find(n, `\blocal\s+(%s)\b`, *field.Id)
+				}
+			}
+		})
+
+		for _, field := range n.Fields {
+			f.visitField(field.ObjectField, scope, inner)
+		}
+		return
+	case *ast.ArrayComp:
+		inner := f.forScope(n, &n.Spec, scope)
+		f.visitForSpec(&n.Spec, inner)
+		f.visit(n.Body, inner)
+		return
+	case *ast.ObjectComp:
+		inner := f.forScope(n, &n.Spec, scope)
+		f.visitForSpec(&n.Spec, inner)
+		for _, field := range n.Fields {
+			f.visitField(field, inner, inner)
+		}
+		return
+	}
+
+	for _, child := range children(node) {
+		f.visit(child, scope)
+	}
+}
+
+// visitField visits an object field. The field name is not in the scope of
+// the object.
+func (f *definitionFinder) visitField(field ast.ObjectField, outer, inner definitionScope) {
+	f.visit(field.Expr1, outer)
+	if field.Method != nil {
+		f.visit(field.Method, inner)
+	} else {
+		f.visit(field.Expr2, inner)
+	}
+	f.visit(field.Expr3, inner)
+}
+
+func (f *definitionFinder) forScope(node ast.Node, spec *ast.ForSpec, scope definitionScope) definitionScope {
+	return scope.with(func(s definitionScope) {
+		for ; spec != nil; spec = spec.Outer {
+			s[spec.VarName] = f.find(node, `\bfor\s+(%s)\b`, spec.VarName)
+		}
+	})
+}
+
+func (f *definitionFinder) visitForSpec(spec *ast.ForSpec, scope definitionScope) {
+	for ; spec != nil; spec = spec.Outer {
+		f.visit(spec.Expr, scope)
+		for _, cond := range spec.Conditions {
+			f.visit(cond.Expr, scope)
+		}
+	}
+}
+
+// contains returns true if the cursor is in a node.
+func (f *definitionFinder) contains(node ast.Node) bool {
+	loc := node.Loc()
+	if loc == nil || !loc.Begin.IsSet() {
+		return false
+	}
+
+	return f.doc.astOffset(loc.Begin) <= f.cursor && f.cursor <= f.doc.astOffset(loc.End)
+}
+
+// find returns the offset of the first match of the first group of pattern in
+// the text of node. The pattern is formatted with the quoted identifier.
+func (f *definitionFinder) find(node ast.Node, pattern string, id ast.Identifier) int {
+	begin := 0
+	if loc := node.Loc(); loc != nil && loc.Begin.IsSet() {
+		begin = f.doc.astOffset(loc.Begin)
+	}
+
+	re := regexp.MustCompile(fmt.Sprintf(pattern, regexp.QuoteMeta(string(id))))
+	m := re.FindStringSubmatchIndex(f.doc.text[begin:])
+	if m == nil {
+		return -1
+	}
+
+	return begin + m[2]
+}
+
+// children returns the child nodes of nodes which don't bind variables.
+func children(node ast.Node) []ast.Node {
+	switch n := node.(type) {
+	case *ast.Apply:
+		nodes := append([]ast.Node{n.Target}, n.Arguments.Positional...)
+		for _, arg := range n.Arguments.Named {
+			nodes = append(nodes, arg.Arg)
+		}
+		return nodes
+	case *ast.ApplyBrace:
+		return []ast.Node{n.Left, n.Right}
+	case *ast.Array:
+		return n.Elements
+	case *ast.Assert:
+		return []ast.Node{n.Cond, n.Message, n.Rest}
+	case *ast.Binary:
+		return []ast.Node{n.Left, n.Right}
+	case *ast.Conditional:
+		return []ast.Node{n.Cond, n.BranchTrue, n.BranchFalse}
+	case *ast.Error:
+		return []ast.Node{n.Expr}
+	case *ast.Index:
+		return []ast.Node{n.Target, n.Index}
+	case *ast.InSuper:
+		return []ast.Node{n.Index}
+	case *ast.Slice:
+		return []ast.Node{n.Target, n.BeginIndex, n.EndIndex, n.Step}
+	case *ast.SuperIndex:
+		return []ast.Node{n.Index}
+	case *ast.Unary:
+		return []ast.Node{n.Expr}
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lsp
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/docparser"
+	"github.com/ksonnet/ksonnet/pkg/ksonnet"
+	"github.com/ksonnet/ksonnet/pkg/params"
+	jsonnetutil "github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// reErrorLocation matches the locations in jsonnet error messages, e.g.
+	// `3:5-10`, `3:5` or `(3:5)-(4:1)`.
+	reErrorLocation = regexp.MustCompile(`^(?:(\d+):(\d+)(?:-(\d+))?|\((\d+):(\d+)\)-\((\d+):(\d+)\))`)
+)
+
+func (s *Server) publishDiagnostics(d *document) error {
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         d.uri,
+		Diagnostics: s.diagnose(d),
+	})
+}
+
+// diagnose parses a document and, if it can be evaluated, evaluates it.
+func (s *Server) diagnose(d *document) []diagnostic {
+	diagnostics := []diagnostic{}
+
+	tokens, err := docparser.Lex(d.path, d.text)
+	if err == nil {
+		_, err = docparser.Parse(tokens)
+	}
+	if err != nil {
+		return append(diagnostics, parseDiagnostic(d, err))
+	}
+
+	if !s.evaluates(d) {
+		return diagnostics
+	}
+
+	vm, err := s.vm(d)
+	if err != nil {
+		log.WithError(err).Debugf("create VM for %s", d.path)
+		return diagnostics
+	}
+
+	if _, err := vm.EvaluateSnippet(d.path, d.text); err != nil {
+		diagnostics = append(diagnostics, evaluationDiagnostic(d, err.Error()))
+	}
+
+	return diagnostics
+}
+
+// evaluates returns true if a document can be evaluated on its own.
+// Environments are only parsed, because they need the app's components.
+func (s *Server) evaluates(d *document) bool {
+	if s.envName == "" {
+		return false
+	}
+
+	switch filepath.Ext(d.path) {
+	case ".jsonnet", ".libsonnet":
+	default:
+		return false
+	}
+
+	return !isWithin(filepath.Join(s.app.Root(), "environments"), d.path)
+}
+
+// vm creates a VM for evaluating a document. Components are evaluated with
+// the params of their module.
+func (s *Server) vm(d *document) (*jsonnetutil.VM, error) {
+	vm := jsonnetutil.NewVM(jsonnetutil.AferoImporterOpt(s.app.Fs()))
+	vm.AddJPath(s.jPaths()...)
+
+	if s.envName == "" {
+		return vm, nil
+	}
+
+	envCode, err := params.JsonnetEnvObject(s.app, s.envName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "build environment %s", s.envName)
+	}
+	vm.ExtCode(ksonnet.EnvExtCodeKey, envCode)
+
+	if moduleName, ok := s.moduleName(d); ok {
+		paramsStr, err := s.paramsFn(s.app, moduleName, s.envName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolve params for module %q", moduleName)
+		}
+		vm.ExtCode(ksonnet.ParamsExtCodeKey, paramsStr)
+	}
+
+	return vm, nil
+}
+
+// moduleName returns the module of a component document.
+func (s *Server) moduleName(d *document) (string, bool) {
+	componentsDir := filepath.Join(s.app.Root(), "components")
+	if !isWithin(componentsDir, d.path) || filepath.Base(d.path) == "params.libsonnet" {
+		return "", false
+	}
+
+	return component.ModuleFromPath(s.app, filepath.Dir(d.path)), true
+}
+
+// isWithin returns true if path is in dir or one of its descendants.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func parseDiagnostic(d *document, err error) diagnostic {
+	diag := diagnostic{
+		Severity: severityError,
+		Source:   "ks",
+		Message:  err.Error(),
+	}
+
+	if se, ok := err.(docparser.StaticError); ok {
+		diag.Range = d.astRange(se.Loc)
+		diag.Message = se.Msg
+	}
+
+	return diag
+}
+
+// evaluationDiagnostic converts a formatted jsonnet error to a diagnostic. The
+// diagnostic is placed at the first location in the error which is in the
+// document, or at the start of the document if there is none.
+func evaluationDiagnostic(d *document, msg string) diagnostic {
+	lines := strings.Split(strings.TrimSpace(msg), "\n")
+	prefix := d.path + ":"
+
+	diag := diagnostic{
+		Severity: severityError,
+		Source:   "ks",
+		Message:  strings.TrimPrefix(lines[0], "RUNTIME ERROR: "),
+	}
+
+	// static errors start with their location.
+	if strings.HasPrefix(lines[0], prefix) {
+		if lr, rest, ok := parseErrorLocation(lines[0][len(prefix):]); ok {
+			diag.Range = d.astRange(lr)
+			diag.Message = strings.TrimSpace(rest)
+			return diag
+		}
+	}
+
+	// runtime errors are followed by a stack trace.
+	for _, line := range lines[1:] {
+		line = strings.TrimPrefix(line, "\t")
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		if lr, _, ok := parseErrorLocation(line[len(prefix):]); ok {
+			diag.Range = d.astRange(lr)
+			break
+		}
+	}
+
+	return diag
+}
+
+// parseErrorLocation parses the location at the start of s. The rest of s is
+// returned.
+func parseErrorLocation(s string) (ast.LocationRange, string, bool) {
+	m := reErrorLocation.FindStringSubmatch(s)
+	if m == nil {
+		return ast.LocationRange{}, "", false
+	}
+
+	atoi := func(s string) int {
+		i, _ := strconv.Atoi(s)
+		return i
+	}
+
+	var lr ast.LocationRange
+	switch {
+	case m[1] != "":
+		lr.Begin = ast.Location{Line: atoi(m[1]), Column: atoi(m[2])}
+		lr.End = lr.Begin
+		if m[3] != "" {
+			lr.End.Column = atoi(m[3])
+		}
+	default:
+		lr.Begin = ast.Location{Line: atoi(m[4]), Column: atoi(m[5])}
+		lr.End = ast.Location{Line: atoi(m[6]), Column: atoi(m[7])}
+	}
+
+	return lr, s[len(m[0]):], true
+}
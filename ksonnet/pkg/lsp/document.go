@@ -0,0 +1,138 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/pkg/errors"
+)
+
+// document is a text document opened by the client.
+type document struct {
+	uri  string
+	path string
+	text string
+}
+
+func newDocument(uri, text string) (*document, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &document{uri: uri, path: path, text: text}, nil
+}
+
+// uriToPath converts a file URI to a filesystem path.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse URI %q", uri)
+	}
+
+	if u.Scheme != "file" {
+		return "", errors.Errorf("unsupported URI %q: only file URIs are supported", uri)
+	}
+
+	return filepath.FromSlash(u.Path), nil
+}
+
+// pathToURI converts a filesystem path to a file URI.
+func pathToURI(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
+
+// lineStart returns the byte offset of the start of a zero-based line.
+func (d *document) lineStart(line int) int {
+	offset := 0
+	for i := 0; i < line; i++ {
+		n := strings.IndexByte(d.text[offset:], '\n')
+		if n < 0 {
+			return len(d.text)
+		}
+		offset += n + 1
+	}
+
+	return offset
+}
+
+// offset converts a position to a byte offset in the document.
+func (d *document) offset(p position) int {
+	offset := d.lineStart(p.Line)
+
+	for units := 0; units < p.Character && offset < len(d.text); {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		if r == '\n' {
+			break
+		}
+
+		units += len(utf16.Encode([]rune{r}))
+		offset += size
+	}
+
+	return offset
+}
+
+// position converts a byte offset in the document to a position.
+func (d *document) position(offset int) position {
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+
+	before := d.text[:offset]
+	line := strings.Count(before, "\n")
+	start := strings.LastIndexByte(before, '\n') + 1
+
+	return position{
+		Line:      line,
+		Character: len(utf16.Encode([]rune(before[start:]))),
+	}
+}
+
+// astOffset converts a jsonnet location, which has a one-based line and a
+// one-based byte column, to a byte offset in the document.
+func (d *document) astOffset(loc ast.Location) int {
+	if loc.Line < 1 {
+		return 0
+	}
+
+	offset := d.lineStart(loc.Line-1) + loc.Column - 1
+	if offset > len(d.text) {
+		return len(d.text)
+	}
+
+	return offset
+}
+
+// astRange converts a jsonnet location range to a range in the document.
+func (d *document) astRange(lr ast.LocationRange) textRange {
+	end := lr.End
+	if !end.IsSet() {
+		end = lr.Begin
+	}
+
+	return textRange{
+		Start: d.position(d.astOffset(lr.Begin)),
+		End:   d.position(d.astOffset(end)),
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lsp
+
+import (
+	"testing"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_document_positions(t *testing.T) {
+	d, err := newDocument("file:///app/components/a%20b.jsonnet", "{\n  name: \"😀\" + x,\n}\n")
+	require.NoError(t, err)
+	require.Equal(t, "/app/components/a b.jsonnet", d.path)
+	require.Equal(t, "file:///app/components/a%20b.jsonnet", pathToURI(d.path))
+
+	// the emoji is two UTF-16 code units and four bytes.
+	x := position{Line: 1, Character: 15}
+	offset := d.offset(x)
+	require.Equal(t, "x", d.text[offset:offset+1])
+	require.Equal(t, x, d.position(offset))
+	require.Equal(t, offset, d.astOffset(ast.Location{Line: 2, Column: 18}))
+
+	// characters past the end of a line are clamped to the line.
+	require.Equal(t, d.lineStart(2)-1, d.offset(position{Line: 1, Character: 100}))
+}
+
+func Test_newDocument_invalid_uri(t *testing.T) {
+	_, err := newDocument("untitled:Untitled-1", "")
+	require.Error(t, err)
+}
+
+func Test_parseErrorLocation(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected ast.LocationRange
+		rest     string
+		ok       bool
+	}{
+		{
+			in: "3:5-10 Unknown variable: x",
+			expected: ast.LocationRange{
+				Begin: ast.Location{Line: 3, Column: 5},
+				End:   ast.Location{Line: 3, Column: 10},
+			},
+			rest: " Unknown variable: x",
+			ok:   true,
+		},
+		{
+			in: "3:5\tobject <anonymous>",
+			expected: ast.LocationRange{
+				Begin: ast.Location{Line: 3, Column: 5},
+				End:   ast.Location{Line: 3, Column: 5},
+			},
+			rest: "\tobject <anonymous>",
+			ok:   true,
+		},
+		{
+			in: "(3:5)-(4:2)",
+			expected: ast.LocationRange{
+				Begin: ast.Location{Line: 3, Column: 5},
+				End:   ast.Location{Line: 4, Column: 2},
+			},
+			ok: true,
+		},
+		{
+			in: "During evaluation",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			lr, rest, ok := parseErrorLocation(tc.in)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, lr)
+			require.Equal(t, tc.rest, rest)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readMessage(t *testing.T) {
+	cases := []struct {
+		name     string
+		in       string
+		expected []string
+		isErr    bool
+	}{
+		{
+			name:     "messages",
+			in:       "Content-Length: 2\r\n\r\n{}Content-Type: application/vscode-jsonrpc; charset=utf-8\r\ncontent-length: 4\r\n\r\nnull",
+			expected: []string{"{}", "null"},
+		},
+		{
+			name:  "missing content length",
+			in:    "Content-Type: application/vscode-jsonrpc\r\n\r\n{}",
+			isErr: true,
+		},
+		{
+			name:  "invalid content length",
+			in:    "Content-Length: two\r\n\r\n{}",
+			isErr: true,
+		},
+		{
+			name:  "short body",
+			in:    "Content-Length: 10\r\n\r\n{}",
+			isErr: true,
+		},
+		{
+			name:  "truncated header",
+			in:    "Content-Length: 2",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tc.in))
+
+			var got []string
+			for {
+				b, err := readMessage(r)
+				if err == io.EOF {
+					break
+				}
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				got = append(got, string(b))
+			}
+
+			require.False(t, tc.isErr, "expected an error")
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func Test_writeMessage(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeMessage(&buf, notification{JSONRPC: "2.0", Method: "exit"})
+	require.NoError(t, err)
+
+	expected := "Content-Length: 47\r\n\r\n" + `{"jsonrpc":"2.0","method":"exit","params":null}`
+	require.Equal(t, expected, buf.String())
+}
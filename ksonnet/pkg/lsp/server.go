@@ -0,0 +1,289 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package lsp implements a Jsonnet language server for the components, params
+// and libraries of a ksonnet app. It speaks the Language Server Protocol over
+// a stream such as stdio.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Server is a language server for a ksonnet app.
+type Server struct {
+	app        app.App
+	envName    string
+	prototypes prototype.Prototypes
+
+	documents map[string]*document
+	out       io.Writer
+	shutdown  bool
+
+	paramsFn func(a app.App, moduleName, envName string) (string, error)
+}
+
+// New creates an instance of Server. Documents are evaluated in the
+// environment envName. If envName is blank, documents are only parsed and
+// completions which need the environment's lib are not offered. Prototypes
+// are offered as snippets.
+func New(a app.App, envName string, prototypes prototype.Prototypes) *Server {
+	return &Server{
+		app:        a,
+		envName:    envName,
+		prototypes: prototypes,
+		documents:  make(map[string]*document),
+
+		paramsFn: resolvedParams,
+	}
+}
+
+// resolvedParams returns the params of a module resolved for an environment.
+func resolvedParams(a app.App, moduleName, envName string) (string, error) {
+	m, err := component.GetModule(a, moduleName)
+	if err != nil {
+		return "", err
+	}
+
+	return m.ResolvedParams(envName)
+}
+
+// Serve reads messages from r and writes messages to w until the client
+// exits or closes r. An error is returned if the client exits without
+// shutting the server down first.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	br := bufio.NewReader(r)
+
+	for {
+		b, err := readMessage(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "read message")
+		}
+
+		var req request
+		if err := json.Unmarshal(b, &req); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("client exited before shutting down the server")
+			}
+			return nil
+		}
+
+		if err := s.handle(&req); err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles a request and replies to it if it is not a notification.
+func (s *Server) handle(req *request) error {
+	result, err := s.dispatch(req)
+
+	if req.isNotification() {
+		if err != nil {
+			log.WithError(err).Debugf("handle %s", req.Method)
+		}
+		return nil
+	}
+
+	if err != nil {
+		rerr, ok := err.(*responseError)
+		if !ok {
+			rerr = &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+		return s.reply(req.ID, nil, rerr)
+	}
+
+	return s.reply(req.ID, result, nil)
+}
+
+func (s *Server) dispatch(req *request) (interface{}, error) {
+	if s.shutdown && req.Method != "shutdown" {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shutting down"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenTextDocumentParams
+		if err := decodeParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didOpen(params)
+	case "textDocument/didChange":
+		var params didChangeTextDocumentParams
+		if err := decodeParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didChange(params)
+	case "textDocument/didClose":
+		var params didCloseTextDocumentParams
+		if err := decodeParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didClose(params)
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := decodeParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params)
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := decodeParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params)
+	}
+
+	return nil, &responseError{
+		Code:    codeMethodNotFound,
+		Message: fmt.Sprintf("method %q is not supported", req.Method),
+	}
+}
+
+func decodeParams(req *request, v interface{}) error {
+	if err := json.Unmarshal(req.Params, v); err != nil {
+		return &responseError{
+			Code:    codeInvalidParams,
+			Message: fmt.Sprintf("invalid params for %s: %v", req.Method, err),
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, rerr *responseError) error {
+	resp := response{JSONRPC: "2.0", ID: id, Error: rerr}
+	if rerr == nil {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return errors.Wrap(err, "marshal result")
+		}
+
+		raw := json.RawMessage(b)
+		resp.Result = &raw
+	}
+
+	return writeMessage(s.out, resp)
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) initialize() initializeResult {
+	return initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync: syncFull,
+			CompletionProvider: completionOptions{
+				TriggerCharacters: []string{".", "/", `"`, "'"},
+			},
+			DefinitionProvider: true,
+		},
+		ServerInfo: serverInfo{Name: "ks"},
+	}
+}
+
+func (s *Server) didOpen(params didOpenTextDocumentParams) error {
+	d, err := newDocument(params.TextDocument.URI, params.TextDocument.Text)
+	if err != nil {
+		return err
+	}
+
+	s.documents[d.uri] = d
+	return s.publishDiagnostics(d)
+}
+
+func (s *Server) didChange(params didChangeTextDocumentParams) error {
+	d, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+
+	if n := len(params.ContentChanges); n > 0 {
+		d.text = params.ContentChanges[n-1].Text
+	}
+
+	return s.publishDiagnostics(d)
+}
+
+func (s *Server) didClose(params didCloseTextDocumentParams) error {
+	uri := params.TextDocument.URI
+	delete(s.documents, uri)
+
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: []diagnostic{},
+	})
+}
+
+func (s *Server) document(uri string) (*document, error) {
+	d, ok := s.documents[uri]
+	if !ok {
+		return nil, &responseError{
+			Code:    codeInvalidParams,
+			Message: fmt.Sprintf("document %q is not open", uri),
+		}
+	}
+
+	return d, nil
+}
+
+// jPaths returns the jsonnet import paths of the app in the order they are
+// added to a VM. Later paths take precedence.
+func (s *Server) jPaths() []string {
+	paths := []string{
+		filepath.Join(s.app.Root(), "vendor"),
+		filepath.Join(s.app.Root(), "lib"),
+	}
+
+	if s.envName != "" {
+		libPath, err := s.app.LibPath(s.envName)
+		if err != nil {
+			log.WithError(err).Debugf("find lib path for environment %s", s.envName)
+		} else {
+			paths = append(paths, libPath)
+		}
+	}
+
+	return paths
+}
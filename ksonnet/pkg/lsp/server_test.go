@@ -0,0 +1,135 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	clientPrefix = "--> "
+	serverPrefix = "<-- "
+)
+
+func withServer(t *testing.T, fn func(s *Server)) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		test.StageDir(t, fs, "app", "/app")
+
+		env := &app.EnvironmentConfig{
+			Name: "default",
+			Path: "default",
+			Destination: &app.EnvironmentDestinationSpec{
+				Server:    "http://example.com",
+				Namespace: "default",
+			},
+		}
+		a.On("Environment", "default").Return(env, nil)
+
+		configMap, err := prototype.DefaultBuilder(test.ReadTestData(t, "config-map.jsonnet"))
+		require.NoError(t, err)
+
+		s := New(a, "default", prototype.Prototypes{configMap})
+		s.paramsFn = func(a app.App, moduleName, envName string) (string, error) {
+			require.Equal(t, "", moduleName)
+			require.Equal(t, "default", envName)
+			return `{global: {}, components: {guestbook: {name: "guestbook", replicas: 1}}}`, nil
+		}
+
+		fn(s)
+	})
+}
+
+// runSession replays a recorded JSON-RPC session. Lines starting with "-->"
+// are sent by the client, and lines starting with "<--" are the messages the
+// server is expected to send, in order.
+func runSession(t *testing.T, s *Server, name string) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "sessions", name))
+	require.NoError(t, err)
+
+	var in bytes.Buffer
+	var expected []string
+	for _, line := range strings.Split(string(b), "\n") {
+		switch {
+		case strings.HasPrefix(line, clientPrefix):
+			msg := strings.TrimPrefix(line, clientPrefix)
+			fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+		case strings.HasPrefix(line, serverPrefix):
+			expected = append(expected, strings.TrimPrefix(line, serverPrefix))
+		}
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, s.Serve(&in, &out))
+
+	var got []string
+	r := bufio.NewReader(&out)
+	for {
+		msg, err := readMessage(r)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, string(msg))
+	}
+
+	require.Len(t, got, len(expected), "server messages:\n%s", strings.Join(got, "\n"))
+	for i := range expected {
+		require.JSONEq(t, expected[i], got[i], "server message %d", i+1)
+	}
+}
+
+func TestServer_sessions(t *testing.T) {
+	cases := []string{
+		"lifecycle.session",
+		"completion.session",
+		"definition.session",
+		"diagnostics.session",
+	}
+
+	for _, tc := range cases {
+		t.Run(tc, func(t *testing.T) {
+			withServer(t, func(s *Server) {
+				runSession(t, s, tc)
+			})
+		})
+	}
+}
+
+func TestServer_Serve_exit_without_shutdown(t *testing.T) {
+	withServer(t, func(s *Server) {
+		msg := `{"jsonrpc":"2.0","method":"exit"}`
+		in := strings.NewReader(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(msg), msg))
+
+		var out bytes.Buffer
+		err := s.Serve(in, &out)
+		require.Error(t, err)
+		require.Empty(t, out.String())
+	})
+}
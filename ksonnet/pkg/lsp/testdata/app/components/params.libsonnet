@@ -0,0 +1,9 @@
+{
+  global: {},
+  components: {
+    guestbook: {
+      name: "guestbook",
+      replicas: 1,
+    },
+  },
+}
@@ -0,0 +1,14 @@
+{
+  apps:: {
+    v1beta2:: {
+      deployment:: {
+        new(name):: {apiVersion: "apps/v1beta2", kind: "Deployment", metadata: {name: name}},
+        withReplicas(replicas):: {spec: {replicas: replicas}},
+        mixin:: {},
+      },
+    },
+  },
+  core:: {
+    v1:: {},
+  },
+}
@@ -29,6 +29,20 @@ const (
 	// AnnotationManaged annotation holds the pristine object.
 	AnnotationManaged = "ksonnet.io/managed"
 
+	// AnnotationOriginalImages annotation holds the image references an
+	// object's containers had before they were pinned to digests. It is a JSON
+	// object keyed by container name.
+	AnnotationOriginalImages = "ksonnet.io/original-images"
+
+	// AnnotationParams annotation holds the component parameters an object
+	// was applied with.
+	AnnotationParams = "ksonnet.io/params"
+
+	// AnnotationPolicyExempt annotation exempts an object from policies. It is
+	// a comma separated list of policy names, optionally prefixed with the
+	// environment the exemption applies to, e.g. `prod:no-latest-image`.
+	AnnotationPolicyExempt = "policy.ksonnet.io/exempt"
+
 	// LabelDeployManager label signifies an object is deployed with ksonnet.
 	LabelDeployManager = "app.kubernetes.io/deploy-manager"
 
@@ -18,13 +18,16 @@ package openapi
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	oaerrors "github.com/go-openapi/errors"
 	"github.com/go-openapi/spec"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/validate"
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/kubespec"
 	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/crd"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -34,13 +37,30 @@ var (
 	errUnsupportedDefinition = errors.New("unsupported definition")
 )
 
-// ValidateAgainstSchema validates a document against the schema.
-func ValidateAgainstSchema(a app.App, obj *unstructured.Unstructured, envName string) []error {
+// ValidationError is a problem with a field of an object.
+type ValidationError struct {
+	// Path is the JSON path of the field, e.g. `$.spec.replicas`.
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidateAgainstSchema validates a document against the schema. Custom
+// resources are validated against the schemas of their CRDs. Schema
+// violations are returned as ValidationErrors.
+func ValidateAgainstSchema(a app.App, obj *unstructured.Unstructured, envName string, crds ...*crd.CRD) []error {
 	v := newValidateAgainstSchema()
+	v.crds = crds
 	return v.run(a, obj, envName)
 }
 
 type validateAgainstSchema struct {
+	crds []*crd.CRD
+
 	definitionName func(*unstructured.Unstructured) (string, error)
 	loadSchema     func(app.App, string, string) (*spec.Schema, error)
 	validate       func(*spec.Schema, interface{}, strfmt.Registry) error
@@ -55,25 +75,94 @@ func newValidateAgainstSchema() *validateAgainstSchema {
 }
 
 func (v *validateAgainstSchema) run(a app.App, obj *unstructured.Unstructured, envName string) []error {
-	name, err := v.definitionName(obj)
-	if err != nil {
-		if err == errUnsupportedDefinition {
-			return nil
+	schema, ok := crdSchema(v.crds, obj)
+	if ok && schema == nil {
+		// the CRD does not have a schema.
+		return nil
+	}
+
+	if !ok {
+		name, err := v.definitionName(obj)
+		if err != nil {
+			if err == errUnsupportedDefinition {
+				return nil
+			}
+
+			return []error{err}
 		}
 
-		return []error{err}
+		schema, err = v.loadSchema(a, name, envName)
+		if err != nil {
+			return []error{err}
+		}
 	}
 
-	schema, err := v.loadSchema(a, name, envName)
-	if err != nil {
-		return []error{err}
+	if err := v.validate(schema, obj.Object, strfmt.Default); err != nil {
+		return validationErrors(err)
 	}
 
-	if err := v.validate(schema, obj.Object, strfmt.Default); err != nil {
+	return nil
+}
+
+// crdSchema finds the schema for a custom resource. It returns false if the
+// object is not defined by any of the CRDs.
+func crdSchema(crds []*crd.CRD, obj *unstructured.Unstructured) (*spec.Schema, bool) {
+	gvk := obj.GroupVersionKind()
+
+	for _, c := range crds {
+		if c.Group != gvk.Group || c.Kind != gvk.Kind {
+			continue
+		}
+
+		for _, v := range c.Versions {
+			if v.Name == gvk.Version {
+				return v.Schema, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// validationErrors converts the errors from validating an object to
+// ValidationErrors.
+func validationErrors(err error) []error {
+	switch t := err.(type) {
+	case *oaerrors.CompositeError:
+		var errs []error
+		for _, e := range t.Errors {
+			errs = append(errs, validationErrors(e)...)
+		}
+		return errs
+	case *oaerrors.Validation:
+		msg := t.Error()
+		msg = strings.TrimPrefix(msg, t.Name+" ")
+		msg = strings.TrimPrefix(msg, "in "+t.In+" ")
+
+		return []error{&ValidationError{Path: jsonPath(t.Name), Message: msg}}
+	default:
 		return []error{err}
 	}
+}
 
-	return nil
+// jsonPath converts the name of a field in a validation error to a JSON path,
+// e.g. `spec.containers.0.image` becomes `$.spec.containers[0].image`.
+func jsonPath(name string) string {
+	path := "$"
+	for _, part := range strings.Split(name, ".") {
+		if part == "" {
+			continue
+		}
+
+		if _, err := strconv.Atoi(part); err == nil {
+			path += "[" + part + "]"
+			continue
+		}
+
+		path += "." + part
+	}
+
+	return path
 }
 
 func definitionName(obj *unstructured.Unstructured) (string, error) {
@@ -99,7 +188,7 @@ func definitionName(obj *unstructured.Unstructured) (string, error) {
 			logrus.WithFields(logrus.Fields{
 				"kind":       kind,
 				"apiVersion": parts[0],
-			}).Warn("ksonnet currently does not support CRDs")
+			}).Warn("no schema found for custom resource, skipping validation")
 
 			return "", errUnsupportedDefinition
 		}
@@ -16,12 +16,14 @@
 package openapi
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/go-openapi/spec"
 	"github.com/go-openapi/strfmt"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/crd"
 	"github.com/ksonnet/ksonnet/pkg/util/test"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
@@ -65,6 +67,114 @@ func TestValidateAgainstSchema(t *testing.T) {
 	})
 }
 
+func TestValidateAgainstSchema_crd(t *testing.T) {
+	test.WithApp(t, "/", func(a *mocks.App, fs afero.Fs) {
+		replicas := float64(1)
+		crds := []*crd.CRD{
+			{
+				Group: "example.com",
+				Kind:  "Foo",
+				Versions: []crd.Version{
+					{
+						Name: "v1",
+						Schema: &spec.Schema{
+							SchemaProps: spec.SchemaProps{
+								Properties: map[string]spec.Schema{
+									"spec": {
+										SchemaProps: spec.SchemaProps{
+											Required: []string{"image"},
+											Properties: map[string]spec.Schema{
+												"replicas": {SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"integer"}, Minimum: &replicas}},
+												"ports": {
+													SchemaProps: spec.SchemaProps{
+														Type:  spec.StringOrArray{"array"},
+														Items: &spec.SchemaOrArray{Schema: spec.Int64Property()},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					{Name: "v2"},
+				},
+			},
+		}
+
+		cases := []struct {
+			name       string
+			apiVersion string
+			spec       map[string]interface{}
+			expected   []string
+		}{
+			{
+				name:       "valid",
+				apiVersion: "example.com/v1",
+				spec:       map[string]interface{}{"image": "nginx", "replicas": 2},
+			},
+			{
+				name:       "invalid",
+				apiVersion: "example.com/v1",
+				spec: map[string]interface{}{
+					"replicas": 0,
+					"ports":    []interface{}{80, "http"},
+				},
+				expected: []string{
+					`$.spec.image: is required`,
+					`$.spec.ports: must be of type integer: "string"`,
+					`$.spec.replicas: should be greater than or equal to 1`,
+				},
+			},
+			{
+				name:       "version without schema",
+				apiVersion: "example.com/v2",
+				spec:       map[string]interface{}{"replicas": "many"},
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				obj := &unstructured.Unstructured{
+					Object: map[string]interface{}{
+						"apiVersion": tc.apiVersion,
+						"kind":       "Foo",
+						"spec":       tc.spec,
+					},
+				}
+
+				var got []string
+				for _, err := range ValidateAgainstSchema(a, obj, "default", crds...) {
+					require.IsType(t, &ValidationError{}, err)
+					got = append(got, err.Error())
+				}
+				sort.Strings(got)
+
+				require.Equal(t, tc.expected, got)
+			})
+		}
+	})
+}
+
+func Test_jsonPath(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "", expected: "$"},
+		{name: ".", expected: "$"},
+		{name: "spec.replicas", expected: "$.spec.replicas"},
+		{name: "spec.containers.0.image", expected: "$.spec.containers[0].image"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, jsonPath(tc.name))
+		})
+	}
+}
+
 func Test_definitionName(t *testing.T) {
 	cases := []struct {
 		name         string
@@ -0,0 +1,488 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package params
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+)
+
+var (
+	// reFieldKey matches the key of an object field which ends at the
+	// beginning of the field's value.
+	reFieldKey = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*|'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*")\s*\+?:{1,3}\s*$`)
+	// reIdentifier matches a field key which does not need to be quoted.
+	reIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	jsonnetKeywords = map[string]bool{
+		"assert": true, "else": true, "error": true, "false": true, "for": true,
+		"function": true, "if": true, "import": true, "importstr": true, "in": true,
+		"local": true, "null": true, "self": true, "super": true, "tailstrict": true,
+		"then": true, "true": true,
+	}
+)
+
+// textEdit replaces the source text between begin and end.
+type textEdit struct {
+	begin, end int
+	text       string
+}
+
+// fieldInsert collects the fields inserted into an object, so they can be
+// written with a single edit.
+type fieldInsert struct {
+	edit   *textEdit
+	fields []string
+	render func(fields []string) string
+}
+
+// sourceEditor edits jsonnet source text in place. Edits are made to the spans
+// of the fields which change, so comments, blank lines and the ordering of
+// the rest of the source are left untouched. Objects passed to the editor
+// must come from parsing the editor's source.
+type sourceEditor struct {
+	src     string
+	lines   []int
+	edits   []*textEdit
+	inserts map[*astext.Object]*fieldInsert
+	deleted map[ast.Node]bool
+}
+
+func newSourceEditor(src string) *sourceEditor {
+	lines := []int{0}
+	for i := range src {
+		if src[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+
+	return &sourceEditor{
+		src:     src,
+		lines:   lines,
+		inserts: make(map[*astext.Object]*fieldInsert),
+		deleted: make(map[ast.Node]bool),
+	}
+}
+
+// String returns the source with the edits applied.
+func (e *sourceEditor) String() (string, error) {
+	edits := make([]*textEdit, len(e.edits))
+	copy(edits, e.edits)
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].begin < edits[j].begin
+	})
+
+	var buf bytes.Buffer
+	cur := 0
+	for _, edit := range edits {
+		if edit.begin < cur {
+			return "", errors.New("params edits overlap")
+		}
+
+		buf.WriteString(e.src[cur:edit.begin])
+		buf.WriteString(edit.text)
+		cur = edit.end
+	}
+	buf.WriteString(e.src[cur:])
+
+	return buf.String(), nil
+}
+
+// setField sets the value of a field in an object. Existing fields have their
+// value replaced, while new fields are appended to the object.
+func (e *sourceEditor) setField(obj *astext.Object, id string, value ast.Node) error {
+	of, err := findField(obj, id)
+	if err != nil {
+		if _, ok := err.(*unknownField); !ok {
+			return err
+		}
+
+		of, err = astext.CreateField(id)
+		if err != nil {
+			return err
+		}
+		of.Hide = ast.ObjectFieldInherit
+		of.Expr2 = value
+
+		return e.addField(obj, of)
+	}
+
+	begin, end, err := e.valueSpan(of)
+	if err != nil {
+		return err
+	}
+
+	text, err := e.nodeText(value, e.indentAt(begin))
+	if err != nil {
+		return err
+	}
+
+	e.edits = append(e.edits, &textEdit{begin: begin, end: end, text: text})
+	return nil
+}
+
+// addField appends a new field to an object.
+func (e *sourceEditor) addField(obj *astext.Object, of *astext.ObjectField) error {
+	fi, ok := e.inserts[obj]
+	if !ok {
+		var err error
+		if fi, err = e.newFieldInsert(obj); err != nil {
+			return err
+		}
+		e.inserts[obj] = fi
+		e.edits = append(e.edits, fi.edit)
+	}
+
+	text, err := e.fieldText(of)
+	if err != nil {
+		return err
+	}
+
+	fi.fields = append(fi.fields, text)
+	fi.edit.text = fi.render(fi.fields)
+	return nil
+}
+
+// newFieldInsert determines where and how fields are added to an object.
+// Multi-line objects get a line per field before the closing brace, while
+// single line objects are extended in place.
+func (e *sourceEditor) newFieldInsert(obj *astext.Object) (*fieldInsert, error) {
+	open := e.offset(obj.Loc().Begin)
+	closing := e.offset(obj.Loc().End) - 1
+	if open < 0 || closing <= open || e.src[open] != '{' || e.src[closing] != '}' {
+		return nil, errors.New("unable to locate object in params source")
+	}
+
+	var last *astext.ObjectField
+	for i := range obj.Fields {
+		if !e.deleted[obj.Fields[i].Expr2] {
+			last = &obj.Fields[i]
+		}
+	}
+
+	braceIndent := leadingSpace(e.src[e.lineStart(closing):])
+
+	if e.onlySpaceBefore(closing) && e.lineStart(closing) > open {
+		indent := braceIndent + "  "
+		if len(obj.Fields) > 0 {
+			begin, _, err := e.fieldSpan(obj, len(obj.Fields)-1)
+			if err != nil {
+				return nil, err
+			}
+			indent = leadingSpace(e.src[e.lineStart(begin):])
+		}
+
+		if last != nil && !obj.TrailingComma {
+			_, end, err := e.valueSpan(last)
+			if err != nil {
+				return nil, err
+			}
+			e.edits = append(e.edits, &textEdit{begin: end, end: end, text: ","})
+		}
+
+		pos := e.lineStart(closing)
+		return &fieldInsert{
+			edit: &textEdit{begin: pos, end: pos},
+			render: func(fields []string) string {
+				var buf bytes.Buffer
+				for _, field := range fields {
+					buf.WriteString(indent + e.reindent(field, indent) + ",\n")
+				}
+				return buf.String()
+			},
+		}, nil
+	}
+
+	if last == nil {
+		// the object is empty, so it is expanded to multiple lines.
+		indent := leadingSpace(e.src[e.lineStart(open):])
+		return &fieldInsert{
+			edit: &textEdit{begin: open + 1, end: closing},
+			render: func(fields []string) string {
+				var buf bytes.Buffer
+				buf.WriteString("\n")
+				for _, field := range fields {
+					buf.WriteString(indent + "  " + e.reindent(field, indent+"  ") + ",\n")
+				}
+				buf.WriteString(indent)
+				return buf.String()
+			},
+		}, nil
+	}
+
+	indent := leadingSpace(e.src[e.lineStart(open):])
+	join := func(fields []string) string {
+		var indented []string
+		for _, field := range fields {
+			indented = append(indented, e.reindent(field, indent))
+		}
+		return strings.Join(indented, ", ")
+	}
+
+	if obj.TrailingComma {
+		return &fieldInsert{
+			edit: &textEdit{begin: closing, end: closing},
+			render: func(fields []string) string {
+				return join(fields) + ", "
+			},
+		}, nil
+	}
+
+	_, end, err := e.valueSpan(last)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fieldInsert{
+		edit: &textEdit{begin: end, end: end},
+		render: func(fields []string) string {
+			return ", " + join(fields)
+		},
+	}, nil
+}
+
+// setSuperSugar makes a field in an object inherit from its super object
+// using `+:`.
+func (e *sourceEditor) setSuperSugar(obj *astext.Object, id string) error {
+	for i := range obj.Fields {
+		fieldID, err := jsonnet.FieldID(obj.Fields[i])
+		if err != nil {
+			return err
+		}
+
+		if fieldID != id || obj.Fields[i].SuperSugar {
+			continue
+		}
+
+		begin, _, err := e.fieldSpan(obj, i)
+		if err != nil {
+			return err
+		}
+
+		valueBegin, _, err := e.valueSpan(&obj.Fields[i])
+		if err != nil {
+			return err
+		}
+
+		region := e.src[begin:valueBegin]
+		loc := reFieldKey.FindStringSubmatchIndex(region)
+		if loc == nil {
+			return errors.New("unable to locate field key in params source")
+		}
+
+		colon := begin + loc[3] + strings.Index(region[loc[3]:], ":")
+		e.edits = append(e.edits, &textEdit{begin: colon, end: colon, text: "+"})
+		return nil
+	}
+
+	return nil
+}
+
+// deleteField deletes a field from an object. Fields which are on their own
+// lines are removed along with the comments directly above them.
+func (e *sourceEditor) deleteField(obj *astext.Object, id string) error {
+	for i := range obj.Fields {
+		fieldID, err := jsonnet.FieldID(obj.Fields[i])
+		if err != nil {
+			return err
+		}
+
+		if fieldID != id {
+			continue
+		}
+
+		begin, end, err := e.fieldSpan(obj, i)
+		if err != nil {
+			return err
+		}
+
+		// consume the separator after the field.
+		end = skipSpace(e.src, end)
+		if end < len(e.src) && e.src[end] == ',' {
+			end = skipSpace(e.src, end+1)
+		}
+
+		if e.onlySpaceBefore(begin) && (end == len(e.src) || e.src[end] == '\n') {
+			begin = e.lineStart(begin)
+			for begin > 0 {
+				prev := e.lineStart(begin - 1)
+				line := strings.TrimSpace(e.src[prev : begin-1])
+				if !strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "#") {
+					break
+				}
+				begin = prev
+			}
+
+			if end < len(e.src) {
+				end++
+			}
+		}
+
+		e.deleted[obj.Fields[i].Expr2] = true
+		e.edits = append(e.edits, &textEdit{begin: begin, end: end})
+		return nil
+	}
+
+	return nil
+}
+
+// fieldSpan returns the span of a field from the beginning of its key to the
+// end of its value.
+func (e *sourceEditor) fieldSpan(obj *astext.Object, i int) (int, int, error) {
+	valueBegin, valueEnd, err := e.valueSpan(&obj.Fields[i])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	regionBegin := e.offset(obj.Loc().Begin) + 1
+	if i > 0 {
+		if _, regionBegin, err = e.valueSpan(&obj.Fields[i-1]); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if regionBegin > valueBegin {
+		return 0, 0, errors.New("unable to locate field in params source")
+	}
+
+	loc := reFieldKey.FindStringIndex(e.src[regionBegin:valueBegin])
+	if loc == nil {
+		return 0, 0, errors.New("unable to locate field key in params source")
+	}
+
+	return regionBegin + loc[0], valueEnd, nil
+}
+
+// valueSpan returns the span of a field's value.
+func (e *sourceEditor) valueSpan(of *astext.ObjectField) (int, int, error) {
+	if of.Expr2 == nil || of.Method != nil || of.Expr3 != nil {
+		return 0, 0, errors.New("only plain fields can be edited")
+	}
+
+	begin := e.offset(of.Expr2.Loc().Begin)
+	end := e.offset(of.Expr2.Loc().End)
+	if begin < 0 || end < begin {
+		return 0, 0, errors.New("unable to locate field value in params source")
+	}
+
+	return begin, end, nil
+}
+
+// fieldText formats a new field. Lines after the first are not indented.
+func (e *sourceEditor) fieldText(of *astext.ObjectField) (string, error) {
+	id, err := jsonnet.FieldID(*of)
+	if err != nil {
+		return "", err
+	}
+
+	key := id
+	if !reIdentifier.MatchString(id) || jsonnetKeywords[id] {
+		if key, err = e.nodeText(&ast.LiteralString{Value: id, Kind: ast.StringSingle}, ""); err != nil {
+			return "", err
+		}
+	}
+
+	if of.SuperSugar {
+		key += "+"
+	}
+
+	switch of.Hide {
+	case ast.ObjectFieldHidden:
+		key += "::"
+	case ast.ObjectFieldVisible:
+		key += ":::"
+	default:
+		key += ":"
+	}
+
+	value, err := e.nodeText(of.Expr2, "")
+	if err != nil {
+		return "", err
+	}
+
+	return key + " " + value, nil
+}
+
+// nodeText formats a node which starts on a line with the supplied indent.
+func (e *sourceEditor) nodeText(node ast.Node, indent string) (string, error) {
+	var buf bytes.Buffer
+	if err := jsonnetPrinterFn(&buf, node); err != nil {
+		return "", errors.Wrap(err, "format value")
+	}
+
+	return e.reindent(strings.TrimSpace(buf.String()), indent), nil
+}
+
+// reindent indents all but the first line of text.
+func (e *sourceEditor) reindent(text, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// offset converts a location to an offset in the source.
+func (e *sourceEditor) offset(loc ast.Location) int {
+	if loc.Line < 1 || loc.Line > len(e.lines) {
+		return -1
+	}
+
+	return e.lines[loc.Line-1] + loc.Column - 1
+}
+
+func (e *sourceEditor) lineStart(offset int) int {
+	i := sort.Search(len(e.lines), func(i int) bool { return e.lines[i] > offset })
+	return e.lines[i-1]
+}
+
+func (e *sourceEditor) indentAt(offset int) string {
+	return leadingSpace(e.src[e.lineStart(offset):])
+}
+
+func (e *sourceEditor) onlySpaceBefore(offset int) bool {
+	return strings.TrimSpace(e.src[e.lineStart(offset):offset]) == ""
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+// skipSpace skips spaces, tabs and a trailing line comment.
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+
+	if strings.HasPrefix(s[i:], "//") || strings.HasPrefix(s[i:], "#") {
+		if n := strings.IndexByte(s[i:], '\n'); n >= 0 {
+			return i + n
+		}
+		return len(s)
+	}
+
+	return i
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package params
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_update_preserves_formatting(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		path   []string
+		params map[string]interface{}
+	}{
+		{
+			name:   "change nested values",
+			output: "out-nested.libsonnet",
+			path:   []string{"components", "web"},
+			params: map[string]interface{}{
+				"replicas": 3,
+				"config":   "a: 1\nb: 2\n",
+				"nested": map[string]interface{}{
+					"a": 1,
+					"b": map[string]interface{}{"c": 5},
+				},
+				"labels": map[string]interface{}{
+					"app":       "web",
+					"tier":      "front",
+					"new-label": "y",
+				},
+				"extra": map[string]interface{}{
+					"x": 1,
+					"y": []interface{}{1, "two"},
+				},
+			},
+		},
+		{
+			name:   "replace multi-line string",
+			output: "out-string.libsonnet",
+			path:   []string{"components", "web"},
+			params: map[string]interface{}{
+				"config": "changed\nmulti\n",
+			},
+		},
+		{
+			name:   "single line object",
+			output: "out-inline.libsonnet",
+			path:   []string{"components", "inline"},
+			params: map[string]interface{}{
+				"a": 1,
+				"c": 3,
+			},
+		},
+		{
+			name:   "empty object",
+			output: "out-empty.libsonnet",
+			path:   []string{"components", "empty"},
+			params: map[string]interface{}{
+				"z": "q",
+			},
+		},
+		{
+			name:   "new component",
+			output: "out-new.libsonnet",
+			path:   []string{"components", "brand-new"},
+			params: map[string]interface{}{
+				"z": "q",
+			},
+		},
+		{
+			name:   "quoted keys",
+			output: "out-global.libsonnet",
+			path:   []string{"global"},
+			params: map[string]interface{}{
+				"if": true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := test.ReadTestData(t, filepath.Join("editor", "in.libsonnet"))
+
+			got, err := update(tc.path, src, tc.params)
+			require.NoError(t, err)
+
+			expected := test.ReadTestData(t, filepath.Join("editor", tc.output))
+			require.Equal(t, expected, got)
+		})
+	}
+}
+
+func Test_sourceEditor_overlapping_edits(t *testing.T) {
+	e := newSourceEditor("{ a: 1 }")
+	e.edits = append(e.edits,
+		&textEdit{begin: 2, end: 6, text: "b: 2"},
+		&textEdit{begin: 5, end: 6, text: "3"},
+	)
+
+	_, err := e.String()
+	require.Error(t, err)
+}
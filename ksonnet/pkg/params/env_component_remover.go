@@ -16,7 +16,6 @@
 package params
 
 import (
-	"bytes"
 	"fmt"
 
 	"github.com/google/go-jsonnet/ast"
@@ -61,19 +60,20 @@ func (ecr *EnvComponentRemover) Remove(componentName, snippet string) (string, e
 		return "", err
 	}
 
-	if err = ecr.deleteEntry(obj, componentName); err != nil {
+	e := newSourceEditor(snippet)
+	if err = ecr.deleteEntry(e, obj, componentName); err != nil {
 		return "", errors.Wrap(err, "delete entry")
 	}
 
-	var buf bytes.Buffer
-	if err = jsonnetPrinterFn(&buf, n); err != nil {
+	updated, err := e.String()
+	if err != nil {
 		return "", errors.Wrap(err, "unable to update snippet")
 	}
 
-	return buf.String(), nil
+	return updated, nil
 }
 
-func (ecr *EnvComponentRemover) deleteEntry(obj *astext.Object, componentName string) error {
+func (ecr *EnvComponentRemover) deleteEntry(e *sourceEditor, obj *astext.Object, componentName string) error {
 	of, err := findField(obj, "components")
 	if err != nil {
 		return errors.Wrap(errUnsupportedEnvParams, "unable to find components field")
@@ -84,26 +84,7 @@ func (ecr *EnvComponentRemover) deleteEntry(obj *astext.Object, componentName st
 		return errors.Wrap(errUnsupportedEnvParams, "components field is not an object")
 	}
 
-	match := -1
-
-	var id string
-	for i := range componentsObj.Fields {
-		id, err = jsonnet.FieldID(componentsObj.Fields[i])
-		if err != nil {
-			return err
-		}
-
-		if id == componentName {
-			match = i
-		}
-	}
-
-	if match >= 0 {
-		componentsObj.Fields = append(componentsObj.Fields[:match],
-			componentsObj.Fields[match+1:]...)
-	}
-
-	return nil
+	return e.deleteField(componentsObj, componentName)
 }
 
 func findNamedLocal(l *ast.Local, name string) (*ast.Local, error) {
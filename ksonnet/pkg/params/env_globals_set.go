@@ -16,8 +16,6 @@
 package params
 
 import (
-	"bytes"
-
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
 	nm "github.com/ksonnet/ksonnet-lib/ksonnet-gen/nodemaker"
 	"github.com/ksonnet/ksonnet/metadata/params"
@@ -51,21 +49,21 @@ func (egs *EnvGlobalsSet) Set(snippet string, p params.Params) (string, error) {
 		return "", err
 	}
 
-	if err = egs.setParams(obj, p); err != nil {
+	e := newSourceEditor(snippet)
+	if err = egs.setParams(e, obj, p); err != nil {
 		return "", errors.Wrap(err, "set global params")
 	}
 
-	// root node should be a local if not, return an error
-	var buf bytes.Buffer
-	if err = jsonnetPrinterFn(&buf, n); err != nil {
+	updated, err := e.String()
+	if err != nil {
 		return "", errors.Wrap(err, "unable to update snippet")
 	}
 
-	return buf.String(), nil
+	return updated, nil
 }
 
-func (egs *EnvGlobalsSet) setParams(obj *astext.Object, p params.Params) error {
-	for key := range p {
+func (egs *EnvGlobalsSet) setParams(e *sourceEditor, obj *astext.Object, p params.Params) error {
+	for _, key := range sortedKeys(p) {
 
 		v := p[key]
 		if p1, ok := v.(params.Params); ok {
@@ -92,8 +90,7 @@ func (egs *EnvGlobalsSet) setParams(obj *astext.Object, p params.Params) error {
 			return err
 		}
 
-		path := []string{key}
-		if err = jsonnetSetFn(obj, path, value.Node()); err != nil {
+		if err = e.setField(obj, key, value.Node()); err != nil {
 			return err
 		}
 
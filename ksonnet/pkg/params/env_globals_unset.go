@@ -16,8 +16,6 @@
 package params
 
 import (
-	"bytes"
-
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
 	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
 	"github.com/pkg/errors"
@@ -53,37 +51,19 @@ func (epu *EnvGlobalsUnset) Unset(paramName, snippet string) (string, error) {
 		return "", err
 	}
 
-	if err = epu.unsetEntry(obj, paramName); err != nil {
+	e := newSourceEditor(snippet)
+	if err = epu.unsetEntry(e, obj, paramName); err != nil {
 		return "", errors.Wrap(err, "delete entry")
 	}
 
-	var buf bytes.Buffer
-	if err = jsonnetPrinterFn(&buf, n); err != nil {
+	updated, err := e.String()
+	if err != nil {
 		return "", errors.Wrap(err, "unable to update snippet")
 	}
 
-	return buf.String(), nil
+	return updated, nil
 }
 
-func (epu *EnvGlobalsUnset) unsetEntry(obj *astext.Object, paramName string) error {
-	match := -1
-
-	var id string
-	var err error
-	for i := range obj.Fields {
-		id, err = jsonnet.FieldID(obj.Fields[i])
-		if err != nil {
-			return err
-		}
-
-		if id == paramName {
-			match = i
-		}
-	}
-
-	if match >= 0 {
-		obj.Fields = append(obj.Fields[:match], obj.Fields[match+1:]...)
-	}
-
-	return nil
+func (epu *EnvGlobalsUnset) unsetEntry(e *sourceEditor, obj *astext.Object, paramName string) error {
+	return e.deleteField(obj, paramName)
 }
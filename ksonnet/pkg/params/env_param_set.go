@@ -16,8 +16,6 @@
 package params
 
 import (
-	"bytes"
-
 	"github.com/google/go-jsonnet/ast"
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
 	nm "github.com/ksonnet/ksonnet-lib/ksonnet-gen/nodemaker"
@@ -27,6 +25,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Literal is a string param value which is set as is, rather than being
+// decoded as a jsonnet value. e.g. Literal("3") is set as '3' instead of 3.
+type Literal string
+
 // EnvParamSet sets environment params for a component.
 type EnvParamSet struct {
 }
@@ -56,20 +58,20 @@ func (epa *EnvParamSet) Set(componentName, snippet string, p params.Params) (str
 		return "", err
 	}
 
-	if err = epa.setParams(obj, componentName, p); err != nil {
+	e := newSourceEditor(snippet)
+	if err = epa.setParams(e, obj, componentName, p); err != nil {
 		return "", errors.Wrap(err, "set params")
 	}
 
-	// root node should be a local if not, return an error
-	var buf bytes.Buffer
-	if err = jsonnetPrinterFn(&buf, n); err != nil {
+	updated, err := e.String()
+	if err != nil {
 		return "", errors.Wrap(err, "unable to update snippet")
 	}
 
-	return buf.String(), nil
+	return updated, nil
 }
 
-func (epa *EnvParamSet) setParams(obj *astext.Object, componentName string, p params.Params) error {
+func (epa *EnvParamSet) setParams(e *sourceEditor, obj *astext.Object, componentName string, p params.Params) error {
 	of, err := findField(obj, "components")
 	if err != nil {
 		return errors.Wrap(errUnsupportedEnvParams, "unable to find components field")
@@ -80,48 +82,67 @@ func (epa *EnvParamSet) setParams(obj *astext.Object, componentName string, p pa
 		return errors.Wrap(errUnsupportedEnvParams, "components field is not an object")
 	}
 
-	var componentObj *astext.Object
+	// new components are created with all of their params at once.
+	componentObj := &astext.Object{}
+	isNew := true
+
 	of, err = findField(componentsObj, componentName)
 	if err == nil {
-		of.SuperSugar = true
 		componentObj, ok = of.Expr2.(*astext.Object)
 		if !ok {
 			return errors.Wrapf(errUnsupportedEnvParams, "component field %q is not an object", componentName)
 		}
-	} else {
-		componentObj = &astext.Object{}
-		of, err := astext.CreateField(componentName)
-		if err != nil {
+		isNew = false
+
+		if err = e.setSuperSugar(componentsObj, componentName); err != nil {
 			return err
 		}
-		of.SuperSugar = true
-		of.Expr2 = componentObj
-		of.Hide = ast.ObjectFieldInherit
-		componentsObj.Fields = append(componentsObj.Fields, *of)
 	}
 
-	for key := range p {
-		s, err := p.StringValue(key)
-		if err != nil {
-			return err
+	for _, key := range sortedKeys(p) {
+		// string values are jsonnet encoded. Other values are used as is.
+		var decoded interface{}
+		switch t := p[key].(type) {
+		case Literal:
+			decoded = string(t)
+		case string:
+			var err error
+			decoded, err = jsonnet.DecodeValue(t)
+			if err != nil {
+				return err
+			}
+		default:
+			decoded = t
 		}
 
-		decoded, err := jsonnet.DecodeValue(s)
+		value, err := nm.ValueToNoder(decoded)
 		if err != nil {
 			return err
 		}
 
-		value, err := nm.ValueToNoder(decoded)
-		if err != nil {
-			return err
+		if isNew {
+			if err = jsonnetSetFn(componentObj, []string{key}, value.Node()); err != nil {
+				return err
+			}
+			continue
 		}
 
-		path := []string{key}
-		if err = jsonnetSetFn(componentObj, path, value.Node()); err != nil {
+		if err = e.setField(componentObj, key, value.Node()); err != nil {
 			return err
 		}
+	}
+
+	if !isNew {
+		return nil
+	}
 
+	of, err = astext.CreateField(componentName)
+	if err != nil {
+		return err
 	}
+	of.SuperSugar = true
+	of.Expr2 = componentObj
+	of.Hide = ast.ObjectFieldInherit
 
-	return nil
+	return e.addField(componentsObj, of)
 }
@@ -59,6 +59,19 @@ func TestEnvParamSet(t *testing.T) {
 				"name": "new-component",
 			},
 		},
+		{
+			name:          "typed values",
+			input:         filepath.Join("env", "no-globals", "set", "in.libsonnet"),
+			output:        filepath.Join("env", "no-globals", "set", "out-typed.libsonnet"),
+			componentName: "guestbook",
+			params: params.Params{
+				"replicas":      float64(2),
+				"image":         "nginx",
+				"tag":           Literal("1.0"),
+				"containerPort": "8080",
+				"labels":        map[string]interface{}{"tier": "frontend"},
+			},
+		},
 	}
 
 	for _, tc := range cases {
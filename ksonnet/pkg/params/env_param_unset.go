@@ -16,8 +16,6 @@
 package params
 
 import (
-	"bytes"
-
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
 	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
 	"github.com/pkg/errors"
@@ -54,19 +52,20 @@ func (epu *EnvParamUnset) Unset(componentName, paramName, snippet string) (strin
 		return "", err
 	}
 
-	if err = epu.unsetEntry(obj, componentName, paramName); err != nil {
+	e := newSourceEditor(snippet)
+	if err = epu.unsetEntry(e, obj, componentName, paramName); err != nil {
 		return "", errors.Wrap(err, "delete entry")
 	}
 
-	var buf bytes.Buffer
-	if err = jsonnetPrinterFn(&buf, n); err != nil {
+	updated, err := e.String()
+	if err != nil {
 		return "", errors.Wrap(err, "unable to update snippet")
 	}
 
-	return buf.String(), nil
+	return updated, nil
 }
 
-func (epu *EnvParamUnset) unsetEntry(obj *astext.Object, componentName, paramName string) error {
+func (epu *EnvParamUnset) unsetEntry(e *sourceEditor, obj *astext.Object, componentName, paramName string) error {
 	of, err := findField(obj, "components")
 	if err != nil {
 		return errors.Wrap(errUnsupportedEnvParams, "unable to find components field")
@@ -87,24 +86,5 @@ func (epu *EnvParamUnset) unsetEntry(obj *astext.Object, componentName, paramNam
 		return errors.Wrapf(errUnsupportedEnvParams, "component field %q is not an object", componentName)
 	}
 
-	match := -1
-
-	var id string
-	for i := range componentObj.Fields {
-		id, err = jsonnet.FieldID(componentObj.Fields[i])
-		if err != nil {
-			return err
-		}
-
-		if id == paramName {
-			match = i
-		}
-	}
-
-	if match >= 0 {
-		componentObj.Fields = append(componentObj.Fields[:match],
-			componentObj.Fields[match+1:]...)
-	}
-
-	return nil
+	return e.deleteField(componentObj, paramName)
 }
@@ -0,0 +1,76 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package params
+
+import (
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+)
+
+// Locate returns the source locations of the params set for a component in a
+// module or environment params file. Params which are not set in the file are
+// omitted.
+func Locate(filename, src, componentName string) (map[string]ast.LocationRange, error) {
+	n, err := jsonnet.ParseNode(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := componentParams(n, "")
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make(map[string]ast.LocationRange)
+
+	obj, ok := fieldObject(root, "components")
+	if !ok {
+		return locations, nil
+	}
+
+	obj, ok = fieldObject(obj, componentName)
+	if !ok {
+		return locations, nil
+	}
+
+	for i := range obj.Fields {
+		f := obj.Fields[i]
+		if f.Kind == ast.ObjectLocal || f.Kind == ast.ObjectAssert || f.Expr2 == nil {
+			continue
+		}
+
+		id, err := jsonnet.FieldID(f)
+		if err != nil {
+			continue
+		}
+
+		locations[id] = *f.Expr2.Loc()
+	}
+
+	return locations, nil
+}
+
+// fieldObject returns the value of an object field if it is an object.
+func fieldObject(obj *astext.Object, id string) (*astext.Object, bool) {
+	of, err := findField(obj, id)
+	if err != nil {
+		return nil, false
+	}
+
+	child, ok := of.Expr2.(*astext.Object)
+	return child, ok
+}
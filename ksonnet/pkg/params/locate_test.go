@@ -0,0 +1,86 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package params
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocate(t *testing.T) {
+	cases := []struct {
+		name          string
+		file          string
+		componentName string
+		expected      map[string]int
+	}{
+		{
+			name:          "module params",
+			file:          "params.libsonnet",
+			componentName: "guestbook-ui",
+			expected: map[string]int{
+				"containerPort": 9,
+				"image":         10,
+				"name":          11,
+				"replicas":      12,
+				"servicePort":   13,
+				"type":          14,
+			},
+		},
+		{
+			name:          "environment params",
+			file:          filepath.Join("env", "globals", "set", "out.libsonnet"),
+			componentName: "guestbook",
+			expected: map[string]int{
+				"name":          6,
+				"replicas":      7,
+				"containerPort": 8,
+			},
+		},
+		{
+			name:          "component without params",
+			file:          filepath.Join("env", "globals", "set", "out.libsonnet"),
+			componentName: "other",
+			expected:      map[string]int{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := test.ReadTestData(t, tc.file)
+
+			locations, err := Locate(tc.file, src, tc.componentName)
+			require.NoError(t, err)
+
+			got := make(map[string]int)
+			for k, loc := range locations {
+				assert.Equal(t, tc.file, loc.FileName)
+				got[k] = loc.Begin.Line
+			}
+
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestLocate_invalid(t *testing.T) {
+	_, err := Locate("params.libsonnet", "{", "guestbook")
+	require.Error(t, err)
+}
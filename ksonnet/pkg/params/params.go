@@ -16,15 +16,16 @@
 package params
 
 import (
-	"bytes"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/google/go-jsonnet/ast"
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
 	nm "github.com/ksonnet/ksonnet-lib/ksonnet-gen/nodemaker"
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/printer"
+	"github.com/ksonnet/ksonnet/metadata/params"
 	jsonnetutil "github.com/ksonnet/ksonnet/pkg/util/jsonnet"
 	"github.com/pkg/errors"
 )
@@ -106,7 +107,8 @@ func DeleteFromObject(fieldPath []string, paramsData, key, root string) (string,
 	return updateFn(updatePath, paramsData, props)
 }
 
-// update updates a params file with the params for a component.
+// update updates a params file with the params for a component. Only the
+// params which have changed are rewritten in the source.
 func update(path []string, src string, params map[string]interface{}) (string, error) {
 	n, err := jsonnetParseFn("params.libsonnet", src)
 	if err != nil {
@@ -123,16 +125,102 @@ func update(path []string, src string, params map[string]interface{}) (string, e
 		return "", errors.Wrap(err, "convert params to object")
 	}
 
-	if err := jsonnetSetFn(obj, path, paramsObject.Node()); err != nil {
+	e := newSourceEditor(src)
+	if err := syncPath(e, obj, path, params, paramsObject.Node()); err != nil {
 		return "", errors.Wrap(err, "update params")
 	}
 
-	var buf bytes.Buffer
-	if err := jsonnetPrinterFn(&buf, obj); err != nil {
+	out, err := e.String()
+	if err != nil {
 		return "", errors.Wrap(err, "rebuild params")
 	}
 
-	return buf.String(), nil
+	return out, nil
+}
+
+// syncPath updates the object at path so it contains params. `node` is params
+// as a jsonnet node. Objects missing from the path are created.
+func syncPath(e *sourceEditor, obj *astext.Object, path []string, params map[string]interface{}, node ast.Node) error {
+	if len(path) == 0 {
+		return syncObject(e, obj, params)
+	}
+
+	of, err := findField(obj, path[0])
+	if err == nil {
+		if child, ok := of.Expr2.(*astext.Object); ok {
+			return syncPath(e, child, path[1:], params, node)
+		}
+	} else if _, ok := err.(*unknownField); !ok {
+		return err
+	}
+
+	for i := len(path) - 1; i > 0; i-- {
+		child := &astext.Object{}
+		if err := jsonnetSetFn(child, []string{path[i]}, node); err != nil {
+			return err
+		}
+		node = child
+	}
+
+	return e.setField(obj, path[0], node)
+}
+
+// syncObject updates an object so its fields match params. Fields which
+// already have the desired value are left untouched.
+func syncObject(e *sourceEditor, obj *astext.Object, params map[string]interface{}) error {
+	current, err := convertObjectToMapFn(obj)
+	if err != nil {
+		// the current values can't be compared, so all params are written.
+		current = nil
+	}
+
+	for i := range obj.Fields {
+		id, err := jsonnetFieldIDFn(obj.Fields[i])
+		if err != nil {
+			return err
+		}
+
+		if _, ok := params[id]; !ok {
+			if err := e.deleteField(obj, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(params) {
+		value := params[key]
+
+		cur, exists := current[key]
+		if exists && reflect.DeepEqual(cur, value) {
+			continue
+		}
+
+		if m, ok := value.(map[string]interface{}); ok && exists {
+			if of, err := findField(obj, key); err == nil {
+				if child, ok := of.Expr2.(*astext.Object); ok {
+					if err := syncObject(e, child, m); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+		}
+
+		var node ast.Node = &ast.LiteralNull{}
+		if value != nil {
+			noder, err := nm.ValueToNoder(value)
+			if err != nil {
+				return err
+			}
+			node = noder.Node()
+		}
+
+		if err := e.setField(obj, key, node); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // ToMap converts a component's params to a map.
@@ -238,3 +326,15 @@ func componentParams(node ast.Node, componentName string) (*astext.Object, error
 		return obj, nil
 	}
 }
+
+// sortedKeys returns the keys of params in sorted order, so params are always
+// written in the same order.
+func sortedKeys(p params.Params) []string {
+	var keys []string
+	for k := range p {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
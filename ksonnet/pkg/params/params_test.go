@@ -269,6 +269,7 @@ func Test_update(t *testing.T) {
 		},
 		{
 			name: "unable to set in jsonnet",
+			path: []string{"components", "guestbook-ui"},
 			init: func() {
 				jsonnetParseFn = func(string, string) (ast.Node, error) {
 					return &astext.Object{}, nil
@@ -283,17 +284,13 @@ func Test_update(t *testing.T) {
 			isErr: true,
 		},
 		{
-			name: "unable to print",
+			name:        "unable to print",
+			paramSource: test.ReadTestData(t, "params.libsonnet"),
+			path:        []string{"components", "guestbook-ui"},
+			params: map[string]interface{}{
+				"replicas": 5,
+			},
 			init: func() {
-				jsonnetParseFn = func(string, string) (ast.Node, error) {
-					return &astext.Object{}, nil
-				}
-				nmKVFromMapFn = func(map[string]interface{}) (*nm.Object, error) {
-					return &nm.Object{}, nil
-				}
-				jsonnetSetFn = func(*astext.Object, []string, ast.Node) error {
-					return nil
-				}
 				jsonnetPrinterFn = func(io.Writer, ast.Node) error {
 					return errors.New("failed")
 				}
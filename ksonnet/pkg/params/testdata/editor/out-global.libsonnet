@@ -0,0 +1,26 @@
+{
+  // Global parameters are shared by all components.
+  global: {
+    'if': true,
+  },
+  components: {
+    // The web tier.
+
+    web: {
+      # Scale this up for production.
+      replicas: 1, // per zone
+      config: |||
+        a: 1
+        b: 2
+      |||,
+      nested: { a: 1, b: { c: 2 } },
+      labels: {
+        app: "web",
+        tier: "front", // owned by the web team
+      },
+      old: "x",
+    },
+    inline: { a: 1, b: 2, },
+    empty: {},
+  },
+}
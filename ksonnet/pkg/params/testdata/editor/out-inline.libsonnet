@@ -0,0 +1,24 @@
+{
+  // Global parameters are shared by all components.
+  global: {},
+  components: {
+    // The web tier.
+
+    web: {
+      # Scale this up for production.
+      replicas: 1, // per zone
+      config: |||
+        a: 1
+        b: 2
+      |||,
+      nested: { a: 1, b: { c: 2 } },
+      labels: {
+        app: "web",
+        tier: "front", // owned by the web team
+      },
+      old: "x",
+    },
+    inline: { a: 1, c: 3, },
+    empty: {},
+  },
+}
@@ -0,0 +1,28 @@
+{
+  // Global parameters are shared by all components.
+  global: {},
+  components: {
+    // The web tier.
+
+    web: {
+      # Scale this up for production.
+      replicas: 3, // per zone
+      config: |||
+        a: 1
+        b: 2
+      |||,
+      nested: { a: 1, b: { c: 5 } },
+      labels: {
+        app: "web",
+        tier: "front", // owned by the web team
+        'new-label': 'y',
+      },
+      extra: {
+        x: 1,
+        y: [1, 'two'],
+      },
+    },
+    inline: { a: 1, b: 2, },
+    empty: {},
+  },
+}
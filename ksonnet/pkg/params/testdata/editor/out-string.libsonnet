@@ -0,0 +1,13 @@
+{
+  // Global parameters are shared by all components.
+  global: {},
+  components: {
+    // The web tier.
+
+    web: {
+      config: 'changed\nmulti\n',
+    },
+    inline: { a: 1, b: 2, },
+    empty: {},
+  },
+}
@@ -1,12 +1,12 @@
-local params = std.extVar('__ksonnet/params');
-local globals = import 'globals.libsonnet';
+local params = std.extVar("__ksonnet/params");
+local globals = import "globals.libsonnet";
 local envParams = params + {
-  components+: {},
+  components +: {
+  },
 };
 
 {
   components: {
-    [x]: envParams.components[x] + globals
-    for x in std.objectFields(envParams.components)
+    [x]: envParams.components[x] + globals, for x in std.objectFields(envParams.components)
   },
-}
\ No newline at end of file
+}
@@ -1 +1,3 @@
-{ group: 'dev' }
\ No newline at end of file
+{
+  group: 'dev',
+}
\ No newline at end of file
@@ -1,9 +1,9 @@
-local params = std.extVar('__ksonnet/params');
-local globals = import 'globals.libsonnet';
+local params = std.extVar("__ksonnet/params");
+local globals = import "globals.libsonnet";
 local envParams = params + {
-  components+: {
-    guestbook+: {
-      name: 'guestbook-dev',
+  components +: {
+    guestbook +: {
+      name: "guestbook-dev",
       replicas: params.global.replicas,
     },
     component+: {
@@ -14,7 +14,6 @@ local envParams = params + {
 
 {
   components: {
-    [x]: envParams.components[x] + globals
-    for x in std.objectFields(envParams.components)
+    [x]: envParams.components[x] + globals, for x in std.objectFields(envParams.components)
   },
-}
\ No newline at end of file
+}
@@ -1,16 +1,15 @@
-local params = std.extVar('__ksonnet/params');
-local globals = import 'globals.libsonnet';
+local params = std.extVar("__ksonnet/params");
+local globals = import "globals.libsonnet";
 local envParams = params + {
-  components+: {
-    guestbook+: {
-      name: 'guestbook-dev',
+  components +: {
+    guestbook +: {
+      name: "guestbook-dev",
     },
   },
 };
 
 {
   components: {
-    [x]: envParams.components[x] + globals
-    for x in std.objectFields(envParams.components)
+    [x]: envParams.components[x] + globals, for x in std.objectFields(envParams.components)
   },
-}
\ No newline at end of file
+}
@@ -0,0 +1,15 @@
+local params = import "../../components/params.libsonnet";
+params + {
+  components +: {
+    guestbook +: {
+      name: "guestbook-dev",
+      replicas: 2,
+      containerPort: 8080,
+      image: 'nginx',
+      labels: {
+        tier: 'frontend',
+      },
+      tag: '1.0',
+    },
+  },
+}
\ No newline at end of file
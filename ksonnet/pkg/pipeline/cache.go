@@ -0,0 +1,419 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Version is the version of ksonnet. It is part of every cache key, so
+// objects cached by another version are never used.
+var Version = "dev"
+
+// cacheDisabled disables the object cache for every pipeline.
+var cacheDisabled bool
+
+// DisableCache disables the object cache for pipelines.
+func DisableCache() {
+	cacheDisabled = true
+}
+
+// NoCache disables the object cache for a pipeline.
+func NoCache() Opt {
+	return func(p *Pipeline) {
+		p.noCache = true
+	}
+}
+
+// cacheDir is the directory in the app where rendered objects are cached.
+var cacheDir = filepath.Join(".ksonnet", "cache", "objects")
+
+// defaultMaxCacheEntries is the number of entries kept in the object cache.
+const defaultMaxCacheEntries = 256
+
+// objectCache is a content-addressed cache of the objects rendered for
+// modules. An entry is keyed on a hash of everything which goes into
+// rendering a module: its source, its params, the environment, the files
+// components can import, the evaluation flags and the ksonnet version.
+// Modules which import files outside of the hashed directories are not
+// cached. When there are more than maxEntries entries, the least recently
+// used ones are removed.
+type objectCache struct {
+	fs         afero.Fs
+	dir        string
+	maxEntries int
+
+	// roots are the files and directories whose contents are hashed.
+	roots []string
+
+	once sync.Once
+	base []byte
+	err  error
+
+	baseFn func() ([]byte, error)
+}
+
+func newObjectCache(a app.App, envName string) *objectCache {
+	jpaths := env.JPaths()
+
+	c := &objectCache{
+		fs:         a.Fs(),
+		dir:        filepath.Join(a.Root(), cacheDir),
+		maxEntries: defaultMaxCacheEntries,
+		roots:      hashedRoots(a.Root(), jpaths),
+	}
+
+	c.baseFn = func() ([]byte, error) {
+		return baseHash(a, envName, jpaths)
+	}
+
+	return c
+}
+
+// covers reports whether the contents of a file are part of every cache key.
+// Packages re-vendored for an environment are copies of the app's vendor
+// directory, so they are covered too.
+func (c *objectCache) covers(path string) bool {
+	if env.IsRevendored(path) {
+		return true
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	for _, root := range c.roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// key returns the cache key for the inputs of a module. ok is false if the
+// inputs shared by every module could not be hashed.
+func (c *objectCache) key(parts ...string) (string, bool) {
+	c.once.Do(func() {
+		c.base, c.err = c.baseFn()
+		if c.err != nil {
+			log.WithError(c.err).Debug("object cache is disabled")
+		}
+	})
+
+	if c.err != nil {
+		return "", false
+	}
+
+	h := sha256.New()
+	h.Write(c.base)
+	for _, part := range parts {
+		writeField(h, part)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// get returns the objects cached for a key. The entry's modification time is
+// updated, so it is kept when the cache is pruned.
+func (c *objectCache) get(key string) ([]*unstructured.Unstructured, bool) {
+	data, err := afero.ReadFile(c.fs, c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	now := time.Now()
+	if err = c.fs.Chtimes(c.path(key), now, now); err != nil {
+		log.WithError(err).Debugf("touching object cache entry %s", key)
+	}
+
+	var items []json.RawMessage
+	if err = json.Unmarshal(data, &items); err != nil {
+		log.WithError(err).Debugf("ignoring invalid object cache entry %s", key)
+		return nil, false
+	}
+
+	objects := make([]*unstructured.Unstructured, 0, len(items))
+	for _, item := range items {
+		obj := &unstructured.Unstructured{}
+		if err = obj.UnmarshalJSON(item); err != nil {
+			log.WithError(err).Debugf("ignoring invalid object cache entry %s", key)
+			return nil, false
+		}
+
+		objects = append(objects, obj)
+	}
+
+	return objects, true
+}
+
+// put caches the objects for a key. The entry is written to a temporary file
+// and renamed, so concurrent readers never see a partial entry.
+func (c *objectCache) put(key string, objects []*unstructured.Unstructured) error {
+	items := make([]interface{}, 0, len(objects))
+	for _, obj := range objects {
+		items = append(items, obj.Object)
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return errors.Wrap(err, "encoding objects")
+	}
+
+	if err = c.fs.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	if err = c.ensureIgnored(); err != nil {
+		return err
+	}
+
+	f, err := afero.TempFile(c.fs, c.dir, key+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		c.fs.Remove(f.Name())
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		c.fs.Remove(f.Name())
+		return err
+	}
+
+	if err = c.fs.Rename(f.Name(), c.path(key)); err != nil {
+		return err
+	}
+
+	return c.prune()
+}
+
+// ensureIgnored writes a .gitignore to the cache directory, so cached objects
+// are not committed, including in apps created before the cache existed.
+func (c *objectCache) ensureIgnored() error {
+	path := filepath.Join(c.dir, ".gitignore")
+
+	exists, err := afero.Exists(c.fs, path)
+	if err != nil || exists {
+		return err
+	}
+
+	return afero.WriteFile(c.fs, path, []byte("*\n"), app.DefaultFilePermissions)
+}
+
+// prune removes the least recently used entries when there are more than
+// maxEntries.
+func (c *objectCache) prune() error {
+	fis, err := afero.ReadDir(c.fs, c.dir)
+	if err != nil {
+		return err
+	}
+
+	var entries []os.FileInfo
+	for _, fi := range fis {
+		if fi.Mode().IsRegular() && filepath.Ext(fi.Name()) == ".json" {
+			entries = append(entries, fi)
+		}
+	}
+
+	if len(entries) <= c.maxEntries {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().After(entries[j].ModTime())
+	})
+
+	for _, fi := range entries[c.maxEntries:] {
+		// entries can be removed by a concurrent prune.
+		if err := c.fs.Remove(filepath.Join(c.dir, fi.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *objectCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+var (
+	// cachedFiles are the app files, relative to the root, whose contents
+	// affect every module.
+	cachedFiles = []string{"app.yaml", "app.override.yaml"}
+	// cachedDirs are the app directories whose contents affect every module.
+	// components is included because components can import files from any
+	// module.
+	cachedDirs = []string{"components", "environments", "lib", "vendor"}
+)
+
+// hashedRoots returns the absolute paths of the files and directories hashed
+// by baseHash.
+func hashedRoots(root string, jpaths []string) []string {
+	var roots []string
+	for _, name := range cachedFiles {
+		roots = append(roots, filepath.Join(root, name))
+	}
+	for _, name := range cachedDirs {
+		roots = append(roots, filepath.Join(root, name))
+	}
+	roots = append(roots, jpaths...)
+
+	for i := range roots {
+		if abs, err := filepath.Abs(roots[i]); err == nil {
+			roots[i] = abs
+		}
+	}
+
+	return roots
+}
+
+// baseHash hashes the inputs shared by every module in an environment,
+// including the contents of the jpaths added for component evaluation.
+func baseHash(a app.App, envName string, jpaths []string) ([]byte, error) {
+	h := sha256.New()
+	writeField(h, Version)
+	writeField(h, envName)
+
+	envConfig, err := a.Environment(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(envConfig)
+	if err != nil {
+		return nil, err
+	}
+	writeField(h, string(data))
+
+	var buf bytes.Buffer
+	env.WriteVMConfig(&buf)
+	writeField(h, buf.String())
+
+	for _, name := range cachedFiles {
+		if err := hashFile(h, a.Fs(), a.Root(), filepath.Join(a.Root(), name)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range cachedDirs {
+		if err := hashTree(h, a.Fs(), a.Root(), filepath.Join(a.Root(), name)); err != nil {
+			return nil, err
+		}
+	}
+
+	// the jpaths are already part of the VM config, so only their contents
+	// are hashed here.
+	for _, dir := range jpaths {
+		if err := hashTree(h, a.Fs(), dir, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	return h.Sum(nil), nil
+}
+
+// hashTree hashes the paths and contents of the files in a directory. A
+// missing directory hashes to nothing.
+func hashTree(h hash.Hash, fs afero.Fs, root, dir string) error {
+	var paths []string
+	err := afero.Walk(fs, dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if fi.Mode().IsRegular() {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.Wrapf(err, "hashing %s", dir)
+	}
+
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := hashFile(h, fs, root, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hashFile hashes the path and contents of a file. A missing file hashes to
+// nothing.
+func hashFile(h hash.Hash, fs afero.Fs, root, path string) error {
+	f, err := fs.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return err
+	}
+
+	content := sha256.New()
+	if _, err = io.Copy(content, f); err != nil {
+		return errors.Wrapf(err, "hashing %s", rel)
+	}
+
+	writeField(h, filepath.ToSlash(rel))
+	h.Write(content.Sum(nil))
+
+	return nil
+}
+
+// writeField writes a length-prefixed field, so adjacent fields can't run
+// into each other.
+func writeField(w io.Writer, s string) {
+	fmt.Fprintf(w, "%d:%s", len(s), s)
+}
@@ -0,0 +1,202 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	appmocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_objectCache(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	a := &appmocks.App{}
+	a.On("Root").Return("/app")
+	a.On("Fs").Return(fs)
+	a.On("Environment", "default").Return(&app.EnvironmentConfig{Path: "default"}, nil)
+
+	c := newObjectCache(a, "default")
+
+	key, ok := c.key("module", "source")
+	require.True(t, ok)
+
+	_, hit := c.get(key)
+	require.False(t, hit)
+
+	objects := []*unstructured.Unstructured{
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "config",
+				},
+				"data": map[string]interface{}{
+					"replicas": "3",
+				},
+			},
+		},
+	}
+
+	require.NoError(t, c.put(key, objects))
+
+	got, hit := c.get(key)
+	require.True(t, hit)
+	require.Equal(t, objects, got)
+
+	exists, err := afero.Exists(fs, filepath.Join("/app", cacheDir, key+".json"))
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	other, ok := c.key("module", "other source")
+	require.True(t, ok)
+	assert.NotEqual(t, key, other)
+
+	// fields are length prefixed, so moving a boundary changes the key.
+	shifted, ok := c.key("modules", "ource")
+	require.True(t, ok)
+	assert.NotEqual(t, key, shifted)
+}
+
+func Test_objectCache_covers(t *testing.T) {
+	a := &appmocks.App{}
+	a.On("Root").Return("/app")
+	a.On("Fs").Return(afero.NewMemMapFs())
+
+	c := newObjectCache(a, "default")
+
+	cases := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "/app/app.yaml", expected: true},
+		{path: "/app/components/guestbook.jsonnet", expected: true},
+		{path: "/app/environments/default/main.jsonnet", expected: true},
+		{path: "/app/lib/v1.8.0/k.libsonnet", expected: true},
+		{path: "/app/vendor/incubator/redis/redis.libsonnet", expected: true},
+		{path: "/app/components/../../shared/x.libsonnet", expected: false},
+		{path: "/app/shared.libsonnet", expected: false},
+		{path: "/shared/x.libsonnet", expected: false},
+		{path: "/app/lib..x/k.libsonnet", expected: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.expected, c.covers(tc.path))
+		})
+	}
+}
+
+func Test_objectCache_invalidEntry(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	a := &appmocks.App{}
+	a.On("Root").Return("/app")
+	a.On("Fs").Return(fs)
+
+	c := newObjectCache(a, "default")
+	require.NoError(t, afero.WriteFile(fs, c.path("key"), []byte("{"), 0644))
+
+	_, hit := c.get("key")
+	require.False(t, hit)
+}
+
+func Test_baseHash(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	a := &appmocks.App{}
+	a.On("Root").Return("/app")
+	a.On("Fs").Return(fs)
+	a.On("Environment", "default").Return(&app.EnvironmentConfig{Path: "default"}, nil)
+
+	require.NoError(t, afero.WriteFile(fs, "/app/app.yaml", []byte("kind: ksonnet.io/app"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/app/lib/lib.libsonnet", []byte("{}"), 0644))
+
+	var jpaths []string
+	hash := func() []byte {
+		h, err := baseHash(a, "default", jpaths)
+		require.NoError(t, err)
+		return h
+	}
+
+	original := hash()
+	require.Equal(t, original, hash())
+
+	require.NoError(t, afero.WriteFile(fs, "/app/lib/lib.libsonnet", []byte("{a: 1}"), 0644))
+	libChanged := hash()
+	assert.NotEqual(t, original, libChanged)
+
+	require.NoError(t, afero.WriteFile(fs, "/app/vendor/pkg/pkg.libsonnet", []byte("{}"), 0644))
+	assert.NotEqual(t, libChanged, hash())
+
+	// components can import files from any module.
+	vendored := hash()
+	require.NoError(t, afero.WriteFile(fs, "/app/components/nested/lib.libsonnet", []byte("{}"), 0644))
+	componentsChanged := hash()
+	assert.NotEqual(t, vendored, componentsChanged)
+
+	require.NoError(t, afero.WriteFile(fs, "/jpath/lib.libsonnet", []byte("{}"), 0644))
+	jpaths = []string{"/jpath"}
+	jpathAdded := hash()
+	assert.NotEqual(t, componentsChanged, jpathAdded)
+
+	require.NoError(t, afero.WriteFile(fs, "/jpath/lib.libsonnet", []byte("{a: 1}"), 0644))
+	jpathChanged := hash()
+	assert.NotEqual(t, jpathAdded, jpathChanged)
+
+	version := Version
+	defer func() { Version = version }()
+	Version = "other"
+	assert.NotEqual(t, jpathChanged, hash())
+}
+
+func Test_objectCache_prune(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	a := &appmocks.App{}
+	a.On("Root").Return("/app")
+	a.On("Fs").Return(fs)
+
+	c := newObjectCache(a, "default")
+
+	now := time.Now()
+	for i, key := range []string{"a", "b", "c"} {
+		require.NoError(t, c.put(key, nil))
+		mtime := now.Add(time.Duration(i-3) * time.Hour)
+		require.NoError(t, fs.Chtimes(c.path(key), mtime, mtime))
+	}
+
+	// reading an entry marks it as recently used.
+	_, hit := c.get("a")
+	require.True(t, hit)
+
+	c.maxEntries = 3
+	require.NoError(t, c.put("d", nil))
+
+	for key, expected := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		exists, err := afero.Exists(fs, c.path(key))
+		require.NoError(t, err)
+		assert.Equal(t, expected, exists, "entry %s", key)
+	}
+
+	ignore, err := afero.ReadFile(fs, filepath.Join("/app", cacheDir, ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "*\n", string(ignore))
+}
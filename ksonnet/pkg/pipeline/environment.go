@@ -0,0 +1,174 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/k8s"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// selectorPaths are the paths of the label selectors which select pods.
+	// Common labels are added to selectors which exist, so objects only
+	// select pods in their own environment.
+	selectorPaths = map[string][]string{
+		"Deployment":            {"spec", "selector", "matchLabels"},
+		"StatefulSet":           {"spec", "selector", "matchLabels"},
+		"DaemonSet":             {"spec", "selector", "matchLabels"},
+		"ReplicaSet":            {"spec", "selector", "matchLabels"},
+		"PodDisruptionBudget":   {"spec", "selector", "matchLabels"},
+		"ReplicationController": {"spec", "selector"},
+		"Service":               {"spec", "selector"},
+	}
+
+	// unrenamedKinds are kinds whose names are meaningful to the cluster, so
+	// name prefixes and suffixes aren't applied to them.
+	unrenamedKinds = map[string]bool{
+		"APIService":               true,
+		"CustomResourceDefinition": true,
+		"Namespace":                true,
+	}
+)
+
+// applyEnvironment applies an environment's common labels, common
+// annotations, name prefix and suffix, and namespace override to objects.
+//
+// Labels and annotations are added to objects and their pod templates.
+// Labels are also added to existing pod selectors. Renamed objects have
+// references to them from other rendered objects updated, e.g. the
+// ServiceAccount subjects of RoleBindings or the ConfigMap volumes of pods.
+// The namespace override only applies to namespaced kinds.
+func applyEnvironment(objects []*unstructured.Unstructured, e *app.EnvironmentConfig) {
+	if e == nil {
+		return
+	}
+
+	r := newRenamer(objects, e.NamePrefix, e.NameSuffix)
+
+	for _, obj := range objects {
+		kind := obj.GetKind()
+
+		if len(e.CommonLabels) > 0 {
+			obj.SetLabels(mergeStrings(obj.GetLabels(), e.CommonLabels))
+			if template := podTemplate(obj); template != nil {
+				mergeNestedStrings(template, e.CommonLabels, "metadata", "labels")
+			}
+			if path, ok := selectorPaths[kind]; ok {
+				if selector := nestedMap(obj.Object, path...); selector != nil {
+					mergeNestedStrings(selector, e.CommonLabels)
+				}
+			}
+		}
+
+		if len(e.CommonAnnotations) > 0 {
+			obj.SetAnnotations(mergeStrings(obj.GetAnnotations(), e.CommonAnnotations))
+			if template := podTemplate(obj); template != nil {
+				mergeNestedStrings(template, e.CommonAnnotations, "metadata", "annotations")
+			}
+		}
+
+		if e.NamespaceOverride != "" {
+			if !k8s.IsClusterScoped(kind) {
+				obj.SetNamespace(e.NamespaceOverride)
+			}
+			setSubjectNamespaces(obj, r, e.NamespaceOverride)
+		}
+
+		if r.enabled() {
+			r.renameReferences(obj)
+		}
+	}
+
+	if !r.enabled() {
+		return
+	}
+
+	for _, obj := range objects {
+		if unrenamedKinds[obj.GetKind()] {
+			continue
+		}
+
+		if name := obj.GetName(); name != "" {
+			obj.SetName(r.prefix + name + r.suffix)
+		} else if generateName := obj.GetGenerateName(); generateName != "" {
+			obj.SetGenerateName(r.prefix + generateName)
+		}
+	}
+}
+
+// podTemplate returns the pod template of a workload, or nil if the object
+// doesn't have one.
+func podTemplate(obj *unstructured.Unstructured) map[string]interface{} {
+	path, ok := podSpecPaths[obj.GetKind()]
+	if !ok || len(path) < 2 {
+		return nil
+	}
+
+	return nestedMap(obj.Object, path[:len(path)-1]...)
+}
+
+// setSubjectNamespaces moves the ServiceAccount subjects of role bindings
+// which refer to rendered service accounts to the overridden namespace.
+func setSubjectNamespaces(obj *unstructured.Unstructured, r *renamer, namespace string) {
+	switch obj.GetKind() {
+	case "RoleBinding", "ClusterRoleBinding":
+	default:
+		return
+	}
+
+	subjects, _ := obj.Object["subjects"].([]interface{})
+	for _, item := range subjects {
+		subject, ok := item.(map[string]interface{})
+		if !ok || subject["kind"] != "ServiceAccount" {
+			continue
+		}
+
+		name, _ := subject["name"].(string)
+		if r.has("ServiceAccount", name) {
+			subject["namespace"] = namespace
+		}
+	}
+}
+
+func mergeStrings(m, values map[string]string) map[string]string {
+	if m == nil {
+		m = make(map[string]string, len(values))
+	}
+
+	for k, v := range values {
+		m[k] = v
+	}
+
+	return m
+}
+
+// mergeNestedStrings merges values into the map at a path, creating maps
+// which don't exist.
+func mergeNestedStrings(m map[string]interface{}, values map[string]string, fields ...string) {
+	for _, field := range fields {
+		next, ok := m[field].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[field] = next
+		}
+		m = next
+	}
+
+	for k, v := range values {
+		m[k] = v
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_applyEnvironment(t *testing.T) {
+	cases := []struct {
+		name     string
+		env      *app.EnvironmentConfig
+		expected string
+	}{
+		{
+			name:     "no environment",
+			expected: "objects.yaml",
+		},
+		{
+			name:     "no changes",
+			env:      &app.EnvironmentConfig{Name: "dev"},
+			expected: "objects.yaml",
+		},
+		{
+			name: "labels and annotations",
+			env: &app.EnvironmentConfig{
+				Name:              "dev",
+				CommonLabels:      map[string]string{"env": "dev"},
+				CommonAnnotations: map[string]string{"owner": "web"},
+			},
+			expected: "labels.yaml",
+		},
+		{
+			name: "name prefix and suffix",
+			env: &app.EnvironmentConfig{
+				Name:       "dev",
+				NamePrefix: "dev-",
+				NameSuffix: "-v1",
+			},
+			expected: "rename.yaml",
+		},
+		{
+			name: "namespace override",
+			env: &app.EnvironmentConfig{
+				Name:              "dev",
+				NamespaceOverride: "dev",
+			},
+			expected: "namespace.yaml",
+		},
+		{
+			name: "all",
+			env: &app.EnvironmentConfig{
+				Name:              "dev",
+				CommonLabels:      map[string]string{"env": "dev"},
+				CommonAnnotations: map[string]string{"owner": "web"},
+				NamePrefix:        "dev-",
+				NameSuffix:        "-v1",
+				NamespaceOverride: "dev",
+			},
+			expected: "all.yaml",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := readObjects(t, "objects.yaml")
+
+			applyEnvironment(objects, tc.env)
+
+			require.Equal(t, readObjects(t, tc.expected), objects)
+		})
+	}
+}
+
+func readObjects(t *testing.T, name string) []*unstructured.Unstructured {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "environment", name))
+	require.NoError(t, err)
+
+	var objects []*unstructured.Unstructured
+	for _, doc := range strings.Split(string(data), "\n---\n") {
+		var m map[string]interface{}
+		require.NoError(t, yaml.Unmarshal([]byte(doc), &m))
+		objects = append(objects, &unstructured.Unstructured{Object: m})
+	}
+
+	return objects
+}
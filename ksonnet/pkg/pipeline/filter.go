@@ -0,0 +1,146 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"path"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ObjectFilter selects objects by component, kind, name and labels. Objects
+// have to match every criterion which is set, so an empty filter selects
+// every object. Components are matched using the component label, so a
+// filter can select objects in a cluster as well as rendered objects.
+type ObjectFilter struct {
+	// Components are the components whose objects are selected.
+	Components []string
+	// ExcludeComponents are the components whose objects are not selected.
+	ExcludeComponents []string
+	// Kinds are the kinds of selected objects. They are matched without
+	// regard to case.
+	Kinds []string
+	// Names are the names of selected objects. Names can be shell patterns,
+	// e.g. web-*.
+	Names []string
+	// Selector is a label selector, e.g. tier=frontend,env!=dev.
+	Selector string
+}
+
+// ObjectMatcher reports whether an object with a kind, name and labels is
+// selected by a filter.
+type ObjectMatcher func(kind, name string, objectLabels map[string]string) bool
+
+// IsEmpty returns true if the filter selects every object.
+func (f ObjectFilter) IsEmpty() bool {
+	return len(f.Components) == 0 &&
+		len(f.ExcludeComponents) == 0 &&
+		len(f.Kinds) == 0 &&
+		len(f.Names) == 0 &&
+		f.Selector == ""
+}
+
+// Matcher validates the filter and returns an ObjectMatcher for it.
+func (f ObjectFilter) Matcher() (ObjectMatcher, error) {
+	selector, err := labels.Parse(f.Selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse selector %q", f.Selector)
+	}
+
+	for _, pattern := range f.Names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "parse name %q", pattern)
+		}
+	}
+
+	components := componentLabelSet(f.Components)
+	excluded := componentLabelSet(f.ExcludeComponents)
+
+	return func(kind, name string, objectLabels map[string]string) bool {
+		component := objectLabels[metadata.LabelComponent]
+		if len(components) > 0 && !components[component] {
+			return false
+		}
+		if excluded[component] {
+			return false
+		}
+
+		if len(f.Kinds) > 0 && !matchesKind(f.Kinds, kind) {
+			return false
+		}
+
+		if len(f.Names) > 0 && !matchesName(f.Names, name) {
+			return false
+		}
+
+		return selector.Matches(labels.Set(objectLabels))
+	}, nil
+}
+
+// Filter returns the objects selected by the filter.
+func (f ObjectFilter) Filter(objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	if f.IsEmpty() {
+		return objects, nil
+	}
+
+	match, err := f.Matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*unstructured.Unstructured
+	for _, obj := range objects {
+		if match(obj.GetKind(), obj.GetName(), obj.GetLabels()) {
+			ret = append(ret, obj)
+		}
+	}
+
+	return ret, nil
+}
+
+// componentLabelSet converts component names to component label values.
+func componentLabelSet(componentNames []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range componentNames {
+		set[componentLabel(name)] = true
+	}
+
+	return set
+}
+
+func matchesKind(kinds []string, kind string) bool {
+	for _, k := range kinds {
+		if strings.EqualFold(k, kind) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesName(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
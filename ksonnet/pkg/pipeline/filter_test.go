@@ -0,0 +1,127 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjectFilter_Filter(t *testing.T) {
+	object := func(component, kind, name string, labels map[string]string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		u.SetKind(kind)
+		u.SetName(name)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[metadata.LabelComponent] = component
+		u.SetLabels(labels)
+		return u
+	}
+
+	objects := []*unstructured.Unstructured{
+		object("guestbook", "Deployment", "guestbook-ui", map[string]string{"tier": "frontend"}),
+		object("guestbook", "Service", "guestbook-ui", map[string]string{"tier": "frontend"}),
+		object("redis", "Deployment", "redis", map[string]string{"tier": "backend"}),
+		object("redis", "ConfigMap", "redis-config", nil),
+		object("nested.db", "StatefulSet", "db", map[string]string{"tier": "backend"}),
+	}
+
+	cases := []struct {
+		name     string
+		filter   ObjectFilter
+		expected []int
+		isErr    bool
+	}{
+		{
+			name:     "empty",
+			expected: []int{0, 1, 2, 3, 4},
+		},
+		{
+			name:     "components",
+			filter:   ObjectFilter{Components: []string{"redis", "nested/db"}},
+			expected: []int{2, 3, 4},
+		},
+		{
+			name:     "excluded components",
+			filter:   ObjectFilter{ExcludeComponents: []string{"guestbook"}},
+			expected: []int{2, 3, 4},
+		},
+		{
+			name:     "kinds",
+			filter:   ObjectFilter{Kinds: []string{"deployment", "ConfigMap"}},
+			expected: []int{0, 2, 3},
+		},
+		{
+			name:     "names",
+			filter:   ObjectFilter{Names: []string{"redis*", "db"}},
+			expected: []int{2, 3, 4},
+		},
+		{
+			name:     "selector",
+			filter:   ObjectFilter{Selector: "tier=frontend"},
+			expected: []int{0, 1},
+		},
+		{
+			name:     "selector without label",
+			filter:   ObjectFilter{Selector: "tier!=frontend"},
+			expected: []int{2, 3, 4},
+		},
+		{
+			name: "all criteria",
+			filter: ObjectFilter{
+				Components:        []string{"guestbook", "redis"},
+				ExcludeComponents: []string{"redis"},
+				Kinds:             []string{"Service"},
+				Names:             []string{"guestbook-*"},
+				Selector:          "tier in (frontend)",
+			},
+			expected: []int{1},
+		},
+		{
+			name:   "invalid selector",
+			filter: ObjectFilter{Selector: "tier in frontend"},
+			isErr:  true,
+		},
+		{
+			name:   "invalid name",
+			filter: ObjectFilter{Names: []string{"[redis"}},
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.filter.Filter(objects)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var expected []*unstructured.Unstructured
+			for _, i := range tc.expected {
+				expected = append(expected, objects[i])
+			}
+
+			require.Equal(t, expected, got)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"encoding/json"
+	gostrings "strings"
+
+	clustermetadata "github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/util/dockerregistry"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// podSpecPaths are the paths of the pod specs in the kinds whose images
+	// are resolved.
+	podSpecPaths = map[string][]string{
+		"Pod":         {"spec"},
+		"Deployment":  {"spec", "template", "spec"},
+		"StatefulSet": {"spec", "template", "spec"},
+		"DaemonSet":   {"spec", "template", "spec"},
+		"ReplicaSet":  {"spec", "template", "spec"},
+		"Job":         {"spec", "template", "spec"},
+		"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+	}
+)
+
+// ResolveImages is an option which pins the container images of the
+// rendered objects to digests using a resolver. Share a resolver between
+// pipelines to share its cache.
+func ResolveImages(r dockerregistry.ResolverClient) Opt {
+	return func(p *Pipeline) {
+		p.imageResolver = r
+	}
+}
+
+// PinImages rewrites the container images in pod templates to digest
+// references. The original references are recorded in the
+// AnnotationOriginalImages annotation of each object which changed.
+func PinImages(objects []*unstructured.Unstructured, r dockerregistry.ResolverClient) error {
+	for _, obj := range objects {
+		path, ok := podSpecPaths[obj.GetKind()]
+		if !ok {
+			continue
+		}
+
+		podSpec := nestedMap(obj.Object, path...)
+		if podSpec == nil {
+			continue
+		}
+
+		originals := make(map[string]string)
+		for _, field := range []string{"initContainers", "containers"} {
+			list, _ := podSpec[field].([]interface{})
+			for _, item := range list {
+				c, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				image, _ := c["image"].(string)
+				if image == "" || gostrings.Contains(image, "@") {
+					continue
+				}
+
+				resolved, err := r.ManifestV2Digest(image)
+				if err != nil {
+					return errors.Wrapf(err, "resolving image %q in %s %s", image, obj.GetKind(), obj.GetName())
+				}
+
+				if resolved == image {
+					continue
+				}
+
+				name, _ := c["name"].(string)
+				originals[name] = image
+				c["image"] = resolved
+			}
+		}
+
+		if len(originals) == 0 {
+			continue
+		}
+
+		data, err := json.Marshal(originals)
+		if err != nil {
+			return err
+		}
+
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[clustermetadata.AnnotationOriginalImages] = string(data)
+		obj.SetAnnotations(annotations)
+	}
+
+	return nil
+}
+
+// nestedMap returns the map at a path without copying it, or nil if there
+// isn't one.
+func nestedMap(m map[string]interface{}, fields ...string) map[string]interface{} {
+	for _, field := range fields {
+		var ok bool
+		if m, ok = m[field].(map[string]interface{}); !ok {
+			return nil
+		}
+	}
+
+	return m
+}
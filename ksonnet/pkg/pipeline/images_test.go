@@ -0,0 +1,161 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	appmocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type fakeImageResolver map[string]string
+
+func (r fakeImageResolver) ManifestV2Digest(image string) (string, error) {
+	resolved, ok := r[image]
+	if !ok {
+		return "", errors.Errorf("image %q was not found", image)
+	}
+
+	return resolved, nil
+}
+
+func podObject(kind string, containers ...interface{}) *unstructured.Unstructured {
+	podSpec := map[string]interface{}{"containers": containers}
+
+	spec := map[string]interface{}{
+		"template": map[string]interface{}{"spec": podSpec},
+	}
+
+	switch kind {
+	case "Pod":
+		spec = podSpec
+	case "CronJob":
+		spec = map[string]interface{}{
+			"jobTemplate": map[string]interface{}{"spec": spec},
+		}
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": "app"},
+			"spec":       spec,
+		},
+	}
+}
+
+func container(name, image string) interface{} {
+	return map[string]interface{}{"name": name, "image": image}
+}
+
+func TestPinImages(t *testing.T) {
+	resolver := fakeImageResolver{
+		"nginx:1.15":  "nginx@sha256:1",
+		"redis":       "redis@sha256:2",
+		"pinned:1.0":  "pinned:1.0",
+		"busybox:1.2": "busybox@sha256:3",
+	}
+
+	cases := []struct {
+		name      string
+		object    *unstructured.Unstructured
+		expected  *unstructured.Unstructured
+		originals string
+		isErr     bool
+	}{
+		{
+			name:      "deployment",
+			object:    podObject("Deployment", container("web", "nginx:1.15"), container("cache", "redis")),
+			expected:  podObject("Deployment", container("web", "nginx@sha256:1"), container("cache", "redis@sha256:2")),
+			originals: `{"cache":"redis","web":"nginx:1.15"}`,
+		},
+		{
+			name:      "pod",
+			object:    podObject("Pod", container("shell", "busybox:1.2")),
+			expected:  podObject("Pod", container("shell", "busybox@sha256:3")),
+			originals: `{"shell":"busybox:1.2"}`,
+		},
+		{
+			name:      "cron job",
+			object:    podObject("CronJob", container("shell", "busybox:1.2")),
+			expected:  podObject("CronJob", container("shell", "busybox@sha256:3")),
+			originals: `{"shell":"busybox:1.2"}`,
+		},
+		{
+			name:     "already pinned",
+			object:   podObject("StatefulSet", container("web", "nginx@sha256:1"), container("app", "pinned:1.0")),
+			expected: podObject("StatefulSet", container("web", "nginx@sha256:1"), container("app", "pinned:1.0")),
+		},
+		{
+			name:     "kind without pods",
+			object:   podObject("Service", container("web", "unknown:1.0")),
+			expected: podObject("Service", container("web", "unknown:1.0")),
+		},
+		{
+			name:   "unresolvable image",
+			object: podObject("DaemonSet", container("web", "unknown:1.0")),
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := PinImages([]*unstructured.Unstructured{tc.object}, resolver)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tc.originals != "" {
+				tc.expected.SetAnnotations(map[string]string{
+					metadata.AnnotationOriginalImages: tc.originals,
+				})
+			}
+
+			require.Equal(t, tc.expected, tc.object)
+		})
+	}
+}
+
+func TestPipeline_Objects_resolveImages(t *testing.T) {
+	resolver := fakeImageResolver{"nginx:1.15": "nginx@sha256:1"}
+
+	a := &appmocks.App{}
+	a.On("Environment", "default").Return(&app.EnvironmentConfig{Path: "default"}, nil)
+
+	p := New(a, "default", ResolveImages(resolver))
+	p.buildObjectsFn = func(*Pipeline, []string) ([]*unstructured.Unstructured, error) {
+		return []*unstructured.Unstructured{podObject("Deployment", container("web", "nginx:1.15"))}, nil
+	}
+
+	got, err := p.Objects(nil)
+	require.NoError(t, err)
+
+	expected := podObject("Deployment", container("web", "nginx@sha256:1"))
+	expected.SetAnnotations(map[string]string{
+		metadata.AnnotationOriginalImages: `{"web":"nginx:1.15"}`,
+	})
+
+	require.Equal(t, []*unstructured.Unstructured{expected}, got)
+}
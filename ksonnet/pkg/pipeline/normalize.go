@@ -0,0 +1,158 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// normalizeComponent converts the value a component evaluates to into the
+// objects it describes. This is the only place component shapes are
+// interpreted. A component may evaluate to:
+//
+//   - an object with a kind, which is returned as is.
+//   - a list, i.e. an object whose kind ends in "List" (v1/List,
+//     ServiceList, ...) which is a v1 object or has items. Its items must
+//     be an array, and are normalized in order.
+//   - an array, possibly nested. Its elements are normalized in order.
+//   - an object without a kind whose values are normalized in key order.
+//   - null, which produces no objects. Nulls nested in any of the above
+//     are dropped as well.
+//
+// The resulting order depends only on the component's value, so rendering
+// the same component twice produces the same objects in the same order.
+// Values that can't be converted result in an error naming the component and
+// the location of the value.
+func normalizeComponent(componentName string, v interface{}) ([]map[string]interface{}, error) {
+	var objects []map[string]interface{}
+	if err := normalizeValue(v, "", &objects); err != nil {
+		return nil, errors.Wrapf(err, "component %q", componentName)
+	}
+
+	return objects, nil
+}
+
+func normalizeValue(v interface{}, path string, objects *[]map[string]interface{}) error {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for i, item := range t {
+			if err := normalizeValue(item, fmt.Sprintf("%s[%d]", path, i), objects); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		kind, ok := t["kind"]
+		if !ok {
+			if _, ok := t["apiVersion"]; ok {
+				return errors.Errorf("%s has an apiVersion but no kind", describePath(path))
+			}
+			return normalizeObjects(t, path, objects)
+		}
+
+		kindName, ok := kind.(string)
+		if !ok {
+			return errors.Errorf("%s has a kind of type %s, expected a string", describePath(path), jsonType(kind))
+		}
+
+		if !isList(t, kindName) {
+			*objects = append(*objects, t)
+			return nil
+		}
+
+		items, ok := t["items"]
+		if !ok {
+			return errors.Errorf("%s is a %s without items", describePath(path), kindName)
+		}
+
+		list, ok := items.([]interface{})
+		if !ok {
+			return errors.Errorf("%s is a %s with items of type %s, expected an array", describePath(path), kindName, jsonType(items))
+		}
+
+		return normalizeValue(list, path+".items", objects)
+	default:
+		return errors.Errorf("%s is a %s, expected an object, an array or null", describePath(path), jsonType(v))
+	}
+}
+
+// isList returns true if an object is a list. A kind ending in "List" is not
+// enough, since custom resources can have kinds like that, so the object
+// must also be a v1 object or have items.
+func isList(m map[string]interface{}, kind string) bool {
+	if !strings.HasSuffix(kind, "List") {
+		return false
+	}
+
+	if m["apiVersion"] == "v1" {
+		return true
+	}
+
+	_, ok := m["items"]
+	return ok
+}
+
+// normalizeObjects normalizes the values of an object without a kind in key
+// order.
+func normalizeObjects(m map[string]interface{}, path string, objects *[]map[string]interface{}) error {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := normalizeValue(m[k], path+"."+k, objects); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return "value"
+	}
+
+	return "value at " + strings.TrimPrefix(path, ".")
+}
+
+// jsonType names the JSON type of a decoded value.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, int64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_normalizeComponent(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    string
+		expected []string
+		isErr    string
+	}{
+		{
+			name:     "object",
+			value:    `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "a"}}`,
+			expected: []string{"Service/a"},
+		},
+		{
+			name:  "null",
+			value: `null`,
+		},
+		{
+			name: "v1 list",
+			value: `{"apiVersion": "v1", "kind": "List", "items": [
+				{"kind": "Service", "metadata": {"name": "a"}},
+				{"kind": "Deployment", "metadata": {"name": "b"}}
+			]}`,
+			expected: []string{"Service/a", "Deployment/b"},
+		},
+		{
+			name: "typed list",
+			value: `{"apiVersion": "v1", "kind": "ServiceList", "items": [
+				{"kind": "Service", "metadata": {"name": "a"}},
+				{"kind": "Service", "metadata": {"name": "b"}}
+			]}`,
+			expected: []string{"Service/a", "Service/b"},
+		},
+		{
+			name:  "list with empty items",
+			value: `{"apiVersion": "v1", "kind": "List", "items": []}`,
+		},
+		{
+			name:     "custom resource with a list kind",
+			value:    `{"apiVersion": "example.com/v1", "kind": "PlayList", "metadata": {"name": "a"}}`,
+			expected: []string{"PlayList/a"},
+		},
+		{
+			name: "nested lists",
+			value: `{"kind": "List", "items": [
+				{"kind": "List", "items": [{"kind": "Service", "metadata": {"name": "a"}}]},
+				{"kind": "Service", "metadata": {"name": "b"}}
+			]}`,
+			expected: []string{"Service/a", "Service/b"},
+		},
+		{
+			name: "array",
+			value: `[
+				{"kind": "Service", "metadata": {"name": "a"}},
+				null,
+				{"kind": "Deployment", "metadata": {"name": "b"}}
+			]`,
+			expected: []string{"Service/a", "Deployment/b"},
+		},
+		{
+			name: "nested arrays",
+			value: `[
+				[{"kind": "Service", "metadata": {"name": "a"}}, []],
+				[[{"kind": "Service", "metadata": {"name": "b"}}]]
+			]`,
+			expected: []string{"Service/a", "Service/b"},
+		},
+		{
+			name: "object of objects",
+			value: `{
+				"service": {"kind": "Service", "metadata": {"name": "c"}},
+				"deployment": {"kind": "Deployment", "metadata": {"name": "d"}},
+				"extra": {},
+				"disabled": null
+			}`,
+			expected: []string{"Deployment/d", "Service/c"},
+		},
+		{
+			name: "mixed",
+			value: `{
+				"b": [{"kind": "List", "items": [{"kind": "Service", "metadata": {"name": "b"}}]}],
+				"a": {"nested": {"kind": "Service", "metadata": {"name": "a"}}}
+			}`,
+			expected: []string{"Service/a", "Service/b"},
+		},
+		{
+			name:  "scalar",
+			value: `"service"`,
+			isErr: `component "c": value is a string, expected an object, an array or null`,
+		},
+		{
+			name:  "scalar in array",
+			value: `[{"kind": "List", "items": [{"kind": "Service", "metadata": {"name": "a"}}, 1]}]`,
+			isErr: `component "c": value at [0].items[1] is a number, expected an object, an array or null`,
+		},
+		{
+			name:  "scalar in object",
+			value: `{"service": {"name": true}}`,
+			isErr: `component "c": value at service.name is a boolean, expected an object, an array or null`,
+		},
+		{
+			name:  "missing kind",
+			value: `[{"apiVersion": "v1", "metadata": {"name": "a"}}]`,
+			isErr: `component "c": value at [0] has an apiVersion but no kind`,
+		},
+		{
+			name:  "invalid kind",
+			value: `{"kind": 1}`,
+			isErr: `component "c": value has a kind of type number, expected a string`,
+		},
+		{
+			name:  "list without items",
+			value: `{"apiVersion": "v1", "kind": "List"}`,
+			isErr: `component "c": value is a List without items`,
+		},
+		{
+			name:  "list with null items",
+			value: `{"kind": "ServiceList", "items": null}`,
+			isErr: `component "c": value is a ServiceList with items of type null, expected an array`,
+		},
+		{
+			name:  "invalid items",
+			value: `{"kind": "List", "items": {}}`,
+			isErr: `component "c": value is a List with items of type object, expected an array`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var v interface{}
+			require.NoError(t, json.Unmarshal([]byte(tc.value), &v))
+
+			objects, err := normalizeComponent("c", v)
+			if tc.isErr != "" {
+				require.EqualError(t, err, tc.isErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for _, object := range objects {
+				metadata := object["metadata"].(map[string]interface{})
+				got = append(got, object["kind"].(string)+"/"+metadata["name"].(string))
+			}
+
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
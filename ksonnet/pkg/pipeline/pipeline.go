@@ -21,7 +21,11 @@ import (
 	"io"
 	"path/filepath"
 	"regexp"
+	goruntime "runtime"
+	"sort"
 	gostrings "strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -32,12 +36,11 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/env"
 	clustermetadata "github.com/ksonnet/ksonnet/pkg/metadata"
 	"github.com/ksonnet/ksonnet/pkg/params"
+	"github.com/ksonnet/ksonnet/pkg/util/dockerregistry"
 	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
-	"github.com/ksonnet/ksonnet/pkg/util/k8s"
 	"github.com/ksonnet/ksonnet/pkg/util/strings"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // OverrideManager overrides the component manager interface for a pipeline.
@@ -47,6 +50,22 @@ func OverrideManager(c component.Manager) Opt {
 	}
 }
 
+// Concurrency sets the number of modules a pipeline renders at once.
+func Concurrency(n int) Opt {
+	return func(p *Pipeline) {
+		p.concurrency = n
+	}
+}
+
+// OverrideParams overrides component params after they are resolved for the
+// pipeline's environment. It is keyed by component name and then by param.
+// Components in nested modules are named <module>.<component>.
+func OverrideParams(overrides map[string]map[string]interface{}) Opt {
+	return func(p *Pipeline) {
+		p.paramOverrides = overrides
+	}
+}
+
 // Opt is an option for configuring Pipeline.
 type Opt func(p *Pipeline)
 
@@ -58,7 +77,14 @@ type Pipeline struct {
 	buildObjectsFn      func(*Pipeline, []string) ([]*unstructured.Unstructured, error)
 	evaluateEnvFn       func(a app.App, envName, components, paramsStr string, opts ...jsonnet.VMOpt) (string, error)
 	evaluateEnvParamsFn func(a app.App, sourcePath, paramsStr, envName, moduleName string) (string, error)
+	loadParamsSchemaFn  func(a app.App, moduleName string) (*component.ParamsSchema, error)
 	stubModuleFn        func(m component.Module) (string, error)
+	imageResolver       dockerregistry.ResolverClient
+	concurrency         int
+	paramOverrides      map[string]map[string]interface{}
+	noCache             bool
+	cache               *objectCache
+	cacheOnce           sync.Once
 }
 
 // New creates an instance of Pipeline.
@@ -71,7 +97,9 @@ func New(ksApp app.App, envName string, opts ...Opt) *Pipeline {
 		buildObjectsFn:      buildObjects,
 		evaluateEnvFn:       env.Evaluate,
 		evaluateEnvParamsFn: params.EvaluateEnv,
+		loadParamsSchemaFn:  component.LoadParamsSchema,
 		stubModuleFn:        stubModule,
+		concurrency:         goruntime.NumCPU(),
 	}
 
 	for _, opt := range opts {
@@ -179,12 +207,33 @@ func (p *Pipeline) Components(filter []string) ([]component.Component, error) {
 	return components, nil
 }
 
-// Objects converts components into Kubernetes objects.
+// Objects converts components into Kubernetes objects. The environment's
+// common labels, annotations, name prefix and suffix, and namespace override
+// are applied to them. If the pipeline resolves images, the objects' images
+// are pinned to digests.
 func (p *Pipeline) Objects(filter []string) ([]*unstructured.Unstructured, error) {
-	return p.buildObjectsFn(p, filter)
+	objects, err := p.buildObjectsFn(p, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := p.app.Environment(p.envName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "load environment %s", p.envName)
+	}
+	applyEnvironment(objects, e)
+
+	if p.imageResolver != nil {
+		if err = PinImages(objects, p.imageResolver); err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
 }
 
 func (p *Pipeline) moduleObjects(module component.Module, filter []string) ([]*unstructured.Unstructured, error) {
+	start := time.Now()
 	doc := &astext.Object{}
 
 	object, componentMap, err := module.Render(p.envName, filter...)
@@ -195,28 +244,114 @@ func (p *Pipeline) moduleObjects(module component.Module, filter []string) ([]*u
 	doc.Fields = append(doc.Fields, object.Fields...)
 
 	// apply environment parameters
-	moduleParamData, err := module.ResolvedParams(p.envName)
+	envParamData, err := p.moduleEnvParams(module)
 	if err != nil {
 		return nil, err
 	}
 
-	envParamsPath, err := env.Path(p.app, p.envName, "params.libsonnet")
-	if err != nil {
+	var buf bytes.Buffer
+	if err = printer.Fprint(&buf, doc); err != nil {
 		return nil, err
 	}
 
-	envParamData, err := p.evaluateEnvParamsFn(p.app, envParamsPath, moduleParamData, p.envName, module.Name())
+	evaluate := func(opts ...jsonnet.VMOpt) ([]*unstructured.Unstructured, error) {
+		if !collectingStats() {
+			return p.evaluateModule(buf.String(), envParamData, componentMap, filter, opts...)
+		}
+
+		timer := newComponentTimer()
+		instrumented, err := timer.instrument(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		var source bytes.Buffer
+		if err = printer.Fprint(&source, instrumented); err != nil {
+			return nil, err
+		}
+
+		objects, err := p.evaluateModule(source.String(), envParamData, componentMap, filter, append(opts, timer.vmOpt())...)
+		if err != nil {
+			return nil, err
+		}
+
+		recordModuleStats(ModuleStats{
+			Name:       module.Name(),
+			Elapsed:    time.Since(start),
+			Components: timer.components(time.Now()),
+		})
+
+		return objects, nil
+	}
+
+	cache := p.objectCache()
+	if cache == nil {
+		return evaluate()
+	}
+
+	componentTypes, err := json.Marshal(componentMap)
 	if err != nil {
 		return nil, err
 	}
 
-	var buf bytes.Buffer
-	if err = printer.Fprint(&buf, doc); err != nil {
+	key, ok := cache.key(module.Name(), gostrings.Join(filter, ","), buf.String(), envParamData, string(componentTypes))
+	if ok {
+		if objects, hit := cache.get(key); hit {
+			log.WithField("module-name", module.Name()).Debug("using cached objects")
+			recordModuleStats(ModuleStats{
+				Name:       module.Name(),
+				Elapsed:    time.Since(start),
+				Cached:     true,
+				Components: make([]ComponentStats, 0),
+			})
+			return objects, nil
+		}
+	}
+
+	// changes to files outside of the directories hashed for the key would
+	// not invalidate the entry, so modules which import them aren't cached.
+	var uncovered string
+	recordImports := jsonnet.RecordImports(func(path string) {
+		if uncovered == "" && !cache.covers(path) {
+			uncovered = path
+		}
+	})
+
+	objects, err := evaluate(recordImports)
+	if err != nil {
 		return nil, err
 	}
 
+	if uncovered != "" {
+		log.WithField("module-name", module.Name()).Debugf("not caching objects because they import %s", uncovered)
+	} else if ok {
+		if err = cache.put(key, objects); err != nil {
+			log.WithError(err).Debugf("caching objects for module %q", module.Name())
+		}
+	}
+
+	return objects, nil
+}
+
+// objectCache returns the pipeline's object cache, or nil if caching is
+// disabled.
+func (p *Pipeline) objectCache() *objectCache {
+	if p.noCache || cacheDisabled {
+		return nil
+	}
+
+	p.cacheOnce.Do(func() {
+		p.cache = newObjectCache(p.app, p.envName)
+	})
+
+	return p.cache
+}
+
+// evaluateModule evaluates a rendered module and converts its components
+// into objects.
+func (p *Pipeline) evaluateModule(source, envParamData string, componentMap map[string]string, filter []string, opts ...jsonnet.VMOpt) ([]*unstructured.Unstructured, error) {
 	// evaluate module with jsonnet.
-	evaluated, err := p.evaluateEnvFn(p.app, p.envName, buf.String(), envParamData)
+	evaluated, err := p.evaluateEnvFn(p.app, p.envName, source, envParamData, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -227,51 +362,225 @@ func (p *Pipeline) moduleObjects(module component.Module, filter []string) ([]*u
 		return nil, err
 	}
 
-	ret := make([]runtime.Object, 0, len(m))
-
-	for componentName, v := range m {
+	var names []string
+	for componentName := range m {
 		if len(filter) != 0 && !strings.InSlice(componentName, filter) {
 			continue
 		}
+		names = append(names, componentName)
+	}
+	sort.Strings(names)
 
-		componentObject, ok := v.(map[string]interface{})
+	var ret []*unstructured.Unstructured
+
+	for _, componentName := range names {
+		v := m[componentName]
+
+		componentType, ok := componentMap[componentName]
 		if !ok {
-			return nil, errors.Errorf("component %q is not an object", componentName)
+			// Items in a list won't end up in this map, so assume they are jsonnet.
+			componentType = "jsonnet"
 		}
 
-		labelComponents(componentObject, componentName)
+		if componentType == "yaml" {
+			if v, err = patchComponent(v, envParamData, componentName); err != nil {
+				return nil, err
+			}
+		}
 
-		data, err := json.Marshal(componentObject)
+		objects, err := normalizeComponent(componentName, v)
 		if err != nil {
 			return nil, err
 		}
 
-		componentType, ok := componentMap[componentName]
-		if !ok {
-			// Items in a list won't end up in this map, so assume they are jsonnet.
-			componentType = "jsonnet"
-		}
+		for _, object := range objects {
+			labelComponent(object, componentName)
 
-		var patched string
+			data, err := json.Marshal(object)
+			if err != nil {
+				return nil, err
+			}
 
-		switch componentType {
-		case "jsonnet":
-			patched = string(data)
-		case "yaml":
-			patched, err = params.PatchJSON(string(data), envParamData, componentName)
+			uns, _, err := unstructured.UnstructuredJSONScheme.Decode(data, nil, nil)
 			if err != nil {
-				return nil, errors.Wrap(err, "patching YAML/JSON component")
+				return nil, errors.Wrapf(err, "decoding object in component %q", componentName)
+			}
+
+			u, ok := uns.(*unstructured.Unstructured)
+			if !ok {
+				return nil, errors.Errorf("component %q contains unexpected object %T", componentName, uns)
 			}
+			ret = append(ret, u)
+		}
+	}
+
+	return ret, nil
+}
+
+// patchComponent applies environment params to a YAML/JSON component.
+func patchComponent(v interface{}, envParamData, componentName string) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := params.PatchJSON(string(data), envParamData, componentName)
+	if err != nil {
+		return nil, errors.Wrap(err, "patching YAML/JSON component")
+	}
+
+	var out interface{}
+	if err := json.Unmarshal([]byte(patched), &out); err != nil {
+		return nil, errors.Wrap(err, "decoding patched YAML/JSON component")
+	}
+
+	return out, nil
+}
+
+// moduleEnvParams evaluates the parameters for a module in the pipeline's
+// environment.
+func (p *Pipeline) moduleEnvParams(module component.Module) (string, error) {
+	moduleParamData, err := module.ResolvedParams(p.envName)
+	if err != nil {
+		return "", err
+	}
+
+	envParamsPath, err := env.Path(p.app, p.envName, "params.libsonnet")
+	if err != nil {
+		return "", err
+	}
+
+	envParamData, err := p.evaluateEnvParamsFn(p.app, envParamsPath, moduleParamData, p.envName, module.Name())
+	if err != nil || len(p.paramOverrides) == 0 {
+		return envParamData, err
+	}
+
+	return overrideParams(envParamData, p.paramOverrides)
+}
+
+// overrideParams sets params in evaluated environment params.
+func overrideParams(envParamData string, overrides map[string]map[string]interface{}) (string, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(envParamData), &m); err != nil {
+		return "", errors.Wrap(err, "decode environment params")
+	}
+
+	components, ok := m["components"].(map[string]interface{})
+	if !ok {
+		return envParamData, nil
+	}
+
+	for componentName, params := range overrides {
+		componentParams, ok := components[componentName].(map[string]interface{})
+		if !ok {
+			continue
 		}
 
-		uns, _, err := unstructured.UnstructuredJSONScheme.Decode([]byte(patched), nil, nil)
+		for k, v := range params {
+			componentParams[k] = v
+		}
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// ComponentParams returns the params of a component resolved for the
+// pipeline's environment. Components in nested modules are named
+// <module>.<component>.
+func (p *Pipeline) ComponentParams(componentName string) (map[string]interface{}, error) {
+	moduleName := "/"
+	if i := gostrings.LastIndex(componentName, "."); i >= 0 {
+		moduleName = componentName[:i]
+	}
+
+	module, err := p.cm.Module(p.app, moduleName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "load module %s", moduleName)
+	}
+
+	envParamData, err := p.moduleEnvParams(module)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved struct {
+		Components map[string]map[string]interface{} `json:"components"`
+	}
+	if err = json.Unmarshal([]byte(envParamData), &resolved); err != nil {
+		return nil, errors.Wrapf(err, "decode params for module %q", moduleName)
+	}
+
+	params, ok := resolved.Components[componentName]
+	if !ok {
+		return nil, errors.Errorf("component %q has no params in environment %q", componentName, p.envName)
+	}
+
+	return params, nil
+}
+
+// ValidateParams validates the resolved parameters of the pipeline's
+// environment against the params schemas of its modules.
+func (p *Pipeline) ValidateParams() []error {
+	modules, err := p.Modules()
+	if err != nil {
+		return []error{errors.Wrap(err, "get modules")}
+	}
+
+	var errs []error
+
+	for _, m := range modules {
+		schema, err := p.loadParamsSchemaFn(p.app, m.Name())
 		if err != nil {
-			return nil, errors.Wrap(err, "decoding unstructured")
+			errs = append(errs, errors.Wrapf(err, "load params schema for module %q", m.Name()))
+			continue
+		}
+
+		if schema == nil {
+			continue
+		}
+
+		envParamData, err := p.moduleEnvParams(m)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		var resolved struct {
+			Components map[string]map[string]interface{} `json:"components"`
+		}
+		if err = json.Unmarshal([]byte(envParamData), &resolved); err != nil {
+			errs = append(errs, errors.Wrapf(err, "decode params for module %q", m.Name()))
+			continue
+		}
+
+		// nested modules prefix their component names with the module name.
+		prefix := ""
+		if m.Name() != "/" {
+			prefix = m.Name() + "."
+		}
+
+		components, err := m.Components()
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "get components for module %q", m.Name()))
+			continue
+		}
+
+		// components without params are validated as an empty object, so
+		// their required params are reported.
+		for _, c := range components {
+			name := c.Name(false)
+			if err := schema.Validate(name, resolved.Components[prefix+name]); err != nil {
+				errs = append(errs, err)
+			}
 		}
-		ret = append(ret, uns)
 	}
 
-	return k8s.FlattenToV1(ret)
+	return errs
 }
 
 // YAML converts components into YAML.
@@ -327,45 +636,53 @@ func buildObjects(p *Pipeline, filter []string) ([]*unstructured.Unstructured, e
 		return nil, errors.Wrap(err, "get modules")
 	}
 
-	var ret []*unstructured.Unstructured
-
-	for _, m := range modules {
-		log.WithFields(log.Fields{
-			"action":      "pipeline",
-			"module-name": m.Name(),
-		}).Debug("building objects")
-
-		objects, err := p.moduleObjects(m, filter)
-		if err != nil {
-			return nil, err
-		}
+	// modules are rendered by a pool of workers. Results are collected in
+	// module order, so the output doesn't depend on scheduling.
+	results := make([][]*unstructured.Unstructured, len(modules))
+	errs := make([]error, len(modules))
 
-		ret = append(ret, objects...)
+	workers := p.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(modules) {
+		workers = len(modules)
 	}
 
-	return ret, nil
-}
-
-func labelComponents(m map[string]interface{}, name string) {
-	if m["apiVersion"] == "v1" && m["kind"] == "List" {
-		list, ok := m["items"].([]interface{})
-		if !ok {
-			return
-		}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				m := modules[i]
+				log.WithFields(log.Fields{
+					"action":      "pipeline",
+					"module-name": m.Name(),
+				}).Debug("building objects")
 
-		for _, item := range list {
-			itemMap, ok := item.(map[string]interface{})
-			if !ok {
-				continue
+				results[i], errs[i] = p.moduleObjects(m, filter)
 			}
+		}()
+	}
+
+	for i := range modules {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
 
-			labelComponent(itemMap, name)
+	var ret []*unstructured.Unstructured
+	for i := range modules {
+		if errs[i] != nil {
+			return nil, errs[i]
 		}
 
-		return
+		ret = append(ret, results[i]...)
 	}
 
-	labelComponent(m, name)
+	return ret, nil
 }
 
 func labelComponent(m map[string]interface{}, name string) {
@@ -381,9 +698,12 @@ func labelComponent(m map[string]interface{}, name string) {
 		metadata["labels"] = labels
 	}
 
+	labels[clustermetadata.LabelComponent] = componentLabel(name)
+}
+
+// componentLabel returns the value of the component label for a component.
+func componentLabel(name string) string {
 	// TODO: this should be owned by module
 	name = gostrings.TrimPrefix(name, "/")
-	name = gostrings.Replace(name, "/", ".", -1)
-
-	labels[clustermetadata.LabelComponent] = name
+	return gostrings.Replace(name, "/", ".", -1)
 }
@@ -16,10 +16,15 @@
 package pipeline
 
 import (
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/go-openapi/spec"
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	appmocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
@@ -28,6 +33,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/metadata"
 	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
 	"github.com/pkg/errors"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -195,8 +201,331 @@ func TestPipeline_Objects(t *testing.T) {
 	})
 }
 
+func TestPipeline_Objects_concurrent(t *testing.T) {
+	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		names := []string{"/", "a", "b", "c", "d"}
+		calls := mockModules(p, m, a, names)
+
+		p.concurrency = 3
+
+		got, err := p.Objects(nil)
+		require.NoError(t, err)
+
+		// objects are returned in module order.
+		require.Len(t, got, len(names))
+		for i, name := range names {
+			assert.Equal(t, name, got[i].GetName())
+		}
+		assert.Equal(t, int32(len(names)), atomic.LoadInt32(calls))
+	})
+}
+
+func TestPipeline_Objects_cache(t *testing.T) {
+	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		names := []string{"/", "nested"}
+		calls := mockModules(p, m, a, names)
+
+		expected, err := p.Objects(nil)
+		require.NoError(t, err)
+		require.Equal(t, int32(2), atomic.LoadInt32(calls))
+
+		cached := New(a, "default", OverrideManager(m))
+		cached.evaluateEnvFn = p.evaluateEnvFn
+		cached.evaluateEnvParamsFn = p.evaluateEnvParamsFn
+
+		got, err := cached.Objects(nil)
+		require.NoError(t, err)
+		require.Equal(t, expected, got)
+		require.Equal(t, int32(2), atomic.LoadInt32(calls), "expected cached objects")
+
+		uncached := New(a, "default", OverrideManager(m), NoCache())
+		uncached.evaluateEnvFn = p.evaluateEnvFn
+		uncached.evaluateEnvParamsFn = p.evaluateEnvParamsFn
+
+		got, err = uncached.Objects(nil)
+		require.NoError(t, err)
+		require.Equal(t, expected, got)
+		require.Equal(t, int32(4), atomic.LoadInt32(calls))
+	})
+}
+
+func TestPipeline_Objects_cache_out_of_tree_import(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shared")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	shared := filepath.Join(dir, "shared.libsonnet")
+	require.NoError(t, ioutil.WriteFile(shared, []byte(`{name: "before"}`), 0644))
+
+	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		mockModules(p, m, a, []string{"/"})
+
+		evaluateEnv := func(_ app.App, envName, components, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
+			vm := jsonnet.NewVM(opts...)
+			snippet := fmt.Sprintf(`{service: {apiVersion: "v1", kind: "Service", metadata: import %q}}`, shared)
+			return vm.EvaluateSnippet("/app/environments/default/main.jsonnet", snippet)
+		}
+		p.evaluateEnvFn = evaluateEnv
+
+		got, err := p.Objects(nil)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		require.Equal(t, "before", got[0].GetName())
+
+		require.NoError(t, ioutil.WriteFile(shared, []byte(`{name: "after"}`), 0644))
+
+		changed := New(a, "default", OverrideManager(m))
+		changed.evaluateEnvFn = evaluateEnv
+		changed.evaluateEnvParamsFn = p.evaluateEnvParamsFn
+
+		got, err = changed.Objects(nil)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		require.Equal(t, "after", got[0].GetName(), "expected objects rendered from the changed import")
+	})
+}
+
+func TestPipeline_ComponentParams(t *testing.T) {
+	cases := []struct {
+		name          string
+		componentName string
+		moduleName    string
+		overrides     map[string]map[string]interface{}
+		expected      map[string]interface{}
+		isErr         bool
+	}{
+		{
+			name:          "root component",
+			componentName: "guestbook",
+			moduleName:    "/",
+			expected:      map[string]interface{}{"replicas": float64(1), "image": "nginx"},
+		},
+		{
+			name:          "nested component",
+			componentName: "nested.redis",
+			moduleName:    "nested",
+			expected:      map[string]interface{}{"replicas": float64(2)},
+		},
+		{
+			name:          "overridden",
+			componentName: "guestbook",
+			moduleName:    "/",
+			overrides: map[string]map[string]interface{}{
+				"guestbook": {"replicas": 5},
+				"missing":   {"replicas": 5},
+			},
+			expected: map[string]interface{}{"replicas": float64(5), "image": "nginx"},
+		},
+		{
+			name:          "unknown component",
+			componentName: "missing",
+			moduleName:    "/",
+			isErr:         true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+				p.paramOverrides = tc.overrides
+
+				module := &cmocks.Module{}
+				module.On("Name").Return(tc.moduleName)
+				module.On("ResolvedParams", "default").Return("", nil)
+				m.On("Module", p.app, tc.moduleName).Return(module, nil)
+
+				a.On("Environment", "default").Return(&app.EnvironmentConfig{Path: "default"}, nil)
+
+				p.evaluateEnvParamsFn = func(_ app.App, paramsPath, paramData, envName, moduleName string) (string, error) {
+					return `{"components": {"guestbook": {"replicas": 1, "image": "nginx"}, "nested.redis": {"replicas": 2}}}`, nil
+				}
+
+				got, err := p.ComponentParams(tc.componentName)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, got)
+			})
+		})
+	}
+}
+
+func BenchmarkPipeline_Objects(b *testing.B) {
+	cases := []struct {
+		name string
+		opts []Opt
+	}{
+		{name: "sequential", opts: []Opt{Concurrency(1), NoCache()}},
+		{name: "concurrent", opts: []Opt{Concurrency(8), NoCache()}},
+		{name: "cached"},
+	}
+
+	// testify mocks record every call, so the benchmark uses fakes.
+	m := &benchManager{}
+	for i := 0; i < 16; i++ {
+		m.modules = append(m.modules, benchModule{name: fmt.Sprintf("module%d", i)})
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			a := &benchApp{fs: afero.NewMemMapFs()}
+			p := New(a, "default", append(tc.opts, OverrideManager(m))...)
+
+			p.evaluateEnvParamsFn = func(_ app.App, paramsPath, paramData, envName, moduleName string) (string, error) {
+				return moduleName, nil
+			}
+
+			// evaluation dominates rendering, so simulate its cost.
+			p.evaluateEnvFn = func(_ app.App, envName, components, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
+				time.Sleep(5 * time.Millisecond)
+				return fmt.Sprintf(`{"service": {"apiVersion": "v1", "kind": "Service", "metadata": {"name": %q}}}`, paramsStr), nil
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := p.Objects(nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type benchApp struct {
+	app.App
+	fs afero.Fs
+}
+
+func (a *benchApp) Root() string { return "/" }
+func (a *benchApp) Fs() afero.Fs { return a.fs }
+func (a *benchApp) Environment(name string) (*app.EnvironmentConfig, error) {
+	return &app.EnvironmentConfig{Path: name}, nil
+}
+
+type benchManager struct {
+	component.Manager
+	modules []component.Module
+}
+
+func (m *benchManager) Modules(a app.App, envName string) ([]component.Module, error) {
+	return m.modules, nil
+}
+
+type benchModule struct {
+	component.Module
+	name string
+}
+
+func (m benchModule) Name() string { return m.name }
+func (m benchModule) ResolvedParams(envName string) (string, error) {
+	return "", nil
+}
+func (m benchModule) Render(envName string, componentNames ...string) (*astext.Object, map[string]string, error) {
+	return &astext.Object{}, map[string]string{"service": "jsonnet"}, nil
+}
+
+// mockModules sets up modules which each render a service named after the
+// module. It returns a count of the modules evaluated.
+func mockModules(p *Pipeline, m *cmocks.Manager, a *appmocks.App, names []string) *int32 {
+	var modules []component.Module
+	for _, name := range names {
+		module := &cmocks.Module{}
+		module.On("Name").Return(name)
+		module.On("Render", "default").Return(&astext.Object{}, map[string]string{"service": "jsonnet"}, nil)
+		module.On("ResolvedParams", "default").Return("", nil)
+		modules = append(modules, module)
+	}
+
+	m.On("Modules", p.app, "default").Return(modules, nil)
+	a.On("Environment", "default").Return(&app.EnvironmentConfig{Path: "default"}, nil)
+
+	p.evaluateEnvParamsFn = func(_ app.App, paramsPath, paramData, envName, moduleName string) (string, error) {
+		return moduleName, nil
+	}
+
+	calls := new(int32)
+	p.evaluateEnvFn = func(_ app.App, envName, components, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
+		atomic.AddInt32(calls, 1)
+		return fmt.Sprintf(`{"service": {"apiVersion": "v1", "kind": "Service", "metadata": {"name": %q}}}`, paramsStr), nil
+	}
+
+	return calls
+}
+
+func TestPipeline_ValidateParams(t *testing.T) {
+	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		newComponent := func(name string) component.Component {
+			c := &cmocks.Component{}
+			c.On("Name", false).Return(name)
+			return c
+		}
+
+		root := &cmocks.Module{}
+		root.On("Name").Return("/")
+		root.On("ResolvedParams", "default").Return("", nil)
+		root.On("Components").Return([]component.Component{newComponent("guestbook"), newComponent("unparameterized")}, nil)
+
+		nested := &cmocks.Module{}
+		nested.On("Name").Return("nested")
+		nested.On("ResolvedParams", "default").Return("", nil)
+		nested.On("Components").Return([]component.Component{newComponent("redis")}, nil)
+
+		unschemed := &cmocks.Module{}
+		unschemed.On("Name").Return("other")
+
+		modules := []component.Module{root, nested, unschemed}
+		m.On("Modules", p.app, "default").Return(modules, nil)
+
+		env := &app.EnvironmentConfig{Path: "default"}
+		a.On("Environment", "default").Return(env, nil)
+
+		schema := func(componentName string) *component.ParamsSchema {
+			return &component.ParamsSchema{
+				Components: map[string]spec.Schema{
+					componentName: *spec.MapProperty(nil).
+						SetProperty("replicas", *spec.Int64Property().WithMinimum(1, false)),
+					"missing": *spec.MapProperty(nil),
+					"unparameterized": *spec.MapProperty(nil).
+						SetProperty("image", *spec.StringProperty()).
+						WithRequired("image"),
+				},
+			}
+		}
+
+		p.loadParamsSchemaFn = func(_ app.App, moduleName string) (*component.ParamsSchema, error) {
+			switch moduleName {
+			case "/":
+				return schema("guestbook"), nil
+			case "nested":
+				return schema("redis"), nil
+			default:
+				return nil, nil
+			}
+		}
+
+		p.evaluateEnvParamsFn = func(_ app.App, paramsPath, paramData, envName, moduleName string) (string, error) {
+			switch moduleName {
+			case "/":
+				return `{"components": {"guestbook": {"replicas": 3}}}`, nil
+			default:
+				return `{"components": {"nested.redis": {"replicas": 0}}}`, nil
+			}
+		}
+
+		errs := p.ValidateParams()
+		require.Len(t, errs, 2)
+		require.Contains(t, errs[0].Error(), `"unparameterized"`)
+		require.Contains(t, errs[1].Error(), `"redis"`)
+	})
+}
+
 func TestPipeline_YAML(t *testing.T) {
 	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		a.On("Environment", "default").Return(&app.EnvironmentConfig{Path: "default"}, nil)
+
 		p.buildObjectsFn = func(_ *Pipeline, filter []string) ([]*unstructured.Unstructured, error) {
 			u := []*unstructured.Unstructured{
 				{
@@ -296,6 +625,7 @@ func Test_stubModule(t *testing.T) {
 func withPipeline(t *testing.T, fn func(p *Pipeline, m *cmocks.Manager, a *appmocks.App)) {
 	a := &appmocks.App{}
 	a.On("Root").Return("/")
+	a.On("Fs").Return(afero.NewMemMapFs())
 	envName := "default"
 
 	manager := &cmocks.Manager{}
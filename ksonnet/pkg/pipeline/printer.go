@@ -49,18 +49,20 @@ func printYAML(out io.Writer, objects []*unstructured.Unstructured) error {
 	return nil
 }
 
+// printJSON prints objects as a v1 List, so the output is always one JSON
+// document with the same shape.
 func printJSON(out io.Writer, objects []*unstructured.Unstructured) error {
 	enc := json.NewEncoder(out)
 	enc.SetIndent("", "  ")
+
+	items := make([]interface{}, 0, len(objects))
 	for _, obj := range objects {
-		// TODO: this is not valid framing for JSON
-		if len(objects) > 1 {
-			fmt.Fprintln(out, "---")
-		}
-		if err := enc.Encode(obj); err != nil {
-			return err
-		}
+		items = append(items, obj.Object)
 	}
 
-	return nil
+	return enc.Encode(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
 }
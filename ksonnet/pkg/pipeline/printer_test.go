@@ -0,0 +1,82 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFprint(t *testing.T) {
+	a := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "a"}}
+	b := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "b"}}
+
+	cases := []struct {
+		name     string
+		objects  []*unstructured.Unstructured
+		format   string
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "yaml",
+			objects:  []*unstructured.Unstructured{a, b},
+			format:   "yaml",
+			expected: "---\nkind: a\n---\nkind: b\n",
+		},
+		{
+			name:     "json with no objects",
+			objects:  []*unstructured.Unstructured{},
+			format:   "json",
+			expected: "{\n  \"apiVersion\": \"v1\",\n  \"items\": [],\n  \"kind\": \"List\"\n}\n",
+		},
+		{
+			name:     "json with one object",
+			objects:  []*unstructured.Unstructured{a},
+			format:   "json",
+			expected: "{\n  \"apiVersion\": \"v1\",\n  \"items\": [\n    {\n      \"kind\": \"a\"\n    }\n  ],\n  \"kind\": \"List\"\n}\n",
+		},
+		{
+			name:     "json list",
+			objects:  []*unstructured.Unstructured{a, b},
+			format:   "json",
+			expected: "{\n  \"apiVersion\": \"v1\",\n  \"items\": [\n    {\n      \"kind\": \"a\"\n    },\n    {\n      \"kind\": \"b\"\n    }\n  ],\n  \"kind\": \"List\"\n}\n",
+		},
+		{
+			name:    "unknown format",
+			objects: []*unstructured.Unstructured{a},
+			format:  "xml",
+			isErr:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Fprint(&buf, tc.objects, tc.format)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// renamer updates references between rendered objects when their names are
+// given a prefix or suffix. References to objects which weren't rendered
+// are left alone.
+type renamer struct {
+	prefix string
+	suffix string
+	names  map[string]bool
+}
+
+func newRenamer(objects []*unstructured.Unstructured, prefix, suffix string) *renamer {
+	r := &renamer{
+		prefix: prefix,
+		suffix: suffix,
+		names:  make(map[string]bool),
+	}
+
+	for _, obj := range objects {
+		r.names[obj.GetKind()+"/"+obj.GetName()] = true
+	}
+
+	return r
+}
+
+func (r *renamer) enabled() bool {
+	return r.prefix != "" || r.suffix != ""
+}
+
+// has returns true if an object with a kind and name was rendered.
+func (r *renamer) has(kind, name string) bool {
+	return r.names[kind+"/"+name]
+}
+
+// renameField renames the reference to an object of a kind at a path in m.
+func (r *renamer) renameField(m map[string]interface{}, kind string, fields ...string) {
+	if unrenamedKinds[kind] {
+		return
+	}
+
+	parent := nestedMap(m, fields[:len(fields)-1]...)
+	if parent == nil {
+		return
+	}
+
+	field := fields[len(fields)-1]
+	name, _ := parent[field].(string)
+	if name != "" && r.has(kind, name) {
+		parent[field] = r.prefix + name + r.suffix
+	}
+}
+
+// renameReferences renames the references in an object to other rendered
+// objects.
+func (r *renamer) renameReferences(obj *unstructured.Unstructured) {
+	switch obj.GetKind() {
+	case "RoleBinding", "ClusterRoleBinding":
+		if roleRef := nestedMap(obj.Object, "roleRef"); roleRef != nil {
+			kind, _ := roleRef["kind"].(string)
+			r.renameField(roleRef, kind, "name")
+		}
+		for _, subject := range nestedSlice(obj.Object, "subjects") {
+			if subject["kind"] == "ServiceAccount" {
+				r.renameField(subject, "ServiceAccount", "name")
+			}
+		}
+	case "StatefulSet":
+		r.renameField(obj.Object, "Service", "spec", "serviceName")
+	case "Ingress":
+		spec := nestedMap(obj.Object, "spec")
+		if spec == nil {
+			break
+		}
+		r.renameBackend(nestedMap(spec, "backend"))
+		r.renameBackend(nestedMap(spec, "defaultBackend"))
+		for _, rule := range nestedSlice(spec, "rules") {
+			for _, path := range nestedSlice(rule, "http", "paths") {
+				r.renameBackend(nestedMap(path, "backend"))
+			}
+		}
+		for _, tls := range nestedSlice(spec, "tls") {
+			r.renameField(tls, "Secret", "secretName")
+		}
+	case "HorizontalPodAutoscaler":
+		if ref := nestedMap(obj.Object, "spec", "scaleTargetRef"); ref != nil {
+			kind, _ := ref["kind"].(string)
+			r.renameField(ref, kind, "name")
+		}
+	}
+
+	if path, ok := podSpecPaths[obj.GetKind()]; ok {
+		if podSpec := nestedMap(obj.Object, path...); podSpec != nil {
+			r.renamePodSpec(podSpec)
+		}
+	}
+}
+
+// renameBackend renames the service of an ingress backend.
+func (r *renamer) renameBackend(backend map[string]interface{}) {
+	if backend == nil {
+		return
+	}
+
+	r.renameField(backend, "Service", "serviceName")
+	r.renameField(backend, "Service", "service", "name")
+}
+
+// renamePodSpec renames the service accounts, secrets, config maps and
+// volume claims a pod refers to.
+func (r *renamer) renamePodSpec(podSpec map[string]interface{}) {
+	r.renameField(podSpec, "ServiceAccount", "serviceAccountName")
+	r.renameField(podSpec, "ServiceAccount", "serviceAccount")
+
+	for _, secret := range nestedSlice(podSpec, "imagePullSecrets") {
+		r.renameField(secret, "Secret", "name")
+	}
+
+	for _, volume := range nestedSlice(podSpec, "volumes") {
+		r.renameField(volume, "ConfigMap", "configMap", "name")
+		r.renameField(volume, "Secret", "secret", "secretName")
+		r.renameField(volume, "PersistentVolumeClaim", "persistentVolumeClaim", "claimName")
+		for _, source := range nestedSlice(volume, "projected", "sources") {
+			r.renameField(source, "ConfigMap", "configMap", "name")
+			r.renameField(source, "Secret", "secret", "name")
+		}
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		for _, container := range nestedSlice(podSpec, field) {
+			for _, from := range nestedSlice(container, "envFrom") {
+				r.renameField(from, "ConfigMap", "configMapRef", "name")
+				r.renameField(from, "Secret", "secretRef", "name")
+			}
+			for _, env := range nestedSlice(container, "env") {
+				r.renameField(env, "ConfigMap", "valueFrom", "configMapKeyRef", "name")
+				r.renameField(env, "Secret", "valueFrom", "secretKeyRef", "name")
+			}
+		}
+	}
+}
+
+// nestedSlice returns the objects in the array at a path, or nil if there
+// isn't one.
+func nestedSlice(m map[string]interface{}, fields ...string) []map[string]interface{} {
+	parent := nestedMap(m, fields[:len(fields)-1]...)
+	if parent == nil {
+		return nil
+	}
+
+	items, _ := parent[fields[len(fields)-1]].([]interface{})
+
+	var ret []map[string]interface{}
+	for _, item := range items {
+		if itemMap, ok := item.(map[string]interface{}); ok {
+			ret = append(ret, itemMap)
+		}
+	}
+
+	return ret
+}
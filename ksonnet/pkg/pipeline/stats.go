@@ -0,0 +1,231 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	jsonnetgo "github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+)
+
+const (
+	// statsMarkFn is the native function instrumented modules call when a
+	// component is first evaluated.
+	statsMarkFn = "ksonnetStatsMark"
+)
+
+var (
+	// statsMu guards collecting and moduleStats.
+	statsMu     sync.Mutex
+	collecting  bool
+	moduleStats []ModuleStats
+)
+
+// ComponentStats are statistics about evaluating a component.
+type ComponentStats struct {
+	Name string `json:"name"`
+	// Elapsed is the time from when the component was first evaluated until
+	// the next component was. Jsonnet is evaluated lazily, so time spent in
+	// imports shared with other components is counted for the first
+	// component which uses them.
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// ModuleStats are statistics about rendering a module.
+type ModuleStats struct {
+	Name    string        `json:"name"`
+	Elapsed time.Duration `json:"elapsed"`
+	// Cached is true if the objects of the module were read from the object
+	// cache rather than evaluated.
+	Cached     bool             `json:"cached"`
+	Components []ComponentStats `json:"components"`
+}
+
+// Stats are statistics about rendering objects.
+type Stats struct {
+	Modules []ModuleStats `json:"modules"`
+	Jsonnet jsonnet.Stats `json:"jsonnet"`
+}
+
+// CollectStats starts collecting statistics about the modules rendered by
+// every pipeline and about Jsonnet evaluation. Stats collected previously are
+// discarded.
+func CollectStats() {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	collecting = true
+	moduleStats = nil
+	jsonnet.CollectStats()
+}
+
+// StopStats stops collecting statistics.
+func StopStats() {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	collecting = false
+	moduleStats = nil
+	jsonnet.StopStats()
+}
+
+// CurrentStats returns the stats collected since CollectStats was called.
+// Modules are sorted by name.
+func CurrentStats() Stats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	s := Stats{
+		Modules: make([]ModuleStats, len(moduleStats)),
+		Jsonnet: jsonnet.CurrentStats(),
+	}
+	copy(s.Modules, moduleStats)
+
+	sort.SliceStable(s.Modules, func(i, j int) bool {
+		return s.Modules[i].Name < s.Modules[j].Name
+	})
+
+	return s
+}
+
+func collectingStats() bool {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	return collecting
+}
+
+func recordModuleStats(ms ModuleStats) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	if collecting {
+		moduleStats = append(moduleStats, ms)
+	}
+}
+
+type componentMark struct {
+	name string
+	at   time.Time
+}
+
+// componentTimer times the evaluation of the components in a module.
+type componentTimer struct {
+	mu    sync.Mutex
+	marks []componentMark
+	seen  map[string]bool
+}
+
+func newComponentTimer() *componentTimer {
+	return &componentTimer{
+		seen: make(map[string]bool),
+	}
+}
+
+// instrument returns a copy of a rendered module whose components call the
+// timer when they are first evaluated. Components are evaluated in turn as
+// the module is manifested, so the time between marks is the time spent on
+// a component.
+func (t *componentTimer) instrument(doc *astext.Object) (*astext.Object, error) {
+	instrumented := &astext.Object{
+		Fields: make(astext.ObjectFields, 0, len(doc.Fields)),
+	}
+
+	for _, f := range doc.Fields {
+		name, err := jsonnet.FieldID(f)
+		if err != nil {
+			return nil, err
+		}
+
+		native := ast.Identifier("native")
+		mark := &ast.Apply{
+			Target: &ast.Apply{
+				Target: &ast.Index{
+					Target: &ast.Var{Id: ast.Identifier("std")},
+					Id:     &native,
+				},
+				Arguments: ast.Arguments{
+					Positional: ast.Nodes{&ast.LiteralString{Value: statsMarkFn, Kind: ast.StringDouble}},
+				},
+			},
+			Arguments: ast.Arguments{
+				Positional: ast.Nodes{&ast.LiteralString{Value: name, Kind: ast.StringDouble}},
+			},
+		}
+
+		f.Expr2 = &ast.Conditional{
+			Cond:        mark,
+			BranchTrue:  &ast.Parens{Inner: f.Expr2},
+			BranchFalse: &ast.LiteralNull{},
+		}
+
+		instrumented.Fields = append(instrumented.Fields, f)
+	}
+
+	return instrumented, nil
+}
+
+// vmOpt adds the timer's native function to a VM.
+func (t *componentTimer) vmOpt() jsonnet.VMOpt {
+	return func(vm *jsonnet.VM) {
+		vm.AddFunctions(&jsonnetgo.NativeFunction{
+			Name:   statsMarkFn,
+			Params: ast.Identifiers{"name"},
+			Func:   t.mark,
+		})
+	}
+}
+
+func (t *componentTimer) mark(args []interface{}) (interface{}, error) {
+	name, _ := args[0].(string)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.seen[name] {
+		t.seen[name] = true
+		t.marks = append(t.marks, componentMark{name: name, at: time.Now()})
+	}
+
+	return true, nil
+}
+
+// components returns the stats of the components which were evaluated, in
+// the order they were evaluated. end is when evaluation finished.
+func (t *componentTimer) components(end time.Time) []ComponentStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make([]ComponentStats, 0, len(t.marks))
+	for i, m := range t.marks {
+		next := end
+		if i+1 < len(t.marks) {
+			next = t.marks[i+1].at
+		}
+
+		stats = append(stats, ComponentStats{
+			Name:    m.name,
+			Elapsed: next.Sub(m.at),
+		})
+	}
+
+	return stats
+}
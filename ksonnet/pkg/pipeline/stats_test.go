@@ -0,0 +1,104 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/printer"
+	appmocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_componentTimer(t *testing.T) {
+	src := `{
+  b: local replicas = 2; {replicas: replicas},
+  a: {kind: "Service"},
+  "nested.c": [1, 2],
+}`
+
+	doc, err := jsonnet.Parse("module.jsonnet", src)
+	require.NoError(t, err)
+
+	timer := newComponentTimer()
+	instrumented, err := timer.instrument(doc)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, printer.Fprint(&buf, instrumented))
+
+	vm := jsonnet.NewVM(timer.vmOpt())
+	got, err := vm.EvaluateSnippet("module.jsonnet", buf.String())
+	require.NoError(t, err)
+
+	expected, err := jsonnet.NewVM().EvaluateSnippet("module.jsonnet", src)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, got)
+
+	// fields are manifested in name order.
+	stats := timer.components(time.Now())
+	require.Len(t, stats, 3)
+	for i, name := range []string{"a", "b", "nested.c"} {
+		assert.Equal(t, name, stats[i].Name)
+		assert.True(t, stats[i].Elapsed >= 0)
+	}
+}
+
+func TestPipeline_Objects_stats(t *testing.T) {
+	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		mockModules(p, m, a, []string{"nested", "/"})
+
+		_, err := p.Objects(nil)
+		require.NoError(t, err)
+		assert.Empty(t, CurrentStats().Modules, "stats aren't collected by default")
+
+		CollectStats()
+		defer StopStats()
+
+		_, err = p.Objects(nil)
+		require.NoError(t, err)
+
+		stats := CurrentStats()
+		require.Len(t, stats.Modules, 2)
+		assert.Equal(t, "/", stats.Modules[0].Name)
+		assert.Equal(t, "nested", stats.Modules[1].Name)
+		for _, ms := range stats.Modules {
+			assert.True(t, ms.Cached, "objects were cached by the first render")
+		}
+
+		CollectStats()
+
+		uncached := New(a, "default", OverrideManager(m), NoCache())
+		uncached.evaluateEnvFn = p.evaluateEnvFn
+		uncached.evaluateEnvParamsFn = p.evaluateEnvParamsFn
+
+		_, err = uncached.Objects(nil)
+		require.NoError(t, err)
+
+		stats = CurrentStats()
+		require.Len(t, stats.Modules, 2)
+		for _, ms := range stats.Modules {
+			assert.False(t, ms.Cached)
+			assert.NotNil(t, ms.Components)
+		}
+	})
+}
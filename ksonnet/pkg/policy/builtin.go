@@ -0,0 +1,192 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// PolicyNoLatestImage reports containers whose image is untagged or
+	// tagged `latest`.
+	PolicyNoLatestImage = "no-latest-image"
+	// PolicyRequireResourceLimits reports containers without cpu and memory
+	// limits.
+	PolicyRequireResourceLimits = "require-resource-limits"
+	// PolicyNoHostNetwork reports pods which use the host's network.
+	PolicyNoHostNetwork = "no-host-network"
+
+	builtinSource = "built-in"
+)
+
+var (
+	// builtins are the built-in policies. They are enabled in app.yaml.
+	builtins = map[string]Policy{
+		PolicyNoLatestImage: {
+			Name:        PolicyNoLatestImage,
+			Description: "Container images must be pinned to a tag other than latest",
+			check:       eachObject(checkNoLatestImage),
+		},
+		PolicyRequireResourceLimits: {
+			Name:        PolicyRequireResourceLimits,
+			Description: "Containers must set cpu and memory limits",
+			check:       eachObject(checkResourceLimits),
+		},
+		PolicyNoHostNetwork: {
+			Name:        PolicyNoHostNetwork,
+			Description: "Pods must not use the host's network",
+			check:       eachObject(checkNoHostNetwork),
+		},
+	}
+)
+
+func init() {
+	for name, p := range builtins {
+		p.Severity = SeverityError
+		p.Source = builtinSource
+		builtins[name] = p
+	}
+}
+
+// eachObject creates a checkFn from a function which checks a single object.
+func eachObject(fn func(obj *unstructured.Unstructured) []string) checkFn {
+	return func(objects []*unstructured.Unstructured) ([][]string, error) {
+		results := make([][]string, len(objects))
+		for i, obj := range objects {
+			results[i] = fn(obj)
+		}
+
+		return results, nil
+	}
+}
+
+func checkNoLatestImage(obj *unstructured.Unstructured) []string {
+	var messages []string
+	for _, podSpec := range podSpecs(obj) {
+		for _, c := range containers(podSpec) {
+			image, _, _ := unstructured.NestedString(c, "image")
+			if isLatest(image) {
+				name, _, _ := unstructured.NestedString(c, "name")
+				messages = append(messages, fmt.Sprintf("container %q uses image %q", name, image))
+			}
+		}
+	}
+
+	return messages
+}
+
+// isLatest reports if an image is untagged or tagged latest. Images pinned to
+// a digest are never latest.
+func isLatest(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+
+	// the tag follows the last colon, unless the colon separates a registry
+	// host from its port.
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return true
+	}
+
+	return image[i+1:] == "latest"
+}
+
+func checkResourceLimits(obj *unstructured.Unstructured) []string {
+	var messages []string
+	for _, podSpec := range podSpecs(obj) {
+		for _, c := range containers(podSpec) {
+			limits := nestedMap(c, "resources", "limits")
+
+			var missing []string
+			for _, resource := range []string{"cpu", "memory"} {
+				if _, ok := limits[resource]; !ok {
+					missing = append(missing, resource)
+				}
+			}
+
+			if len(missing) > 0 {
+				name, _, _ := unstructured.NestedString(c, "name")
+				messages = append(messages, fmt.Sprintf("container %q does not set a %s limit",
+					name, strings.Join(missing, " or ")))
+			}
+		}
+	}
+
+	return messages
+}
+
+func checkNoHostNetwork(obj *unstructured.Unstructured) []string {
+	for _, podSpec := range podSpecs(obj) {
+		if hostNetwork, _, _ := unstructured.NestedBool(podSpec, "hostNetwork"); hostNetwork {
+			return []string{"pod uses the host's network"}
+		}
+	}
+
+	return nil
+}
+
+// podSpecs returns the pod specs in an object. The pipeline flattens lists,
+// so objects are never lists.
+func podSpecs(obj *unstructured.Unstructured) []map[string]interface{} {
+	var path []string
+	switch obj.GetKind() {
+	case "Pod":
+		path = []string{"spec"}
+	case "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	default:
+		path = []string{"spec", "template", "spec"}
+	}
+
+	spec := nestedMap(obj.Object, path...)
+	if spec == nil {
+		return nil
+	}
+
+	return []map[string]interface{}{spec}
+}
+
+// nestedMap returns the map at a path, or nil if there isn't one. Unlike
+// unstructured.NestedMap, it does not copy the map.
+func nestedMap(m map[string]interface{}, fields ...string) map[string]interface{} {
+	for _, field := range fields {
+		var ok bool
+		if m, ok = m[field].(map[string]interface{}); !ok {
+			return nil
+		}
+	}
+
+	return m
+}
+
+// containers returns the init containers and containers in a pod spec.
+func containers(podSpec map[string]interface{}) []map[string]interface{} {
+	var out []map[string]interface{}
+	for _, field := range []string{"initContainers", "containers"} {
+		list, _ := podSpec[field].([]interface{})
+		for _, item := range list {
+			if c, ok := item.(map[string]interface{}); ok {
+				out = append(out, c)
+			}
+		}
+	}
+
+	return out
+}
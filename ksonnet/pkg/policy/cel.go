@@ -0,0 +1,133 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package policy
+
+import (
+	"bufio"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/ksonnet/ksonnet/utils"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// celObjectVar is the CEL variable which holds the object being checked.
+const celObjectVar = "object"
+
+// A CEL policy is a CEL expression which is evaluated for each object. It
+// either returns a bool, where false is a violation, or a list of violation
+// messages. Its description and severity are set with `@description` and
+// `@severity` comments:
+//
+//	// @description Deployments must run more than one replica
+//	// @severity warning
+//	object.kind != "Deployment" || object.spec.replicas > 1
+type celPolicy struct {
+	description string
+	severity    string
+	program     cel.Program
+}
+
+// parseCEL compiles the CEL policy in src.
+func parseCEL(name, src string) (*celPolicy, error) {
+	p := &celPolicy{}
+
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		fields := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(line, "//")), " ", 2)
+		value := ""
+		if len(fields) == 2 {
+			value = strings.TrimSpace(fields[1])
+		}
+
+		switch fields[0] {
+		case "@description":
+			p.description = value
+		case "@severity":
+			p.severity = value
+		}
+	}
+
+	env, err := cel.NewEnv(cel.Variable(celObjectVar, cel.DynType))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating CEL environment")
+	}
+
+	checked, issues := env.Compile(src)
+	if issues != nil && issues.Err() != nil {
+		return nil, errors.Errorf("compiling %s: %v", name, issues.Err())
+	}
+
+	if p.program, err = env.Program(checked); err != nil {
+		return nil, errors.Wrapf(err, "compiling %s", name)
+	}
+
+	return p, nil
+}
+
+// check returns the violation messages for each object.
+func (p *celPolicy) check(name string) checkFn {
+	return func(objects []*unstructured.Unstructured) ([][]string, error) {
+		results := make([][]string, 0, len(objects))
+		for _, obj := range objects {
+			messages, err := p.violations(name, obj)
+			if err != nil {
+				return nil, errors.Wrapf(err, "checking %s %s", obj.GetKind(), utils.FqName(obj))
+			}
+
+			results = append(results, messages)
+		}
+
+		return results, nil
+	}
+}
+
+func (p *celPolicy) violations(name string, obj *unstructured.Unstructured) ([]string, error) {
+	out, _, err := p.program.Eval(map[string]interface{}{celObjectVar: obj.Object})
+	if err != nil {
+		return nil, err
+	}
+
+	switch out.Type() {
+	case types.BoolType:
+		if out == types.True {
+			return []string{}, nil
+		}
+
+		message := p.description
+		if message == "" {
+			message = fmt.Sprintf("object violates policy %q", name)
+		}
+		return []string{message}, nil
+	case types.ListType:
+		messages, err := out.ConvertToNative(reflect.TypeOf([]string{}))
+		if err != nil {
+			return nil, errors.New("policy must return a bool or a list of strings")
+		}
+		return messages.([]string), nil
+	default:
+		return nil, errors.Errorf("policy must return a bool or a list of strings, not %s", out.Type().TypeName())
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// policiesDir is the directory custom policies live in.
+	policiesDir = "policies"
+
+	// objectsExtCode is the ExtCode key for the objects being checked.
+	objectsExtCode = "__ksonnet/objects"
+)
+
+// A custom policy is a Jsonnet object with an optional description and
+// severity, and a `violations` function which returns the violation messages
+// for an object:
+//
+//	{
+//	  description: "Objects must have a team label",
+//	  severity: "warning",
+//	  violations(object):: if "team" in object.metadata.labels then [] else ["missing team label"],
+//	}
+var (
+	customMetadataSnippet = `
+local policy = {description: "", severity: ""} + (import %s);
+{description: policy.description, severity: policy.severity}
+`
+	customCheckSnippet = `
+local policy = import %s;
+[policy.violations(object) for object in std.extVar("` + objectsExtCode + `")]
+`
+)
+
+// loadCustom loads the custom policies in the app's policies directory.
+func loadCustom(a app.App) ([]Policy, error) {
+	dir := filepath.Join(a.Root(), policiesDir)
+	exists, err := afero.DirExists(a.Fs(), dir)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	fis, err := afero.ReadDir(a.Fs(), dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []Policy
+	sources := make(map[string]string)
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+
+		var p Policy
+		path := filepath.Join(dir, fi.Name())
+		switch filepath.Ext(fi.Name()) {
+		case ".jsonnet":
+			p, err = loadCustomPolicy(a, path)
+		case ".cel":
+			p, err = loadCELPolicy(a, path)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading policy %s", fi.Name())
+		}
+
+		if source, ok := sources[p.Name]; ok {
+			return nil, errors.Errorf("policy %q is defined by both %s and %s", p.Name, source, p.Source)
+		}
+		sources[p.Name] = p.Source
+
+		policies = append(policies, p)
+	}
+
+	return policies, nil
+}
+
+func loadCustomPolicy(a app.App, path string) (Policy, error) {
+	out, err := evaluate(a, path, fmt.Sprintf(customMetadataSnippet, quote(path)), "[]")
+	if err != nil {
+		return Policy{}, err
+	}
+
+	var m struct {
+		Description string `json:"description"`
+		Severity    string `json:"severity"`
+	}
+	if err = json.Unmarshal([]byte(out), &m); err != nil {
+		return Policy{}, errors.Wrap(err, "decoding policy")
+	}
+
+	return newCustomPolicy(a, path, m.Description, m.Severity, customCheck(a, path))
+}
+
+func loadCELPolicy(a app.App, path string) (Policy, error) {
+	data, err := afero.ReadFile(a.Fs(), path)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	name := policyName(path)
+	p, err := parseCEL(name, string(data))
+	if err != nil {
+		return Policy{}, err
+	}
+
+	return newCustomPolicy(a, path, p.description, p.severity, p.check(name))
+}
+
+func newCustomPolicy(a app.App, path, description, severity string, check checkFn) (Policy, error) {
+	s, err := ParseSeverity(severity)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	rel, err := filepath.Rel(a.Root(), path)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	return Policy{
+		Name:        policyName(path),
+		Description: description,
+		Severity:    s,
+		Source:      rel,
+		check:       check,
+	}, nil
+}
+
+// policyName returns the name of the custom policy at path.
+func policyName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func customCheck(a app.App, path string) checkFn {
+	return func(objects []*unstructured.Unstructured) ([][]string, error) {
+		items := make([]interface{}, 0, len(objects))
+		for _, obj := range objects {
+			items = append(items, obj.Object)
+		}
+
+		data, err := json.Marshal(items)
+		if err != nil {
+			return nil, errors.Wrap(err, "encoding objects")
+		}
+
+		out, err := evaluate(a, path, fmt.Sprintf(customCheckSnippet, quote(path)), string(data))
+		if err != nil {
+			return nil, err
+		}
+
+		var results [][]string
+		if err = json.Unmarshal([]byte(out), &results); err != nil {
+			return nil, errors.New("violations must return an array of strings")
+		}
+
+		return results, nil
+	}
+}
+
+// evaluate evaluates a snippet for the policy at path. Policies can import
+// the app's lib and vendor directories.
+func evaluate(a app.App, path, snippet, objects string) (string, error) {
+	vm := jsonnet.NewVM(jsonnet.AferoImporterOpt(a.Fs()))
+	vm.AddJPath(
+		filepath.Join(a.Root(), "lib"),
+		filepath.Join(a.Root(), "vendor"),
+	)
+	vm.ExtCode(objectsExtCode, objects)
+
+	return vm.EvaluateSnippet(path, snippet)
+}
+
+// quote quotes a string as a Jsonnet string literal.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,224 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package policy checks the objects rendered for an environment against
+// policies. Built-in policies are enabled in the `policies` section of
+// app.yaml. Custom policies are CEL expressions (`.cel`) or Jsonnet files
+// (`.jsonnet`) in the app's `policies` directory, and are always enabled
+// unless app.yaml turns them off.
+package policy
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/utils"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Severity is the severity of a policy.
+type Severity string
+
+const (
+	// SeverityError violations block `apply`.
+	SeverityError Severity = "error"
+	// SeverityWarning violations are reported, but do not block `apply`.
+	SeverityWarning Severity = "warning"
+	// SeverityOff disables a policy.
+	SeverityOff Severity = "off"
+)
+
+// ParseSeverity parses a severity. A blank severity is SeverityError.
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(s) {
+	case SeverityError, SeverityWarning, SeverityOff:
+		return Severity(s), nil
+	case "":
+		return SeverityError, nil
+	default:
+		return "", errors.Errorf("invalid severity %q; valid options are error, warning and off", s)
+	}
+}
+
+// checkFn checks objects against a policy. It returns the violation messages
+// for each object, in the same order as objects.
+type checkFn func(objects []*unstructured.Unstructured) ([][]string, error)
+
+// Policy is a rule objects are checked against.
+type Policy struct {
+	Name        string
+	Description string
+	Severity    Severity
+	// Source is "built-in" or the path of a custom policy.
+	Source string
+
+	check checkFn
+}
+
+// Violation is an object which violates a policy.
+type Violation struct {
+	Policy    string   `json:"policy"`
+	Severity  Severity `json:"severity"`
+	Component string   `json:"component,omitempty"`
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Message   string   `json:"message"`
+}
+
+// Errors returns the number of violations which block `apply`.
+func Errors(violations []Violation) int {
+	var n int
+	for _, v := range violations {
+		if v.Severity == SeverityError {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Checker checks objects against an app's policies.
+type Checker struct {
+	app     app.App
+	envName string
+
+	loadFn func(a app.App) ([]Policy, error)
+}
+
+// New creates an instance of Checker.
+func New(a app.App, envName string) *Checker {
+	return &Checker{
+		app:     a,
+		envName: envName,
+		loadFn:  Load,
+	}
+}
+
+// Check checks objects against the app's policies. Violations are sorted by
+// policy name, and then in the order of objects.
+func (c *Checker) Check(objects []*unstructured.Unstructured) ([]Violation, error) {
+	policies, err := c.loadFn(c.app)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []Violation
+	for _, p := range policies {
+		if p.Severity == SeverityOff {
+			continue
+		}
+
+		var checked []*unstructured.Unstructured
+		for _, obj := range objects {
+			if !isExempt(obj, c.envName, p.Name) {
+				checked = append(checked, obj)
+			}
+		}
+
+		results, err := p.check(checked)
+		if err != nil {
+			return nil, errors.Wrapf(err, "checking policy %q", p.Name)
+		}
+
+		for i, messages := range results {
+			obj := checked[i]
+			for _, message := range messages {
+				violations = append(violations, Violation{
+					Policy:    p.Name,
+					Severity:  p.Severity,
+					Component: obj.GetLabels()[metadata.LabelComponent],
+					Kind:      obj.GetKind(),
+					Name:      utils.FqName(obj),
+					Message:   message,
+				})
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// Load loads the app's built-in and custom policies, sorted by name.
+// Severities in app.yaml override the defaults of the policies.
+func Load(a app.App) ([]Policy, error) {
+	configs, err := a.Policies()
+	if err != nil {
+		return nil, err
+	}
+
+	custom, err := loadCustom(a)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Policy)
+	for _, p := range custom {
+		byName[p.Name] = p
+	}
+
+	for name, config := range configs {
+		p, ok := byName[name]
+		if !ok {
+			if p, ok = builtins[name]; !ok {
+				return nil, errors.Errorf("app.yaml configures unknown policy %q", name)
+			}
+		}
+
+		if config != nil && config.Severity != "" {
+			if p.Severity, err = ParseSeverity(config.Severity); err != nil {
+				return nil, errors.Wrapf(err, "policy %q", name)
+			}
+		}
+
+		byName[name] = p
+	}
+
+	var policies []Policy
+	for _, p := range byName {
+		policies = append(policies, p)
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	return policies, nil
+}
+
+// isExempt reports if an object is exempt from a policy in an environment.
+func isExempt(obj *unstructured.Unstructured, envName, policyName string) bool {
+	value, ok := obj.GetAnnotations()[metadata.AnnotationPolicyExempt]
+	if !ok {
+		return false
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		env := "*"
+		if i := strings.LastIndex(entry, ":"); i >= 0 {
+			env, entry = entry[:i], entry[i+1:]
+		}
+
+		if (env == "*" || env == envName) && (entry == "*" || entry == policyName) {
+			return true
+		}
+	}
+
+	return false
+}
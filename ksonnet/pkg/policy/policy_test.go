@@ -0,0 +1,292 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func deployment(name, image string, annotations map[string]interface{}) *unstructured.Unstructured {
+	metadata := map[string]interface{}{
+		"name":      name,
+		"namespace": "default",
+		"labels": map[string]interface{}{
+			"ksonnet.io/component": name,
+		},
+	}
+	if annotations != nil {
+		metadata["annotations"] = annotations
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1beta1",
+			"kind":       "Deployment",
+			"metadata":   metadata,
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"hostNetwork": true,
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  name,
+								"image": image,
+								"resources": map[string]interface{}{
+									"limits": map[string]interface{}{"cpu": "100m"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		c := New(a, "prod")
+		c.loadFn = func(app.App) ([]Policy, error) {
+			p := builtins[PolicyNoLatestImage]
+			limits := builtins[PolicyRequireResourceLimits]
+			limits.Severity = SeverityWarning
+			off := builtins[PolicyNoHostNetwork]
+			off.Severity = SeverityOff
+			return []Policy{p, limits, off}, nil
+		}
+
+		objects := []*unstructured.Unstructured{
+			deployment("guestbook", "nginx", nil),
+			deployment("redis", "redis:4.0", nil),
+			deployment("exempt", "nginx:latest", map[string]interface{}{
+				"policy.ksonnet.io/exempt": "no-latest-image, dev:require-resource-limits",
+			}),
+			deployment("exempt-prod", "nginx:latest", map[string]interface{}{
+				"policy.ksonnet.io/exempt": "prod:*",
+			}),
+		}
+
+		violations, err := c.Check(objects)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, Report(&buf, table.FormatTable, violations))
+		test.AssertOutput(t, "check.txt", buf.String())
+
+		assert.Equal(t, 1, Errors(violations))
+	})
+}
+
+func TestLoad(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		test.StageDir(t, fs, "app", "/app")
+
+		a.On("Policies").Return(app.PolicyConfigs{
+			PolicyNoLatestImage: &app.PolicyConfig{Severity: "warning"},
+			PolicyNoHostNetwork: nil,
+			"defaults":          &app.PolicyConfig{Severity: "off"},
+		}, nil)
+
+		policies, err := Load(a)
+		require.NoError(t, err)
+
+		type summary struct {
+			name, severity, source, description string
+		}
+
+		var got []summary
+		for _, p := range policies {
+			got = append(got, summary{p.Name, string(p.Severity), p.Source, p.Description})
+		}
+
+		expected := []summary{
+			{"defaults", "off", "policies/defaults.jsonnet", ""},
+			{"min-replicas", "warning", "policies/min-replicas.cel", "Deployments must run more than one replica"},
+			{"no-host-network", "error", "built-in", "Pods must not use the host's network"},
+			{"no-latest-image", "warning", "built-in", "Container images must be pinned to a tag other than latest"},
+			{"team-label", "warning", "policies/team-label.jsonnet", "Objects must have a team label"},
+		}
+		require.Equal(t, expected, got)
+	})
+}
+
+func TestLoad_unknown_policy(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		a.On("Policies").Return(app.PolicyConfigs{"unknown": nil}, nil)
+
+		_, err := Load(a)
+		require.Error(t, err)
+	})
+}
+
+func TestParseSeverity(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected Severity
+		isErr    bool
+	}{
+		{in: "", expected: SeverityError},
+		{in: "error", expected: SeverityError},
+		{in: "warning", expected: SeverityWarning},
+		{in: "off", expected: SeverityOff},
+		{in: "fatal", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseSeverity(tc.in)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func Test_isLatest(t *testing.T) {
+	cases := []struct {
+		image    string
+		expected bool
+	}{
+		{image: "nginx", expected: true},
+		{image: "nginx:latest", expected: true},
+		{image: "nginx:1.15", expected: false},
+		{image: "registry:5000/nginx", expected: true},
+		{image: "registry:5000/nginx:1.15", expected: false},
+		{image: "nginx@sha256:abc", expected: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.image, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isLatest(tc.image))
+		})
+	}
+}
+
+func Test_customCheck(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		test.StageDir(t, fs, "app", "/app")
+
+		objects := []*unstructured.Unstructured{
+			deployment("guestbook", "nginx", nil),
+			deployment("redis", "redis", nil),
+		}
+		objects[0].SetLabels(map[string]string{"team": ""})
+		objects[1].SetLabels(map[string]string{"team": "data"})
+
+		check := customCheck(a, "/app/policies/team-label.jsonnet")
+		results, err := check(objects)
+		require.NoError(t, err)
+
+		expected := [][]string{{"object does not have a team label"}, {}}
+		require.Equal(t, expected, results)
+	})
+}
+
+func Test_loadCELPolicy(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		test.StageDir(t, fs, "app", "/app")
+
+		p, err := loadCELPolicy(a, "/app/policies/min-replicas.cel")
+		require.NoError(t, err)
+
+		objects := []*unstructured.Unstructured{
+			deployment("guestbook", "nginx", nil),
+			deployment("redis", "redis", nil),
+		}
+		require.NoError(t, unstructured.SetNestedField(objects[1].Object, int64(3), "spec", "replicas"))
+
+		results, err := p.check(objects)
+		require.NoError(t, err)
+
+		expected := [][]string{{"Deployments must run more than one replica"}, {}}
+		require.Equal(t, expected, results)
+	})
+}
+
+func Test_parseCEL(t *testing.T) {
+	cases := []struct {
+		name      string
+		src       string
+		expected  []string
+		isErr     bool
+		isEvalErr bool
+	}{
+		{
+			name:     "passes",
+			src:      `object.kind == "Deployment"`,
+			expected: []string{},
+		},
+		{
+			name:     "violation without a description",
+			src:      `!object.spec.template.spec.hostNetwork`,
+			expected: []string{`object violates policy "test"`},
+		},
+		{
+			name:     "violation messages",
+			src:      `object.spec.template.spec.containers.filter(c, !c.image.contains(":")).map(c, c.name + " is not pinned")`,
+			expected: []string{"guestbook is not pinned"},
+		},
+		{
+			name:  "invalid expression",
+			src:   `object.kind ==`,
+			isErr: true,
+		},
+		{
+			name:      "invalid result",
+			src:       `object.kind`,
+			isEvalErr: true,
+		},
+		{
+			name:      "missing field",
+			src:       `object.spec.replicas > 1`,
+			isEvalErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := parseCEL("test", tc.src)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			got, err := p.violations("test", deployment("guestbook", "nginx", nil))
+			if tc.isEvalErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
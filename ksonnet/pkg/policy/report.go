@@ -0,0 +1,35 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package policy
+
+import (
+	"io"
+
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+)
+
+// Report writes violations to w as a table or JSON.
+func Report(w io.Writer, format table.Format, violations []Violation) error {
+	t := table.New("policyViolations", w)
+	t.SetFormat(format)
+	t.SetHeader([]string{"severity", "policy", "component", "kind", "name", "message"})
+
+	for _, v := range violations {
+		t.Append([]string{string(v.Severity), v.Policy, v.Component, v.Kind, v.Name, v.Message})
+	}
+
+	return t.Render()
+}
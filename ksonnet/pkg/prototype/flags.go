@@ -34,6 +34,18 @@ func (e *FlagDefinitionError) Error() string {
 	return fmt.Sprintf("unable to define flag %q", e.name)
 }
 
+// MissingParametersError is an error returned when required prototype
+// parameters were not supplied.
+type MissingParametersError struct {
+	Prototype string
+	Params    ParamSchemas
+}
+
+func (e *MissingParametersError) Error() string {
+	return fmt.Sprintf("failed to instantiate prototype %q. The following required parameters are missing:\n%s",
+		e.Prototype, e.Params.PrettyString(""))
+}
+
 // BindFlags creates a flag set using a prototype's parameters.
 func BindFlags(p *Prototype) (fs *pflag.FlagSet, err error) {
 	fs = pflag.NewFlagSet("prototype-flags", pflag.ContinueOnError)
@@ -81,7 +93,9 @@ func ExtractParameters(fs afero.Fs, p *Prototype, flags *pflag.FlagSet) (map[str
 	}
 
 	if valuesFilePath != "" {
-		updateValuesFromValuesFile(fs, values, valuesFilePath)
+		if err = updateValuesFromValuesFile(fs, p, values, valuesFilePath); err != nil {
+			return nil, err
+		}
 	}
 
 	if err = checkMissingParameters(p, values, required); err != nil {
@@ -100,6 +114,12 @@ func updateValuesFromFlag(p *Prototype, values map[string]string, param *ParamSc
 		return errors.Errorf("prototype %q has multiple parameters with name %q", p.Name, param.Name)
 	}
 
+	if val != "" || param.Default != nil {
+		if err = param.Validate(val); err != nil {
+			return err
+		}
+	}
+
 	quoted, err := param.Quote(val)
 	if err != nil {
 		return err
@@ -110,7 +130,7 @@ func updateValuesFromFlag(p *Prototype, values map[string]string, param *ParamSc
 }
 
 // updateValuesFromValuesFile updates values from a values file. It mutates the map which is passed in.
-func updateValuesFromValuesFile(fs afero.Fs, values map[string]string, valuesFilePath string) error {
+func updateValuesFromValuesFile(fs afero.Fs, p *Prototype, values map[string]string, valuesFilePath string) error {
 	f, err := fs.Open(valuesFilePath)
 	if err != nil {
 		return errors.Wrap(err, "opening values file")
@@ -133,6 +153,17 @@ func updateValuesFromValuesFile(fs afero.Fs, values map[string]string, valuesFil
 		if err != nil {
 			return errors.Wrapf(err, "retrieving %q from values file", k)
 		}
+
+		for _, param := range p.Params {
+			if param.Name != k {
+				continue
+			}
+
+			if err = param.validateJSON(v); err != nil {
+				return errors.Wrapf(err, "validating %q from values file", k)
+			}
+		}
+
 		values[k] = v
 	}
 
@@ -147,14 +178,17 @@ func checkMissingParameters(p *Prototype, values map[string]string, required map
 		keys = append(keys, k)
 	}
 
-	for k, v := range required {
-		if strings.InSlice(k, keys) && values[k] == `""` {
-			missingRequired = append(missingRequired, v)
+	// Walk the prototype's params so missing params are reported in the
+	// order they were declared.
+	for _, param := range p.Params {
+		k := param.Name
+		if _, ok := required[k]; ok && strings.InSlice(k, keys) && values[k] == `""` {
+			missingRequired = append(missingRequired, param)
 		}
 	}
 
 	if len(missingRequired) > 0 {
-		return errors.Errorf("failed to instantiate prototype %q. The following required parameters are missing:\n%s", p.Name, missingRequired.PrettyString(""))
+		return &MissingParametersError{Prototype: p.Name, Params: missingRequired}
 	}
 
 	return nil
@@ -62,6 +62,15 @@ func JsonnetParse(src string) (*Prototype, error) {
 		}
 	}
 
+	// The body is Jsonnet unless the prototype declares otherwise.
+	switch s.Template.bodyType {
+	case YAML:
+		s.Template.YAMLBody, s.Template.JsonnetBody = s.Template.JsonnetBody, nil
+	case JSON:
+		s.Template.JSONBody, s.Template.JsonnetBody = s.Template.JsonnetBody, nil
+	}
+	s.Template.bodyType = ""
+
 	return s, nil
 }
 
@@ -200,6 +209,10 @@ func newDirective(src string) directive {
 		return paramDirective(parts[1])
 	case "optionalParam":
 		return optParamDirective(parts[1])
+	case "templateEngine":
+		return templateEngineDirective(parts[1])
+	case "templateType":
+		return templateTypeDirective(parts[1])
 	default:
 		return func(*Prototype) error {
 			return errors.Errorf("unknown prototype directive %q", parts[0])
@@ -235,11 +248,35 @@ func shortDescriptionDirective(description string) func(*Prototype) error {
 	}
 }
 
+func templateEngineDirective(src string) func(*Prototype) error {
+	return func(s *Prototype) error {
+		engine, err := ParseTemplateEngine(src)
+		if err != nil {
+			return errors.Wrap(err, "invalid templateEngine tag")
+		}
+
+		s.Template.Engine = engine
+		return nil
+	}
+}
+
+func templateTypeDirective(src string) func(*Prototype) error {
+	return func(s *Prototype) error {
+		t, err := ParseTemplateType(src)
+		if err != nil {
+			return errors.Wrap(err, "invalid templateType tag")
+		}
+
+		s.Template.bodyType = t
+		return nil
+	}
+}
+
 func paramDirective(src string) func(*Prototype) error {
 	return func(s *Prototype) error {
 		split := strings.SplitN(src, " ", 3)
 		if len(split) < 3 {
-			return fmt.Errorf("param fields must have '<name> <type> <description>, but got:\n%s", src)
+			return fmt.Errorf("param fields must have '<name> <type> [options] <description>, but got:\n%s", src)
 		}
 
 		pt, err := parseParamType(split[1])
@@ -247,13 +284,20 @@ func paramDirective(src string) func(*Prototype) error {
 			return errors.Wrap(err, "invalid param tag")
 		}
 
-		s.Params = append(s.Params, &ParamSchema{
-			Name:        split[0],
-			Alias:       &split[0],
-			Description: split[2],
-			Default:     nil,
-			Type:        pt,
-		})
+		ps := &ParamSchema{
+			Name:    split[0],
+			Alias:   &split[0],
+			Default: nil,
+			Type:    pt,
+		}
+
+		description, err := parseParamOptions(ps, split[2])
+		if err != nil {
+			return err
+		}
+		ps.Description = description
+
+		s.Params = append(s.Params, ps)
 
 		return nil
 	}
@@ -261,9 +305,11 @@ func paramDirective(src string) func(*Prototype) error {
 
 func optParamDirective(src string) func(*Prototype) error {
 	return func(s *Prototype) error {
-		split := strings.SplitN(src, " ", 4)
-		if len(split) < 4 {
-			return fmt.Errorf("optional param fields must have '<name> <type> <default-val> <description> (<default-val> currently cannot contain spaces), but got:\n%s", src)
+		usage := fmt.Errorf("optional param fields must have '<name> <type> [options] <default-val> <description> (<default-val> currently cannot contain spaces), but got:\n%s", src)
+
+		split := strings.SplitN(src, " ", 3)
+		if len(split) < 3 {
+			return usage
 		}
 
 		pt, err := parseParamType(split[1])
@@ -271,14 +317,62 @@ func optParamDirective(src string) func(*Prototype) error {
 			return err
 		}
 
-		s.Params = append(s.Params, &ParamSchema{
-			Name:        split[0],
-			Alias:       &split[0],
-			Default:     &split[2],
-			Description: split[3],
-			Type:        pt,
-		})
+		ps := &ParamSchema{
+			Name:  split[0],
+			Alias: &split[0],
+			Type:  pt,
+		}
+
+		rest, err := parseParamOptions(ps, split[2])
+		if err != nil {
+			return err
+		}
+
+		fields := strings.SplitN(rest, " ", 2)
+		if len(fields) < 2 {
+			return usage
+		}
+
+		ps.Default = &fields[0]
+		ps.Description = fields[1]
+
+		if len(ps.Enum) > 0 || ps.Pattern != "" {
+			if err := ps.Validate(*ps.Default); err != nil {
+				return errors.Wrapf(err, "invalid default for param %q", ps.Name)
+			}
+		}
+
+		s.Params = append(s.Params, ps)
 
 		return nil
 	}
 }
+
+// parseParamOptions consumes the `key=value` options which may follow a
+// param's type, e.g. `enum=TCP,UDP`, `pattern=^[a-z]+$` or `example=nginx`.
+// Option values cannot contain spaces. It returns the remainder of the directive.
+func parseParamOptions(ps *ParamSchema, src string) (string, error) {
+	for {
+		split := strings.SplitN(src, " ", 2)
+		if len(split) < 2 {
+			return src, nil
+		}
+
+		switch {
+		case strings.HasPrefix(split[0], "enum="):
+			ps.Enum = strings.Split(strings.TrimPrefix(split[0], "enum="), ",")
+		case strings.HasPrefix(split[0], "pattern="):
+			pattern := strings.TrimPrefix(split[0], "pattern=")
+			if _, err := regexp.Compile(pattern); err != nil {
+				return "", errors.Wrapf(err, "invalid pattern for param %q", ps.Name)
+			}
+			ps.Pattern = pattern
+		case strings.HasPrefix(split[0], "example="):
+			ps.Example = strings.TrimPrefix(split[0], "example=")
+		default:
+			return src, nil
+		}
+
+		src = split[1]
+	}
+}
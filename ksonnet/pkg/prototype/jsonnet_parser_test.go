@@ -45,6 +45,10 @@ func TestJsonnetParse(t *testing.T) {
 			name: "parse comments",
 			file: "prototype3",
 		},
+		{
+			name: "go template with a yaml body",
+			file: "prototype4",
+		},
 	}
 
 	for _, tc := range cases {
@@ -88,6 +92,16 @@ func Test_newDirective(t *testing.T) {
 			src:   "unknown invalid",
 			isErr: true,
 		},
+		{
+			name:  "unknown template engine",
+			src:   "templateEngine mustache",
+			isErr: true,
+		},
+		{
+			name:  "unknown template type",
+			src:   "templateType toml",
+			isErr: true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -125,6 +139,38 @@ func Test_paramDirective(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with options",
+			src:  "name string enum=a,b pattern=^[a-z]+$ Name of the service",
+			expected: ParamSchemas{
+				{
+					Name:        "name",
+					Alias:       strings.Ptr("name"),
+					Description: "Name of the service",
+					Type:        String,
+					Enum:        []string{"a", "b"},
+					Pattern:     "^[a-z]+$",
+				},
+			},
+		},
+		{
+			name: "with example",
+			src:  "image string example=nginx:1.15 Container image to deploy",
+			expected: ParamSchemas{
+				{
+					Name:        "image",
+					Alias:       strings.Ptr("image"),
+					Description: "Container image to deploy",
+					Type:        String,
+					Example:     "nginx:1.15",
+				},
+			},
+		},
+		{
+			name:  "invalid pattern",
+			src:   "name string pattern=[ Name of the service",
+			isErr: true,
+		},
 		{
 			name:  "invalid type",
 			src:   "name invalid Name of the service",
@@ -174,6 +220,30 @@ func Test_optParamDirective(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with options",
+			src:  "protocol string enum=TCP,UDP TCP Protocol to use",
+			expected: ParamSchemas{
+				{
+					Name:        "protocol",
+					Alias:       strings.Ptr("protocol"),
+					Description: "Protocol to use",
+					Default:     strings.Ptr("TCP"),
+					Type:        String,
+					Enum:        []string{"TCP", "UDP"},
+				},
+			},
+		},
+		{
+			name:  "default does not satisfy options",
+			src:   "protocol string enum=TCP,UDP SCTP Protocol to use",
+			isErr: true,
+		},
+		{
+			name:  "missing description",
+			src:   "name string name",
+			isErr: true,
+		},
 		{
 			name:  "invalid type",
 			src:   "name invalid Name of the service",
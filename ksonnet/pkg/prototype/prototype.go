@@ -16,12 +16,17 @@
 package prototype
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/blang/semver"
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
 	"github.com/ksonnet/ksonnet/pkg/util/version"
 	"github.com/pkg/errors"
 )
@@ -39,6 +44,8 @@ const (
 	shortDescriptionTag = "@shortDescription"
 	paramTag            = "@param"
 	optParamTag         = "@optionalParam"
+	templateEngineTag   = "@templateEngine"
+	templateTypeTag     = "@templateType"
 )
 
 // Prototype is the JSON-serializable representation of a prototype
@@ -57,6 +64,10 @@ type Prototype struct {
 }
 
 func (s *Prototype) validate() error {
+	if _, err := ParseTemplateEngine(string(s.Template.Engine)); err != nil {
+		return err
+	}
+
 	compatVer, _ := semver.Make(DefaultAPIVersion)
 	ver, err := semver.Make(s.APIVersion)
 	if err != nil {
@@ -143,11 +154,41 @@ func ParseTemplateType(t string) (TemplateType, error) {
 	}
 }
 
+// TemplateEngine is the engine which substitutes parameters into a
+// prototype's template.
+type TemplateEngine string
+
+const (
+	// SnippetEngine substitutes parameters with TextMate snippet markers, e.g.
+	// `${name}`. Jsonnet templates import parameters with `param://`. It is
+	// the default engine.
+	SnippetEngine TemplateEngine = "snippet"
+
+	// GoTemplateEngine renders templates with Go's text/template.
+	GoTemplateEngine TemplateEngine = "gotemplate"
+)
+
+// ParseTemplateEngine attempts to parse a string as a `TemplateEngine`.
+func ParseTemplateEngine(e string) (TemplateEngine, error) {
+	switch strings.ToLower(e) {
+	case "", "snippet":
+		return SnippetEngine, nil
+	case "gotemplate":
+		return GoTemplateEngine, nil
+	default:
+		return "", fmt.Errorf("Unrecognized template engine '%s'; must be one of: [snippet, gotemplate]", e)
+	}
+}
+
 // SnippetSchema is the JSON-serializable representation of the TextMate snippet
 // specification, as implemented by the Language Server Protocol.
 type SnippetSchema struct {
 	Prefix string `json:"prefix"`
 
+	// Engine is the engine which renders the template. The snippet engine is
+	// used if it is empty.
+	Engine TemplateEngine `json:"engine,omitempty"`
+
 	// Description describes what the prototype does.
 	Description string `json:"description"`
 
@@ -160,6 +201,10 @@ type SnippetSchema struct {
 	JSONBody    []string `json:"jsonBody"`
 	YAMLBody    []string `json:"yamlBody"`
 	JsonnetBody []string `json:"jsonnetBody"`
+
+	// bodyType is the type of the body of a prototype declared with
+	// directives. It is only set while the prototype is being parsed.
+	bodyType TemplateType
 }
 
 // Body attempts to retrieve the template body associated with some
@@ -184,6 +229,17 @@ func (schema *SnippetSchema) Body(t TemplateType) (template []string, err error)
 	return
 }
 
+// DefaultTemplateType returns the template type used when none is requested.
+// It is Jsonnet, unless the prototype only has templates of other types.
+func (schema *SnippetSchema) DefaultTemplateType() TemplateType {
+	available := schema.AvailableTemplates()
+	if len(available) == 0 || len(schema.JsonnetBody) != 0 {
+		return Jsonnet
+	}
+
+	return available[0]
+}
+
 // AvailableTemplates returns the list of available `TemplateType`s this
 // prototype implements.
 func (schema *SnippetSchema) AvailableTemplates() (ts []TemplateType) {
@@ -210,6 +266,139 @@ type ParamSchema struct {
 	Description string    `json:"description"`
 	Default     *string   `json:"default"` // `nil` only if the parameter is optional.
 	Type        ParamType `json:"type"`
+
+	// Enum lists the values the parameter may take. Optional.
+	Enum []string `json:"enum,omitempty"`
+	// Pattern is a regular expression the parameter must match. Optional.
+	Pattern string `json:"pattern,omitempty"`
+	// Example is a sample value for the parameter. It is used when testing
+	// prototypes. Optional.
+	Example string `json:"example,omitempty"`
+}
+
+// SampleValue returns a value which can be used for the parameter when
+// testing a prototype. It is the parameter's example if it has one, then its
+// default, then the first value of its enum. Otherwise, a placeholder value
+// for the parameter's type is returned.
+func (ps *ParamSchema) SampleValue() string {
+	switch {
+	case ps.Example != "":
+		return ps.Example
+	case ps.Default != nil:
+		return *ps.Default
+	case len(ps.Enum) > 0:
+		return ps.Enum[0]
+	}
+
+	switch ps.Type {
+	case Number:
+		return "1"
+	case Object:
+		return "{}"
+	case Array:
+		return "[]"
+	default:
+		return ps.Name
+	}
+}
+
+// Validate checks a parameter value, as it would be supplied on the command
+// line, against the parameter's type and its enum and pattern constraints.
+func (ps *ParamSchema) Validate(value string) error {
+	switch ps.Type {
+	case Number:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return ps.typeError(value)
+		}
+	case Object, Array:
+		node, err := jsonnet.ParseNode(ps.Name, value)
+		if err != nil {
+			return errors.Wrapf(err, "parameter %q is not valid Jsonnet", ps.Name)
+		}
+
+		if t := literalType(node); t != "" && t != ps.Type.String() {
+			return ps.typeError(value)
+		}
+	}
+
+	return ps.checkConstraints(value)
+}
+
+// validateJSON checks a parameter value which has already been evaluated to
+// JSON, e.g. a value read from a values file.
+func (ps *ParamSchema) validateJSON(value string) error {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return errors.Wrapf(err, "decoding value for parameter %q", ps.Name)
+	}
+
+	if s, ok := decoded.(string); ok {
+		if ps.Type != String && ps.Type != NumberOrString {
+			return ps.typeError(value)
+		}
+		return ps.checkConstraints(s)
+	}
+
+	if ps.Type == String {
+		return ps.typeError(value)
+	}
+
+	return ps.Validate(value)
+}
+
+func (ps *ParamSchema) checkConstraints(value string) error {
+	if len(ps.Enum) > 0 {
+		found := false
+		for _, e := range ps.Enum {
+			if e == value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return errors.Errorf("parameter %q must be one of [%s], but got %q",
+				ps.Name, strings.Join(ps.Enum, ", "), value)
+		}
+	}
+
+	if ps.Pattern != "" {
+		re, err := regexp.Compile(ps.Pattern)
+		if err != nil {
+			return errors.Wrapf(err, "invalid pattern for parameter %q", ps.Name)
+		}
+
+		if !re.MatchString(value) {
+			return errors.Errorf("parameter %q must match %q, but got %q", ps.Name, ps.Pattern, value)
+		}
+	}
+
+	return nil
+}
+
+func (ps *ParamSchema) typeError(value string) error {
+	return errors.Errorf("parameter %q must be of type %s, but got %s", ps.Name, ps.Type.String(), value)
+}
+
+// literalType returns the type of a literal Jsonnet node. It returns an empty
+// string for nodes whose type can only be known after evaluation.
+func literalType(node ast.Node) string {
+	switch node.(type) {
+	case *ast.Object, *astext.Object, *ast.ObjectComp:
+		return "object"
+	case *ast.Array, *ast.ArrayComp:
+		return "array"
+	case *ast.LiteralNumber:
+		return "number"
+	case *ast.LiteralString:
+		return "string"
+	case *ast.LiteralBoolean:
+		return "boolean"
+	case *ast.LiteralNull:
+		return "null"
+	default:
+		return ""
+	}
 }
 
 // Quote will parse a prototype parameter and quote it appropriately, so that it
@@ -276,10 +465,17 @@ func (ps ParamSchemas) PrettyString(prefix string) string {
 			info = fmt.Sprintf(" [type: %s]", p.Type.String())
 		}
 
+		if len(p.Enum) > 0 {
+			info += fmt.Sprintf(" [one of: %s]", strings.Join(p.Enum, ", "))
+		}
+		if p.Pattern != "" {
+			info += fmt.Sprintf(" [pattern: %s]", p.Pattern)
+		}
+
 		// NOTE: If we don't add 1 here, the longest line will look like:
 		// `--flag=<flag>Description is here.`
 		space := strings.Repeat(" ", max-len(flag)+1)
-		pretty := fmt.Sprintf(prefix + flag + space + p.Description + info)
+		pretty := prefix + flag + space + p.Description + info
 		prettyFlags = append(prettyFlags, pretty)
 	}
 
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/blang/semver"
+	strutil "github.com/ksonnet/ksonnet/pkg/util/strings"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -247,3 +248,198 @@ func TestParseTemplateType(t *testing.T) {
 		})
 	}
 }
+
+func TestParamSchema_Validate(t *testing.T) {
+	cases := []struct {
+		name   string
+		schema ParamSchema
+		value  string
+		isErr  bool
+	}{
+		{
+			name:   "number",
+			schema: ParamSchema{Name: "port", Type: Number},
+			value:  "80",
+		},
+		{
+			name:   "invalid number",
+			schema: ParamSchema{Name: "port", Type: Number},
+			value:  "eighty",
+			isErr:  true,
+		},
+		{
+			name:   "object",
+			schema: ParamSchema{Name: "labels", Type: Object},
+			value:  `{app: "nginx"}`,
+		},
+		{
+			name:   "array given for object",
+			schema: ParamSchema{Name: "labels", Type: Object},
+			value:  `["nginx"]`,
+			isErr:  true,
+		},
+		{
+			name:   "enum",
+			schema: ParamSchema{Name: "protocol", Type: String, Enum: []string{"TCP", "UDP"}},
+			value:  "UDP",
+		},
+		{
+			name:   "value not in enum",
+			schema: ParamSchema{Name: "protocol", Type: String, Enum: []string{"TCP", "UDP"}},
+			value:  "SCTP",
+			isErr:  true,
+		},
+		{
+			name:   "pattern",
+			schema: ParamSchema{Name: "name", Type: String, Pattern: "^[a-z]+$"},
+			value:  "nginx",
+		},
+		{
+			name:   "value does not match pattern",
+			schema: ParamSchema{Name: "name", Type: String, Pattern: "^[a-z]+$"},
+			value:  "Nginx",
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.schema.Validate(tc.value)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestParamSchemas_PrettyString(t *testing.T) {
+	defaultPercent := "50%"
+	ps := ParamSchemas{
+		{Name: "name", Type: String, Description: "Name of the app"},
+		{Name: "ratio", Type: String, Description: "Traffic split in %", Default: &defaultPercent, Pattern: `^\d{1,3}%$`},
+	}
+
+	expected := "  --name=<name>   Name of the app [type: string]\n" +
+		"  --ratio=<ratio> Traffic split in % [default: 50%, type: string] [pattern: ^\\d{1,3}%$]"
+	require.Equal(t, expected, ps.PrettyString("  "))
+}
+
+func TestParamSchema_SampleValue(t *testing.T) {
+	cases := []struct {
+		name     string
+		schema   ParamSchema
+		expected string
+	}{
+		{
+			name:     "example",
+			schema:   ParamSchema{Name: "image", Type: String, Example: "nginx", Default: strutil.Ptr("redis")},
+			expected: "nginx",
+		},
+		{
+			name:     "default",
+			schema:   ParamSchema{Name: "image", Type: String, Default: strutil.Ptr("redis")},
+			expected: "redis",
+		},
+		{
+			name:     "enum",
+			schema:   ParamSchema{Name: "protocol", Type: String, Enum: []string{"TCP", "UDP"}},
+			expected: "TCP",
+		},
+		{
+			name:     "number",
+			schema:   ParamSchema{Name: "replicas", Type: Number},
+			expected: "1",
+		},
+		{
+			name:     "object",
+			schema:   ParamSchema{Name: "labels", Type: Object},
+			expected: "{}",
+		},
+		{
+			name:     "array",
+			schema:   ParamSchema{Name: "ports", Type: Array},
+			expected: "[]",
+		},
+		{
+			name:     "string",
+			schema:   ParamSchema{Name: "name", Type: String},
+			expected: "name",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.schema.SampleValue())
+		})
+	}
+}
+
+func TestParseTemplateEngine(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected TemplateEngine
+		isErr    bool
+	}{
+		{
+			name:     "",
+			expected: SnippetEngine,
+		},
+		{
+			name:     "snippet",
+			expected: SnippetEngine,
+		},
+		{
+			name:     "gotemplate",
+			expected: GoTemplateEngine,
+		},
+		{
+			name:  "mustache",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := ParseTemplateEngine(tc.name)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, e)
+		})
+	}
+}
+
+func TestSnippetSchema_DefaultTemplateType(t *testing.T) {
+	cases := []struct {
+		name     string
+		schema   SnippetSchema
+		expected TemplateType
+	}{
+		{
+			name:     "jsonnet",
+			schema:   SnippetSchema{JsonnetBody: []string{"{}"}, YAMLBody: []string{"{}"}},
+			expected: Jsonnet,
+		},
+		{
+			name:     "yaml only",
+			schema:   SnippetSchema{YAMLBody: []string{"{}"}},
+			expected: YAML,
+		},
+		{
+			name:     "no templates",
+			expected: Jsonnet,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.schema.DefaultTemplateType())
+		})
+	}
+}
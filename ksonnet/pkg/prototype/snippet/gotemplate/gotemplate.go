@@ -0,0 +1,126 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package gotemplate renders prototype templates with Go's text/template.
+// Templates have access to the sprig functions, e.g. `default`, `quote` and
+// `toJson`, except `env` and `expandenv`, and to `toYaml`. Parameters are available as `.Params`:
+//
+//	ports:
+//	{{- range .Params.ports }}
+//	  - containerPort: {{ . }}
+//	{{- end }}
+package gotemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/prototype/snippet"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+)
+
+type goTemplate struct {
+	name string
+	t    *template.Template
+}
+
+var _ snippet.Template = (*goTemplate)(nil)
+
+// Parse parses a Go template.
+func Parse(name, text string) (snippet.Template, error) {
+	t, err := template.New(name).
+		Funcs(funcMap()).
+		Option("missingkey=error").
+		Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing template %q", name)
+	}
+
+	return &goTemplate{name: name, t: t}, nil
+}
+
+// Evaluate renders the template. Values are Jsonnet literals, as they are
+// for snippets, and are decoded before they are passed to the template.
+func (gt *goTemplate) Evaluate(values map[string]string) (string, error) {
+	params := make(map[string]interface{})
+	for k, v := range values {
+		decoded, err := decode(k, v)
+		if err != nil {
+			return "", err
+		}
+
+		params[k] = decoded
+	}
+
+	data := map[string]interface{}{
+		"Params": params,
+	}
+
+	var buf bytes.Buffer
+	if err := gt.t.Execute(&buf, data); err != nil {
+		return "", errors.Wrapf(err, "rendering template %q", gt.name)
+	}
+
+	return buf.String(), nil
+}
+
+// decode decodes a Jsonnet literal. Values which are not valid JSON, e.g.
+// objects with unquoted keys, are evaluated.
+func decode(name, value string) (interface{}, error) {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+		return decoded, nil
+	}
+
+	vm := jsonnet.NewVM()
+	evaluated, err := vm.EvaluateSnippet(name, value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "evaluating value of %q", name)
+	}
+
+	if err = json.Unmarshal([]byte(evaluated), &decoded); err != nil {
+		return nil, errors.Wrapf(err, "decoding value of %q", name)
+	}
+
+	return decoded, nil
+}
+
+func funcMap() template.FuncMap {
+	m := sprig.TxtFuncMap()
+	m["toYaml"] = toYAML
+
+	// Like Helm, don't let prototypes read the environment of the user who
+	// generates a component.
+	delete(m, "env")
+	delete(m, "expandenv")
+
+	return m
+}
+
+// toYAML marshals a value to YAML. Like sprig's `toJson`, it returns an empty
+// string if the value can't be marshalled.
+func toYAML(v interface{}) string {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(string(b), "\n")
+}
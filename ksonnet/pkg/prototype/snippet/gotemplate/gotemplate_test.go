@@ -0,0 +1,103 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package gotemplate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		values   map[string]string
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "string",
+			template: "name: {{ .Params.name }}",
+			values:   map[string]string{"name": `"nginx"`},
+			expected: "name: nginx",
+		},
+		{
+			name:     "conditional",
+			template: "{{ if gt .Params.replicas 1.0 }}replicated{{ else }}single{{ end }}",
+			values:   map[string]string{"replicas": "3"},
+			expected: "replicated",
+		},
+		{
+			name:     "loop over array",
+			template: "{{ range .Params.ports }}- {{ . }}\n{{ end }}",
+			values:   map[string]string{"ports": "[80, 443]"},
+			expected: "- 80\n- 443\n",
+		},
+		{
+			name:     "jsonnet object",
+			template: `{{ .Params.labels | toYaml }}`,
+			values:   map[string]string{"labels": `{app: "nginx", tier: "web"}`},
+			expected: "app: nginx\ntier: web",
+		},
+		{
+			name:     "sprig functions",
+			template: `{{ .Params.name | upper | quote }}`,
+			values:   map[string]string{"name": `"nginx"`},
+			expected: `"NGINX"`,
+		},
+		{
+			name:     "missing param",
+			template: "{{ .Params.image }}",
+			values:   map[string]string{"name": `"nginx"`},
+			isErr:    true,
+		},
+		{
+			name:     "invalid value",
+			template: "{{ .Params.labels }}",
+			values:   map[string]string{"labels": "{"},
+			isErr:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tm, err := Parse(tc.name, tc.template)
+			require.NoError(t, err)
+
+			got, err := tm.Evaluate(tc.values)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	_, err := Parse("invalid", "{{ .Params.name ")
+	require.Error(t, err)
+}
+
+func TestParse_environmentFunctions(t *testing.T) {
+	for _, text := range []string{`{{ env "HOME" }}`, `{{ expandenv "$HOME" }}`} {
+		_, err := Parse("env", text)
+		require.Error(t, err, text)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package unittest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// FormatTAP reports results using the Test Anything Protocol.
+	FormatTAP = "tap"
+	// FormatJUnit reports results as JUnit XML.
+	FormatJUnit = "junit"
+)
+
+// Report writes suites to w in format.
+func Report(w io.Writer, format string, suites []Suite) error {
+	switch format {
+	case FormatTAP:
+		return reportTAP(w, suites)
+	case FormatJUnit:
+		return reportJUnit(w, suites)
+	default:
+		return errors.Errorf("invalid output format %q; valid options are %s and %s", format, FormatTAP, FormatJUnit)
+	}
+}
+
+// reportTAP writes suites using version 13 of the Test Anything Protocol.
+// Failure messages are written as YAML diagnostics.
+func reportTAP(w io.Writer, suites []Suite) error {
+	var total int
+	for _, s := range suites {
+		if s.Err != nil {
+			total++
+			continue
+		}
+		total += len(s.Cases)
+	}
+
+	fmt.Fprintln(w, "TAP version 13")
+	fmt.Fprintf(w, "1..%d\n", total)
+
+	n := 0
+	for _, s := range suites {
+		if s.Err != nil {
+			n++
+			fmt.Fprintf(w, "not ok %d - %s\n", n, s.Path)
+			writeTAPDiagnostic(w, s.Err.Error())
+			continue
+		}
+
+		for _, c := range s.Cases {
+			n++
+			if c.Passed() {
+				fmt.Fprintf(w, "ok %d - %s: %s\n", n, s.Path, c.Name)
+				continue
+			}
+
+			fmt.Fprintf(w, "not ok %d - %s: %s\n", n, s.Path, c.Name)
+			writeTAPDiagnostic(w, c.Failure)
+		}
+	}
+
+	return nil
+}
+
+func writeTAPDiagnostic(w io.Writer, message string) {
+	fmt.Fprintln(w, "  ---")
+	fmt.Fprintln(w, "  message: |")
+	for _, line := range strings.Split(strings.TrimSpace(message), "\n") {
+		fmt.Fprintf(w, "    %s\n", line)
+	}
+	fmt.Fprintln(w, "  ...")
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// reportJUnit writes suites as JUnit XML. A suite which could not be
+// evaluated is reported as a single case with an error.
+func reportJUnit(w io.Writer, suites []Suite) error {
+	doc := junitTestSuites{}
+
+	for _, s := range suites {
+		js := junitTestSuite{Name: s.Path}
+
+		if s.Err != nil {
+			js.Tests = 1
+			js.Errors = 1
+			js.Cases = append(js.Cases, junitTestCase{
+				Name:      s.Path,
+				Classname: s.Path,
+				Time:      junitTime(0),
+				Error:     newJUnitMessage(s.Err.Error()),
+			})
+		}
+
+		var elapsed time.Duration
+		for _, c := range s.Cases {
+			elapsed += c.Duration
+			js.Tests++
+
+			jc := junitTestCase{
+				Name:      c.Name,
+				Classname: s.Path,
+				Time:      junitTime(c.Duration),
+			}
+
+			if !c.Passed() {
+				js.Failures++
+				jc.Failure = newJUnitMessage(c.Failure)
+			}
+
+			js.Cases = append(js.Cases, jc)
+		}
+
+		js.Time = junitTime(elapsed)
+		doc.Suites = append(doc.Suites, js)
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding JUnit report")
+	}
+
+	fmt.Fprint(w, xml.Header)
+	fmt.Fprintln(w, string(b))
+	return nil
+}
+
+// newJUnitMessage uses the first line of text as the message.
+func newJUnitMessage(text string) *junitMessage {
+	text = strings.TrimSpace(text)
+	return &junitMessage{
+		Message: strings.SplitN(text, "\n", 2)[0],
+		Text:    text,
+	}
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package unittest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	suites := []Suite{
+		{
+			Path: "components/deployment_test.jsonnet",
+			Cases: []Case{
+				{Name: "replicas", Duration: 2 * time.Millisecond},
+				{Name: "pdb", Failure: "RUNTIME ERROR: missing PodDisruptionBudget\n\tdeployment_test.jsonnet:3:3"},
+			},
+		},
+		{
+			Path: "tests/prod_test.jsonnet",
+			Err:  errors.New("STATIC ERROR: unexpected end of file"),
+		},
+	}
+
+	cases := []struct {
+		name     string
+		format   string
+		expected string
+		isErr    bool
+	}{
+		{name: "tap", format: FormatTAP, expected: "report.tap"},
+		{name: "junit", format: FormatJUnit, expected: "report.xml"},
+		{name: "invalid format", format: "html", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Report(&buf, tc.format, suites)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			test.AssertOutput(t, tc.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package unittest runs component tests. A component test is a Jsonnet file
+// named `*_test.jsonnet` in the `components` or `tests` directory of an app.
+// It evaluates to an object whose fields are test cases. A case passes if it
+// evaluates to true. The objects rendered for the environment are available
+// as `std.extVar("__ksonnet/objects")`.
+package unittest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	componentsDir = "components"
+	testsDir      = "tests"
+	rootModule    = "/"
+)
+
+// Case is the result of a test case.
+type Case struct {
+	Name     string
+	Failure  string
+	Duration time.Duration
+}
+
+// Passed reports if the case passed.
+func (c Case) Passed() bool {
+	return c.Failure == ""
+}
+
+// Suite is the result of a test file. Err is set if the file could not be
+// evaluated, in which case there are no cases.
+type Suite struct {
+	Path  string
+	Cases []Case
+	Err   error
+}
+
+// Failures returns the number of cases which failed. A suite which could not
+// be evaluated counts as one failure.
+func (s Suite) Failures() int {
+	if s.Err != nil {
+		return 1
+	}
+
+	var n int
+	for _, c := range s.Cases {
+		if !c.Passed() {
+			n++
+		}
+	}
+
+	return n
+}
+
+type evaluateFn func(a app.App, envName, filename, snippet, objects, paramsStr string, opts ...jsonnet.VMOpt) (string, error)
+
+// Runner runs component tests in an environment.
+type Runner struct {
+	app     app.App
+	envName string
+
+	objectsFn  func(a app.App, envName string) ([]*unstructured.Unstructured, error)
+	paramsFn   func(a app.App, envName, moduleName string) (string, error)
+	evaluateFn evaluateFn
+}
+
+// New creates an instance of Runner.
+func New(a app.App, envName string) *Runner {
+	return &Runner{
+		app:        a,
+		envName:    envName,
+		objectsFn:  renderObjects,
+		paramsFn:   moduleParams,
+		evaluateFn: env.EvaluateTest,
+	}
+}
+
+// Discover finds the component tests in an app. Paths are relative to the
+// app root.
+func Discover(a app.App) ([]string, error) {
+	var paths []string
+
+	for _, dir := range []string{componentsDir, testsDir} {
+		root := filepath.Join(a.Root(), dir)
+		exists, err := afero.DirExists(a.Fs(), root)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			continue
+		}
+
+		err = afero.Walk(a.Fs(), root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if fi.IsDir() || !component.IsTest(path) {
+				return nil
+			}
+
+			rel, err := filepath.Rel(a.Root(), path)
+			if err != nil {
+				return err
+			}
+
+			paths = append(paths, rel)
+			return nil
+		})
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "finding tests in %s", dir)
+		}
+	}
+
+	return paths, nil
+}
+
+// Run runs the tests at paths, which are relative to the app root. The
+// environment is rendered once and shared by every test.
+func (r *Runner) Run(paths []string) ([]Suite, error) {
+	objects, err := r.objectsFn(r.app, r.envName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering environment %q", r.envName)
+	}
+
+	items := make([]interface{}, 0, len(objects))
+	for _, obj := range objects {
+		items = append(items, obj.Object)
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding rendered objects")
+	}
+
+	var suites []Suite
+	for _, path := range paths {
+		suite := Suite{Path: path}
+		suite.Cases, suite.Err = r.runFile(path, string(data))
+		suites = append(suites, suite)
+	}
+
+	return suites, nil
+}
+
+func (r *Runner) runFile(path, objects string) ([]Case, error) {
+	paramsStr, err := r.paramsFn(r.app, r.envName, moduleName(path))
+	if err != nil {
+		return nil, err
+	}
+
+	filename := filepath.Join(r.app.Root(), path)
+	opt := jsonnet.AferoImporterOpt(r.app.Fs())
+
+	src := fmt.Sprintf("std.objectFields(import %s)", quote(filename))
+	out, err := r.evaluateFn(r.app, r.envName, filename, src, objects, paramsStr, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if err = json.Unmarshal([]byte(out), &names); err != nil {
+		return nil, errors.Errorf("%s must evaluate to an object of test cases", path)
+	}
+
+	var cases []Case
+	for _, name := range names {
+		start := time.Now()
+
+		src = fmt.Sprintf("(import %s)[%s]", quote(filename), quote(name))
+		out, err = r.evaluateFn(r.app, r.envName, filename, src, objects, paramsStr, opt)
+
+		c := Case{Name: name, Duration: time.Since(start)}
+		switch {
+		case err != nil:
+			c.Failure = strings.TrimSpace(err.Error())
+		case strings.TrimSpace(out) != "true":
+			c.Failure = fmt.Sprintf("expected true, got %s", strings.TrimSpace(out))
+		}
+
+		cases = append(cases, c)
+	}
+
+	return cases, nil
+}
+
+// moduleName returns the module whose params are used by a test. Tests in
+// the `tests` directory use the params of the root module.
+func moduleName(path string) string {
+	dir := filepath.Dir(filepath.ToSlash(path))
+	if !strings.HasPrefix(dir, componentsDir+"/") {
+		return rootModule
+	}
+
+	return strings.Replace(strings.TrimPrefix(dir, componentsDir+"/"), "/", ".", -1)
+}
+
+// quote quotes a string as a Jsonnet string literal.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func renderObjects(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+	return pipeline.New(a, envName).Objects(nil)
+}
+
+func moduleParams(a app.App, envName, moduleName string) (string, error) {
+	return pipeline.New(a, envName).EnvParameters(moduleName, true)
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package unittest
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiscover(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		files := []string{
+			"/app/components/deployment.jsonnet",
+			"/app/components/deployment_test.jsonnet",
+			"/app/components/nested/service_test.jsonnet",
+			"/app/tests/prod_test.jsonnet",
+			"/app/tests/helpers.libsonnet",
+		}
+		for _, f := range files {
+			require.NoError(t, afero.WriteFile(fs, f, []byte("{}"), app.DefaultFilePermissions))
+		}
+
+		paths, err := Discover(a)
+		require.NoError(t, err)
+
+		expected := []string{
+			"components/deployment_test.jsonnet",
+			"components/nested/service_test.jsonnet",
+			"tests/prod_test.jsonnet",
+		}
+		require.Equal(t, expected, paths)
+	})
+}
+
+func TestRunner_Run(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		r := New(a, "prod")
+		r.objectsFn = func(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+			require.Equal(t, "prod", envName)
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Deployment"}}
+			return []*unstructured.Unstructured{obj}, nil
+		}
+		r.paramsFn = func(a app.App, envName, moduleName string) (string, error) {
+			return `{"module":"` + moduleName + `"}`, nil
+		}
+		r.evaluateFn = func(a app.App, envName, filename, snippet, objects, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
+			assert.Equal(t, `[{"kind":"Deployment"}]`, objects)
+
+			switch snippet {
+			case `std.objectFields(import "/app/components/nested/service_test.jsonnet")`:
+				assert.Equal(t, `{"module":"nested"}`, paramsStr)
+				return `["has port", "is deployment", "replicas"]`, nil
+			case `(import "/app/components/nested/service_test.jsonnet")["has port"]`:
+				return "true\n", nil
+			case `(import "/app/components/nested/service_test.jsonnet")["is deployment"]`:
+				return "false\n", nil
+			case `(import "/app/components/nested/service_test.jsonnet")["replicas"]`:
+				return "", errors.New("RUNTIME ERROR: expected 5 replicas")
+			case `std.objectFields(import "/app/tests/prod_test.jsonnet")`:
+				assert.Equal(t, `{"module":"/"}`, paramsStr)
+				return "", errors.New("STATIC ERROR: unexpected end of file")
+			default:
+				t.Fatalf("unexpected snippet %s", snippet)
+				return "", nil
+			}
+		}
+
+		suites, err := r.Run([]string{"components/nested/service_test.jsonnet", "tests/prod_test.jsonnet"})
+		require.NoError(t, err)
+		require.Len(t, suites, 2)
+
+		s := suites[0]
+		require.NoError(t, s.Err)
+		require.Len(t, s.Cases, 3)
+		assert.True(t, s.Cases[0].Passed())
+		assert.Equal(t, "expected true, got false", s.Cases[1].Failure)
+		assert.Equal(t, "RUNTIME ERROR: expected 5 replicas", s.Cases[2].Failure)
+		assert.Equal(t, 2, s.Failures())
+
+		s = suites[1]
+		require.Error(t, s.Err)
+		assert.Equal(t, 1, s.Failures())
+	})
+}
+
+func TestRunner_Run_render_error(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		r := New(a, "prod")
+		r.objectsFn = func(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+			return nil, errors.New("failed")
+		}
+
+		_, err := r.Run([]string{"tests/prod_test.jsonnet"})
+		require.Error(t, err)
+	})
+}
+
+func Test_moduleName(t *testing.T) {
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "components/a_test.jsonnet", expected: "/"},
+		{path: "components/nested/a_test.jsonnet", expected: "nested"},
+		{path: "components/a/b/a_test.jsonnet", expected: "a.b"},
+		{path: "tests/a_test.jsonnet", expected: "/"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.expected, moduleName(tc.path))
+		})
+	}
+}
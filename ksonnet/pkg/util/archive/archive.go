@@ -32,3 +32,8 @@ type FileHandler func(*File) error
 type Unarchiver interface {
 	Unarchive(io.Reader, FileHandler) error
 }
+
+// Archiver archives files to a writer.
+type Archiver interface {
+	Archive(io.Writer, []*File) error
+}
@@ -17,9 +17,11 @@ package archive
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"errors"
 	"io"
+	"time"
 )
 
 // Tgz handles gzip'd tar archives.
@@ -68,3 +70,40 @@ func (t *Tgz) Unarchive(r io.Reader, handler FileHandler) error {
 	}
 	return nil
 }
+
+// Archive tars and gzips files to a writer, in the order they are given.
+// Headers don't include modification times or owners, so archiving the same
+// files always gives the same bytes.
+func (t *Tgz) Archive(w io.Writer, files []*File) error {
+	gzWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	for _, f := range files {
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, f.Reader); err != nil {
+			return err
+		}
+
+		header := &tar.Header{
+			Name:     f.Name,
+			Mode:     0644,
+			Size:     int64(buf.Len()),
+			ModTime:  time.Unix(0, 0),
+			Typeflag: tar.TypeReg,
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if _, err := tarWriter.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return gzWriter.Close()
+}
@@ -16,7 +16,9 @@
 package archive
 
 import (
+	"bytes"
 	"errors"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -107,3 +109,42 @@ func Test_UnTgz_handler_failed(t *testing.T) {
 	err = tgz.Unarchive(f, handler)
 	require.Error(t, err)
 }
+
+func Test_Tgz_Archive(t *testing.T) {
+	files := func() []*File {
+		return []*File{
+			{Name: "chart/Chart.yaml", Reader: strings.NewReader("name: chart\n")},
+			{Name: "chart/templates/service.yaml", Reader: strings.NewReader("kind: Service\n")},
+		}
+	}
+
+	tgz := &Tgz{}
+
+	var buf bytes.Buffer
+	require.NoError(t, tgz.Archive(&buf, files()))
+
+	got := make(map[string]string)
+	handler := func(tf *File) error {
+		data, err := ioutil.ReadAll(tf.Reader)
+		if err != nil {
+			return err
+		}
+
+		got[tf.Name] = string(data)
+		return nil
+	}
+
+	archived := buf.Bytes()
+	require.NoError(t, tgz.Unarchive(bytes.NewReader(archived), handler))
+
+	expected := map[string]string{
+		"chart/Chart.yaml":             "name: chart\n",
+		"chart/templates/service.yaml": "kind: Service\n",
+	}
+	require.Equal(t, expected, got)
+
+	// archiving is deterministic.
+	buf.Reset()
+	require.NoError(t, tgz.Archive(&buf, files()))
+	require.Equal(t, archived, buf.Bytes())
+}
@@ -0,0 +1,197 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package dockerregistry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// dockerHubHost is the host the Docker CLI stores Docker Hub credentials
+	// under.
+	dockerHubHost = "index.docker.io"
+
+	// dockerHubServerURL is the server URL credential helpers store Docker
+	// Hub credentials under.
+	dockerHubServerURL = "https://index.docker.io/v1/"
+
+	// credentialHelperPrefix prefixes the name of credential helper
+	// executables.
+	credentialHelperPrefix = "docker-credential-"
+)
+
+// credentialHelperFn looks up the credentials for a server URL with a
+// credential helper.
+type credentialHelperFn func(helper, serverURL string) (string, string, error)
+
+// dockerConfig is the subset of the Docker CLI's config.json which holds
+// registry credentials.
+type dockerConfig struct {
+	Auths       map[string]dockerAuth `json:"auths"`
+	CredHelpers map[string]string     `json:"credHelpers"`
+	CredsStore  string                `json:"credsStore"`
+
+	helperFn credentialHelperFn
+}
+
+type dockerAuth struct {
+	// Auth is base64 encoded `username:password`.
+	Auth     string `json:"auth"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// dockerConfigPath returns the path of the Docker CLI's config.json. It
+// honors DOCKER_CONFIG like the Docker CLI does.
+func dockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+
+	return filepath.Join(os.Getenv("HOME"), ".docker", "config.json")
+}
+
+// loadDockerConfig loads a Docker CLI config. A missing config has no
+// credentials.
+func loadDockerConfig(path string) (*dockerConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &dockerConfig{}, nil
+		}
+		return nil, err
+	}
+
+	config := dockerConfig{helperFn: runCredentialHelper}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrapf(err, "decoding docker config %s", path)
+	}
+
+	return &config, nil
+}
+
+// Credentials returns the username and password for a registry host. Like
+// the Docker CLI, a credential helper configured for the host is used first,
+// then the default credentials store, then the credentials in the config
+// itself. Blank credentials are returned if there are none for the host.
+func (c *dockerConfig) Credentials(host string) (string, string, error) {
+	host = normalizeRegistryHost(host)
+
+	serverURL := host
+	if host == dockerHubHost {
+		serverURL = dockerHubServerURL
+	}
+
+	for key, helper := range c.CredHelpers {
+		if normalizeRegistryHost(key) == host {
+			return c.helperFn(helper, serverURL)
+		}
+	}
+
+	if c.CredsStore != "" {
+		username, password, err := c.helperFn(c.CredsStore, serverURL)
+		if err != nil || username != "" || password != "" {
+			return username, password, err
+		}
+	}
+
+	username, password := c.authCredentials(host)
+	return username, password, nil
+}
+
+// authCredentials returns the credentials stored in the config for a
+// normalized registry host.
+func (c *dockerConfig) authCredentials(host string) (string, string) {
+	for key, auth := range c.Auths {
+		if normalizeRegistryHost(key) != host {
+			continue
+		}
+
+		if auth.Auth == "" {
+			return auth.Username, auth.Password
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", ""
+		}
+
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return "", ""
+		}
+
+		return parts[0], parts[1]
+	}
+
+	return "", ""
+}
+
+// normalizeRegistryHost strips the scheme and path from a registry address,
+// and maps the Docker Hub hosts to the address the Docker CLI uses.
+func normalizeRegistryHost(address string) string {
+	address = strings.TrimPrefix(address, "https://")
+	address = strings.TrimPrefix(address, "http://")
+	if i := strings.Index(address, "/"); i >= 0 {
+		address = address[:i]
+	}
+
+	switch address {
+	case defaultRegistry, "docker.io":
+		return dockerHubHost
+	}
+
+	return address
+}
+
+// runCredentialHelper runs a Docker credential helper to look up the
+// credentials for a server URL. Missing credentials are not an error.
+func runCredentialHelper(helper, serverURL string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(credentialHelperPrefix+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		out := stdout.String() + stderr.String()
+		if strings.Contains(out, "credentials not found") {
+			return "", "", nil
+		}
+
+		return "", "", errors.Wrapf(err, "running %s%s: %s", credentialHelperPrefix, helper, strings.TrimSpace(out))
+	}
+
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return "", "", errors.Wrapf(err, "decoding %s%s output", credentialHelperPrefix, helper)
+	}
+
+	return creds.Username, creds.Secret, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package dockerregistry
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_loadDockerConfig(t *testing.T) {
+	config, err := loadDockerConfig(filepath.Join("testdata", "config.json"))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		host     string
+		username string
+		password string
+	}{
+		{name: "docker hub", host: "registry-1.docker.io", username: "hub", password: "hub-secret"},
+		{name: "encoded auth", host: "ghcr.io", username: "user", password: "secret"},
+		{name: "username and password", host: "harbor.example.com:8443", username: "robot", password: "token"},
+		{name: "invalid auth", host: "broken.example.com"},
+		{name: "unknown host", host: "quay.io"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			username, password, err := config.Credentials(tc.host)
+			require.NoError(t, err)
+			require.Equal(t, tc.username, username)
+			require.Equal(t, tc.password, password)
+		})
+	}
+}
+
+func Test_loadDockerConfig_missing(t *testing.T) {
+	config, err := loadDockerConfig(filepath.Join("testdata", "missing.json"))
+	require.NoError(t, err)
+
+	username, password, err := config.Credentials("ghcr.io")
+	require.NoError(t, err)
+	require.Empty(t, username)
+	require.Empty(t, password)
+}
+
+func Test_authTransport_credentials(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "secret", password)
+		w.Header().Set("Docker-Content-Digest", "sha256:abcde")
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	inner := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+
+	tr := NewAuthTransport(inner).(*authTransport)
+	tr.credentialsFn = func(host string) (string, string, error) {
+		require.Equal(t, u.Host, host)
+		return "user", "secret", nil
+	}
+
+	c := NewRegistryClient(&http.Client{Transport: tr}, ts.URL)
+
+	digest, err := c.ManifestDigest("foo/bar", "latest")
+	require.NoError(t, err)
+	require.Equal(t, "sha256:abcde", digest)
+}
+
+func Test_dockerConfig_Credentials_helpers(t *testing.T) {
+	config := &dockerConfig{
+		Auths: map[string]dockerAuth{
+			"quay.io": {Username: "quay", Password: "quay-secret"},
+		},
+		CredHelpers: map[string]string{
+			"123456789012.dkr.ecr.us-east-1.amazonaws.com": "ecr-login",
+		},
+		CredsStore: "desktop",
+		helperFn: func(helper, serverURL string) (string, string, error) {
+			switch {
+			case helper == "ecr-login" && serverURL == "123456789012.dkr.ecr.us-east-1.amazonaws.com":
+				return "AWS", "ecr-token", nil
+			case helper == "desktop" && serverURL == "https://index.docker.io/v1/":
+				return "hub", "hub-token", nil
+			case helper == "desktop" && serverURL == "broken.example.com":
+				return "", "", errors.New("helper failed")
+			default:
+				return "", "", nil
+			}
+		},
+	}
+
+	cases := []struct {
+		name     string
+		host     string
+		username string
+		password string
+		isErr    bool
+	}{
+		{name: "credential helper", host: "123456789012.dkr.ecr.us-east-1.amazonaws.com", username: "AWS", password: "ecr-token"},
+		{name: "credentials store", host: "registry-1.docker.io", username: "hub", password: "hub-token"},
+		{name: "not in credentials store", host: "quay.io", username: "quay", password: "quay-secret"},
+		{name: "unknown host", host: "ghcr.io"},
+		{name: "helper error", host: "broken.example.com", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			username, password, err := config.Credentials(tc.host)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.username, username)
+			require.Equal(t, tc.password, password)
+		})
+	}
+}
@@ -63,10 +63,14 @@ func (n ImageName) String() string {
 	return buf.String()
 }
 
-// RegistryRepoName returns the "repository" as used in the registry URL
+// RegistryRepoName returns the "repository" as used in the registry URL.
+// Official Docker Hub images live in the "library" repository.
 func (n ImageName) RegistryRepoName() string {
 	repo := n.Repository
 	if repo == "" {
+		if n.RegistryURL() != fmt.Sprintf("https://%s", defaultRegistry) {
+			return n.Name
+		}
 		repo = "library"
 	}
 	return fmt.Sprintf("%s/%s", repo, n.Name)
@@ -75,41 +79,59 @@ func (n ImageName) RegistryRepoName() string {
 // RegistryURL returns the deduced base URL of the registry for this image
 func (n ImageName) RegistryURL() string {
 	reg := n.Registry
-	if reg == "" {
+	switch reg {
+	case "", "docker.io", "index.docker.io":
 		reg = defaultRegistry
 	}
 	return fmt.Sprintf("https://%s", reg)
 }
 
-// ParseImageName parses a docker image into an ImageName struct
+// ParseImageName parses a docker image into an ImageName struct. Like the
+// Docker CLI, the first component of the name is a registry if it contains
+// a "." or ":", or is "localhost". Otherwise the image is on Docker Hub.
 func ParseImageName(image string) (ImageName, error) {
 	ret := ImageName{}
 
-	if parts := strings.Split(image, "/"); len(parts) == 1 {
-		ret.Name = parts[0]
-	} else if len(parts) == 2 {
-		ret.Repository = parts[0]
-		ret.Name = parts[1]
-	} else if len(parts) == 3 {
+	parts := strings.Split(image, "/")
+	if len(parts) > 1 && isRegistryHost(parts[0]) {
 		ret.Registry = parts[0]
-		ret.Repository = parts[1]
-		ret.Name = parts[2]
-	} else {
-		return ret, fmt.Errorf("Malformed docker image name: %s", image)
+		parts = parts[1:]
+	}
+
+	for _, part := range parts {
+		if part == "" {
+			return ret, fmt.Errorf("Malformed docker image name: %s", image)
+		}
 	}
 
+	ret.Repository = strings.Join(parts[:len(parts)-1], "/")
+	ret.Name = parts[len(parts)-1]
+
 	if parts := strings.Split(ret.Name, "@"); len(parts) == 2 {
 		ret.Name = parts[0]
 		ret.Digest = parts[1]
-	} else if parts := strings.Split(ret.Name, ":"); len(parts) == 2 {
+	} else if len(parts) > 2 {
+		return ret, fmt.Errorf("Malformed docker image digest: %s", image)
+	}
+
+	if parts := strings.Split(ret.Name, ":"); len(parts) == 2 {
 		ret.Name = parts[0]
 		ret.Tag = parts[1]
 	} else if len(parts) == 1 {
-		ret.Name = parts[0]
 		ret.Tag = "latest"
 	} else {
 		return ret, fmt.Errorf("Malformed docker image name/tag: %s", image)
 	}
 
+	if ret.Name == "" || ret.Tag == "" {
+		return ret, fmt.Errorf("Malformed docker image name/tag: %s", image)
+	}
+
 	return ret, nil
 }
+
+// isRegistryHost reports if the first component of an image name is a
+// registry host.
+func isRegistryHost(s string) bool {
+	return strings.ContainsAny(s, ".:") || s == "localhost"
+}
@@ -55,6 +55,7 @@ func TestImageName_String(t *testing.T) {
 func TestImageName_RegistryRepoName(t *testing.T) {
 	cases := []struct {
 		name     string
+		registry string
 		repoName string
 		expected string
 	}{
@@ -67,11 +68,22 @@ func TestImageName_RegistryRepoName(t *testing.T) {
 			name:     "without repo name",
 			expected: "library/bar",
 		},
+		{
+			name:     "without repo name on docker hub",
+			registry: "docker.io",
+			expected: "library/bar",
+		},
+		{
+			name:     "without repo name on a private registry",
+			registry: "ghcr.io",
+			expected: "bar",
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			in := ImageName{
+				Registry:   tc.registry,
 				Repository: tc.repoName,
 				Name:       "bar",
 			}
@@ -140,7 +152,27 @@ func TestParseImageName(t *testing.T) {
 			expected: "foo/bar/baz:latest",
 		},
 		{
-			name:  "foo/bar/baz/qux",
+			name:     "foo/bar/baz/qux",
+			expected: "foo/bar/baz/qux:latest",
+		},
+		{
+			name:     "ghcr.io/app:1.0",
+			expected: "ghcr.io/app:1.0",
+		},
+		{
+			name:     "localhost:5000/app",
+			expected: "localhost:5000/app:latest",
+		},
+		{
+			name:     "harbor.example.com/project/team/app:1.0@sha256:abcded",
+			expected: "harbor.example.com/project/team/app@sha256:abcded",
+		},
+		{
+			name:  "foo//bar",
+			isErr: true,
+		},
+		{
+			name:  "foo/bar:",
 			isErr: true,
 		},
 		{
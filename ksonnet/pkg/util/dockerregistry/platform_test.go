@@ -0,0 +1,65 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package dockerregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlatform(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected Platform
+		isErr    bool
+	}{
+		{
+			name:     "linux/amd64",
+			expected: Platform{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			name:     "linux/arm64/v8",
+			expected: Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		},
+		{
+			name:  "linux",
+			isErr: true,
+		},
+		{
+			name:  "linux//v8",
+			isErr: true,
+		},
+		{
+			name:  "linux/arm/v7/extra",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParsePlatform(tc.name)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+			require.Equal(t, tc.name, got.String())
+		})
+	}
+}
@@ -28,11 +28,23 @@ import (
 )
 
 const (
-	mimeTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	mimeTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mimeTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mimeTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mimeTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
 )
 
 var (
 	commaRegexp = regexp.MustCompile(", *")
+
+	// manifestMIMETypes are the manifest types the client accepts, in order
+	// of preference.
+	manifestMIMETypes = []string{
+		mimeTypeDockerManifest,
+		mimeTypeDockerManifestList,
+		mimeTypeOCIManifest,
+		mimeTypeOCIIndex,
+	}
 )
 
 // imageNotFoundError is an image not found error.
@@ -65,27 +77,80 @@ func NewRegistryClient(client *http.Client, url string) *Registry {
 }
 
 // ManifestDigest fetches the manifest digest for a given reponame and tag.
+// For a multi-platform image, this is the digest of its manifest list or
+// image index.
 func (r *Registry) ManifestDigest(reponame, tag string) (string, error) {
-	url := fmt.Sprintf("%s/v2/%s/manifests/%s", r.URL, reponame, tag)
+	resp, err := r.fetchManifest(http.MethodHead, reponame, tag)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	return contentDigest(resp)
+}
 
-	req, err := http.NewRequest(http.MethodHead, url, nil)
+// PlatformManifestDigest fetches the manifest digest of the image for a
+// platform. For a single platform image, this is the same as
+// ManifestDigest.
+func (r *Registry) PlatformManifestDigest(reponame, tag string, platform Platform) (string, error) {
+	resp, err := r.fetchManifest(http.MethodGet, reponame, tag)
 	if err != nil {
 		return "", err
 	}
-	req.Header.Add("Accept", mimeTypeDockerManifest)
+	defer resp.Body.Close()
+
+	switch mediaType(resp.Header.Get("Content-Type")) {
+	case mimeTypeDockerManifestList, mimeTypeOCIIndex:
+	default:
+		return contentDigest(resp)
+	}
+
+	var index manifestIndex
+	if err = json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return "", errors.Wrap(err, "decoding image index")
+	}
+
+	for _, m := range index.Manifests {
+		if m.Platform.matches(platform) {
+			return m.Digest, nil
+		}
+	}
+
+	return "", &imageNotFoundError{name: fmt.Sprintf("%s:%s for %s", reponame, tag, platform)}
+}
+
+func (r *Registry) fetchManifest(method, reponame, tag string) (*http.Response, error) {
+	url := fmt.Sprintf("%s/v2/%s/manifests/%s", r.URL, reponame, tag)
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, mimeType := range manifestMIMETypes {
+		req.Header.Add("Accept", mimeType)
+	}
 	resp, err := r.Client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	switch resp.StatusCode {
 	case http.StatusOK:
+		return resp, nil
 	case http.StatusNotFound:
-		return "", &imageNotFoundError{name: fmt.Sprintf("%s:%s", reponame, tag)}
+		resp.Body.Close()
+		return nil, &imageNotFoundError{name: fmt.Sprintf("%s:%s", reponame, tag)}
+	case http.StatusUnauthorized:
+		resp.Body.Close()
+		return nil, errors.Errorf("request failed with %s; check the credentials for %s in the docker config",
+			resp.Status, req.URL.Host)
 	default:
-		return "", errors.Errorf("request failed with %s", resp.Status)
+		resp.Body.Close()
+		return nil, errors.Errorf("request failed with %s", resp.Status)
 	}
+}
 
+func contentDigest(resp *http.Response) (string, error) {
 	digest := resp.Header.Get("Docker-Content-Digest")
 	if digest == "" {
 		return "", errors.New("No digest in response")
@@ -94,6 +159,15 @@ func (r *Registry) ManifestDigest(reponame, tag string) (string, error) {
 	return digest, nil
 }
 
+// mediaType strips the parameters from a Content-Type.
+func mediaType(contentType string) string {
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	return strings.TrimSpace(contentType)
+}
+
 // stolen from golang 1.8
 func stripPort(hostport string) string {
 	colon := strings.IndexByte(hostport, ':')
@@ -157,6 +231,23 @@ func NewAuthTransport(inner http.RoundTripper) http.RoundTripper {
 	}
 }
 
+// NewDockerConfigAuthTransport returns a roundtripper like NewAuthTransport
+// which authenticates with the registry credentials in the Docker CLI's
+// config.json.
+func NewDockerConfigAuthTransport(inner http.RoundTripper) (http.RoundTripper, error) {
+	config, err := loadDockerConfig(dockerConfigPath())
+	if err != nil {
+		return nil, err
+	}
+
+	return &authTransport{
+		Transport:     inner,
+		Client:        &http.Client{Transport: inner},
+		tokenCache:    map[string]string{},
+		credentialsFn: config.Credentials,
+	}, nil
+}
+
 type authTransport struct {
 	Client     *http.Client
 	Transport  http.RoundTripper
@@ -164,6 +255,19 @@ type authTransport struct {
 	HostDomain string
 	Username   string
 	Password   string
+
+	// credentialsFn looks up the credentials for a registry host. If it is
+	// nil, Username and Password are used.
+	credentialsFn func(host string) (string, string, error)
+}
+
+// credentials returns the credentials for a registry host.
+func (t *authTransport) credentials(host string) (string, string, error) {
+	if t.credentialsFn == nil {
+		return t.Username, t.Password, nil
+	}
+
+	return t.credentialsFn(host)
 }
 
 // RoundTrip is required for the http.RoundTripper interface
@@ -172,19 +276,31 @@ func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	resp, err := t.Transport.RoundTrip(req)
 	log.Debugf("<= err=%v resp=%v", err, resp)
 	if err == nil && resp.StatusCode == http.StatusUnauthorized && matchesDomain(req.URL, t.HostDomain) {
+		username, password, err := t.credentials(req.URL.Host)
+		if err != nil {
+			resp.Body.Close()
+			return nil, errors.Wrapf(err, "loading credentials for %s", req.URL.Host)
+		}
+
 		schemes := parseAuthHeader(resp.Header)
 		for _, scheme := range schemes {
 			if scheme.Scheme == "basic" {
+				if username == "" && password == "" {
+					// there are no credentials to retry with
+					return resp, nil
+				}
+				resp.Body.Close()
 				log.Debugf("Retrying with basic auth")
-				req.SetBasicAuth(t.Username, t.Password)
+				req.SetBasicAuth(username, password)
 				log.Debugf("=> %v", req)
 				return t.Transport.RoundTrip(req)
 			}
 			if scheme.Scheme == "bearer" {
-				token, err := t.bearerAuth(scheme.Params["realm"], scheme.Params["service"], scheme.Params["scope"])
+				token, err := t.bearerAuth(scheme.Params["realm"], scheme.Params["service"], scheme.Params["scope"], username, password)
 				if err != nil {
 					return resp, err
 				}
+				resp.Body.Close()
 				log.Debugf("Retrying with bearer auth")
 				req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 				log.Debugf("=> %v", req)
@@ -197,8 +313,8 @@ func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return resp, err
 }
 
-func (t *authTransport) bearerAuth(realm, service, scope string) (string, error) {
-	cacheKey := fmt.Sprintf("%s!%s!%s", realm, service, scope)
+func (t *authTransport) bearerAuth(realm, service, scope, username, password string) (string, error) {
+	cacheKey := fmt.Sprintf("%s!%s!%s!%s", realm, service, scope, username)
 	if token := t.tokenCache[cacheKey]; token != "" {
 		return token, nil
 	}
@@ -220,8 +336,8 @@ func (t *authTransport) bearerAuth(realm, service, scope string) (string, error)
 		return "", err
 	}
 
-	if t.Username != "" || t.Password != "" {
-		req.SetBasicAuth(t.Username, t.Password)
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
 	}
 
 	log.Debugf("Performing oauth request to %s", req.URL)
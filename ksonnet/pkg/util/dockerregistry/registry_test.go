@@ -53,3 +53,100 @@ func Test_RegistryClient_ManifestDigest(t *testing.T) {
 
 	require.Equal(t, "sha256:abcde", digest)
 }
+
+func Test_RegistryClient_PlatformManifestDigest(t *testing.T) {
+	index := `{
+  "manifests": [
+    {"digest": "sha256:amd64", "platform": {"os": "linux", "architecture": "amd64"}},
+    {"digest": "sha256:armv7", "platform": {"os": "linux", "architecture": "arm", "variant": "v7"}}
+  ]
+}`
+
+	cases := []struct {
+		name        string
+		contentType string
+		platform    string
+		expected    string
+		isErr       bool
+	}{
+		{
+			name:        "manifest list",
+			contentType: mimeTypeDockerManifestList,
+			platform:    "linux/amd64",
+			expected:    "sha256:amd64",
+		},
+		{
+			name:        "oci index with variant",
+			contentType: mimeTypeOCIIndex + "; charset=utf-8",
+			platform:    "linux/arm/v7",
+			expected:    "sha256:armv7",
+		},
+		{
+			name:        "single platform manifest",
+			contentType: mimeTypeOCIManifest,
+			platform:    "linux/arm64",
+			expected:    "sha256:index",
+		},
+		{
+			name:        "platform not in index",
+			contentType: mimeTypeOCIIndex,
+			platform:    "windows/amd64",
+			isErr:       true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, manifestMIMETypes, r.Header["Accept"])
+				assert.Equal(t, http.MethodGet, r.Method)
+
+				w.Header().Set("Content-Type", tc.contentType)
+				w.Header().Set("Docker-Content-Digest", "sha256:index")
+				w.Write([]byte(index))
+			}))
+			defer ts.Close()
+
+			client := &http.Client{
+				Timeout: 1 * time.Second,
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				},
+			}
+
+			platform, err := ParsePlatform(tc.platform)
+			require.NoError(t, err)
+
+			c := NewRegistryClient(client, ts.URL)
+			digest, err := c.PlatformManifestDigest("foo/bar", "latest", platform)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, digest)
+		})
+	}
+}
+
+func Test_authTransport_basic_without_credentials(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		assert.False(t, ok)
+
+		w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	tr := NewAuthTransport(&http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	})
+
+	c := NewRegistryClient(&http.Client{Transport: tr}, ts.URL)
+
+	_, err := c.ManifestDigest("foo/bar", "latest")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "check the credentials")
+}
@@ -17,9 +17,11 @@ package dockerregistry
 
 import (
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 // Resolver is able to resolve docker image names into more specific forms
@@ -29,15 +31,22 @@ type Resolver interface {
 
 type registryResolver struct {
 	Client *http.Client
-	cache  map[string]string
+
+	// platform, if set, resolves multi-platform images to the digest of the
+	// image for the platform.
+	platform *Platform
+
+	mu    sync.Mutex
+	cache map[string]string
 }
 
 // newRegistryResolver returns a resolver that looks up a docker
 // registry to resolve digests
-func newRegistryResolver(httpClient *http.Client) Resolver {
+func newRegistryResolver(httpClient *http.Client, platform *Platform) Resolver {
 	return &registryResolver{
-		Client: httpClient,
-		cache:  make(map[string]string),
+		Client:   httpClient,
+		platform: platform,
+		cache:    make(map[string]string),
 	}
 }
 
@@ -47,13 +56,22 @@ func (r *registryResolver) Resolve(n *ImageName) error {
 		return nil
 	}
 
-	if digest, ok := r.cache[n.String()]; ok {
+	r.mu.Lock()
+	digest, ok := r.cache[n.String()]
+	r.mu.Unlock()
+	if ok {
 		n.Digest = digest
 		return nil
 	}
 
 	c := NewRegistryClient(r.Client, n.RegistryURL())
-	digest, err := c.ManifestDigest(n.RegistryRepoName(), n.Tag)
+
+	var err error
+	if r.platform != nil {
+		digest, err = c.PlatformManifestDigest(n.RegistryRepoName(), n.Tag, *r.platform)
+	} else {
+		digest, err = c.ManifestDigest(n.RegistryRepoName(), n.Tag)
+	}
 	if err != nil {
 		switch err.(type) {
 		case *imageNotFoundError:
@@ -63,7 +81,10 @@ func (r *registryResolver) Resolve(n *ImageName) error {
 		}
 	}
 
+	r.mu.Lock()
 	r.cache[n.String()] = digest
+	r.mu.Unlock()
+
 	n.Digest = digest
 	return nil
 }
@@ -73,23 +94,51 @@ type ResolverClient interface {
 	ManifestV2Digest(image string) (string, error)
 }
 
-// DefaultResolverClient resolves digests for a docker image.
+// DefaultResolverClient resolves digests for a docker image. Digests are
+// cached, so an instance can be shared to resolve many images.
 type DefaultResolverClient struct {
 	clientFactory func() *http.Client
+	platform      *Platform
+
+	once     sync.Once
+	resolver Resolver
+}
+
+// DigesterOpt is an option for configuring DefaultResolverClient.
+type DigesterOpt func(*DefaultResolverClient)
+
+// WithPlatform resolves multi-platform images to the digest of the image for
+// a platform, instead of the digest of their manifest list or image index.
+func WithPlatform(p Platform) DigesterOpt {
+	return func(d *DefaultResolverClient) {
+		d.platform = &p
+	}
 }
 
 var _ ResolverClient = (*DefaultResolverClient)(nil)
 
 // NewDefaultDigester creates an instance of DefaultDigester.
-func NewDefaultDigester() *DefaultResolverClient {
-	return &DefaultResolverClient{
+func NewDefaultDigester(opts ...DigesterOpt) *DefaultResolverClient {
+	d := &DefaultResolverClient{
 		clientFactory: func() *http.Client {
+			transport, err := NewDockerConfigAuthTransport(http.DefaultTransport)
+			if err != nil {
+				log.WithError(err).Warn("unable to load docker registry credentials")
+				transport = NewAuthTransport(http.DefaultTransport)
+			}
+
 			return &http.Client{
-				Transport: NewAuthTransport(http.DefaultTransport),
+				Transport: transport,
 				Timeout:   15 * time.Second,
 			}
 		},
 	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
 }
 
 // ManifestV2Digest returns the 'Docker-Content-Digest' field of the the
@@ -100,10 +149,11 @@ func (d *DefaultResolverClient) ManifestV2Digest(image string) (string, error) {
 		return "", errors.Wrap(err, "parsing image name")
 	}
 
-	client := d.clientFactory()
+	d.once.Do(func() {
+		d.resolver = newRegistryResolver(d.clientFactory(), d.platform)
+	})
 
-	resolver := newRegistryResolver(client)
-	if err = resolver.Resolve(&n); err != nil {
+	if err = d.resolver.Resolve(&n); err != nil {
 		return "", errors.Wrap(err, "resolving image")
 	}
 
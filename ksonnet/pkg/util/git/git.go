@@ -0,0 +1,134 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// Runner runs a git command in a directory and returns its standard output.
+type Runner func(dir string, args ...string) ([]byte, error)
+
+// DefaultRunner runs git commands using the git binary found in PATH.
+func DefaultRunner(dir string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return nil, errors.Wrapf(err, "running git %s", strings.Join(args, " "))
+		}
+		return nil, errors.Errorf("running git %s: %s", strings.Join(args, " "), msg)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// Fs returns an in-memory filesystem containing dir as it was at revision
+// rev. Files are stored at the same absolute paths they have in the working
+// tree, so an application loaded from dir can be loaded from the returned
+// filesystem using the same root.
+func Fs(dir, rev string) (afero.Fs, error) {
+	return FsWithRunner(DefaultRunner, dir, rev)
+}
+
+// FsWithRunner is Fs using a custom Runner.
+func FsWithRunner(run Runner, dir, rev string) (afero.Fs, error) {
+	if rev == "" {
+		return nil, errors.New("git revision is blank")
+	}
+
+	if _, err := run(dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}"); err != nil {
+		return nil, errors.Errorf("%q is not a valid git revision", rev)
+	}
+
+	prefix, err := run(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding location of %s in git repository", dir)
+	}
+
+	topLevel, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding root of git repository for %s", dir)
+	}
+
+	treeish := fmt.Sprintf("%s:%s", rev, strings.TrimSpace(string(prefix)))
+	archive, err := run(strings.TrimSpace(string(topLevel)), "archive", "--format=tar", treeish)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s at revision %s", dir, rev)
+	}
+
+	fs := afero.NewMemMapFs()
+	if err := untar(fs, dir, bytes.NewReader(archive)); err != nil {
+		return nil, errors.Wrapf(err, "extracting %s at revision %s", dir, rev)
+	}
+
+	return fs, nil
+}
+
+func untar(fs afero.Fs, root string, r io.Reader) error {
+	tr := tar.NewReader(r)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(root, filepath.FromSlash(header.Name))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := fs.MkdirAll(path, os.FileMode(header.Mode)|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+
+			if err := afero.WriteFile(fs, path, data, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		default:
+			// noop
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func withRepo(t *testing.T, fn func(root string)) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root, err := ioutil.TempDir("", "ks-git")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	root, err = filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+
+	fn(root)
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	args = append([]string{"-c", "user.name=ks", "-c", "user.email=ks@example.com"}, args...)
+	_, err := DefaultRunner(dir, args...)
+	require.NoError(t, err)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func TestFs(t *testing.T) {
+	withRepo(t, func(root string) {
+		appDir := filepath.Join(root, "deploy", "app")
+		paramsPath := filepath.Join(appDir, "components", "params.libsonnet")
+
+		gitCmd(t, root, "init", "-q")
+		writeFile(t, filepath.Join(root, "README.md"), "readme")
+		writeFile(t, paramsPath, "v1")
+		gitCmd(t, root, "add", ".")
+		gitCmd(t, root, "commit", "-q", "-m", "first")
+
+		writeFile(t, paramsPath, "v2")
+		gitCmd(t, root, "commit", "-q", "-a", "-m", "second")
+
+		fs, err := Fs(appDir, "HEAD~1")
+		require.NoError(t, err)
+
+		b, err := afero.ReadFile(fs, paramsPath)
+		require.NoError(t, err)
+		require.Equal(t, "v1", string(b))
+
+		exists, err := afero.Exists(fs, filepath.Join(root, "README.md"))
+		require.NoError(t, err)
+		require.False(t, exists, "files outside of the directory should not be extracted")
+
+		fs, err = Fs(appDir, "HEAD")
+		require.NoError(t, err)
+
+		b, err = afero.ReadFile(fs, paramsPath)
+		require.NoError(t, err)
+		require.Equal(t, "v2", string(b))
+	})
+}
+
+func TestFs_invalid_revision(t *testing.T) {
+	withRepo(t, func(root string) {
+		gitCmd(t, root, "init", "-q")
+		writeFile(t, filepath.Join(root, "file"), "content")
+		gitCmd(t, root, "add", ".")
+		gitCmd(t, root, "commit", "-q", "-m", "first")
+
+		_, err := Fs(root, "does-not-exist")
+		require.Error(t, err)
+
+		_, err = Fs(root, "")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,202 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jsonnet
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/printer"
+	"github.com/ksonnet/ksonnet/pkg/docparser"
+	"github.com/pkg/errors"
+)
+
+// UnsupportedFormatError is returned by Format when the printer is unable to
+// format a snippet without changing more than its layout.
+type UnsupportedFormatError struct {
+	Filename string
+	Reason   string
+}
+
+func (e *UnsupportedFormatError) Error() string {
+	return fmt.Sprintf("formatting %s %s", e.Filename, e.Reason)
+}
+
+// Format formats a jsonnet snippet with the same printer ksonnet uses when it
+// writes jsonnet. The printer only keeps comments which are directly above an
+// object field, and it does not print all nodes (e.g. text blocks) in a way it
+// can read back unchanged. In these cases, or if the formatted snippet does not
+// parse to the same AST as the original, Format returns an
+// UnsupportedFormatError rather than change the snippet.
+func Format(filename, src string) (string, error) {
+	out, err := formatOnce(filename, src)
+	if err != nil {
+		return "", err
+	}
+
+	srcNode, err := ParseNode(filename, src)
+	if err != nil {
+		return "", err
+	}
+
+	outNode, err := ParseNode(filename, out)
+	if err != nil {
+		return "", &UnsupportedFormatError{Filename: filename, Reason: "would not parse"}
+	}
+
+	if !equalNodes(reflect.ValueOf(srcNode), reflect.ValueOf(outNode)) {
+		return "", &UnsupportedFormatError{Filename: filename, Reason: "would change its meaning"}
+	}
+
+	before, err := docparser.PlacedComments(filename, src)
+	if err != nil {
+		return "", err
+	}
+
+	after, err := docparser.PlacedComments(filename, out)
+	if err != nil {
+		return "", err
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		return "", &UnsupportedFormatError{Filename: filename, Reason: "would remove or move comments"}
+	}
+
+	again, err := formatOnce(filename, out)
+	if err != nil {
+		return "", err
+	}
+
+	if again != out {
+		return "", &UnsupportedFormatError{Filename: filename, Reason: "is not stable"}
+	}
+
+	return out, nil
+}
+
+func formatOnce(filename, src string) (string, error) {
+	node, err := ParseNode(filename, src)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err = printer.Fprint(&buf, node); err != nil {
+		return "", errors.Wrap(err, "format jsonnet")
+	}
+
+	return strings.TrimRight(buf.String(), "\n") + "\n", nil
+}
+
+// layoutFields are AST fields which only describe how a node is written, and
+// which may change when a snippet is formatted.
+var layoutFields = map[string]bool{
+	"NodeBase":       true,
+	"Comment":        true,
+	"Oneline":        true,
+	"TrailingComma":  true,
+	"OriginalString": true,
+	"BlockIndent":    true,
+}
+
+// equalNodes reports whether two ASTs are the same, ignoring layout.
+func equalNodes(a, b reflect.Value) bool {
+	if a.Kind() != b.Kind() {
+		return false
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		if a.Elem().Type() != b.Elem().Type() {
+			return false
+		}
+		return equalNodes(a.Elem(), b.Elem())
+	case reflect.Struct:
+		if a.Type() != b.Type() {
+			return false
+		}
+		if a.Type() == reflect.TypeOf(ast.ObjectField{}) && a.CanInterface() {
+			fa, fb := a.Interface().(ast.ObjectField), b.Interface().(ast.ObjectField)
+			nameA, okA := fieldName(&fa)
+			nameB, okB := fieldName(&fb)
+			if okA && okB {
+				if nameA != nameB {
+					return false
+				}
+				// Compare the rest of the fields with the names cleared.
+				for _, f := range []*ast.ObjectField{&fa, &fb} {
+					f.Kind, f.Id, f.Expr1 = ast.ObjectAssert, nil, nil
+				}
+				return equalNodes(reflect.ValueOf(fa), reflect.ValueOf(fb))
+			}
+		}
+		for i := 0; i < a.NumField(); i++ {
+			name := a.Type().Field(i).Name
+			if layoutFields[name] || (name == "Kind" && a.Type() == reflect.TypeOf(ast.LiteralString{})) {
+				continue
+			}
+			if !equalNodes(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalNodes(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	default:
+		return false
+	}
+}
+
+// fieldName returns the name of an object field with a fixed name, so `a: 1`
+// and `"a": 1` are treated as the same field.
+func fieldName(f *ast.ObjectField) (string, bool) {
+	switch f.Kind {
+	case ast.ObjectFieldID:
+		if f.Id != nil {
+			return string(*f.Id), true
+		}
+	case ast.ObjectFieldStr:
+		if s, ok := f.Expr1.(*ast.LiteralString); ok {
+			return s.Value, true
+		}
+	}
+
+	return "", false
+}
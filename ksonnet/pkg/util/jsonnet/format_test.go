@@ -0,0 +1,155 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jsonnet
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		name          string
+		src           string
+		expected      string
+		isErr         bool
+		isUnsupported bool
+	}{
+		{
+			name:     "in general",
+			src:      "{\n\"a\":   \"b\",\n    c: [1,2]\n}",
+			expected: "{\n  a: 'b',\n  c: [1, 2],\n}\n",
+		},
+		{
+			name:     "field comments",
+			src:      "{\n  // the a field\n  a: 1\n}",
+			expected: "{\n  // the a field\n  a: 1,\n}\n",
+		},
+		{
+			name:          "comment which would be removed",
+			src:           "// header\n{\n  a: 1,\n}\n",
+			isErr:         true,
+			isUnsupported: true,
+		},
+		{
+			name:          "comments in an empty object",
+			src:           "{\n  global: {\n    // replicas: 4,\n  },\n}\n",
+			isErr:         true,
+			isUnsupported: true,
+		},
+		{
+			name:          "text block which is not stable",
+			src:           "{\n  a: {\n    b:\n    |||\n    text\n  |||\n  },\n}\n",
+			isErr:         true,
+			isUnsupported: true,
+		},
+		{
+			name:     "parens in a product",
+			src:      "(1 + 2) * 3",
+			expected: "(1 + 2) * 3\n",
+		},
+		{
+			name:     "parens in a difference",
+			src:      "1 - (2 - 3)",
+			expected: "1 - (2 - 3)\n",
+		},
+		{
+			name:     "parens in a quotient",
+			src:      "1 / (2 * 3)",
+			expected: "1 / (2 * 3)\n",
+		},
+		{
+			name:     "parens in a negation",
+			src:      "-(1 - 2)",
+			expected: "-(1 - 2)\n",
+		},
+		{
+			name:     "parens in a logical not",
+			src:      "!(true && false)",
+			expected: "!(true && false)\n",
+		},
+		{
+			name:     "parens around a conditional",
+			src:      "(if true then 1 else 2) + 1",
+			expected: "(if true then 1 else 2) + 1\n",
+		},
+		{
+			name:     "parens in an object field",
+			src:      "{\n  a: (1 + 2) * 3,\n}\n",
+			expected: "{\n  a: (1 + 2) * 3,\n}\n",
+		},
+		{
+			name:          "trailing comment which would move",
+			src:           "{\n  a: 1, // about a\n  b: 2,\n}\n",
+			isErr:         true,
+			isUnsupported: true,
+		},
+		{
+			name:  "invalid jsonnet",
+			src:   "{",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Format("test.jsonnet", tc.src)
+			if tc.isErr {
+				require.Error(t, err)
+				_, ok := err.(*UnsupportedFormatError)
+				require.Equal(t, tc.isUnsupported, ok)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+
+			again, err := Format("test.jsonnet", got)
+			require.NoError(t, err)
+			require.Equal(t, got, again, "formatting should be idempotent")
+		})
+	}
+}
+
+func TestEqualNodes(t *testing.T) {
+	cases := []struct {
+		name     string
+		a        string
+		b        string
+		expected bool
+	}{
+		{name: "same layout", a: "1 + 2", b: "1 + 2", expected: true},
+		{name: "different layout", a: "{\"a\":   1}", b: "{\n  a: 1,\n}", expected: true},
+		{name: "different quotes", a: "'a'", b: "\"a\"", expected: true},
+		{name: "dropped parens", a: "(1 + 2) * 3", b: "1 + 2 * 3"},
+		{name: "different operator", a: "1 - 2", b: "1 + 2"},
+		{name: "different field name", a: "{a: 1}", b: "{b: 1}"},
+		{name: "different field visibility", a: "{a: 1}", b: "{a:: 1}"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := ParseNode("a.jsonnet", tc.a)
+			require.NoError(t, err)
+			b, err := ParseNode("b.jsonnet", tc.b)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expected, equalNodes(reflect.ValueOf(a), reflect.ValueOf(b)))
+		})
+	}
+}
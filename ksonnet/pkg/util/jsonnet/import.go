@@ -133,8 +133,10 @@ func (ai *AferoImporter) tryPath(dir, importedPath string) (found bool, content
 	return true, content, absPath, err
 }
 
-// Import imports a file.
-func (ai *AferoImporter) Import(dir, importedPath string) (contents jsonnet.Contents, foundHere string, err error) {
+// Import imports a file. Paths are relative to the importing file first, and
+// then to the import paths.
+func (ai *AferoImporter) Import(importedFrom, importedPath string) (contents jsonnet.Contents, foundHere string, err error) {
+	dir, _ := path.Split(importedFrom)
 	found, content, foundHere, err := ai.tryPath(dir, importedPath)
 	if err != nil {
 		return jsonnet.MakeContents(""), "", err
@@ -166,3 +168,32 @@ func AferoImporterOpt(fs afero.Fs) VMOpt {
 		vm.importer = &AferoImporter{Fs: fs}
 	}
 }
+
+// RecordImports configures a VM to call fn with the path of every file it
+// imports.
+func RecordImports(fn func(path string)) VMOpt {
+	return func(vm *VM) {
+		vm.importFns = append(vm.importFns, fn)
+	}
+}
+
+// recordingImporter calls functions with the path of every imported file.
+type recordingImporter struct {
+	jsonnet.Importer
+
+	fns []func(path string)
+}
+
+// Import imports a file and records its path.
+func (ri *recordingImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	contents, foundAt, err := ri.Importer.Import(importedFrom, importedPath)
+	if err != nil {
+		return contents, foundAt, err
+	}
+
+	for _, fn := range ri.fns {
+		fn(foundAt)
+	}
+
+	return contents, foundAt, nil
+}
@@ -88,3 +88,49 @@ func TestImport(t *testing.T) {
 	}
 
 }
+
+func TestAferoImporter_Import(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/app/components/local.libsonnet", []byte("local"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/app/lib/local.libsonnet", []byte("lib"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/app/lib/lib.libsonnet", []byte("lib"), 0644))
+
+	ai := &AferoImporter{Fs: fs}
+	ai.AddJPath("/app/lib")
+
+	cases := []struct {
+		name      string
+		path      string
+		foundHere string
+		isErr     bool
+	}{
+		{
+			name:      "relative to the importing file",
+			path:      "local.libsonnet",
+			foundHere: "/app/components/local.libsonnet",
+		},
+		{
+			name:      "in a jpath",
+			path:      "lib.libsonnet",
+			foundHere: "/app/lib/lib.libsonnet",
+		},
+		{
+			name:  "missing",
+			path:  "missing.libsonnet",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, foundHere, err := ai.Import("/app/components/component.jsonnet", tc.path)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.foundHere, foundHere)
+		})
+	}
+}
@@ -220,6 +220,8 @@ func nodeValue(node ast.Node) (interface{}, error) {
 		return DecodeValue(fmt.Sprint(t.Value))
 	case *ast.LiteralNull:
 		return nil, nil
+	case *ast.Parens:
+		return nodeValue(t.Inner)
 	}
 }
 
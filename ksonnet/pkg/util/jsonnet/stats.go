@@ -0,0 +1,210 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jsonnet
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-jsonnet"
+)
+
+var (
+	// statsMu guards collector.
+	statsMu sync.Mutex
+	// collector collects stats for every evaluation while it is set.
+	collector *statsCollector
+)
+
+// ImportStats are statistics about the imports of a file.
+type ImportStats struct {
+	Path string `json:"path"`
+	// Count is the number of times the file was imported.
+	Count int `json:"count"`
+	// Hits is the number of imports served from the import cache of a VM
+	// which had already imported the file.
+	Hits int `json:"hits"`
+	// Size is the size of the file in bytes.
+	Size int `json:"size"`
+}
+
+// Stats are statistics about Jsonnet evaluation.
+type Stats struct {
+	// VMs is the number of Jsonnet VMs created.
+	VMs int `json:"vms"`
+	// Elapsed is the time spent evaluating snippets.
+	Elapsed time.Duration `json:"elapsed"`
+	// Imports are the files which were imported, in path order.
+	Imports []ImportStats `json:"imports"`
+}
+
+// ImportCount returns the total number of imports.
+func (s *Stats) ImportCount() int {
+	var count int
+	for _, is := range s.Imports {
+		count += is.Count
+	}
+	return count
+}
+
+// ImportHits returns the total number of imports served from an import
+// cache.
+func (s *Stats) ImportHits() int {
+	var hits int
+	for _, is := range s.Imports {
+		hits += is.Hits
+	}
+	return hits
+}
+
+// ImportSize returns the total size of the imported files. Each file is
+// counted once.
+func (s *Stats) ImportSize() int {
+	var size int
+	for _, is := range s.Imports {
+		size += is.Size
+	}
+	return size
+}
+
+// CollectStats starts collecting statistics about Jsonnet evaluation. Stats
+// collected previously are discarded.
+func CollectStats() {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	collector = &statsCollector{
+		imports: make(map[string]*ImportStats),
+	}
+}
+
+// StopStats stops collecting statistics.
+func StopStats() {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	collector = nil
+}
+
+// CollectingStats reports if stats are being collected.
+func CollectingStats() bool {
+	return currentCollector() != nil
+}
+
+// CurrentStats returns the stats collected since CollectStats was called.
+func CurrentStats() Stats {
+	c := currentCollector()
+	if c == nil {
+		return Stats{Imports: make([]ImportStats, 0)}
+	}
+
+	return c.stats()
+}
+
+func currentCollector() *statsCollector {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	return collector
+}
+
+type statsCollector struct {
+	mu      sync.Mutex
+	vms     int
+	elapsed time.Duration
+	imports map[string]*ImportStats
+}
+
+func (c *statsCollector) addVM() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.vms++
+}
+
+func (c *statsCollector) addElapsed(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.elapsed += d
+}
+
+func (c *statsCollector) addImport(path string, size int, hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	is, ok := c.imports[path]
+	if !ok {
+		is = &ImportStats{Path: path, Size: size}
+		c.imports[path] = is
+	}
+
+	is.Count++
+	if hit {
+		is.Hits++
+	}
+}
+
+func (c *statsCollector) stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := Stats{
+		VMs:     c.vms,
+		Elapsed: c.elapsed,
+		Imports: make([]ImportStats, 0, len(c.imports)),
+	}
+
+	for _, is := range c.imports {
+		s.Imports = append(s.Imports, *is)
+	}
+
+	sort.Slice(s.Imports, func(i, j int) bool {
+		return s.Imports[i].Path < s.Imports[j].Path
+	})
+
+	return s
+}
+
+// statsImporter records the imports of a single VM.
+type statsImporter struct {
+	jsonnet.Importer
+
+	collector *statsCollector
+	seen      map[string]bool
+}
+
+func newStatsImporter(importer jsonnet.Importer, c *statsCollector) *statsImporter {
+	return &statsImporter{
+		Importer:  importer,
+		collector: c,
+		seen:      make(map[string]bool),
+	}
+}
+
+// Import imports a file and records it.
+func (si *statsImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	contents, foundAt, err := si.Importer.Import(importedFrom, importedPath)
+	if err != nil {
+		return contents, foundAt, err
+	}
+
+	si.collector.addImport(foundAt, len(contents.String()), si.seen[foundAt])
+	si.seen[foundAt] = true
+
+	return contents, foundAt, nil
+}
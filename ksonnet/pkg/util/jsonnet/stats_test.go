@@ -0,0 +1,69 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jsonnet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	lib := filepath.Join(dir, "lib.libsonnet")
+	require.NoError(t, ioutil.WriteFile(lib, []byte(`{a: 1}`), 0644))
+
+	evaluate := func() {
+		vm := NewVM()
+		vm.AddJPath(dir)
+
+		out, err := vm.EvaluateSnippet("snippet", `(import "lib.libsonnet").a + (import "lib.libsonnet").a`)
+		require.NoError(t, err)
+		assert.Equal(t, "2\n", out)
+	}
+
+	// stats aren't collected by default.
+	evaluate()
+	require.False(t, CollectingStats())
+	assert.Equal(t, Stats{Imports: []ImportStats{}}, CurrentStats())
+
+	CollectStats()
+	defer StopStats()
+
+	require.True(t, CollectingStats())
+
+	evaluate()
+	evaluate()
+
+	stats := CurrentStats()
+	assert.Equal(t, 2, stats.VMs)
+	assert.True(t, stats.Elapsed > 0)
+	assert.Equal(t, []ImportStats{{Path: lib, Count: 4, Hits: 2, Size: 6}}, stats.Imports)
+	assert.Equal(t, 4, stats.ImportCount())
+	assert.Equal(t, 2, stats.ImportHits())
+	assert.Equal(t, 6, stats.ImportSize())
+
+	// collecting again discards the stats collected previously.
+	CollectStats()
+	assert.Equal(t, 0, CurrentStats().VMs)
+}
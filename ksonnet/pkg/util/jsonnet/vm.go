@@ -51,6 +51,8 @@ type VM struct {
 
 	// importer is used by the jsonnet vm to resolve imports
 	importer Importer
+	// importFns are called with the path of every imported file.
+	importFns []func(path string)
 }
 
 // NewVM creates an instance of VM.
@@ -132,7 +134,20 @@ func (vm *VM) EvaluateSnippet(name, snippet string) (string, error) {
 	registerNativeFuncs(jvm)
 
 	vm.importer.AddJPath(vm.jPaths...)
-	jvm.Importer(vm.importer)
+
+	var importer jsonnet.Importer = vm.importer
+
+	stats := currentCollector()
+	if stats != nil {
+		stats.addVM()
+		importer = newStatsImporter(importer, stats)
+	}
+
+	if len(vm.importFns) > 0 {
+		importer = &recordingImporter{Importer: importer, fns: vm.importFns}
+	}
+
+	jvm.Importer(importer)
 
 	for k, v := range vm.extCodes {
 		jvm.ExtCode(k, v)
@@ -167,6 +182,10 @@ func (vm *VM) EvaluateSnippet(name, snippet string) (string, error) {
 	}
 
 	defer func() {
+		if stats != nil {
+			stats.addElapsed(time.Since(now))
+		}
+
 		fields["elapsed"] = time.Since(now)
 		logrus.WithFields(fields).Debug("jsonnet evaluate snippet")
 	}()
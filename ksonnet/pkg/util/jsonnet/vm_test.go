@@ -108,6 +108,22 @@ func TestVM_EvaluateSnippet_memory_importer(t *testing.T) {
 	require.Equal(t, "evaluated", out)
 }
 
+func TestVM_EvaluateSnippet_record_imports(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	test.StageFile(t, fs, "set-map.jsonnet", "/lib/set-map.jsonnet")
+
+	var imported []string
+	vm := NewVM(AferoImporterOpt(fs), RecordImports(func(path string) {
+		imported = append(imported, path)
+	}))
+	vm.AddJPath("/lib")
+
+	_, err := vm.EvaluateSnippet("snippet", `import "set-map.jsonnet"`)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"/lib/set-map.jsonnet"}, imported)
+}
+
 func Test_regexSubst(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -0,0 +1,130 @@
+// Copyright 2018 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jsonnet
+
+import (
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+)
+
+// Walk calls fn for node and all of its descendants. Children are not visited
+// if fn returns false.
+func Walk(node ast.Node, fn func(ast.Node) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+
+	for _, child := range Children(node) {
+		Walk(child, fn)
+	}
+}
+
+// Children returns the child nodes of a node as written in the source.
+func Children(node ast.Node) []ast.Node {
+	var nodes []ast.Node
+	add := func(children ...ast.Node) {
+		for _, child := range children {
+			if child != nil {
+				nodes = append(nodes, child)
+			}
+		}
+	}
+
+	switch n := node.(type) {
+	case *ast.Apply:
+		add(n.Target)
+		add(n.Arguments.Positional...)
+		for _, arg := range n.Arguments.Named {
+			add(arg.Arg)
+		}
+	case *ast.ApplyBrace:
+		add(n.Left, n.Right)
+	case *ast.Array:
+		add(n.Elements...)
+	case *ast.ArrayComp:
+		add(n.Body)
+		add(forSpecChildren(&n.Spec)...)
+	case *ast.Assert:
+		add(n.Cond, n.Message, n.Rest)
+	case *ast.Binary:
+		add(n.Left, n.Right)
+	case *ast.Conditional:
+		add(n.Cond, n.BranchTrue, n.BranchFalse)
+	case *ast.Error:
+		add(n.Expr)
+	case *ast.Function:
+		for _, p := range n.Parameters.Optional {
+			add(p.DefaultArg)
+		}
+		add(n.Body)
+	case *ast.Index:
+		add(n.Target, n.Index)
+	case *ast.InSuper:
+		add(n.Index)
+	case *ast.Local:
+		for _, bind := range n.Binds {
+			add(bind.Body)
+			if bind.Fun != nil {
+				add(bind.Fun)
+			}
+		}
+		add(n.Body)
+	case *ast.Object:
+		for _, f := range n.Fields {
+			add(fieldChildren(f)...)
+		}
+	case *astext.Object:
+		for _, f := range n.Fields {
+			add(fieldChildren(f.ObjectField)...)
+		}
+	case *ast.ObjectComp:
+		for _, f := range n.Fields {
+			add(fieldChildren(f)...)
+		}
+		add(forSpecChildren(&n.Spec)...)
+	case *ast.Parens:
+		add(n.Inner)
+	case *ast.Slice:
+		add(n.Target, n.BeginIndex, n.EndIndex, n.Step)
+	case *ast.SuperIndex:
+		add(n.Index)
+	case *ast.Unary:
+		add(n.Expr)
+	}
+
+	return nodes
+}
+
+func fieldChildren(f ast.ObjectField) []ast.Node {
+	nodes := []ast.Node{f.Expr1, f.Expr2, f.Expr3}
+	if f.Method != nil {
+		nodes = append(nodes, f.Method)
+	}
+
+	return nodes
+}
+
+func forSpecChildren(spec *ast.ForSpec) []ast.Node {
+	var nodes []ast.Node
+	for ; spec != nil; spec = spec.Outer {
+		nodes = append(nodes, spec.Expr)
+		for _, cond := range spec.Conditions {
+			nodes = append(nodes, cond.Expr)
+		}
+	}
+
+	return nodes
+}
@@ -0,0 +1,45 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package k8s
+
+var (
+	// clusterScopedKinds are built-in kinds which do not have a namespace.
+	clusterScopedKinds = map[string]bool{
+		"APIService":                     true,
+		"CertificateSigningRequest":      true,
+		"ClusterRole":                    true,
+		"ClusterRoleBinding":             true,
+		"ComponentStatus":                true,
+		"CustomResourceDefinition":       true,
+		"InitializerConfiguration":       true,
+		"MutatingWebhookConfiguration":   true,
+		"Namespace":                      true,
+		"Node":                           true,
+		"PersistentVolume":               true,
+		"PodSecurityPolicy":              true,
+		"PriorityClass":                  true,
+		"StorageClass":                   true,
+		"ValidatingWebhookConfiguration": true,
+		"VolumeAttachment":               true,
+	}
+)
+
+// IsClusterScoped returns true if kind is a built-in kind which does not have
+// a namespace. Scopes of custom resources are only known to the cluster, so
+// they are assumed to be namespaced.
+func IsClusterScoped(kind string) bool {
+	return clusterScopedKinds[kind]
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/emicklei/go-restful-swagger12"
 	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -159,9 +160,21 @@ func ClientForResource(pool dynamic.ClientPool, disco discovery.DiscoveryInterfa
 	return rc, nil
 }
 
+// UnknownKindError is returned when the server doesn't serve a kind.
+type UnknownKindError struct {
+	GroupVersionKind schema.GroupVersionKind
+}
+
+func (e *UnknownKindError) Error() string {
+	return fmt.Sprintf("Server is unable to handle %s", e.GroupVersionKind)
+}
+
 func serverResourceForGroupVersionKind(disco discovery.DiscoveryInterface, gvk schema.GroupVersionKind) (*metav1.APIResource, error) {
 	resources, err := disco.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
 	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, &UnknownKindError{GroupVersionKind: gvk}
+		}
 		return nil, err
 	}
 
@@ -172,5 +185,5 @@ func serverResourceForGroupVersionKind(disco discovery.DiscoveryInterface, gvk s
 		}
 	}
 
-	return nil, fmt.Errorf("Server is unable to handle %s", gvk)
+	return nil, &UnknownKindError{GroupVersionKind: gvk}
 }